@@ -20,26 +20,37 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"text/tabwriter"
+
 	"github.com/rabbitstack/fibratus/internal/bootstrap"
 	"github.com/rabbitstack/fibratus/pkg/config"
 	errs "github.com/rabbitstack/fibratus/pkg/errors"
 	"github.com/rabbitstack/fibratus/pkg/util/rest"
 	"github.com/spf13/cobra"
-	"os"
 )
 
 var Command = &cobra.Command{
 	Use:   "config",
 	Short: "Show runtime config",
-	RunE:  printConfig,
+	Long: `
+	Shows the configuration of the running Fibratus instance. With the --effective flag,
+	the configuration is resolved locally by merging the configuration file, the role
+	profile, and drop-in files. Each setting is annotated with the source of its value.
+	`,
+	Example: `  fibratus config --effective --config-profile domain-controller`,
+	RunE:    printConfig,
 }
 
 var (
 	// config command options
 	cfg = config.NewWithOpts(config.WithStats())
+
+	effective bool
 )
 
 func init() {
+	Command.PersistentFlags().BoolVar(&effective, "effective", false, "Prints the effective configuration merged from all layers along with the source of each setting")
 	cfg.MustViperize(Command)
 }
 
@@ -47,7 +58,10 @@ func printConfig(cmd *cobra.Command, args []string) error {
 	if err := bootstrap.InitConfigAndLogger(cfg); err != nil {
 		return err
 	}
-	body, err := rest.Get(rest.WithTransport(cfg.API.Transport), rest.WithURI("config"))
+	if effective {
+		return printEffective()
+	}
+	body, err := rest.Get(rest.WithAPIConfig(cfg.API), rest.WithURI("config"))
 	if err != nil {
 		return errs.ErrHTTPServerUnavailable(cfg.API.Transport, err)
 	}
@@ -57,3 +71,17 @@ func printConfig(cmd *cobra.Command, args []string) error {
 	}
 	return nil
 }
+
+// printEffective prints effective settings annotated with sources.
+func printEffective() error {
+	settings, err := cfg.Effective()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, s := range settings {
+		fmt.Fprintf(w, "%s\t%v\t%s\n", s.Key, s.Value, s.Source)
+	}
+	return w.Flush()
+}
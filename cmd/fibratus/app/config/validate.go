@@ -0,0 +1,153 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/rabbitstack/fibratus/pkg/config"
+	errs "github.com/rabbitstack/fibratus/pkg/errors"
+	"github.com/rabbitstack/fibratus/pkg/util/log"
+	"github.com/rabbitstack/fibratus/pkg/yara"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Validate the configuration file",
+	Long: `
+	Validates the configuration file along with the role profile and drop-in files
+	against the configuration schema, and checks the settings for semantic errors.
+	Issues are reported with the file, line, and column of the offending setting.
+	The command exits with a non-zero status if any errors are found.
+	`,
+	Example: `  fibratus config validate C:\Fibratus\Config\fibratus.yml --config-profile domain-controller`,
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    validate,
+}
+
+var (
+	// validate command options
+	output string
+	strict bool
+)
+
+func init() {
+	validateCmd.Flags().StringVarP(&output, "output", "o", "text", "Specifies the output format. Available formats are text and json")
+	validateCmd.Flags().BoolVar(&strict, "strict", false, "Treats warnings as errors")
+	Command.AddCommand(validateCmd)
+}
+
+func validate(cmd *cobra.Command, args []string) error {
+	if output != "text" && output != "json" {
+		return fmt.Errorf("unknown output format %s", output)
+	}
+	file := cfg.File()
+	if len(args) > 0 {
+		file = args[0]
+	}
+	if err := cfg.TryLoadFile(file); err != nil {
+		return err
+	}
+
+	issues, err := cfg.Check()
+	if err != nil {
+		return err
+	}
+	// the configuration is initialized after it is checked, since
+	// the initialization stops at the first invalid setting without
+	// reporting its location
+	if !hasErrors(issues) {
+		if err := cfg.Init(); err != nil {
+			return err
+		}
+		if err := log.InitFromConfig(cfg.Log, "fibratus.log"); err != nil {
+			return err
+		}
+		issues = append(issues, compileYaraRules()...)
+	}
+
+	var nerrs int
+	for _, issue := range issues {
+		if issue.Severity == config.SeverityError || strict {
+			nerrs++
+		}
+	}
+
+	if output == "json" {
+		if issues == nil {
+			issues = []config.Issue{}
+		}
+		b, err := json.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	} else {
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+		if len(issues) == 0 {
+			fmt.Printf("%s is valid\n", file)
+		}
+	}
+
+	if nerrs > 0 {
+		return fmt.Errorf("found %d error(s) in the configuration", nerrs)
+	}
+	return nil
+}
+
+// hasErrors determines if any of the issues has the error severity.
+func hasErrors(issues []config.Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == config.SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// compileYaraRules compiles YARA rules and reports compiler
+// errors located in rule files.
+func compileYaraRules() []config.Issue {
+	if !cfg.Yara.Enabled {
+		return nil
+	}
+	compileErrors, err := yara.Compile(cfg.Yara)
+	if err != nil {
+		if errors.Is(err, errs.ErrUnsupported) {
+			return []config.Issue{{Key: "yara.rule", Severity: config.SeverityWarning, Message: "yara rules weren't compiled: " + err.Error()}}
+		}
+		return []config.Issue{{Key: "yara.rule", Severity: config.SeverityError, Message: err.Error()}}
+	}
+	issues := make([]config.Issue, 0, len(compileErrors))
+	for _, e := range compileErrors {
+		issues = append(issues, config.Issue{
+			File:     e.Filename,
+			Line:     e.Line,
+			Key:      "yara.rule",
+			Severity: config.SeverityError,
+			Message:  e.Text,
+		})
+	}
+	return issues
+}
@@ -25,8 +25,10 @@ import (
 	"github.com/rabbitstack/fibratus/cmd/fibratus/app/list"
 	"github.com/rabbitstack/fibratus/cmd/fibratus/app/replay"
 	"github.com/rabbitstack/fibratus/cmd/fibratus/app/rules"
+	"github.com/rabbitstack/fibratus/cmd/fibratus/app/secrets"
 	"github.com/rabbitstack/fibratus/cmd/fibratus/app/service"
 	"github.com/rabbitstack/fibratus/cmd/fibratus/app/stats"
+	"github.com/rabbitstack/fibratus/cmd/fibratus/app/tail"
 	"github.com/spf13/cobra"
 	"runtime"
 )
@@ -59,9 +61,11 @@ func init() {
 	RootCmd.AddCommand(replay.Command)
 	RootCmd.AddCommand(service.Command)
 	RootCmd.AddCommand(stats.Command)
+	RootCmd.AddCommand(tail.Command)
 	RootCmd.AddCommand(config.Command)
 	RootCmd.AddCommand(list.Command)
 	RootCmd.AddCommand(rules.Command)
+	RootCmd.AddCommand(secrets.Command)
 	RootCmd.AddCommand(runCmd)
 	RootCmd.AddCommand(docsCmd)
 	RootCmd.AddCommand(versionCmd)
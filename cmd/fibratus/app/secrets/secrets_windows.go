@@ -0,0 +1,155 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secrets
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/secrets"
+	"github.com/spf13/cobra"
+	"golang.org/x/sys/windows"
+)
+
+var Command = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage secrets in the encrypted keystore",
+	Long: `
+	Manages secrets stored in the encrypted keystore. Secrets are referenced from
+	the configuration file with the keystore:// scheme, e.g. keystore://es-password.
+	The keystore is encrypted with the machine-scoped Data Protection API.
+	`,
+}
+
+var setCmd = &cobra.Command{
+	Use:     "set NAME",
+	Short:   "Store the secret read from the standard input",
+	Example: `  fibratus secrets set es-password`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    set,
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List secret names",
+	RunE:  list,
+}
+
+var removeCmd = &cobra.Command{
+	Use:   "remove NAME",
+	Short: "Remove the secret from the keystore",
+	Args:  cobra.ExactArgs(1),
+	RunE:  remove,
+}
+
+var cfg = config.NewWithOpts()
+
+func init() {
+	cfg.MustViperize(Command)
+
+	Command.AddCommand(setCmd)
+	Command.AddCommand(listCmd)
+	Command.AddCommand(removeCmd)
+}
+
+func set(cmd *cobra.Command, args []string) error {
+	ks, err := openKeystore()
+	if err != nil {
+		return err
+	}
+	value, err := readSecret(args[0])
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		return errors.New("secret value is empty")
+	}
+	if err := ks.Set(args[0], value); err != nil {
+		return err
+	}
+	if err := ks.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("Secret %s stored in %s\n", args[0], cfg.Keystore())
+	return nil
+}
+
+func list(cmd *cobra.Command, args []string) error {
+	ks, err := openKeystore()
+	if err != nil {
+		return err
+	}
+	for _, name := range ks.Names() {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+func remove(cmd *cobra.Command, args []string) error {
+	ks, err := openKeystore()
+	if err != nil {
+		return err
+	}
+	if err := ks.Remove(args[0]); err != nil {
+		return err
+	}
+	if err := ks.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("Secret %s removed from %s\n", args[0], cfg.Keystore())
+	return nil
+}
+
+// openKeystore opens the keystore whose path is given either
+// by the command line flag or the configuration file. Secret
+// references aren't resolved here, so the keystore can be
+// populated before the configuration starts referencing it.
+func openKeystore() (*secrets.Keystore, error) {
+	if err := cfg.TryLoadFile(cfg.File()); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return secrets.OpenKeystore(cfg.Keystore())
+}
+
+// readSecret reads the secret value from the standard input. If the
+// standard input is attached to the console, the echo is disabled
+// while the secret is typed.
+func readSecret(name string) (string, error) {
+	stdin := windows.Handle(os.Stdin.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(stdin, &mode); err == nil {
+		fmt.Printf("Enter the value for %s: ", name)
+		if err := windows.SetConsoleMode(stdin, mode&^windows.ENABLE_ECHO_INPUT); err != nil {
+			return "", err
+		}
+		defer func() {
+			_ = windows.SetConsoleMode(stdin, mode)
+			fmt.Println()
+		}()
+	}
+	value, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && value == "" {
+		return "", err
+	}
+	return strings.TrimRight(value, "\r\n"), nil
+}
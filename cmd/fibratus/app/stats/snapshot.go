@@ -0,0 +1,222 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/health"
+)
+
+// excludedVars are the variables published by the Go runtime that
+// are omitted from the snapshot
+var excludedVars = map[string]bool{"cmdline": true, "memstats": true}
+
+// snapshot is the point-in-time state of runtime metrics. Nested
+// expvar maps are flattened into dotted metric names, so keyed
+// metrics, such as per-rule match counters, are identified by
+// the metric name followed by the key.
+type snapshot struct {
+	Time    time.Time          `json:"time"`
+	Metrics map[string]any     `json:"metrics"`
+	Rates   map[string]float64 `json:"rates,omitempty"`
+	Health  *health.Report     `json:"health,omitempty"`
+}
+
+// newSnapshot builds the snapshot from the expvar endpoint response.
+func newSnapshot(body []byte, t time.Time) (*snapshot, error) {
+	var vars map[string]any
+	if err := json.Unmarshal(body, &vars); err != nil {
+		return nil, err
+	}
+	s := &snapshot{Time: t, Metrics: make(map[string]any)}
+	for name, v := range vars {
+		if excludedVars[name] {
+			continue
+		}
+		flatten(name, v, s.Metrics)
+	}
+	return s, nil
+}
+
+// loadSnapshot reads the snapshot from the file. The file either contains
+// the snapshot produced by the JSON output mode, or the raw expvar response.
+func loadSnapshot(path string) (*snapshot, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s snapshot
+	if err := json.Unmarshal(b, &s); err == nil && s.Metrics != nil {
+		return &s, nil
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := newSnapshot(b, fi.ModTime())
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid stats snapshot: %v", path, err)
+	}
+	return snap, nil
+}
+
+// flatten stores scalar values of the variable in the metrics map. Arrays,
+// such as histogram buckets, are skipped, but histogram counts and sums
+// are retained.
+func flatten(name string, v any, metrics map[string]any) {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, v := range val {
+			flatten(name+"."+k, v, metrics)
+		}
+	case []any:
+	case float64, string, bool:
+		metrics[name] = val
+	case nil:
+	default:
+		metrics[name] = fmt.Sprintf("%v", val)
+	}
+}
+
+// filter retains metrics starting with any of the given prefixes.
+func (s *snapshot) filter(prefixes []string) {
+	if len(prefixes) == 0 {
+		return
+	}
+	for name := range s.Metrics {
+		var keep bool
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(name, prefix) {
+				keep = true
+				break
+			}
+		}
+		if !keep {
+			delete(s.Metrics, name)
+		}
+	}
+}
+
+// names returns sorted metric names.
+func (s *snapshot) names() []string {
+	names := make([]string, 0, len(s.Metrics))
+	for name := range s.Metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// computeRates computes per-second rates of numeric metrics
+// that changed since the previous snapshot.
+func (s *snapshot) computeRates(prev *snapshot) {
+	if prev == nil {
+		return
+	}
+	elapsed := s.Time.Sub(prev.Time).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	s.Rates = make(map[string]float64)
+	for name, v := range s.Metrics {
+		n, ok := v.(float64)
+		if !ok {
+			continue
+		}
+		p, ok := prev.Metrics[name].(float64)
+		if !ok {
+			continue
+		}
+		s.Rates[name] = (n - p) / elapsed
+	}
+}
+
+// change represents the difference of the metric value between two snapshots.
+type change struct {
+	Old   any     `json:"old"`
+	New   any     `json:"new"`
+	Delta float64 `json:"delta,omitempty"`
+	Rate  float64 `json:"rate,omitempty"`
+}
+
+// diff is the difference between two snapshots.
+type diff struct {
+	From    time.Time          `json:"from"`
+	To      time.Time          `json:"to"`
+	Changes map[string]*change `json:"changes"`
+}
+
+// diffSnapshots returns metrics that were added, removed,
+// or changed their values between two snapshots.
+func diffSnapshots(from, to *snapshot) *diff {
+	d := &diff{From: from.Time, To: to.Time, Changes: make(map[string]*change)}
+	elapsed := to.Time.Sub(from.Time).Seconds()
+	for name, v := range to.Metrics {
+		old, ok := from.Metrics[name]
+		if ok && old == v {
+			continue
+		}
+		c := &change{Old: old, New: v}
+		n, isNum := v.(float64)
+		o, wasNum := old.(float64)
+		if isNum && (wasNum || !ok) {
+			c.Delta = n - o
+			if elapsed > 0 {
+				c.Rate = c.Delta / elapsed
+			}
+		}
+		d.Changes[name] = c
+	}
+	for name, old := range from.Metrics {
+		if _, ok := to.Metrics[name]; !ok {
+			d.Changes[name] = &change{Old: old}
+		}
+	}
+	return d
+}
+
+// names returns sorted names of changed metrics.
+func (d *diff) names() []string {
+	names := make([]string, 0, len(d.Changes))
+	for name := range d.Changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// group returns the group of the metric determined by
+// the given number of leading name segments.
+func group(name string, depth int) string {
+	if depth <= 0 {
+		return ""
+	}
+	segments := strings.SplitN(name, ".", depth+1)
+	if len(segments) <= depth {
+		// the metric name is the group itself
+		return strings.Join(segments[:len(segments)-1], ".")
+	}
+	return strings.Join(segments[:depth], ".")
+}
@@ -19,14 +19,21 @@
 package stats
 
 import (
+	"context"
 	"encoding/json"
-	"github.com/rabbitstack/fibratus/internal/bootstrap"
+	"errors"
+	"fmt"
+	"math"
 	"os"
-	"reflect"
+	"os/signal"
+	"strconv"
+	"time"
 
 	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/rabbitstack/fibratus/internal/bootstrap"
 	"github.com/rabbitstack/fibratus/pkg/config"
 	errs "github.com/rabbitstack/fibratus/pkg/errors"
+	"github.com/rabbitstack/fibratus/pkg/health"
 	"github.com/rabbitstack/fibratus/pkg/util/rest"
 	"github.com/spf13/cobra"
 )
@@ -34,124 +41,265 @@ import (
 var Command = &cobra.Command{
 	Use:   "stats",
 	Short: "Show runtime stats",
-	RunE:  stats,
+	Long: `
+	Shows runtime metrics exposed by the running Fibratus instance. Metrics are
+	grouped by the name prefix. In watch mode, metrics are refreshed periodically
+	along with per-second rates. The JSON output can be saved and later compared
+	with another snapshot or live metrics.
+	`,
+	Example: `  fibratus stats --prefix aggregator --prefix eventsource
+  fibratus stats --watch --interval 2s
+  fibratus stats --output json > before.json
+  fibratus stats --diff before.json`,
+	RunE: stats,
 }
 
-var cfg = config.NewWithOpts(config.WithStats())
+var (
+	// stats command options
+	cfg = config.NewWithOpts(config.WithStats())
+
+	output     string
+	prefixes   []string
+	groupDepth int
+	watch      bool
+	interval   time.Duration
+	diffs      []string
+)
+
+// output formats
+const (
+	tableOutput = "table"
+	jsonOutput  = "json"
+)
+
+// clearScreen moves the cursor to the top left corner and clears the screen
+const clearScreen = "\033[H\033[2J"
 
 func init() {
+	Command.PersistentFlags().StringVarP(&output, "output", "o", tableOutput, "Output format. Possible values are table and json")
+	Command.PersistentFlags().StringSliceVar(&prefixes, "prefix", nil, "Shows only metrics whose names start with any of the given prefixes")
+	Command.PersistentFlags().IntVar(&groupDepth, "group-depth", 1, "Number of leading name segments used to group metrics. Zero disables grouping")
+	Command.PersistentFlags().BoolVarP(&watch, "watch", "w", false, "Refreshes metrics periodically and shows per-second rates")
+	Command.PersistentFlags().DurationVar(&interval, "interval", time.Second, "Refresh interval in watch mode")
+	Command.PersistentFlags().StringSliceVar(&diffs, "diff", nil, "Compares two snapshots saved with the JSON output. If one snapshot is given, it is compared with live metrics")
 	cfg.MustViperize(Command)
 }
 
-// Stats stores runtime statistics that are retrieved from the expvar endpoint.
-type Stats struct {
-	AggregatorBatchEvents               int            `json:"aggregator.batch.events"`
-	AggregatorFlushesCount              int            `json:"aggregator.flushes.count"`
-	AggregatorEventErrors               int            `json:"aggregator.event.errors"`
-	AggregatorTransformerErrors         map[string]int `json:"aggregator.transformer.errors"`
-	AggregatorWorkerClientPublishErrors int            `json:"aggregator.worker.client.publish.errors"`
-	FilamentDictErrors                  int            `json:"filament.dict.errors"`
-	FilamentEventBatchFlushes           int            `json:"filament.event.batch.flushes"`
-	FilamentEventErrors                 map[string]int `json:"filament.event.errors"`
-	FilamentEventProcessErrors          int            `json:"filament.event.process.errors"`
-	FilterAccessorErrors                map[string]int `json:"filter.accessor.errors"`
-	FsFileObjectHandleHits              int            `json:"fs.file.object.handle.hits"`
-	FsFileObjectMisses                  int            `json:"fs.file.object.misses"`
-	FsFileReleases                      int            `json:"fs.file.releases"`
-	FsTotalRundownFiles                 int            `json:"fs.total.rundown.files"`
-	HandleDeferredEvictions             int            `json:"handle.deferred.evictions"`
-	HandleNameQueryFailures             map[string]int `json:"handle.name.query.failures"`
-	HandleSnapshotCount                 int            `json:"handle.snapshot.count"`
-	HandleSnapshotBytes                 int            `json:"handle.snapshot.bytes"`
-	HandleTypesCount                    int            `json:"handle.types.count"`
-	HandleTypeNameMisses                int            `json:"handle.type.name.misses"`
-	HandleWaitTimeouts                  int            `json:"handle.wait.timeouts"`
-	HostnameErrors                      map[string]int `json:"hostname.errors"`
-	CapFlusherErrors                    map[string]int `json:"cap.flusher.errors"`
-	CapHandleWriteErrors                int            `json:"cap.handle.write.errors"`
-	CapEventUnmarshalErrors             int            `json:"cap.event.unmarshal.errors"`
-	CapEventWriteErrors                 int            `json:"cap.event.write.errors"`
-	CapEventSourceConsumerErrors        int            `json:"cap.eventsource.consumer.errors"`
-	CapOverflowErrors                   int            `json:"cap.overflow.errors"`
-	CapReadBytes                        int            `json:"cap.read.bytes"`
-	CapReadEvents                       int            `json:"cap.read.events"`
-	CapReaderDroppedByFilter            int            `json:"cap.reader.dropped.by.filter"`
-	CapReaderHandleUnmarshalErrors      int            `json:"cap.reader.handle.unmarshal.errors"`
-	EventProcessorFailures              int            `json:"event.processor.failures"`
-	EventSeqInitErrors                  map[string]int `json:"event.seq.init.errors"`
-	EventSeqStoreErrors                 int            `json:"event.seq.store.errors"`
-	EventTimestampUnmarshalErrors       int            `json:"event.timestamp.unmarshal.errors"`
-	EventSourceBuffersRead              int            `json:"eventsource.buffers.read"`
-	EventSourceEventsEnqueued           int            `json:"eventsource.events.enqueued"`
-	EventSourceEventsDequeued           int            `json:"eventsource.events.dequeued"`
-	EventSourceUnknownEvents            int            `json:"eventsource.events.unknown"`
-	EventSourceEventsProcessed          int            `json:"eventsource.events.processed"`
-	EventSourceExcludedEvents           int            `json:"eventsource.excluded.events"`
-	EventSourceEventsFailures           map[string]int `json:"eventsource.events.failures"`
-	LoggerErrors                        map[string]int `json:"logger.errors"`
-	OutputAMQPChannelFailures           int            `json:"output.amqp.channel.failures"`
-	OutputAMQPConnectionFailures        int            `json:"output.amqp.connection.failures"`
-	OutputAMQPPublishErrors             int            `json:"output.amqp.publish.errors"`
-	OutputConsoleErrors                 int            `json:"output.console.errors"`
-	OutputNullBlackholeEvents           int            `json:"output.null.blackhole.events"`
-	PeSkippedImages                     int            `json:"pe.skipped.images"`
-	PeDirectoryParseErrors              int            `json:"pe.directory.parse.errors"`
-	PeVersionResourcesParseErrors       int            `json:"pe.version.resources.parse.errors"`
-	ProcessCount                        int            `json:"process.count"`
-	ProcessModuleCount                  int            `json:"process.module.count"`
-	ProcessLookupFailureCount           map[int]int    `json:"process.lookup.failure.count"`
-	ProcessPebReadErrors                int            `json:"process.peb.read.errors"`
-	ProcessReaped                       int            `json:"process.reaped"`
-	ProcessThreadCount                  int            `json:"process.thread.count"`
-	RegistryKcbCount                    int            `json:"registry.kcb.count"`
-	RegistryKcbMisses                   int            `json:"registry.kcb.misses"`
-	RegistryKeyHandleHits               int            `json:"registry.key.handle.hits"`
-	RegistryUnknownKeysCount            int            `json:"registry.unknown.keys.count"`
-	StackwalkEnqueued                   int            `json:"stackwalk.enqueued"`
-	StackwalkFlushes                    int            `json:"stackwalk.flushes"`
-	StackwalkFlushesProcs               map[string]int `json:"stackwalk.flushes.procs"`
-	StackwalkFlushesEvents              map[string]int `json:"stackwalk.flushes.events"`
-	YaraImageScans                      int            `json:"yara.image.scans"`
-	YaraProcScans                       int            `json:"yara.proc.scans"`
-	YaraRuleMatches                     int            `json:"yara.rule.matches"`
-}
-
 func stats(cmd *cobra.Command, args []string) error {
+	if output != tableOutput && output != jsonOutput {
+		return fmt.Errorf("unknown output format: %s", output)
+	}
+	if len(diffs) > 2 {
+		return errors.New("at most two snapshots can be compared")
+	}
 	if err := bootstrap.InitConfigAndLogger(cfg); err != nil {
 		return err
 	}
+
+	switch {
+	case len(diffs) > 0:
+		return diffStats()
+	case watch:
+		return watchStats()
+	default:
+		snap, err := fetchSnapshot(true)
+		if err != nil {
+			return err
+		}
+		return render(snap)
+	}
+}
+
+// fetchSnapshot retrieves runtime metrics from the expvar endpoint
+// and optionally the health report of agent components.
+func fetchSnapshot(withHealth bool) (*snapshot, error) {
 	c := cfg.API
-	body, err := rest.Get(rest.WithTransport(c.Transport), rest.WithURI("debug/vars"))
+	body, err := rest.Get(rest.WithAPIConfig(c), rest.WithURI("debug/vars"))
 	if err != nil {
-		return errs.ErrHTTPServerUnavailable(c.Transport, err)
+		return nil, errs.ErrHTTPServerUnavailable(c.Transport, err)
 	}
-	var stats Stats
-	if err := json.Unmarshal(body, &stats); err != nil {
-		return err
+	snap, err := newSnapshot(body, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	snap.filter(prefixes)
+	if !withHealth {
+		return snap, nil
 	}
 
-	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"Name", "Value"})
-	t.SetStyle(table.StyleLight)
+	body, err = rest.Get(rest.WithAPIConfig(c), rest.WithURI("healthz"))
+	if err != nil {
+		return nil, errs.ErrHTTPServerUnavailable(c.Transport, err)
+	}
+	// the health section is omitted if the
+	// report served by /healthz can't be decoded
+	var report health.Report
+	if err := json.Unmarshal(body, &report); err == nil {
+		snap.Health = &report
+	}
+	return snap, nil
+}
 
-	typ := reflect.TypeOf(stats)
-	val := reflect.ValueOf(stats)
+// watchStats periodically refreshes metrics until interrupted.
+func watchStats() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
 
-	for i := 0; i < typ.NumField(); i++ {
-		f := typ.Field(i)
-		tag := f.Tag.Get("json")
+	if interval < time.Millisecond*100 {
+		interval = time.Millisecond * 100
+	}
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
 
-		if tag == "" {
-			continue
+	var prev *snapshot
+	for {
+		snap, err := fetchSnapshot(output == tableOutput)
+		if err != nil {
+			return err
+		}
+		snap.computeRates(prev)
+		if output == tableOutput {
+			fmt.Print(clearScreen)
 		}
-		if !val.Field(i).CanInterface() {
-			continue
+		if err := render(snap); err != nil {
+			return err
+		}
+		prev = snap
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick.C:
 		}
-		t.AppendRow(table.Row{tag, val.Field(i).Interface()})
 	}
+}
 
+// diffStats compares two snapshots, or the snapshot with live metrics.
+func diffStats() error {
+	from, err := loadSnapshot(diffs[0])
+	if err != nil {
+		return err
+	}
+	from.filter(prefixes)
+	var to *snapshot
+	if len(diffs) == 2 {
+		to, err = loadSnapshot(diffs[1])
+		if err != nil {
+			return err
+		}
+		to.filter(prefixes)
+	} else {
+		to, err = fetchSnapshot(false)
+		if err != nil {
+			return err
+		}
+	}
+
+	d := diffSnapshots(from, to)
+	if output == jsonOutput {
+		return json.NewEncoder(os.Stdout).Encode(d)
+	}
+
+	t := newTable()
+	t.AppendHeader(table.Row{"Name", "Old", "New", "Delta", "Rate/s"})
+	var last string
+	for i, name := range d.names() {
+		if g := group(name, groupDepth); g != last && i > 0 {
+			t.AppendSeparator()
+		}
+		last = group(name, groupDepth)
+		c := d.Changes[name]
+		row := table.Row{name, formatValue(c.Old), formatValue(c.New), "", ""}
+		if _, ok := c.New.(float64); ok {
+			row[3], row[4] = formatNumber(c.Delta), formatNumber(c.Rate)
+		}
+		t.AppendRow(row)
+	}
+	fmt.Printf("Changes between %s and %s (%v)\n", d.From.Format(time.RFC3339), d.To.Format(time.RFC3339), d.To.Sub(d.From).Round(time.Second))
+	t.Render()
+
+	return nil
+}
+
+// render prints the snapshot in the selected output format.
+func render(snap *snapshot) error {
+	if output == jsonOutput {
+		return json.NewEncoder(os.Stdout).Encode(snap)
+	}
+
+	t := newTable()
+	if snap.Rates != nil {
+		t.AppendHeader(table.Row{"Name", "Value", "Rate/s"})
+	} else {
+		t.AppendHeader(table.Row{"Name", "Value"})
+	}
+	var last string
+	for i, name := range snap.names() {
+		if g := group(name, groupDepth); g != last && i > 0 {
+			t.AppendSeparator()
+		}
+		last = group(name, groupDepth)
+		row := table.Row{name, formatValue(snap.Metrics[name])}
+		if snap.Rates != nil {
+			rate, ok := snap.Rates[name]
+			if ok {
+				row = append(row, formatNumber(rate))
+			} else {
+				row = append(row, "")
+			}
+		}
+		t.AppendRow(row)
+	}
 	t.Render()
 
+	if snap.Health != nil {
+		renderHealth(*snap.Health)
+	}
+
 	return nil
 }
+
+// renderHealth prints the condensed health summary of agent components.
+func renderHealth(report health.Report) {
+	readiness := "ready"
+	if !report.Ready {
+		readiness = "not ready"
+	}
+	fmt.Printf("\nHealth: %s (%s)\n", report.Status, readiness)
+
+	t := newTable()
+	t.AppendHeader(table.Row{"Component", "Status", "Ready", "Message"})
+	for _, comp := range report.Components {
+		t.AppendRow(table.Row{comp.Name, comp.Status, comp.Ready, comp.Message})
+	}
+	t.Render()
+}
+
+func newTable() table.Writer {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleLight)
+	return t
+}
+
+// formatValue formats the metric value. Numbers are printed
+// without the exponent and trailing zeros.
+func formatValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "-"
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// formatNumber formats deltas and rates. Fractional
+// numbers are rounded to two decimals.
+func formatNumber(n float64) string {
+	if n == math.Trunc(n) {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(n, 'f', 2, 64)
+}
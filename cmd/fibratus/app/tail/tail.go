@@ -0,0 +1,115 @@
+/*
+ * Copyright 2019-2020 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/rabbitstack/fibratus/internal/bootstrap"
+	"github.com/rabbitstack/fibratus/pkg/config"
+	errs "github.com/rabbitstack/fibratus/pkg/errors"
+	"github.com/rabbitstack/fibratus/pkg/util/rest"
+	"github.com/spf13/cobra"
+)
+
+var Command = &cobra.Command{
+	Use:   "tail",
+	Short: "Stream live events from the running instance",
+	Long: `
+	Streams live events from the running Fibratus instance. Only the events that satisfy
+	the filter expression are delivered. The filter is evaluated on the server side, so
+	the instance doesn't need to be reconfigured.
+	`,
+	Example: `  fibratus tail --filter "evt.name = 'CreateProcess' and ps.name = 'cmd.exe'"`,
+	RunE:    tail,
+}
+
+var (
+	// tail command options
+	cfg = config.NewWithOpts(config.WithStats())
+
+	filter string
+	rate   float64
+	pretty bool
+)
+
+func init() {
+	Command.PersistentFlags().StringVar(&filter, "filter", "", "Filter expression that events have to satisfy in order to be streamed")
+	Command.PersistentFlags().Float64Var(&rate, "rate", 0, "Maximum number of events per second streamed to the client. The server enforces its own limit")
+	Command.PersistentFlags().BoolVar(&pretty, "pretty", false, "Indicates if events are pretty-printed")
+	cfg.MustViperize(Command)
+}
+
+func tail(cmd *cobra.Command, args []string) error {
+	if err := bootstrap.InitConfigAndLogger(cfg); err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	query := url.Values{}
+	if filter != "" {
+		query.Set("filter", filter)
+	}
+	if rate > 0 {
+		query.Set("rate", strconv.FormatFloat(rate, 'f', -1, 64))
+	}
+
+	handle := func(typ string, data []byte) error {
+		switch typ {
+		case "dropped":
+			var msg struct {
+				Dropped uint64 `json:"dropped"`
+			}
+			if err := json.Unmarshal(data, &msg); err == nil {
+				fmt.Fprintf(os.Stderr, "%d events dropped by the server\n", msg.Dropped)
+			}
+			return nil
+		default:
+			if pretty {
+				var b bytes.Buffer
+				if err := json.Indent(&b, data, "", "  "); err == nil {
+					data = b.Bytes()
+				}
+			}
+			_, err := fmt.Fprintln(os.Stdout, string(data))
+			return err
+		}
+	}
+
+	err := rest.Stream(ctx, handle, rest.WithAPIConfig(cfg.API), rest.WithURI("api/v1/events/stream"), rest.WithQuery(query))
+	var statusErr *rest.StatusError
+	switch {
+	case err == nil || ctx.Err() != nil:
+		return nil
+	case errors.As(err, &statusErr):
+		return err
+	default:
+		return errs.ErrHTTPServerUnavailable(cfg.API.Transport, err)
+	}
+}
@@ -27,16 +27,19 @@ import (
 	"github.com/rabbitstack/fibratus/pkg/aggregator"
 	"github.com/rabbitstack/fibratus/pkg/alertsender"
 	"github.com/rabbitstack/fibratus/pkg/api"
+	"github.com/rabbitstack/fibratus/pkg/api/stream"
 	"github.com/rabbitstack/fibratus/pkg/cap"
 	"github.com/rabbitstack/fibratus/pkg/config"
 	"github.com/rabbitstack/fibratus/pkg/filament"
 	"github.com/rabbitstack/fibratus/pkg/filter"
 	"github.com/rabbitstack/fibratus/pkg/fs"
 	"github.com/rabbitstack/fibratus/pkg/handle"
+	"github.com/rabbitstack/fibratus/pkg/history"
 	"github.com/rabbitstack/fibratus/pkg/ps"
 	"github.com/rabbitstack/fibratus/pkg/rules"
 	"github.com/rabbitstack/fibratus/pkg/symbolize"
 	"github.com/rabbitstack/fibratus/pkg/sys"
+	"github.com/rabbitstack/fibratus/pkg/tracing"
 	"github.com/rabbitstack/fibratus/pkg/util/multierror"
 	"github.com/rabbitstack/fibratus/pkg/util/signals"
 	"github.com/rabbitstack/fibratus/pkg/util/signature"
@@ -62,6 +65,8 @@ type App struct {
 	psnap      ps.Snapshotter
 	filament   filament.Filament
 	agg        *aggregator.BufferedAggregator
+	hub        *stream.Hub
+	history    *history.Buffer
 	writer     cap.Writer
 	reader     cap.Reader
 	signals    chan struct{}
@@ -235,6 +240,12 @@ func (f *App) Run(args []string) error {
 			}
 		}()
 	} else {
+		// start self-tracing before events are pushed to the queue
+		if cfg.Tracing.Enabled {
+			if err := tracing.Init(cfg.Tracing); err != nil {
+				return err
+			}
+		}
 		// register stack symbolizer
 		if cfg.EventSource.StackEnrichment {
 			f.symbolizer = symbolize.NewSymbolizer(symbolize.NewDebugHelpResolver(cfg), f.psnap, cfg, false)
@@ -256,6 +267,24 @@ func (f *App) Run(args []string) error {
 			}
 			f.evs.RegisterEventListener(scanner)
 		}
+		// register recent events buffer and live event streaming
+		// hub. If there are no other listeners, they agree to
+		// enqueue all events to retain the regular event flow
+		// to outputs. The buffer is registered after the rule
+		// engine, so alerts carry the events that preceded the
+		// rule match as context
+		enqueue := !f.evs.HasListeners()
+		if cfg.History.Enabled {
+			f.history = history.NewBuffer(cfg, f.psnap, enqueue)
+			f.evs.RegisterEventListener(f.history)
+			if f.engine != nil {
+				f.engine.RegisterEventHistory(f.history)
+			}
+		}
+		if cfg.API.IsManagementEnabled() {
+			f.hub = stream.NewHub(cfg, f.psnap, enqueue)
+			f.evs.RegisterEventListener(f.hub)
+		}
 		err = f.evs.Open(cfg)
 		if err != nil {
 			return multierror.Wrap(err, f.evs.Close())
@@ -274,7 +303,13 @@ func (f *App) Run(args []string) error {
 		}
 	}
 	// start the HTTP server
-	return api.StartServer(cfg)
+	return api.StartServer(
+		cfg,
+		api.WithRuleEngine(f.engine),
+		api.WithProcessSnapshotter(f.psnap),
+		api.WithStreamHub(f.hub),
+		api.WithEventHistory(f.history),
+	)
 }
 
 // WriteCapture writes the event stream to the capture file.
@@ -417,6 +452,9 @@ func (f *App) Shutdown() error {
 			errs = append(errs, err)
 		}
 	}
+	if err := tracing.Shutdown(); err != nil {
+		errs = append(errs, err)
+	}
 	if err := handle.CloseTimeout(); err != nil {
 		errs = append(errs, err)
 	}
@@ -38,6 +38,8 @@ import (
 // control will bootstrap the instrumentation engine based on eBPF.
 type EventSourceControl struct {
 	evs source.EventSource
+	// listeners is the number of registered event listeners
+	listeners int
 }
 
 func NewEventSourceControl(
@@ -71,4 +73,10 @@ func (s *EventSourceControl) SetFilter(f filter.Filter) {
 
 func (s *EventSourceControl) RegisterEventListener(lis event.Listener) {
 	s.evs.RegisterEventListener(lis)
+	s.listeners++
+}
+
+// HasListeners determines if any event listeners are registered.
+func (s *EventSourceControl) HasListeners() bool {
+	return s.listeners > 0
 }
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"expvar"
 	"fmt"
+	"sync/atomic"
 	"time"
 	"unsafe"
 
@@ -31,6 +32,8 @@ import (
 	"github.com/rabbitstack/fibratus/pkg/event"
 	"github.com/rabbitstack/fibratus/pkg/filter"
 	"github.com/rabbitstack/fibratus/pkg/handle"
+	"github.com/rabbitstack/fibratus/pkg/health"
+	hconfig "github.com/rabbitstack/fibratus/pkg/health/config"
 	"github.com/rabbitstack/fibratus/pkg/ps"
 	"github.com/rabbitstack/fibratus/pkg/source"
 	"github.com/rabbitstack/fibratus/pkg/sys/etw"
@@ -89,6 +92,10 @@ type EventSource struct {
 	filter    filter.Filter
 	listeners []event.Listener
 
+	// running is the number of traces processing events
+	running  atomic.Int32
+	activity *health.Activity
+
 	isClosed bool
 }
 
@@ -112,6 +119,7 @@ func NewEventSource(
 		psnap:      psnap,
 		hsnap:      hsnap,
 		listeners:  make([]event.Listener, 0),
+		activity:   health.NewActivity(),
 	}
 	return evs
 }
@@ -277,6 +285,7 @@ func (e *EventSource) Open(config *config.Config) error {
 
 		// Start event processing loop
 		errch := make(chan error)
+		e.running.Add(1)
 		go t.Process(errch)
 
 		go func(trace *Trace) {
@@ -284,6 +293,7 @@ func (e *EventSource) Open(config *config.Config) error {
 			case <-e.stop:
 				return
 			case err := <-errch:
+				e.running.Add(-1)
 				log.Infof("stopping [%s] trace processing", trace.Name)
 				if err != nil && !errors.Is(err, errs.ErrTraceCancelled) {
 					e.errs <- fmt.Errorf("unable to process %s trace: %v", trace.Name, err)
@@ -292,9 +302,38 @@ func (e *EventSource) Open(config *config.Config) error {
 		}(t)
 	}
 
+	health.Register("eventsource", e.health)
+
 	return nil
 }
 
+// health evaluates the event source health. The event source is
+// unhealthy if any trace stopped processing events, or no events
+// were consumed within the configured period.
+func (e *EventSource) health(c hconfig.Config) health.Component {
+	running, traces := int(e.running.Load()), len(e.traces)
+	idle := e.activity.Observe(eventsProcessed.Value() + buffersRead.Value())
+	comp := health.Component{
+		Status: health.Healthy,
+		Ready:  true,
+		Details: map[string]any{
+			"traces":           traces,
+			"running_traces":   running,
+			"events_processed": eventsProcessed.Value(),
+			"idle":             idle.Round(time.Second).String(),
+		},
+	}
+	switch {
+	case running < traces:
+		comp.Status = health.Unhealthy
+		comp.Message = fmt.Sprintf("%d of %d traces stopped processing events", traces-running, traces)
+	case c.EventsourceStall > 0 && idle > c.EventsourceStall:
+		comp.Status = health.Unhealthy
+		comp.Message = fmt.Sprintf("no events consumed in the last %v", idle.Round(time.Second))
+	}
+	return comp
+}
+
 // Close shutdowns all tracing sessions orderly. Firstly,
 // the buffers are flushed. Then, the trace is closed to
 // signal the event callback to stop consuming more events.
@@ -304,6 +343,8 @@ func (e *EventSource) Close() error {
 		return nil
 	}
 
+	health.Unregister("eventsource")
+
 	for _, consumer := range e.consumers {
 		if err := consumer.Close(); err != nil {
 			log.Warnf("couldn't close consumer: %v", err)
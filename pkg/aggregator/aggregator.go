@@ -21,12 +21,17 @@ package aggregator
 import (
 	"errors"
 	"expvar"
+	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers"
 	"github.com/rabbitstack/fibratus/pkg/alertsender"
 	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/health"
+	hconfig "github.com/rabbitstack/fibratus/pkg/health/config"
 	"github.com/rabbitstack/fibratus/pkg/outputs"
+	"github.com/rabbitstack/fibratus/pkg/tracing"
 	log "github.com/sirupsen/logrus"
 
 	// initialize outputs
@@ -36,6 +41,8 @@ import (
 	_ "github.com/rabbitstack/fibratus/pkg/outputs/eventlog"
 	_ "github.com/rabbitstack/fibratus/pkg/outputs/http"
 	_ "github.com/rabbitstack/fibratus/pkg/outputs/null"
+	_ "github.com/rabbitstack/fibratus/pkg/outputs/otlp"
+	_ "github.com/rabbitstack/fibratus/pkg/outputs/s3"
 
 	// initialize alert senders
 	_ "github.com/rabbitstack/fibratus/pkg/alertsender/mail"
@@ -70,13 +77,23 @@ type BufferedAggregator struct {
 	errsc   <-chan error
 	stop    chan struct{}
 	flusher *time.Ticker
+	// ticker that triggers flushing of rollup summaries
+	rollupFlusher *time.Ticker
 	// queue of inbound events
 	evts []*event.Event
+	// spans of sampled events waiting in the queue
+	spans []*tracing.Span
 	// work queue that forwarder passes to outputs
 	wq         queue
 	submitter  *submitter
 	transforms []transformers.Transformer
+	sampler    *sampler
+	rollup     *rollup
 	c          Config
+	// lastFlush stores the time of the last flush
+	lastFlush atomic.Int64
+	// lag is the delay between the oldest event occurrence in the batch and the batch flush
+	lag atomic.Int64
 }
 
 // NewBuffered creates a new instance of the event aggregator.
@@ -103,6 +120,18 @@ func NewBuffered(
 	}
 
 	var err error
+	agg.sampler, err = newSampler(aggConfig.Sampling)
+	if err != nil {
+		return nil, err
+	}
+	agg.rollup = newRollup(aggConfig.Rollup)
+	if aggConfig.Rollup.Enabled {
+		interval := aggConfig.Rollup.Interval
+		if interval < flushInterval {
+			interval = flushInterval
+		}
+		agg.rollupFlusher = time.NewTicker(interval)
+	}
 	agg.submitter, err = newSubmitter(agg.wq, outputConfig)
 	if err != nil {
 		return nil, err
@@ -117,6 +146,10 @@ func NewBuffered(
 		return nil, err
 	}
 
+	agg.lastFlush.Store(time.Now().UnixNano())
+	health.Register("aggregator", agg.health)
+	health.Register("queue", agg.queueHealth)
+
 	go agg.run()
 
 	return agg, nil
@@ -126,8 +159,17 @@ func NewBuffered(
 func (agg *BufferedAggregator) Stop() error {
 	agg.stop <- struct{}{}
 
-	// flush enqueued events
+	health.Unregister("aggregator")
+	health.Unregister("queue")
+	agg.submitter.unregisterHealth()
+
+	// flush enqueued events along with pending rollup summaries
+	for _, evt := range agg.rollup.flush() {
+		agg.transform(evt)
+		agg.evts = append(agg.evts, evt)
+	}
 	b := event.NewBatch(agg.evts...)
+	agg.endSpans(b.Len())
 	if b.Len() > 0 {
 		done := make(chan struct{}, 1)
 		go func() {
@@ -157,18 +199,37 @@ func (agg *BufferedAggregator) Stop() error {
 // run starts the aggregator loop. The aggregator receives event stream from the upstream channel, buffers
 // them to intermediate queue and dispatches batches to downstream worker queue.
 func (agg *BufferedAggregator) run() {
+	var rollupc <-chan time.Time
+	if agg.rollupFlusher != nil {
+		rollupc = agg.rollupFlusher.C
+	}
 	for {
 		select {
 		case <-agg.stop:
 			agg.flusher.Stop()
+			if agg.rollupFlusher != nil {
+				agg.rollupFlusher.Stop()
+			}
 			return
+		case <-rollupc:
+			// summaries are shipped along with the next batch
+			for _, evt := range agg.rollup.flush() {
+				agg.transform(evt)
+				agg.evts = append(agg.evts, evt)
+			}
 		case <-agg.flusher.C:
+			agg.sampler.prune()
+			now := time.Now()
+			agg.lastFlush.Store(now.UnixNano())
 			if len(agg.evts) == 0 {
+				agg.lag.Store(0)
 				continue
 			}
+			agg.lag.Store(int64(now.Sub(agg.evts[0].Timestamp)))
 			b := event.NewBatch(agg.evts...)
 			l := b.Len()
 			batchEvents.Add(l)
+			agg.endSpans(l)
 			// push the batch to the work queue
 			if l > 0 {
 				agg.wq <- b
@@ -177,18 +238,94 @@ func (agg *BufferedAggregator) run() {
 			// clear the queue
 			agg.evts = nil
 		case evt := <-agg.evtsc:
-			for _, transform := range agg.transforms {
-				err := transform.Transform(evt)
-				if err != nil {
-					transformerErrors.Add(err.Error(), 1)
-				}
+			eventsDequeued.Add(1)
+			span := tracing.Begin(evt.Seq, "aggregator.batch")
+			if !agg.sampler.keep(evt) {
+				span.SetString("outcome", "sampled")
+				span.End()
+				continue
+			}
+			if agg.rollup.add(evt) {
+				span.SetString("outcome", "rolled-up")
+				span.End()
+				continue
 			}
+			agg.transform(evt)
 			// push the event to the queue
 			agg.evts = append(agg.evts, evt)
-			eventsDequeued.Add(1)
+			if span != nil {
+				agg.spans = append(agg.spans, span)
+			}
 		case err := <-agg.errsc:
 			eventsErrors.Add(1)
 			log.Errorf("event processing failure: %v", err)
 		}
 	}
 }
+
+// transform applies all configured transformers to the event.
+func (agg *BufferedAggregator) transform(evt *event.Event) {
+	for _, transform := range agg.transforms {
+		err := transform.Transform(evt)
+		if err != nil {
+			transformerErrors.Add(err.Error(), 1)
+		}
+	}
+}
+
+// endSpans completes the spans of sampled events
+// that are flushed in the batch of the given size.
+func (agg *BufferedAggregator) endSpans(size int64) {
+	for _, span := range agg.spans {
+		span.SetString("outcome", "flushed")
+		span.SetInt("batch.size", size)
+		span.End()
+	}
+	agg.spans = nil
+}
+
+// health evaluates the aggregator health. The aggregator
+// is unhealthy if it didn't flush within the configured
+// period.
+func (agg *BufferedAggregator) health(c hconfig.Config) health.Component {
+	since := time.Since(time.Unix(0, agg.lastFlush.Load()))
+	comp := health.Component{
+		Status: health.Healthy,
+		Ready:  true,
+		Details: map[string]any{
+			"last_flush": time.Unix(0, agg.lastFlush.Load()),
+			"flushes":    flushesCount.Value(),
+		},
+	}
+	if c.AggregatorStall > 0 && since > c.AggregatorStall {
+		comp.Status = health.Unhealthy
+		comp.Message = fmt.Sprintf("no flush in the last %v", since.Round(time.Second))
+	}
+	return comp
+}
+
+// queueHealth evaluates the health of the event queue. The queue is
+// degraded if events are flushed with the delay exceeding the max queue
+// lag, or the inbound queue is full.
+func (agg *BufferedAggregator) queueHealth(c hconfig.Config) health.Component {
+	lag := time.Duration(agg.lag.Load())
+	backlog, capacity := len(agg.evtsc), cap(agg.evtsc)
+	comp := health.Component{
+		Status: health.Healthy,
+		Ready:  true,
+		Details: map[string]any{
+			"lag":      lag.String(),
+			"backlog":  backlog,
+			"capacity": capacity,
+		},
+	}
+	switch {
+	case c.MaxQueueLag > 0 && lag > c.MaxQueueLag:
+		comp.Status = health.Degraded
+		comp.Message = fmt.Sprintf("events are flushed with %v lag", lag.Round(time.Millisecond))
+	case capacity > 0 && backlog == capacity:
+		comp.Status = health.Degraded
+		comp.Message = "event queue is full"
+	}
+	return comp
+}
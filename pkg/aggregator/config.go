@@ -25,26 +25,50 @@ import (
 )
 
 const (
-	flushPeriod  = "aggregator.flush-period"
-	flushTimeout = "aggregator.flush-timeout"
+	flushPeriod    = "aggregator.flush-period"
+	flushTimeout   = "aggregator.flush-timeout"
+	rollupEnabled  = "aggregator.rollup.enabled"
+	rollupInterval = "aggregator.rollup.interval"
+	rollupEvents   = "aggregator.rollup.events"
 )
 
+// RollupConfig contains the settings for folding repeated events into summaries.
+type RollupConfig struct {
+	// Enabled indicates if the rollup mode is enabled.
+	Enabled bool `json:"aggregator.rollup.enabled" yaml:"aggregator.rollup.enabled"`
+	// Interval determines how often summary events are flushed.
+	Interval time.Duration `json:"aggregator.rollup.interval" yaml:"aggregator.rollup.interval"`
+	// Events contains the list of event names that are rolled up.
+	Events []string `json:"aggregator.rollup.events" yaml:"aggregator.rollup.events"`
+}
+
 // Config contains aggregator-specific configuration tweaks.
 type Config struct {
 	// FlushPeriod determines the period for flushing batches to outputs.
 	FlushPeriod time.Duration `json:"aggregator.flush-period" yaml:"aggregator.flush-period"`
 	// FlushTimeout represents the max time to wait before announcing failed flushing of enqueued events
 	FlushTimeout time.Duration `json:"aggregator.flush-timeout" yaml:"aggregator.flush-timeout"`
+	// Sampling contains the list of sampling policies applied to events before they are batched
+	Sampling []SamplingPolicy `json:"aggregator.sampling" yaml:"aggregator.sampling"`
+	// Rollup contains the settings for the event rollup mode
+	Rollup RollupConfig `json:"aggregator.rollup" yaml:"aggregator.rollup"`
 }
 
 // AddFlags registers persistent aggregator flags.
 func AddFlags(flags *pflag.FlagSet) {
 	flags.Duration(flushPeriod, time.Millisecond*200, "Determines the period for flushing batches to outputs")
 	flags.Duration(flushTimeout, time.Second*4, "Represents the max time to wait before announcing failed flushing of enqueued events on aggregator shutdown")
+	flags.Bool(rollupEnabled, false, "Indicates if repeated events are rolled up into summary events")
+	flags.Duration(rollupInterval, time.Second*30, "Determines the period for flushing summary events")
+	flags.StringSlice(rollupEvents, []string{"ReadFile", "WriteFile", "RecvTCPv4", "RecvTCPv6", "RecvUDPv4", "RecvUDPv6", "SendTCPv4", "SendTCPv6", "SendUDPv4", "SendUDPv6"}, "A list of event names that are rolled up into summary events")
 }
 
-// InitFromViper initializes aggregator flags from viper.
+// InitFromViper initializes aggregator flags from viper. Sampling
+// policies are decoded by the configuration loader.
 func (c *Config) InitFromViper(v *viper.Viper) {
 	c.FlushPeriod = v.GetDuration(flushPeriod)
 	c.FlushTimeout = v.GetDuration(flushTimeout)
+	c.Rollup.Enabled = v.GetBool(rollupEnabled)
+	c.Rollup.Interval = v.GetDuration(rollupInterval)
+	c.Rollup.Events = v.GetStringSlice(rollupEvents)
 }
@@ -0,0 +1,202 @@
+/*
+ * Copyright 2019-2020 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aggregator
+
+import (
+	"expvar"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/event/params"
+)
+
+var (
+	// rolledupEvents counts the number of events folded into summaries
+	rolledupEvents = expvar.NewInt("aggregator.rollup.events")
+	// rollupSummaries counts the number of emitted summary events
+	rollupSummaries = expvar.NewInt("aggregator.rollup.summaries")
+)
+
+// rollupKey groups events by process, event type and target.
+type rollupKey struct {
+	pid    uint32
+	typ    event.Type
+	target string
+}
+
+// summary accumulates the state of the rolled up events.
+type summary struct {
+	evt   *event.Event // the first event in the group
+	seq   uint64
+	count uint64
+	bytes uint64
+	first time.Time
+	last  time.Time
+}
+
+// rollup folds repeated events into summary records. Events are
+// grouped by the process identifier, event type, and the target,
+// which is the file path for file events, and the remote endpoint
+// for network events. Summaries are flushed on regular intervals
+// as synthetic events tagged with the rollup metadata key.
+type rollup struct {
+	mu        sync.Mutex
+	events    map[string]bool
+	summaries map[rollupKey]*summary
+}
+
+func newRollup(c RollupConfig) *rollup {
+	r := &rollup{
+		events:    make(map[string]bool),
+		summaries: make(map[rollupKey]*summary),
+	}
+	if !c.Enabled {
+		return r
+	}
+	for _, name := range c.Events {
+		r.events[name] = true
+	}
+	return r
+}
+
+// add folds the event into the summary. It returns false if the event
+// is not eligible for the rollup and should be forwarded to outputs.
+// Events that matched any of the rules are never rolled up.
+func (r *rollup) add(evt *event.Event) bool {
+	if !r.events[evt.Name] || evt.ContainsMeta(event.RuleNameKey) {
+		return false
+	}
+	target, ok := rollupTarget(evt)
+	if !ok {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := rollupKey{pid: evt.PID, typ: evt.Type, target: target}
+	s, ok := r.summaries[key]
+	if !ok {
+		s = &summary{evt: evt, first: evt.Timestamp}
+		r.summaries[key] = s
+	}
+	s.count++
+	s.bytes += rollupBytes(evt)
+	s.seq = evt.Seq
+	if evt.Timestamp.After(s.last) {
+		s.last = evt.Timestamp
+	}
+	if evt.Timestamp.Before(s.first) {
+		s.first = evt.Timestamp
+	}
+	rolledupEvents.Add(1)
+
+	return true
+}
+
+// flush produces summary events and resets the rollup state.
+func (r *rollup) flush() []*event.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.summaries) == 0 {
+		return nil
+	}
+	evts := make([]*event.Event, 0, len(r.summaries))
+	for key, s := range r.summaries {
+		evts = append(evts, s.event())
+		delete(r.summaries, key)
+	}
+	rollupSummaries.Add(int64(len(evts)))
+	return evts
+}
+
+// event builds the synthetic summary event from the first
+// event in the group and the accumulated counters.
+func (s *summary) event() *event.Event {
+	e := &event.Event{
+		Seq:         s.seq,
+		Timestamp:   s.last,
+		PID:         s.evt.PID,
+		Tid:         s.evt.Tid,
+		Type:        s.evt.Type,
+		CPU:         s.evt.CPU,
+		Name:        s.evt.Name,
+		Category:    s.evt.Category,
+		Description: s.evt.Description,
+		Host:        s.evt.Host,
+		Params:      make(event.Params),
+		Metadata:    make(map[event.MetadataKey]any),
+		PS:          s.evt.PS,
+	}
+	for name, par := range s.evt.Params {
+		e.Params[name] = par
+	}
+	for k, v := range s.evt.Metadata {
+		e.Metadata[k] = v
+	}
+	e.AppendParam(params.RollupCount, params.Uint64, s.count)
+	e.AppendParam(params.RollupBytes, params.Uint64, s.bytes)
+	e.AppendParam(params.RollupFirstTimestamp, params.Time, s.first)
+	e.AppendParam(params.RollupLastTimestamp, params.Time, s.last)
+	e.AddMeta(event.RollupKey, true)
+	return e
+}
+
+// rollupTarget returns the grouping target of the event. For
+// file events, this is the file path, and for network events
+// the target is the remote endpoint.
+func rollupTarget(evt *event.Event) (string, bool) {
+	switch evt.Category {
+	case event.File:
+		path := evt.GetParamAsString(params.FilePath)
+		return path, path != ""
+	case event.Net:
+		ipParam, portParam := params.NetDIP, params.NetDport
+		switch evt.Type {
+		case event.RecvTCPv4, event.RecvTCPv6, event.RecvUDPv4, event.RecvUDPv6, event.AcceptTCPv4, event.AcceptTCPv6:
+			ipParam, portParam = params.NetSIP, params.NetSport
+		}
+		ip, err := evt.Params.GetIP(ipParam)
+		if err != nil {
+			return "", false
+		}
+		port, _ := evt.Params.GetUint16(portParam)
+		return net.JoinHostPort(ip.String(), strconv.Itoa(int(port))), true
+	case event.Registry:
+		key := evt.GetParamAsString(params.RegPath)
+		return key, key != ""
+	}
+	return "", false
+}
+
+// rollupBytes returns the number of bytes transferred by the event.
+func rollupBytes(evt *event.Event) uint64 {
+	switch evt.Category {
+	case event.File:
+		size, _ := evt.Params.GetUint32(params.FileIoSize)
+		return uint64(size)
+	case event.Net:
+		size, _ := evt.Params.GetUint32(params.NetSize)
+		return uint64(size)
+	}
+	return 0
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2019-2020 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aggregator
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/event/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollup(t *testing.T) {
+	r := newRollup(RollupConfig{Enabled: true, Events: []string{"ReadFile", "RecvTCPv4"}})
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		evt := &event.Event{
+			Seq:       uint64(i),
+			Type:      event.ReadFile,
+			Name:      "ReadFile",
+			Category:  event.File,
+			PID:       1234,
+			Timestamp: now.Add(time.Second * time.Duration(i)),
+			Params: event.Params{
+				params.FilePath:   {Name: params.FilePath, Type: params.UnicodeString, Value: `C:\Windows\System32\kernel32.dll`},
+				params.FileIoSize: {Name: params.FileIoSize, Type: params.Uint32, Value: uint32(4096)},
+			},
+			Metadata: make(map[event.MetadataKey]any),
+		}
+		assert.True(t, r.add(evt))
+	}
+
+	for i := 0; i < 2; i++ {
+		evt := &event.Event{
+			Type:      event.RecvTCPv4,
+			Name:      "RecvTCPv4",
+			Category:  event.Net,
+			PID:       1234,
+			Timestamp: now,
+			Params: event.Params{
+				params.NetSIP:   {Name: params.NetSIP, Type: params.IPv4, Value: net.ParseIP("216.58.201.174")},
+				params.NetSport: {Name: params.NetSport, Type: params.Uint16, Value: uint16(443)},
+				params.NetSize:  {Name: params.NetSize, Type: params.Uint32, Value: uint32(100)},
+			},
+			Metadata: make(map[event.MetadataKey]any),
+		}
+		assert.True(t, r.add(evt))
+	}
+
+	// not eligible for the rollup
+	assert.False(t, r.add(&event.Event{Type: event.WriteFile, Name: "WriteFile", Category: event.File, Metadata: make(map[event.MetadataKey]any)}))
+
+	// rule matches are forwarded as-is
+	evt := &event.Event{
+		Type:     event.ReadFile,
+		Name:     "ReadFile",
+		Category: event.File,
+		PID:      1234,
+		Params: event.Params{
+			params.FilePath: {Name: params.FilePath, Type: params.UnicodeString, Value: `C:\Windows\System32\kernel32.dll`},
+		},
+		Metadata: map[event.MetadataKey]any{event.RuleNameKey: "Suspicious DLL read"},
+	}
+	assert.False(t, r.add(evt))
+
+	evts := r.flush()
+	require.Len(t, evts, 2)
+	assert.Len(t, r.summaries, 0)
+
+	for _, e := range evts {
+		assert.True(t, e.ContainsMeta(event.RollupKey))
+		switch e.Type {
+		case event.ReadFile:
+			assert.Equal(t, uint64(3), e.Params.MustGetUint64(params.RollupCount))
+			assert.Equal(t, uint64(12288), e.Params.MustGetUint64(params.RollupBytes))
+			assert.Equal(t, now, e.Params.MustGetTime(params.RollupFirstTimestamp))
+			assert.Equal(t, now.Add(time.Second*2), e.Params.MustGetTime(params.RollupLastTimestamp))
+			assert.Equal(t, uint64(2), e.Seq)
+		case event.RecvTCPv4:
+			assert.Equal(t, uint64(2), e.Params.MustGetUint64(params.RollupCount))
+			assert.Equal(t, uint64(200), e.Params.MustGetUint64(params.RollupBytes))
+		default:
+			t.Fatalf("unexpected summary event %s", e.Name)
+		}
+	}
+
+	assert.Nil(t, r.flush())
+}
+
+func TestRollupDisabled(t *testing.T) {
+	r := newRollup(RollupConfig{Events: []string{"ReadFile"}})
+	evt := &event.Event{
+		Type:     event.ReadFile,
+		Name:     "ReadFile",
+		Category: event.File,
+		Params: event.Params{
+			params.FilePath: {Name: params.FilePath, Type: params.UnicodeString, Value: `C:\Windows\System32\kernel32.dll`},
+		},
+		Metadata: make(map[event.MetadataKey]any),
+	}
+	assert.False(t, r.add(evt))
+}
@@ -0,0 +1,259 @@
+/*
+ * Copyright 2019-2020 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aggregator
+
+import (
+	"expvar"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"golang.org/x/time/rate"
+)
+
+var (
+	// sampledEvents counts the events that were evaluated and kept by each sampling policy
+	sampledEvents = expvar.NewMap("aggregator.sampler.sampled")
+	// droppedEvents counts the events that were discarded by each sampling policy
+	droppedEvents = expvar.NewMap("aggregator.sampler.dropped")
+)
+
+// idleTimeout specifies how long the per-process limiters and
+// deduplication windows are kept around since they were last used
+const idleTimeout = time.Minute * 2
+
+// SamplingPolicy describes how the events of the given types
+// or categories are thinned out before they are batched. All
+// configured strategies are applied in the order of fixed ratio,
+// rate limit, and deduplication. The event is dropped as soon
+// as any of the strategies rejects it.
+type SamplingPolicy struct {
+	// Name is the policy name used to label the sampler counters.
+	Name string `json:"name" yaml:"name" mapstructure:"name"`
+	// Events contains the list of event names the policy applies to.
+	Events []string `json:"events" yaml:"events" mapstructure:"events"`
+	// Categories contains the list of event categories the policy applies to.
+	Categories []string `json:"categories" yaml:"categories" mapstructure:"categories"`
+	// Ratio is the fraction of events in the (0, 1] range that are kept. Zero disables the fixed ratio sampling.
+	Ratio float64 `json:"ratio" yaml:"ratio" mapstructure:"ratio"`
+	// RateLimit is the max number of events per second each process is allowed to emit.
+	RateLimit float64 `json:"rate-limit" yaml:"rate-limit" mapstructure:"rate-limit"`
+	// Burst is the number of events that can exceed the rate limit momentarily.
+	Burst int `json:"burst" yaml:"burst" mapstructure:"burst"`
+	// Dedup keeps the first N events per key within the time window.
+	Dedup DedupConfig `json:"dedup" yaml:"dedup" mapstructure:"dedup"`
+}
+
+// DedupConfig contains the settings for the deduplication sampling strategy.
+type DedupConfig struct {
+	// Max is the number of events with the same key that are kept within the window.
+	Max int `json:"max" yaml:"max" mapstructure:"max"`
+	// Window is the duration of the deduplication window.
+	Window time.Duration `json:"window" yaml:"window" mapstructure:"window"`
+	// Params is the list of event parameters that, along with the process
+	// identifier and the event name, build up the deduplication key.
+	Params []string `json:"params" yaml:"params" mapstructure:"params"`
+}
+
+// validate ensures the policy settings are sane.
+func (p SamplingPolicy) validate() error {
+	if len(p.Events) == 0 && len(p.Categories) == 0 {
+		return fmt.Errorf("%s sampling policy: at least one event or category is required", p.Name)
+	}
+	for _, c := range p.Categories {
+		if !event.IsCategoryKnown(c) {
+			return fmt.Errorf("%s sampling policy: unknown category %q", p.Name, c)
+		}
+	}
+	if p.Ratio < 0 || p.Ratio > 1 {
+		return fmt.Errorf("%s sampling policy: ratio must be in the [0, 1] range", p.Name)
+	}
+	if p.RateLimit < 0 {
+		return fmt.Errorf("%s sampling policy: rate limit can't be negative", p.Name)
+	}
+	if p.Dedup.Max > 0 && p.Dedup.Window <= 0 {
+		return fmt.Errorf("%s sampling policy: dedup window is required", p.Name)
+	}
+	return nil
+}
+
+// limiter is the token bucket rate limiter bound to a single process.
+type limiter struct {
+	*rate.Limiter
+	lastSeen time.Time
+}
+
+// window tracks the number of events observed for the dedup key.
+type window struct {
+	start time.Time
+	count int
+}
+
+// policy is the runtime state of the sampling policy.
+type policy struct {
+	SamplingPolicy
+	events     map[string]bool
+	categories map[event.Category]bool
+	// seen is the number of events evaluated by the fixed ratio strategy
+	seen     uint64
+	limiters map[uint32]*limiter
+	windows  map[string]*window
+}
+
+func (p *policy) applies(evt *event.Event) bool {
+	return p.events[evt.Name] || p.categories[evt.Category]
+}
+
+// keepRatio determines whether the event survives the fixed ratio strategy.
+// The ratio is applied deterministically, so for the ratio of 0.25, every
+// fourth event is kept.
+func (p *policy) keepRatio() bool {
+	n := p.seen
+	p.seen++
+	return uint64(float64(n+1)*p.Ratio) > uint64(float64(n)*p.Ratio)
+}
+
+func (p *policy) keepRate(evt *event.Event, now time.Time) bool {
+	l, ok := p.limiters[evt.PID]
+	if !ok {
+		burst := p.Burst
+		if burst <= 0 {
+			burst = int(p.RateLimit)
+		}
+		if burst <= 0 {
+			burst = 1
+		}
+		l = &limiter{Limiter: rate.NewLimiter(rate.Limit(p.RateLimit), burst)}
+		p.limiters[evt.PID] = l
+	}
+	l.lastSeen = now
+	return l.AllowN(now, 1)
+}
+
+func (p *policy) keepDedup(evt *event.Event, now time.Time) bool {
+	var sb strings.Builder
+	sb.WriteString(strconv.FormatUint(uint64(evt.PID), 10))
+	sb.WriteByte('|')
+	sb.WriteString(evt.Name)
+	for _, name := range p.Dedup.Params {
+		sb.WriteByte('|')
+		sb.WriteString(evt.GetParamAsString(name))
+	}
+	key := sb.String()
+
+	w, ok := p.windows[key]
+	if !ok || now.Sub(w.start) >= p.Dedup.Window {
+		p.windows[key] = &window{start: now, count: 1}
+		return true
+	}
+	w.count++
+	return w.count <= p.Dedup.Max
+}
+
+func (p *policy) keep(evt *event.Event, now time.Time) bool {
+	if p.Ratio > 0 && !p.keepRatio() {
+		return false
+	}
+	if p.RateLimit > 0 && !p.keepRate(evt, now) {
+		return false
+	}
+	if p.Dedup.Max > 0 && !p.keepDedup(evt, now) {
+		return false
+	}
+	return true
+}
+
+// prune removes stale limiters and expired dedup windows.
+func (p *policy) prune(now time.Time) {
+	for pid, l := range p.limiters {
+		if now.Sub(l.lastSeen) > idleTimeout {
+			delete(p.limiters, pid)
+		}
+	}
+	for key, w := range p.windows {
+		if now.Sub(w.start) >= p.Dedup.Window {
+			delete(p.windows, key)
+		}
+	}
+}
+
+// sampler evaluates sampling policies on the events flowing
+// through the aggregator. Events that triggered any of the rules
+// are never sampled out. The sampler is not safe for concurrent
+// use and is only accessed from the aggregator loop.
+type sampler struct {
+	policies []*policy
+	now      func() time.Time
+}
+
+func newSampler(policies []SamplingPolicy) (*sampler, error) {
+	s := &sampler{policies: make([]*policy, 0, len(policies)), now: time.Now}
+	for i, c := range policies {
+		if c.Name == "" {
+			c.Name = "policy-" + strconv.Itoa(i)
+		}
+		if err := c.validate(); err != nil {
+			return nil, err
+		}
+		p := &policy{
+			SamplingPolicy: c,
+			events:         make(map[string]bool),
+			categories:     make(map[event.Category]bool),
+			limiters:       make(map[uint32]*limiter),
+			windows:        make(map[string]*window),
+		}
+		for _, name := range c.Events {
+			p.events[name] = true
+		}
+		for _, cat := range c.Categories {
+			p.categories[event.Category(cat)] = true
+		}
+		s.policies = append(s.policies, p)
+	}
+	return s, nil
+}
+
+// keep returns true if the event should be forwarded to outputs.
+func (s *sampler) keep(evt *event.Event) bool {
+	if len(s.policies) == 0 || evt.ContainsMeta(event.RuleNameKey) {
+		return true
+	}
+	now := s.now()
+	for _, p := range s.policies {
+		if !p.applies(evt) {
+			continue
+		}
+		if !p.keep(evt, now) {
+			droppedEvents.Add(p.Name, 1)
+			return false
+		}
+		sampledEvents.Add(p.Name, 1)
+	}
+	return true
+}
+
+// prune discards the state of inactive processes and expired windows.
+func (s *sampler) prune() {
+	now := s.now()
+	for _, p := range s.policies {
+		p.prune(now)
+	}
+}
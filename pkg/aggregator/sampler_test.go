@@ -0,0 +1,141 @@
+/*
+ * Copyright 2019-2020 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aggregator
+
+import (
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/event/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSampledEvent(name string, cat event.Category, pid uint32, path string) *event.Event {
+	return &event.Event{
+		Name:     name,
+		Category: cat,
+		PID:      pid,
+		Params: event.Params{
+			params.FilePath: {Name: params.FilePath, Type: params.UnicodeString, Value: path},
+		},
+		Metadata: make(map[event.MetadataKey]any),
+	}
+}
+
+func TestSamplerValidation(t *testing.T) {
+	var tests = []struct {
+		policy SamplingPolicy
+		valid  bool
+	}{
+		{SamplingPolicy{Events: []string{"ReadFile"}, Ratio: 0.5}, true},
+		{SamplingPolicy{Ratio: 0.5}, false},
+		{SamplingPolicy{Categories: []string{"foo"}}, false},
+		{SamplingPolicy{Categories: []string{"file"}, Ratio: 1.5}, false},
+		{SamplingPolicy{Categories: []string{"file"}, RateLimit: -1}, false},
+		{SamplingPolicy{Categories: []string{"file"}, Dedup: DedupConfig{Max: 2}}, false},
+	}
+
+	for _, tt := range tests {
+		_, err := newSampler([]SamplingPolicy{tt.policy})
+		if tt.valid {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestSamplerRatio(t *testing.T) {
+	s, err := newSampler([]SamplingPolicy{{Name: "ratio", Events: []string{"ReadFile"}, Ratio: 0.25}})
+	require.NoError(t, err)
+
+	var kept int
+	for i := 0; i < 100; i++ {
+		if s.keep(newSampledEvent("ReadFile", event.File, 1234, `C:\Windows\notepad.exe`)) {
+			kept++
+		}
+	}
+	assert.Equal(t, 25, kept)
+
+	// events not covered by the policy are always kept
+	assert.True(t, s.keep(newSampledEvent("CreateFile", event.File, 1234, `C:\Windows\notepad.exe`)))
+}
+
+func TestSamplerRateLimit(t *testing.T) {
+	s, err := newSampler([]SamplingPolicy{{Name: "rate", Categories: []string{"file"}, RateLimit: 10, Burst: 10}})
+	require.NoError(t, err)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	var kept int
+	for i := 0; i < 50; i++ {
+		if s.keep(newSampledEvent("WriteFile", event.File, 1234, `C:\Temp\file.txt`)) {
+			kept++
+		}
+	}
+	assert.Equal(t, 10, kept)
+
+	// each process is given its own bucket
+	assert.True(t, s.keep(newSampledEvent("WriteFile", event.File, 4321, `C:\Temp\file.txt`)))
+
+	// the bucket is refilled as time passes
+	now = now.Add(time.Millisecond * 500)
+	kept = 0
+	for i := 0; i < 50; i++ {
+		if s.keep(newSampledEvent("WriteFile", event.File, 1234, `C:\Temp\file.txt`)) {
+			kept++
+		}
+	}
+	assert.Equal(t, 5, kept)
+
+	now = now.Add(idleTimeout * 2)
+	s.prune()
+	assert.Len(t, s.policies[0].limiters, 0)
+}
+
+func TestSamplerDedup(t *testing.T) {
+	s, err := newSampler([]SamplingPolicy{{Name: "dedup", Events: []string{"ReadFile"}, Dedup: DedupConfig{Max: 2, Window: time.Minute, Params: []string{params.FilePath}}}})
+	require.NoError(t, err)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	assert.True(t, s.keep(newSampledEvent("ReadFile", event.File, 1234, `C:\Temp\file.txt`)))
+	assert.True(t, s.keep(newSampledEvent("ReadFile", event.File, 1234, `C:\Temp\file.txt`)))
+	assert.False(t, s.keep(newSampledEvent("ReadFile", event.File, 1234, `C:\Temp\file.txt`)))
+	assert.True(t, s.keep(newSampledEvent("ReadFile", event.File, 1234, `C:\Temp\file1.txt`)))
+	assert.True(t, s.keep(newSampledEvent("ReadFile", event.File, 4321, `C:\Temp\file.txt`)))
+
+	// rule matches are exempt from sampling
+	evt := newSampledEvent("ReadFile", event.File, 1234, `C:\Temp\file.txt`)
+	evt.AddMeta(event.RuleNameKey, "Suspicious file access")
+	assert.True(t, s.keep(evt))
+
+	// the window expires
+	now = now.Add(time.Minute)
+	assert.True(t, s.keep(newSampledEvent("ReadFile", event.File, 1234, `C:\Temp\file.txt`)))
+
+	now = now.Add(time.Minute * 2)
+	s.prune()
+	assert.Len(t, s.policies[0].windows, 0)
+
+	assert.Equal(t, int64(1), droppedEvents.Get("dedup").(*expvar.Int).Value())
+}
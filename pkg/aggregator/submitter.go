@@ -19,7 +19,12 @@
 package aggregator
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/health"
+	hconfig "github.com/rabbitstack/fibratus/pkg/health/config"
 	"github.com/rabbitstack/fibratus/pkg/outputs"
 )
 
@@ -30,6 +35,7 @@ type queue chan *event.Batch
 type submitter struct {
 	wq      queue
 	workers []*worker
+	typ     outputs.Type
 }
 
 func newSubmitter(wq queue, outputConfig outputs.Config) (*submitter, error) {
@@ -41,10 +47,65 @@ func newSubmitter(wq queue, outputConfig outputs.Config) (*submitter, error) {
 	workers := make([]*worker, len(clients))
 
 	for i, client := range clients {
-		workers[i] = initWorker(wq, client)
+		workers[i] = initWorker(wq, client, outputConfig.Type)
+	}
+
+	s := &submitter{wq: wq, workers: workers, typ: outputConfig.Type}
+	health.Register(s.healthName(), s.health)
+
+	return s, nil
+}
+
+func (s *submitter) healthName() string { return "output." + s.typ.String() }
+
+func (s *submitter) unregisterHealth() { health.Unregister(s.healthName()) }
+
+// health evaluates the output health. The output is not ready until
+// any of its clients is connected. If the output is failing to connect
+// or publish batches for longer than the configured period, it is deemed
+// unhealthy.
+func (s *submitter) health(c hconfig.Config) health.Component {
+	states := make([]health.ProbeState, 0, len(s.workers))
+	var connected int
+	for _, w := range s.workers {
+		if w.connected.Load() {
+			connected++
+		}
+		states = append(states, w.probe.State())
+	}
+	state := health.Merge(states...)
+
+	comp := health.Component{
+		Status:  health.Healthy,
+		Ready:   connected > 0,
+		Details: state.Details(),
+	}
+	comp.Details["clients"] = len(s.workers)
+	comp.Details["connected_clients"] = connected
+
+	stalled := state.IsFailing() && c.OutputStall > 0 && time.Since(state.FailingSince) > c.OutputStall
+	switch {
+	case connected == 0:
+		comp.Status = health.Degraded
+		comp.Message = "output is not connected"
+		if stalled {
+			comp.Status = health.Unhealthy
+		}
+		if state.IsFailing() {
+			comp.Message += ": " + state.LastError
+		}
+	case stalled:
+		comp.Status = health.Unhealthy
+		comp.Message = fmt.Sprintf("failing to publish for %v: %s", time.Since(state.FailingSince).Round(time.Second), state.LastError)
+	case state.IsFailing():
+		comp.Status = health.Degraded
+		comp.Message = state.LastError
+	case connected < len(s.workers):
+		comp.Status = health.Degraded
+		comp.Message = fmt.Sprintf("%d of %d clients are not connected", len(s.workers)-connected, len(s.workers))
 	}
 
-	return &submitter{wq: wq, workers: workers}, nil
+	return comp
 }
 
 func (s *submitter) shutdown() error {
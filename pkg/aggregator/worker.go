@@ -20,24 +20,38 @@ package aggregator
 
 import (
 	"expvar"
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/health"
 	"github.com/rabbitstack/fibratus/pkg/outputs"
+	"github.com/rabbitstack/fibratus/pkg/tracing"
+	"github.com/rabbitstack/fibratus/pkg/util/histogram"
 	log "github.com/sirupsen/logrus"
+	"sync/atomic"
 	"time"
 )
 
 // maxBackoff determines the maximum exponential backoff wait time before reconnecting the client
 const maxBackoff = time.Minute
 
-var clientPublishErrors = expvar.NewInt("aggregator.worker.client.publish.errors")
+var (
+	clientPublishErrors = expvar.NewInt("aggregator.worker.client.publish.errors")
+	// publishLatency measures the time it takes to publish the batch to the output
+	publishLatency = histogram.NewMap("output.publish.seconds", histogram.LatencyBuckets)
+)
 
 type worker struct {
 	qu      queue
 	client  outputs.Client
+	typ     outputs.Type
 	backoff time.Duration
+	// connected indicates if the client is connected to the output
+	connected atomic.Bool
+	// probe records the outcome of batch publishing
+	probe health.Probe
 }
 
-func initWorker(q queue, client outputs.Client) *worker {
-	w := &worker{qu: q, client: client, backoff: time.Second * 2}
+func initWorker(q queue, client outputs.Client, typ outputs.Type) *worker {
+	w := &worker{qu: q, client: client, typ: typ, backoff: time.Second * 2}
 	go w.run()
 	return w
 }
@@ -46,6 +60,7 @@ func (w *worker) run() {
 	for {
 		err := w.client.Connect()
 		if err != nil {
+			w.probe.Failure(err)
 			// schedule an exponential backoff reconnect strategy for the client
 			w.backoff *= 2
 			log.Warnf("fail to connect the client: %v. Reconnecting in %v...", err, w.backoff)
@@ -57,14 +72,40 @@ func (w *worker) run() {
 		}
 		break
 	}
+	w.connected.Store(true)
+	latency := publishLatency.Get(w.typ.String())
 	for batch := range w.qu {
-		if err := w.client.Publish(batch); err != nil {
+		start := time.Now()
+		err := w.client.Publish(batch)
+		latency.Since(start)
+		if tracing.Enabled() {
+			w.trace(batch, start, err)
+		}
+		if err != nil {
 			clientPublishErrors.Add(1)
+			w.probe.Failure(err)
 			log.Warnf("couldn't publish batch to client: %v", err)
+		} else {
+			w.probe.Success()
+		}
+	}
+}
+
+// trace records publish spans for sampled events in the batch.
+func (w *worker) trace(batch *event.Batch, start time.Time, err error) {
+	for _, evt := range batch.Events {
+		span := tracing.BeginAt(evt.Seq, "output.publish", start)
+		if span == nil {
+			continue
 		}
+		span.SetString("output", w.typ.String())
+		span.SetInt("batch.size", batch.Len())
+		span.SetError(err)
+		span.End()
 	}
 }
 
 func (w *worker) close() error {
+	w.connected.Store(false)
 	return w.client.Close()
 }
@@ -19,7 +19,11 @@
 package aggregator
 
 import (
+	"errors"
 	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/health"
+	hconfig "github.com/rabbitstack/fibratus/pkg/health/config"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
@@ -82,7 +86,7 @@ func TestRunWorker(t *testing.T) {
 
 	client := &httpClient{url: srv.URL, wait: make(chan struct{}, 1), expectedPublished: 2}
 
-	w := initWorker(q, client)
+	w := initWorker(q, client, outputs.HTTP)
 	defer w.close()
 
 	<-client.wait
@@ -118,10 +122,45 @@ func TestConnectClientBackoff(t *testing.T) {
 		fail = false
 	})
 
-	w := initWorker(q, client)
+	w := initWorker(q, client, outputs.HTTP)
 	defer w.close()
 
 	<-client.wait
 
 	assert.Equal(t, 2, client.published)
 }
+
+func TestSubmitterHealth(t *testing.T) {
+	w1, w2 := &worker{typ: outputs.HTTP}, &worker{typ: outputs.HTTP}
+	s := &submitter{workers: []*worker{w1, w2}, typ: outputs.HTTP}
+	c := hconfig.Config{OutputStall: time.Hour}
+
+	comp := s.health(c)
+	assert.Equal(t, health.Degraded, comp.Status)
+	assert.False(t, comp.Ready)
+	assert.Equal(t, "output is not connected", comp.Message)
+
+	w1.connected.Store(true)
+	w1.probe.Success()
+	comp = s.health(c)
+	assert.Equal(t, health.Degraded, comp.Status)
+	assert.True(t, comp.Ready)
+	assert.Equal(t, "1 of 2 clients are not connected", comp.Message)
+
+	w2.connected.Store(true)
+	comp = s.health(c)
+	assert.Equal(t, health.Healthy, comp.Status)
+	assert.Equal(t, 2, comp.Details["connected_clients"])
+
+	w2.probe.Failure(errors.New("503 Service Unavailable"))
+	comp = s.health(c)
+	assert.Equal(t, health.Degraded, comp.Status)
+	assert.Equal(t, "503 Service Unavailable", comp.Message)
+
+	comp = s.health(hconfig.Config{OutputStall: time.Nanosecond})
+	assert.Equal(t, health.Unhealthy, comp.Status)
+
+	w1.probe.Success()
+	comp = s.health(c)
+	assert.Equal(t, health.Healthy, comp.Status)
+}
@@ -122,6 +122,9 @@ type Alert struct {
 	Severity Severity
 	// Events contains a list of events that trigger the alert.
 	Events []*event.Event
+	// Context contains recent events of the offending process
+	// tree that preceded the events that triggered the alert.
+	Context []*event.Event
 }
 
 // String returns the alert string representation. If verbose
@@ -139,6 +142,13 @@ func (a Alert) String(verbose bool) string {
 			b.WriteString(fmt.Sprintf("\tEvent #%d:\n", n+1))
 			b.WriteString(strings.TrimSuffix(evt.StringShort(), "\t"))
 		}
+		if len(a.Context) > 0 {
+			b.WriteString("\nRecent activity of the process tree:\n\n")
+			for n, evt := range a.Context {
+				b.WriteString(fmt.Sprintf("\tEvent #%d:\n", n+1))
+				b.WriteString(strings.TrimSuffix(evt.StringShort(), "\t"))
+			}
+		}
 		if a.Text == "" {
 			return fmt.Sprintf("%s\n\nSeverity: %s\n\n%s", a.Title, a.Severity, b.String())
 		}
@@ -166,6 +176,38 @@ func (a *Alert) MDToHTML() error {
 	return nil
 }
 
+// alertEvent is the JSON representation of the alert event.
+type alertEvent struct {
+	Name      string         `json:"name"`
+	Category  string         `json:"category"`
+	Timestamp time.Time      `json:"timestamp"`
+	Params    map[string]any `json:"params"`
+	Callstack []string       `json:"callstack,omitempty"`
+	Proc      *alertProc     `json:"proc,omitempty"`
+}
+
+// alertProc is the JSON representation of the alert event process.
+type alertProc struct {
+	PID            uint32   `json:"pid"`
+	TID            uint32   `json:"tid"`
+	PPID           uint32   `json:"ppid"`
+	Name           string   `json:"name"`
+	Exe            string   `json:"exe"`
+	Cmdline        string   `json:"cmdline,omitempty"`
+	Pname          string   `json:"parent_name,omitempty"`
+	Pcmdline       string   `json:"parent_cmdline,omitempty"`
+	Cwd            string   `json:"cwd,omitempty"`
+	SID            string   `json:"sid"`
+	Username       string   `json:"username"`
+	Domain         string   `json:"domain"`
+	SessionID      uint32   `json:"session_id"`
+	IntegrityLevel string   `json:"integrity_level"`
+	IsWOW64        bool     `json:"is_wow64"`
+	IsPackaged     bool     `json:"is_packaged"`
+	IsProtected    bool     `json:"is_protected"`
+	Ancestors      []string `json:"ancestors"`
+}
+
 // MarshalJSON encodes the alert to JSON format.
 func (a Alert) MarshalJSON() ([]byte, error) {
 	var msg = &struct {
@@ -175,33 +217,8 @@ func (a Alert) MarshalJSON() ([]byte, error) {
 		Text        string            `json:"text,omitempty"`
 		Description string            `json:"description"`
 		Labels      map[string]string `json:"labels,omitempty"`
-		Events      []struct {
-			Name      string         `json:"name"`
-			Category  string         `json:"category"`
-			Timestamp time.Time      `json:"timestamp"`
-			Params    map[string]any `json:"params"`
-			Callstack []string       `json:"callstack,omitempty"`
-			Proc      *struct {
-				PID            uint32   `json:"pid"`
-				TID            uint32   `json:"tid"`
-				PPID           uint32   `json:"ppid"`
-				Name           string   `json:"name"`
-				Exe            string   `json:"exe"`
-				Cmdline        string   `json:"cmdline,omitempty"`
-				Pname          string   `json:"parent_name,omitempty"`
-				Pcmdline       string   `json:"parent_cmdline,omitempty"`
-				Cwd            string   `json:"cwd,omitempty"`
-				SID            string   `json:"sid"`
-				Username       string   `json:"username"`
-				Domain         string   `json:"domain"`
-				SessionID      uint32   `json:"session_id"`
-				IntegrityLevel string   `json:"integrity_level"`
-				IsWOW64        bool     `json:"is_wow64"`
-				IsPackaged     bool     `json:"is_packaged"`
-				IsProtected    bool     `json:"is_protected"`
-				Ancestors      []string `json:"ancestors"`
-			} `json:"proc,omitempty"`
-		} `json:"events"`
+		Events      []alertEvent      `json:"events"`
+		Context     []alertEvent      `json:"context,omitempty"`
 	}{
 		ID:          a.ID,
 		Title:       a.Title,
@@ -209,64 +226,20 @@ func (a Alert) MarshalJSON() ([]byte, error) {
 		Text:        a.Text,
 		Description: a.Description,
 		Labels:      a.Labels,
+		Events:      newAlertEvents(a.Events),
+	}
+	if len(a.Context) > 0 {
+		msg.Context = newAlertEvents(a.Context)
 	}
 
-	events := make([]struct {
-		Name      string         `json:"name"`
-		Category  string         `json:"category"`
-		Timestamp time.Time      `json:"timestamp"`
-		Params    map[string]any `json:"params"`
-		Callstack []string       `json:"callstack,omitempty"`
-		Proc      *struct {
-			PID            uint32   `json:"pid"`
-			TID            uint32   `json:"tid"`
-			PPID           uint32   `json:"ppid"`
-			Name           string   `json:"name"`
-			Exe            string   `json:"exe"`
-			Cmdline        string   `json:"cmdline,omitempty"`
-			Pname          string   `json:"parent_name,omitempty"`
-			Pcmdline       string   `json:"parent_cmdline,omitempty"`
-			Cwd            string   `json:"cwd,omitempty"`
-			SID            string   `json:"sid"`
-			Username       string   `json:"username"`
-			Domain         string   `json:"domain"`
-			SessionID      uint32   `json:"session_id"`
-			IntegrityLevel string   `json:"integrity_level"`
-			IsWOW64        bool     `json:"is_wow64"`
-			IsPackaged     bool     `json:"is_packaged"`
-			IsProtected    bool     `json:"is_protected"`
-			Ancestors      []string `json:"ancestors"`
-		} `json:"proc,omitempty"`
-	}, 0, len(a.Events))
+	return json.Marshal(msg)
+}
 
-	for _, e := range a.Events {
-		var evt = struct {
-			Name      string         `json:"name"`
-			Category  string         `json:"category"`
-			Timestamp time.Time      `json:"timestamp"`
-			Params    map[string]any `json:"params"`
-			Callstack []string       `json:"callstack,omitempty"`
-			Proc      *struct {
-				PID            uint32   `json:"pid"`
-				TID            uint32   `json:"tid"`
-				PPID           uint32   `json:"ppid"`
-				Name           string   `json:"name"`
-				Exe            string   `json:"exe"`
-				Cmdline        string   `json:"cmdline,omitempty"`
-				Pname          string   `json:"parent_name,omitempty"`
-				Pcmdline       string   `json:"parent_cmdline,omitempty"`
-				Cwd            string   `json:"cwd,omitempty"`
-				SID            string   `json:"sid"`
-				Username       string   `json:"username"`
-				Domain         string   `json:"domain"`
-				SessionID      uint32   `json:"session_id"`
-				IntegrityLevel string   `json:"integrity_level"`
-				IsWOW64        bool     `json:"is_wow64"`
-				IsPackaged     bool     `json:"is_packaged"`
-				IsProtected    bool     `json:"is_protected"`
-				Ancestors      []string `json:"ancestors"`
-			} `json:"proc,omitempty"`
-		}{
+func newAlertEvents(evts []*event.Event) []alertEvent {
+	events := make([]alertEvent, 0, len(evts))
+
+	for _, e := range evts {
+		var evt = alertEvent{
 			Name:      e.Name,
 			Category:  string(e.Category),
 			Timestamp: e.Timestamp,
@@ -292,26 +265,7 @@ func (a Alert) MarshalJSON() ([]byte, error) {
 
 		ps := e.PS
 		if ps != nil {
-			evt.Proc = &struct {
-				PID            uint32   `json:"pid"`
-				TID            uint32   `json:"tid"`
-				PPID           uint32   `json:"ppid"`
-				Name           string   `json:"name"`
-				Exe            string   `json:"exe"`
-				Cmdline        string   `json:"cmdline,omitempty"`
-				Pname          string   `json:"parent_name,omitempty"`
-				Pcmdline       string   `json:"parent_cmdline,omitempty"`
-				Cwd            string   `json:"cwd,omitempty"`
-				SID            string   `json:"sid"`
-				Username       string   `json:"username"`
-				Domain         string   `json:"domain"`
-				SessionID      uint32   `json:"session_id"`
-				IntegrityLevel string   `json:"integrity_level"`
-				IsWOW64        bool     `json:"is_wow64"`
-				IsPackaged     bool     `json:"is_packaged"`
-				IsProtected    bool     `json:"is_protected"`
-				Ancestors      []string `json:"ancestors"`
-			}{
+			evt.Proc = &alertProc{
 				PID:            ps.PID,
 				TID:            e.Tid,
 				PPID:           ps.Ppid,
@@ -337,9 +291,8 @@ func (a Alert) MarshalJSON() ([]byte, error) {
 
 		events = append(events, evt)
 	}
-	msg.Events = events
 
-	return json.Marshal(msg)
+	return events
 }
 
 // NewAlert builds a new alert.
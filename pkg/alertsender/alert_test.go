@@ -126,3 +126,43 @@ func TestAlertJSON(t *testing.T) {
 
 	require.Equal(t, expectedJSON, string(b))
 }
+
+func TestAlertJSONContext(t *testing.T) {
+	ps := &pstypes.PS{PID: 2484, Name: "cmd.exe"}
+	alert := NewAlertWithEvents("Suspicious payload", "", nil, High, []*event.Event{{
+		Type:     event.CreateFile,
+		Category: event.File,
+		Name:     "CreateFile",
+		PID:      2484,
+		PS:       ps,
+		Params: event.Params{
+			params.FilePath: {Name: params.FilePath, Type: params.UnicodeString, Value: "C:\\Temp\\payload.exe"}},
+	}})
+	alert.Context = []*event.Event{{
+		Type:     event.CreateProcess,
+		Category: event.Process,
+		Name:     "CreateProcess",
+		PID:      2484,
+		PS:       ps,
+		Params: event.Params{
+			params.ProcessName: {Name: params.ProcessName, Type: params.AnsiString, Value: "powershell.exe"}},
+	}}
+
+	b, err := json.Marshal(alert)
+	require.NoError(t, err)
+
+	var msg struct {
+		Events  []map[string]any `json:"events"`
+		Context []struct {
+			Name   string         `json:"name"`
+			Params map[string]any `json:"params"`
+		} `json:"context"`
+	}
+	require.NoError(t, json.Unmarshal(b, &msg))
+	require.Len(t, msg.Events, 1)
+	require.Len(t, msg.Context, 1)
+	require.Equal(t, "CreateProcess", msg.Context[0].Name)
+	require.Equal(t, "powershell.exe", msg.Context[0].Params[params.ProcessName])
+
+	require.Contains(t, alert.String(true), "Recent activity of the process tree")
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package alertsender
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/rabbitstack/fibratus/pkg/health"
+	hconfig "github.com/rabbitstack/fibratus/pkg/health/config"
+)
+
+// probes tracks the outcome of alerts emitted by each sender
+var probes = struct {
+	sync.RWMutex
+	m map[Type]*health.Probe
+}{m: make(map[Type]*health.Probe)}
+
+// Send emits the alert via the sender and records
+// the outcome in the sender health probe.
+func Send(s Sender, alert Alert) error {
+	err := s.Send(alert)
+	probes.RLock()
+	probe := probes.m[s.Type()]
+	probes.RUnlock()
+	if probe == nil {
+		return err
+	}
+	if err != nil {
+		probe.Failure(err)
+	} else {
+		probe.Success()
+	}
+	return err
+}
+
+// registerHealth registers the health check for the sender.
+func registerHealth(typ Type) {
+	probe := &health.Probe{}
+	probes.Lock()
+	probes.m[typ] = probe
+	probes.Unlock()
+	health.Register(fmt.Sprintf("alertsender.%s", typ), func(c hconfig.Config) health.Component {
+		state := probe.State()
+		comp := health.Component{
+			Status:  health.Healthy,
+			Ready:   true,
+			Details: state.Details(),
+		}
+		switch {
+		case c.MaxAlertsenderErrors > 0 && state.ConsecutiveFailures >= c.MaxAlertsenderErrors:
+			comp.Status = health.Unhealthy
+			comp.Message = fmt.Sprintf("%d consecutive failures: %s", state.ConsecutiveFailures, state.LastError)
+		case state.IsFailing():
+			comp.Status = health.Degraded
+			comp.Message = state.LastError
+		}
+		return comp
+	})
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package alertsender
+
+import (
+	"sync"
+	"time"
+)
+
+// maxRecentAlerts determines the capacity of the recent alerts ring buffer
+const maxRecentAlerts = 500
+
+// RecordedAlert is the alert emitted at the specified timestamp.
+type RecordedAlert struct {
+	// Timestamp is the time the alert was emitted.
+	Timestamp time.Time `json:"timestamp"`
+	// Alert is the emitted alert.
+	Alert Alert `json:"alert"`
+}
+
+// recent keeps the ring buffer of recently emitted alerts.
+var recent = struct {
+	sync.RWMutex
+	alerts []RecordedAlert
+	next   int
+}{alerts: make([]RecordedAlert, 0, maxRecentAlerts)}
+
+// Record stores the alert in the ring buffer of recently emitted alerts.
+// Once the buffer is full, the oldest alert is overwritten.
+func Record(alert Alert) {
+	recent.Lock()
+	defer recent.Unlock()
+	a := RecordedAlert{Timestamp: time.Now(), Alert: alert}
+	if len(recent.alerts) < maxRecentAlerts {
+		recent.alerts = append(recent.alerts, a)
+		return
+	}
+	recent.alerts[recent.next] = a
+	recent.next = (recent.next + 1) % maxRecentAlerts
+}
+
+// Recent returns at most n recently emitted alerts ordered
+// from the newest to the oldest. If n is zero or negative, all
+// alerts stored in the ring buffer are returned.
+func Recent(n int) []RecordedAlert {
+	recent.RLock()
+	defer recent.RUnlock()
+	size := len(recent.alerts)
+	if n <= 0 || n > size {
+		n = size
+	}
+	alerts := make([]RecordedAlert, 0, n)
+	for i := 0; i < n; i++ {
+		// the newest alert sits right before the next write position
+		idx := (recent.next - 1 - i + size) % size
+		alerts = append(alerts, recent.alerts[idx])
+	}
+	return alerts
+}
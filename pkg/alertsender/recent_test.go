@@ -0,0 +1,51 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package alertsender
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecentAlerts(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		Record(NewAlert("alert "+strconv.Itoa(i), "", nil, Normal))
+	}
+
+	alerts := Recent(2)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, "alert 2", alerts[0].Alert.Title)
+	assert.Equal(t, "alert 1", alerts[1].Alert.Title)
+	assert.False(t, alerts[0].Timestamp.IsZero())
+
+	assert.Len(t, Recent(0), 3)
+
+	// overflow the ring buffer
+	for i := 3; i < maxRecentAlerts+10; i++ {
+		Record(NewAlert("alert "+strconv.Itoa(i), "", nil, Normal))
+	}
+
+	alerts = Recent(0)
+	require.Len(t, alerts, maxRecentAlerts)
+	assert.Equal(t, "alert "+strconv.Itoa(maxRecentAlerts+9), alerts[0].Alert.Title)
+	assert.Equal(t, "alert 10", alerts[maxRecentAlerts-1].Alert.Title)
+}
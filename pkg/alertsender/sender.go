@@ -150,6 +150,7 @@ func LoadAll(configs []Config) error {
 			return fmt.Errorf("fail to load %q alertsender: %v", config.Type, err)
 		}
 		alertsenders[config.Type] = alertsender
+		registerHealth(config.Type)
 	}
 	return nil
 }
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticate(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	var tests = []struct {
+		name       string
+		config     config.APIConfig
+		authHeader string
+		tls        *tls.ConnectionState
+		wantStatus int
+	}{
+		{"valid token", config.APIConfig{Token: "s3cr3t"}, "Bearer s3cr3t", nil, http.StatusOK},
+		{"invalid token", config.APIConfig{Token: "s3cr3t"}, "Bearer s3cr3", nil, http.StatusUnauthorized},
+		{"missing token", config.APIConfig{Token: "s3cr3t"}, "", nil, http.StatusUnauthorized},
+		{"basic auth scheme", config.APIConfig{Token: "s3cr3t"}, "Basic s3cr3t", nil, http.StatusUnauthorized},
+		{
+			"verified client certificate",
+			config.APIConfig{TLSCert: "cert.pem", TLSKey: "key.pem", TLSCA: "ca.pem"},
+			"",
+			&tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}},
+			http.StatusOK,
+		},
+		{
+			"unverified client certificate",
+			config.APIConfig{TLSCert: "cert.pem", TLSKey: "key.pem", TLSCA: "ca.pem"},
+			"",
+			&tls.ConnectionState{},
+			http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/rules", nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			req.TLS = tt.tls
+			w := httptest.NewRecorder()
+			authenticate(tt.config, ok).ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/rabbitstack/fibratus/pkg/alertsender"
+)
+
+// defaultAlertsLimit is the number of alerts returned when the limit is not specified
+const defaultAlertsLimit = 50
+
+// Alerts is the handler that returns recently emitted alerts ordered from the newest
+// to the oldest. The number of alerts is controlled by the limit query parameter.
+func Alerts() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := defaultAlertsLimit
+		if l := r.URL.Query().Get("limit"); l != "" {
+			n, err := strconv.Atoi(l)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit: %s", l))
+				return
+			}
+			limit = n
+		}
+		writeJSON(w, http.StatusOK, alertsender.Recent(limit))
+	})
+}
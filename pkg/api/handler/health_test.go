@@ -0,0 +1,70 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/rabbitstack/fibratus/pkg/health"
+	hconfig "github.com/rabbitstack/fibratus/pkg/health/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandlers(t *testing.T) {
+	var c hconfig.Config
+
+	health.Register("output.elasticsearch", func(hconfig.Config) health.Component {
+		return health.Component{Status: health.Degraded, Ready: false, Message: "output is not connected"}
+	})
+	defer health.Unregister("output.elasticsearch")
+
+	find := func(report health.Report, name string) *health.Component {
+		for _, comp := range report.Components {
+			if comp.Name == name {
+				return &comp
+			}
+		}
+		return nil
+	}
+
+	var report health.Report
+	require.Equal(t, http.StatusOK, serve(t, Health(c), http.MethodGet, "/healthz", nil, &report))
+	assert.Equal(t, health.Degraded, report.Status)
+	comp := find(report, "output.elasticsearch")
+	require.NotNil(t, comp)
+	assert.Equal(t, health.Degraded, comp.Status)
+	assert.Equal(t, "output is not connected", comp.Message)
+
+	require.Equal(t, http.StatusServiceUnavailable, serve(t, Ready(c), http.MethodGet, "/readyz", nil, &report))
+	assert.False(t, report.Ready)
+
+	health.Register("output.elasticsearch", func(hconfig.Config) health.Component {
+		return health.Component{Status: health.Unhealthy, Ready: true}
+	})
+	require.Equal(t, http.StatusServiceUnavailable, serve(t, Health(c), http.MethodGet, "/healthz", nil, &report))
+	assert.Equal(t, health.Unhealthy, report.Status)
+
+	health.Register("output.elasticsearch", func(hconfig.Config) health.Component {
+		return health.Component{Status: health.Healthy, Ready: true}
+	})
+	require.Equal(t, http.StatusOK, serve(t, Ready(c), http.MethodGet, "/readyz", nil, &report))
+	assert.True(t, report.Ready)
+}
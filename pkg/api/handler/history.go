@@ -0,0 +1,116 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/history"
+)
+
+// defaultEventsLimit is the number of events returned when the limit is not specified
+const defaultEventsLimit = 500
+
+// Events is the handler that queries recent events retained in the buffer. Events
+// are filtered by the expression given in the filter query parameter, the process
+// identifier in the pid parameter, and the process unique identifier in the uuid
+// parameter. The from and to parameters bound the event timestamp and accept either
+// RFC3339 timestamps or durations relative to the current time, e.g. 5m. Only the
+// most recent events up to the limit are returned in chronological order.
+func Events(buf *history.Buffer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if buf == nil {
+			writeError(w, http.StatusServiceUnavailable, errors.New("recent events buffer is disabled"))
+			return
+		}
+		q, err := parseHistoryQuery(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		evts, err := buf.Query(q)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		resp := make([]json.RawMessage, 0, len(evts))
+		for _, e := range evts {
+			resp = append(resp, e.MarshalJSON())
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+func parseHistoryQuery(r *http.Request) (history.Query, error) {
+	values := r.URL.Query()
+	q := history.Query{
+		Filter: values.Get("filter"),
+		Limit:  defaultEventsLimit,
+	}
+	if l := values.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 0 {
+			return q, fmt.Errorf("invalid limit: %s", l)
+		}
+		q.Limit = n
+	}
+	if pid := values.Get("pid"); pid != "" {
+		n, err := strconv.ParseUint(pid, 10, 32)
+		if err != nil {
+			return q, fmt.Errorf("invalid pid: %s", pid)
+		}
+		q.PID = uint32(n)
+	}
+	if uuid := values.Get("uuid"); uuid != "" {
+		n, err := strconv.ParseUint(uuid, 10, 64)
+		if err != nil {
+			return q, fmt.Errorf("invalid uuid: %s", uuid)
+		}
+		q.UUID = n
+	}
+	var err error
+	if q.From, err = parseTime(values.Get("from")); err != nil {
+		return q, fmt.Errorf("invalid from: %v", err)
+	}
+	if q.To, err = parseTime(values.Get("to")); err != nil {
+		return q, fmt.Errorf("invalid to: %v", err)
+	}
+	return q, nil
+}
+
+// parseTime parses the RFC3339 timestamp or the
+// duration that is subtracted from the current time.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s is neither timestamp nor duration", s)
+	}
+	return time.Now().Add(-d.Abs()), nil
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/alertsender"
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/event/params"
+	htypes "github.com/rabbitstack/fibratus/pkg/handle/types"
+	"github.com/rabbitstack/fibratus/pkg/history"
+	hconfig "github.com/rabbitstack/fibratus/pkg/history/config"
+	"github.com/rabbitstack/fibratus/pkg/ps"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/rabbitstack/fibratus/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serve(t *testing.T, h http.Handler, method, target string, pathValues map[string]string, v any) int {
+	req := httptest.NewRequest(method, target, nil)
+	for k, val := range pathValues {
+		req.SetPathValue(k, val)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	resp := w.Result()
+	defer resp.Body.Close()
+	if v != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+	return resp.StatusCode
+}
+
+func TestRulesHandlers(t *testing.T) {
+	c := &config.Config{
+		EventSource: config.EventSourceConfig{EnableNetEvents: true},
+		Filters: &config.Filters{
+			Rules: config.Rules{
+				FromPaths: []string{"../../rules/_fixtures/simple_matches.yml"},
+			},
+		},
+	}
+	e := rules.NewEngine(new(ps.SnapshotterMock), c)
+	_, err := e.Compile()
+	require.NoError(t, err)
+
+	var res rulesResponse
+	require.Equal(t, http.StatusOK, serve(t, Rules(e), http.MethodGet, "/api/v1/rules", nil, &res))
+	require.NotNil(t, res.Summary)
+	assert.Equal(t, 1, res.Summary.NumberRules)
+	require.Len(t, res.Rules, 1)
+	assert.Equal(t, "match https connections", res.Rules[0].Name)
+	assert.True(t, res.Rules[0].Enabled)
+
+	var rule rules.Rule
+	id := map[string]string{"id": "60ffc2a8-0bde-45c4-9e20-46158250fa91"}
+	require.Equal(t, http.StatusOK, serve(t, EnableRule(e, false), http.MethodPost, "/api/v1/rules/60ffc2a8-0bde-45c4-9e20-46158250fa91/disable", id, &rule))
+	assert.False(t, rule.Enabled)
+	require.Equal(t, http.StatusOK, serve(t, Rule(e), http.MethodGet, "/api/v1/rules/60ffc2a8-0bde-45c4-9e20-46158250fa91", id, &rule))
+	assert.False(t, rule.Enabled)
+	require.Equal(t, http.StatusOK, serve(t, EnableRule(e, true), http.MethodPost, "/api/v1/rules/60ffc2a8-0bde-45c4-9e20-46158250fa91/enable", id, &rule))
+	assert.True(t, rule.Enabled)
+
+	var errResp errorResponse
+	require.Equal(t, http.StatusNotFound, serve(t, Rule(e), http.MethodGet, "/api/v1/rules/unknown", map[string]string{"id": "unknown"}, &errResp))
+	assert.Contains(t, errResp.Error, "not found")
+	// not a sequence rule
+	require.Equal(t, http.StatusNotFound, serve(t, SequencePartials(e), http.MethodGet, "/api/v1/rules/60ffc2a8-0bde-45c4-9e20-46158250fa91/partials", id, nil))
+
+	require.Equal(t, http.StatusServiceUnavailable, serve(t, Rules(nil), http.MethodGet, "/api/v1/rules", nil, &errResp))
+	assert.Equal(t, ErrRuleEngineDisabled.Error(), errResp.Error)
+}
+
+func TestProcessHandlers(t *testing.T) {
+	psnap := new(ps.SnapshotterMock)
+	parent := &pstypes.PS{PID: 4, Name: "System"}
+	proc := &pstypes.PS{
+		PID:     1234,
+		Ppid:    4,
+		Name:    "cmd.exe",
+		Exe:     "C:\\Windows\\System32\\cmd.exe",
+		Parent:  parent,
+		Modules: []pstypes.Module{{Name: "C:\\Windows\\System32\\ntdll.dll", Size: 1024, BaseAddress: 0x7ffb0000}},
+		Handles: []htypes.Handle{{Num: 0x14, Type: "File", Name: "C:\\Windows"}},
+	}
+	psnap.On("Find", uint32(1234)).Return(true, proc)
+	psnap.On("Find", uint32(5678)).Return(false, (*pstypes.PS)(nil))
+
+	pid := map[string]string{"pid": "1234"}
+
+	var p process
+	require.Equal(t, http.StatusOK, serve(t, Process(psnap), http.MethodGet, "/api/v1/ps/1234", pid, &p))
+	assert.Equal(t, "cmd.exe", p.Name)
+	assert.Equal(t, 1, p.Modules)
+	assert.Equal(t, 1, p.Handles)
+	assert.Equal(t, []string{"System (4)"}, p.Ancestors)
+
+	var tree []processNode
+	require.Equal(t, http.StatusOK, serve(t, ProcessTree(psnap), http.MethodGet, "/api/v1/ps/1234/tree", pid, &tree))
+	require.Len(t, tree, 2)
+	assert.Equal(t, uint32(1234), tree[0].PID)
+	assert.Equal(t, uint32(4), tree[1].PID)
+
+	var modules []module
+	require.Equal(t, http.StatusOK, serve(t, ProcessModules(psnap), http.MethodGet, "/api/v1/ps/1234/modules", pid, &modules))
+	require.Len(t, modules, 1)
+	assert.Equal(t, "7ffb0000", modules[0].BaseAddress)
+
+	var handles []map[string]any
+	require.Equal(t, http.StatusOK, serve(t, ProcessHandles(psnap), http.MethodGet, "/api/v1/ps/1234/handles", pid, &handles))
+	require.Len(t, handles, 1)
+	assert.Equal(t, "File", handles[0]["type"])
+
+	require.Equal(t, http.StatusNotFound, serve(t, Process(psnap), http.MethodGet, "/api/v1/ps/5678", map[string]string{"pid": "5678"}, nil))
+	require.Equal(t, http.StatusBadRequest, serve(t, Process(psnap), http.MethodGet, "/api/v1/ps/foo", map[string]string{"pid": "foo"}, nil))
+}
+
+func TestAlertsHandler(t *testing.T) {
+	alertsender.Record(alertsender.NewAlert("Suspicious DLL load", "", nil, alertsender.High))
+	alertsender.Record(alertsender.NewAlert("Credential access", "", nil, alertsender.Critical))
+
+	var alerts []struct {
+		Alert struct {
+			Title    string `json:"title"`
+			Severity string `json:"severity"`
+		} `json:"alert"`
+	}
+	require.Equal(t, http.StatusOK, serve(t, Alerts(), http.MethodGet, "/api/v1/alerts?limit=1", nil, &alerts))
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "Credential access", alerts[0].Alert.Title)
+	assert.Equal(t, "critical", alerts[0].Alert.Severity)
+
+	require.Equal(t, http.StatusBadRequest, serve(t, Alerts(), http.MethodGet, "/api/v1/alerts?limit=x", nil, nil))
+}
+
+func TestEventsHandler(t *testing.T) {
+	cfg := &config.Config{History: hconfig.Config{MaxEvents: 10}, Filters: &config.Filters{}}
+	buf := history.NewBuffer(cfg, new(ps.SnapshotterMock), false)
+
+	for i, name := range []string{"cmd.exe", "notepad.exe", "cmd.exe"} {
+		_, err := buf.ProcessEvent(&event.Event{
+			Seq:       uint64(i + 1),
+			Type:      event.CreateProcess,
+			Name:      "CreateProcess",
+			Category:  event.Process,
+			Timestamp: time.Now(),
+			PID:       uint32(1000 + i),
+			PS:        &pstypes.PS{PID: uint32(1000 + i), Name: name},
+			Params: event.Params{
+				params.ProcessName: {Name: params.ProcessName, Type: params.UnicodeString, Value: name},
+			},
+			Metadata: make(map[event.MetadataKey]any),
+		})
+		require.NoError(t, err)
+	}
+
+	var evts []struct {
+		Seq uint64 `json:"seq"`
+	}
+	require.Equal(t, http.StatusOK, serve(t, Events(buf), http.MethodGet, "/api/v1/events?filter=ps.name+%3D+%27cmd.exe%27&from=5m", nil, &evts))
+	require.Len(t, evts, 2)
+	assert.Equal(t, uint64(1), evts[0].Seq)
+	assert.Equal(t, uint64(3), evts[1].Seq)
+
+	require.Equal(t, http.StatusOK, serve(t, Events(buf), http.MethodGet, "/api/v1/events?pid=1001", nil, &evts))
+	require.Len(t, evts, 1)
+	assert.Equal(t, uint64(2), evts[0].Seq)
+
+	require.Equal(t, http.StatusOK, serve(t, Events(buf), http.MethodGet, "/api/v1/events?limit=1", nil, &evts))
+	require.Len(t, evts, 1)
+	assert.Equal(t, uint64(3), evts[0].Seq)
+
+	require.Equal(t, http.StatusBadRequest, serve(t, Events(buf), http.MethodGet, "/api/v1/events?filter=ps.name+%3D", nil, nil))
+	require.Equal(t, http.StatusBadRequest, serve(t, Events(buf), http.MethodGet, "/api/v1/events?from=yesterday", nil, nil))
+	require.Equal(t, http.StatusServiceUnavailable, serve(t, Events(nil), http.MethodGet, "/api/v1/events", nil, nil))
+}
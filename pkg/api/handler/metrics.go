@@ -0,0 +1,249 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"bytes"
+	"expvar"
+	"fmt"
+	"math"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/rabbitstack/fibratus/pkg/util/histogram"
+)
+
+const (
+	// namespace is prepended to all metric names
+	namespace = "fibratus"
+	// textContentType is the content type of the Prometheus text exposition format
+	textContentType = "text/plain; version=0.0.4; charset=utf-8"
+	// openMetricsContentType is the content type of the OpenMetrics text format
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	// defaultMapLabel is the name of the label holding map keys if not specified by the descriptor
+	defaultMapLabel = "key"
+)
+
+// Metrics is the handler that exposes internal metrics published in expvar
+// in the Prometheus text exposition format. If the scraper accepts the
+// OpenMetrics format, metrics are rendered in the OpenMetrics format instead.
+func Metrics() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e := &exposition{openMetrics: strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")}
+		expvar.Do(func(kv expvar.KeyValue) {
+			e.writeVar(kv.Key, kv.Value)
+		})
+		e.writeRuntime()
+
+		contentType := textContentType
+		if e.openMetrics {
+			e.buf.WriteString("# EOF\n")
+			contentType = openMetricsContentType
+		}
+		w.Header().Set("Content-Type", contentType)
+		if _, err := w.Write(e.buf.Bytes()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// label is the metric label name/value pair.
+type label struct {
+	name  string
+	value string
+}
+
+// exposition renders metric families in the text-based format.
+type exposition struct {
+	buf         bytes.Buffer
+	openMetrics bool
+}
+
+// writeVar converts the expvar variable to the metric family. Variables
+// of unsupported types, such as functions or strings, are skipped.
+func (e *exposition) writeVar(key string, v expvar.Var) {
+	d, ok := descriptors[key]
+	if !ok || d.help == "" {
+		d.help = "Value of the " + key + " variable"
+	}
+	if d.label == "" {
+		d.label = defaultMapLabel
+	}
+	name := metricName(key)
+
+	switch v := v.(type) {
+	case *expvar.Int:
+		typ := typeOrDefault(d.typ, typeCounter)
+		e.writeHeader(name, typ, d.help)
+		e.writeSample(sampleName(name, typ), nil, float64(v.Value()))
+	case *expvar.Float:
+		e.writeHeader(name, typeOrDefault(d.typ, typeGauge), d.help)
+		e.writeSample(sampleName(name, typeOrDefault(d.typ, typeGauge)), nil, v.Value())
+	case *expvar.Map:
+		typ := typeOrDefault(d.typ, typeCounter)
+		e.writeHeader(name, typ, d.help)
+		var sum float64
+		v.Do(func(kv expvar.KeyValue) {
+			val, ok := numericValue(kv.Value)
+			if !ok {
+				return
+			}
+			if d.aggregate {
+				sum += val
+				return
+			}
+			e.writeSample(sampleName(name, typ), []label{{d.label, kv.Key}}, val)
+		})
+		if d.aggregate {
+			e.writeSample(sampleName(name, typ), nil, sum)
+		}
+	case *histogram.Histogram:
+		e.writeHeader(name, typeHistogram, d.help)
+		e.writeHistogram(name, nil, v.Snapshot())
+	case *histogram.Map:
+		e.writeHeader(name, typeHistogram, d.help)
+		v.Do(func(k string, h *histogram.Histogram) {
+			e.writeHistogram(name, []label{{d.label, k}}, h.Snapshot())
+		})
+	}
+}
+
+// writeRuntime renders the basic Go runtime metrics.
+func (e *exposition) writeRuntime() {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	e.writeHeader("go_goroutines", typeGauge, "Number of goroutines that currently exist")
+	e.writeSample("go_goroutines", nil, float64(runtime.NumGoroutine()))
+	e.writeHeader("go_memstats_alloc_bytes", typeGauge, "Number of bytes allocated and still in use")
+	e.writeSample("go_memstats_alloc_bytes", nil, float64(ms.Alloc))
+	e.writeHeader("go_memstats_heap_objects", typeGauge, "Number of allocated objects")
+	e.writeSample("go_memstats_heap_objects", nil, float64(ms.HeapObjects))
+	e.writeHeader("go_memstats_sys_bytes", typeGauge, "Number of bytes obtained from the system")
+	e.writeSample("go_memstats_sys_bytes", nil, float64(ms.Sys))
+	e.writeHeader("go_gc_cycles", typeCounter, "Number of completed GC cycles")
+	e.writeSample(sampleName("go_gc_cycles", typeCounter), nil, float64(ms.NumGC))
+}
+
+func (e *exposition) writeHeader(name string, typ metricType, help string) {
+	// the text format expects the family name to
+	// match the counter sample name including the
+	// _total suffix, while OpenMetrics omits it
+	family := name
+	if !e.openMetrics {
+		family = sampleName(name, typ)
+	}
+	fmt.Fprintf(&e.buf, "# HELP %s %s\n", family, escapeHelp(help))
+	fmt.Fprintf(&e.buf, "# TYPE %s %s\n", family, typ)
+}
+
+func (e *exposition) writeSample(name string, labels []label, value float64) {
+	e.buf.WriteString(name)
+	if len(labels) > 0 {
+		e.buf.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+			e.buf.WriteString(l.name)
+			e.buf.WriteString(`="`)
+			e.buf.WriteString(escapeLabelValue(l.value))
+			e.buf.WriteByte('"')
+		}
+		e.buf.WriteByte('}')
+	}
+	e.buf.WriteByte(' ')
+	e.buf.WriteString(formatFloat(value))
+	e.buf.WriteByte('\n')
+}
+
+func (e *exposition) writeHistogram(name string, labels []label, s histogram.Snapshot) {
+	for _, b := range s.Buckets {
+		e.writeSample(name+"_bucket", append(labels, label{"le", formatFloat(b.UpperBound)}), float64(b.Count))
+	}
+	e.writeSample(name+"_bucket", append(labels, label{"le", "+Inf"}), float64(s.Count))
+	e.writeSample(name+"_sum", labels, s.Sum)
+	e.writeSample(name+"_count", labels, float64(s.Count))
+}
+
+// metricName converts the expvar key to the valid metric name.
+// All characters outside the [a-zA-Z0-9_] range are replaced
+// with underscores.
+func metricName(key string) string {
+	var sb strings.Builder
+	sb.Grow(len(namespace) + len(key) + 1)
+	sb.WriteString(namespace)
+	sb.WriteByte('_')
+	for _, c := range key {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_':
+			sb.WriteRune(c)
+		default:
+			sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
+
+// sampleName returns the name of the sample. Counters have the _total suffix.
+func sampleName(name string, typ metricType) string {
+	if typ == typeCounter {
+		return name + "_total"
+	}
+	return name
+}
+
+func typeOrDefault(typ, def metricType) metricType {
+	if typ == "" {
+		return def
+	}
+	return typ
+}
+
+func numericValue(v expvar.Var) (float64, bool) {
+	switch v := v.(type) {
+	case *expvar.Int:
+		return float64(v.Value()), true
+	case *expvar.Float:
+		return v.Value(), true
+	}
+	return 0, false
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string       { return helpReplacer.Replace(s) }
+func escapeLabelValue(s string) string { return labelReplacer.Replace(s) }
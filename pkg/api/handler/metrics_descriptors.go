@@ -0,0 +1,118 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+// metricType is the Prometheus metric type.
+type metricType string
+
+const (
+	typeCounter   metricType = "counter"
+	typeGauge     metricType = "gauge"
+	typeHistogram metricType = "histogram"
+)
+
+// descriptor provides the metadata of the expvar metric that
+// can't be inferred from the variable itself.
+type descriptor struct {
+	typ  metricType
+	help string
+	// label is the name of the label that holds map keys
+	label string
+	// aggregate indicates map keys are unbounded and the map
+	// is exported as a single sum without labels
+	aggregate bool
+}
+
+// descriptors contains metadata for known metrics. Integer metrics that aren't
+// listed are exported as counters, and maps have their keys in the key label.
+var descriptors = map[string]descriptor{
+	// aggregator
+	"aggregator.batch.events":                 {help: "Total number of events pushed to output batches"},
+	"aggregator.event.errors":                 {help: "Total number of errors received from the event stream"},
+	"aggregator.events.dequeued":              {help: "Total number of events dequeued by the aggregator"},
+	"aggregator.flushes.count":                {help: "Total number of batch flushes"},
+	"aggregator.rollup.events":                {help: "Total number of events folded into rollup summaries"},
+	"aggregator.rollup.summaries":             {help: "Total number of emitted rollup summary events"},
+	"aggregator.sampler.dropped":              {help: "Total number of events discarded by the sampling policy", label: "policy"},
+	"aggregator.sampler.sampled":              {help: "Total number of events kept by the sampling policy", label: "policy"},
+	"aggregator.transformer.errors":           {help: "Total number of transformer errors", label: "error"},
+	"aggregator.worker.client.publish.errors": {help: "Total number of batches that failed to publish"},
+	// rules
+	"filter.filters.count":             {typ: typeGauge, help: "Number of loaded filters"},
+	"filter.matches":                   {help: "Total number of rule matches", label: "rule"},
+	"filter.accessor.errors":           {help: "Total number of filter field accessor errors", label: "error"},
+	"rules.evaluation.seconds":         {typ: typeHistogram, help: "Time spent evaluating the ruleset against the event"},
+	"sequence.partials.count":          {typ: typeGauge, help: "Number of partial matches held by the sequence rule", label: "rule"},
+	"sequence.partial.expirations":     {help: "Total number of expired sequence partials", label: "rule"},
+	"sequence.partial.breaches":        {help: "Total number of partials dropped due to the max partials limit", label: "rule"},
+	"sequence.match.transition.errors": {help: "Total number of sequence state machine transition errors"},
+	// outputs
+	"output.publish.seconds":            {typ: typeHistogram, help: "Time spent publishing the batch to the output", label: "output"},
+	"elasticsearch.failed.docs.reasons": {help: "Total number of failed documents by error type", label: "reason"},
+	// capture and event source
+	"cap.read.events":              {help: "Total number of events read from the capture file"},
+	"cap.read.bytes":               {help: "Total number of bytes read from the capture file"},
+	"cap.flusher.errors":           {help: "Total number of capture flusher errors", label: "error"},
+	"eventsource.events.enqueued":  {help: "Total number of events pushed to the event queue"},
+	"eventsource.events.processed": {help: "Total number of events processed by the event source"},
+	"eventsource.events.excluded":  {help: "Total number of events excluded by the event source"},
+	"eventsource.events.unknown":   {help: "Total number of events with unknown types"},
+	"eventsource.buffers.read":     {help: "Total number of read trace buffers"},
+	"eventsource.events.failed":    {help: "Total number of events that failed to process", label: "error"},
+	"event.seq.init.errors":        {help: "Total number of sequencer initialization errors", label: "error"},
+	// process and handle state
+	"process.count":                {typ: typeGauge, help: "Number of processes in the snapshotter"},
+	"process.thread.count":         {typ: typeGauge, help: "Number of threads in the snapshotter"},
+	"process.module.count":         {typ: typeGauge, help: "Number of modules in the snapshotter"},
+	"process.mmap.count":           {typ: typeGauge, help: "Number of memory-mapped files in the snapshotter"},
+	"process.lookup.failure.count": {help: "Total number of failed process lookups", aggregate: true},
+	"handle.snapshot.count":        {typ: typeGauge, help: "Number of handles in the snapshotter"},
+	"handle.snapshot.bytes":        {typ: typeGauge, help: "Approximate size of the handle snapshotter in bytes"},
+	"handle.types.count":           {typ: typeGauge, help: "Number of handle object types"},
+	"handle.name.query.failures":   {help: "Total number of failed handle name queries", aggregate: true},
+	"va.region.prober.rate.limits": {help: "Total number of rate limited memory region probes", aggregate: true},
+	"fs.metadata.count":            {typ: typeGauge, help: "Number of cached file metadata entries"},
+	"fs.total.map.rundown.files":   {typ: typeGauge, help: "Number of mapped files from the rundown"},
+	"registry.kcb.count":           {typ: typeGauge, help: "Number of registry key control blocks"},
+	"dns.reverse.total.names":      {typ: typeGauge, help: "Number of cached reverse DNS names"},
+	"dns.reverse.failed.lookups":   {help: "Total number of failed reverse DNS lookups", aggregate: true},
+	"signature.count":              {typ: typeGauge, help: "Number of cached module signatures"},
+	"symbolizer.modules.count":     {typ: typeGauge, help: "Number of modules loaded by the symbolizer"},
+	"symbolizer.cached.symbols":    {typ: typeGauge, help: "Number of cached symbols"},
+	"yara.rules.in.compiler":       {typ: typeGauge, help: "Number of rules in the YARA compiler"},
+	"yara.total.scans":             {help: "Total number of YARA scans"},
+	"yara.rule.matches":            {help: "Total number of YARA rule matches"},
+	"logger.errors":                {help: "Total number of logger errors", label: "error"},
+	"hostname.errors":              {help: "Total number of hostname resolution errors", label: "error"},
+	"filament.event.errors":        {help: "Total number of filament event processing errors", label: "error"},
+	"pe.parser.warnings":           {help: "Total number of PE parser warnings", aggregate: true},
+	// event streaming
+	"api.stream.clients":        {typ: typeGauge, help: "Number of connected event streaming clients"},
+	"api.stream.events.sent":    {help: "Total number of events delivered to streaming clients"},
+	"api.stream.events.dropped": {help: "Total number of events dropped for streaming clients", label: "reason"},
+	// recent events buffer
+	"history.events":         {typ: typeGauge, help: "Number of events retained in the recent events buffer"},
+	"history.bytes":          {typ: typeGauge, help: "Approximate memory in bytes occupied by retained events"},
+	"history.events.evicted": {help: "Total number of events evicted from the recent events buffer", label: "reason"},
+	// self-tracing
+	"tracing.spans.recorded": {help: "Total number of recorded tracing spans"},
+	"tracing.spans.dropped":  {help: "Total number of tracing spans dropped due to full export queue"},
+	"tracing.spans.exported": {help: "Total number of exported tracing spans"},
+	"tracing.export.errors":  {help: "Total number of failed tracing span exports"},
+}
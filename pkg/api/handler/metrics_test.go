@@ -0,0 +1,122 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"expvar"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rabbitstack/fibratus/pkg/util/histogram"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, accept string) (string, string) {
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	Metrics().ServeHTTP(w, req)
+	resp := w.Result()
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body), resp.Header.Get("Content-Type")
+}
+
+func TestMetrics(t *testing.T) {
+	descriptors["test.active.count"] = descriptor{typ: typeGauge, help: "Number of active items"}
+	descriptors["test.filter.matches"] = descriptor{help: "Total number of rule matches", label: "rule"}
+	descriptors["test.lookup.failures"] = descriptor{help: "Total number of lookup failures", aggregate: true}
+	descriptors["test.publish.seconds"] = descriptor{typ: typeHistogram, help: "Publish latency", label: "output"}
+
+	expvar.NewInt("test.events.processed").Add(10)
+	expvar.NewInt("test.active.count").Set(25)
+	expvar.NewFloat("test.ratio").Set(0.5)
+
+	matches := expvar.NewMap("test.filter.matches")
+	matches.Add("Suspicious \"DLL\" load", 2)
+	matches.Add("Credential access", 1)
+
+	failures := expvar.NewMap("test.lookup.failures")
+	failures.Add("1234", 3)
+	failures.Add("4321", 2)
+
+	h := histogram.New("test.evaluation.seconds", []float64{0.001, 0.01})
+	h.Observe(0.0005)
+	h.Observe(0.005)
+	h.Observe(0.5)
+
+	hm := histogram.NewMap("test.publish.seconds", []float64{0.1})
+	hm.Get("elasticsearch").Observe(0.05)
+
+	body, contentType := scrape(t, "")
+	assert.Equal(t, textContentType, contentType)
+
+	expected := []string{
+		"# HELP fibratus_test_events_processed_total Value of the test.events.processed variable",
+		"# TYPE fibratus_test_events_processed_total counter",
+		"fibratus_test_events_processed_total 10",
+		"# HELP fibratus_test_active_count Number of active items",
+		"# TYPE fibratus_test_active_count gauge",
+		"fibratus_test_active_count 25",
+		"# TYPE fibratus_test_ratio gauge",
+		"fibratus_test_ratio 0.5",
+		"# HELP fibratus_test_filter_matches_total Total number of rule matches",
+		`fibratus_test_filter_matches_total{rule="Suspicious \"DLL\" load"} 2`,
+		`fibratus_test_filter_matches_total{rule="Credential access"} 1`,
+		"fibratus_test_lookup_failures_total 5",
+		"# TYPE fibratus_test_evaluation_seconds histogram",
+		`fibratus_test_evaluation_seconds_bucket{le="0.001"} 1`,
+		`fibratus_test_evaluation_seconds_bucket{le="0.01"} 2`,
+		`fibratus_test_evaluation_seconds_bucket{le="+Inf"} 3`,
+		"fibratus_test_evaluation_seconds_sum 0.5055",
+		"fibratus_test_evaluation_seconds_count 3",
+		"# HELP fibratus_test_publish_seconds Publish latency",
+		`fibratus_test_publish_seconds_bucket{output="elasticsearch",le="0.1"} 1`,
+		`fibratus_test_publish_seconds_count{output="elasticsearch"} 1`,
+		"# TYPE go_goroutines gauge",
+		"# TYPE go_gc_cycles_total counter",
+	}
+	for _, line := range expected {
+		assert.Contains(t, body, line+"\n")
+	}
+	// functions such as memstats are not exported
+	assert.NotContains(t, body, "fibratus_memstats")
+	assert.NotContains(t, body, "# EOF")
+}
+
+func TestMetricsOpenMetrics(t *testing.T) {
+	body, contentType := scrape(t, "application/openmetrics-text; version=1.0.0")
+	assert.Equal(t, openMetricsContentType, contentType)
+	assert.True(t, strings.HasSuffix(body, "# EOF\n"))
+	assert.Contains(t, body, "# TYPE go_gc_cycles counter\n")
+	assert.Contains(t, body, "go_gc_cycles_total ")
+}
+
+func TestMetricName(t *testing.T) {
+	assert.Equal(t, "fibratus_aggregator_events_dequeued", metricName("aggregator.events.dequeued"))
+	assert.Equal(t, "fibratus_cap_read_bytes", metricName("cap.read-bytes"))
+}
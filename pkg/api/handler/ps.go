@@ -0,0 +1,189 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	htypes "github.com/rabbitstack/fibratus/pkg/handle/types"
+	"github.com/rabbitstack/fibratus/pkg/ps"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+)
+
+// process is the JSON representation of the process state.
+type process struct {
+	PID            uint32    `json:"pid"`
+	Ppid           uint32    `json:"ppid"`
+	UUID           uint64    `json:"uuid"`
+	Name           string    `json:"name"`
+	Exe            string    `json:"exe"`
+	Cmdline        string    `json:"cmdline"`
+	Cwd            string    `json:"cwd,omitempty"`
+	SID            string    `json:"sid"`
+	Username       string    `json:"username"`
+	Domain         string    `json:"domain"`
+	SessionID      uint32    `json:"session_id"`
+	StartTime      time.Time `json:"start_time"`
+	IntegrityLevel string    `json:"integrity_level,omitempty"`
+	ElevationType  string    `json:"elevation_type,omitempty"`
+	IsElevated     bool      `json:"is_elevated"`
+	IsWOW64        bool      `json:"is_wow64"`
+	IsPackaged     bool      `json:"is_packaged"`
+	IsProtected    bool      `json:"is_protected"`
+	Threads        int       `json:"threads"`
+	Modules        int       `json:"modules"`
+	Handles        int       `json:"handles"`
+	Ancestors      []string  `json:"ancestors"`
+}
+
+// processNode is the process in the process tree lineage.
+type processNode struct {
+	PID       uint32    `json:"pid"`
+	Ppid      uint32    `json:"ppid"`
+	UUID      uint64    `json:"uuid"`
+	Name      string    `json:"name"`
+	Exe       string    `json:"exe"`
+	Cmdline   string    `json:"cmdline"`
+	StartTime time.Time `json:"start_time"`
+}
+
+// module is the JSON representation of the module loaded in the process address space.
+type module struct {
+	Name               string `json:"name"`
+	Size               uint64 `json:"size"`
+	Checksum           uint32 `json:"checksum"`
+	BaseAddress        string `json:"base_address"`
+	DefaultBaseAddress string `json:"default_base_address"`
+	SignatureLevel     uint32 `json:"signature_level"`
+	SignatureType      uint32 `json:"signature_type"`
+}
+
+// Process is the handler that returns the state of the process identified by the pid path value.
+func Process(psnap ps.Snapshotter) http.Handler {
+	return withProcess(psnap, func(w http.ResponseWriter, proc *pstypes.PS) {
+		proc.RLock()
+		defer proc.RUnlock()
+		writeJSON(w, http.StatusOK, process{
+			PID:            proc.PID,
+			Ppid:           proc.Ppid,
+			UUID:           proc.UUID(),
+			Name:           proc.Name,
+			Exe:            proc.Exe,
+			Cmdline:        proc.Cmdline,
+			Cwd:            proc.Cwd,
+			SID:            proc.SID,
+			Username:       proc.Username,
+			Domain:         proc.Domain,
+			SessionID:      proc.SessionID,
+			StartTime:      proc.StartTime,
+			IntegrityLevel: proc.TokenIntegrityLevel,
+			ElevationType:  proc.TokenElevationType,
+			IsElevated:     proc.IsTokenElevated,
+			IsWOW64:        proc.IsWOW64,
+			IsPackaged:     proc.IsPackaged,
+			IsProtected:    proc.IsProtected,
+			Threads:        len(proc.Threads),
+			Modules:        len(proc.Modules),
+			Handles:        len(proc.Handles),
+			Ancestors:      proc.Ancestors(),
+		})
+	})
+}
+
+// ProcessTree is the handler that returns the lineage of the process identified by the
+// pid path value. The first node is the process itself followed by all its ancestors.
+func ProcessTree(psnap ps.Snapshotter) http.Handler {
+	return withProcess(psnap, func(w http.ResponseWriter, proc *pstypes.PS) {
+		tree := []processNode{newProcessNode(proc)}
+		pstypes.Walk(func(parent *pstypes.PS) {
+			tree = append(tree, newProcessNode(parent))
+		}, proc)
+		writeJSON(w, http.StatusOK, tree)
+	})
+}
+
+// ProcessModules is the handler that returns modules loaded by the process identified by the pid path value.
+func ProcessModules(psnap ps.Snapshotter) http.Handler {
+	return withProcess(psnap, func(w http.ResponseWriter, proc *pstypes.PS) {
+		proc.RLock()
+		defer proc.RUnlock()
+		modules := make([]module, 0, len(proc.Modules))
+		for _, m := range proc.Modules {
+			modules = append(modules, module{
+				Name:               m.Name,
+				Size:               m.Size,
+				Checksum:           m.Checksum,
+				BaseAddress:        m.BaseAddress.String(),
+				DefaultBaseAddress: m.DefaultBaseAddress.String(),
+				SignatureLevel:     m.SignatureLevel,
+				SignatureType:      m.SignatureType,
+			})
+		}
+		writeJSON(w, http.StatusOK, modules)
+	})
+}
+
+// ProcessHandles is the handler that returns handles owned by the process identified by the pid path value.
+func ProcessHandles(psnap ps.Snapshotter) http.Handler {
+	return withProcess(psnap, func(w http.ResponseWriter, proc *pstypes.PS) {
+		proc.RLock()
+		defer proc.RUnlock()
+		handles := make(htypes.Handles, len(proc.Handles))
+		copy(handles, proc.Handles)
+		writeJSON(w, http.StatusOK, handles)
+	})
+}
+
+func newProcessNode(proc *pstypes.PS) processNode {
+	return processNode{
+		PID:       proc.PID,
+		Ppid:      proc.Ppid,
+		UUID:      proc.UUID(),
+		Name:      proc.Name,
+		Exe:       proc.Exe,
+		Cmdline:   proc.Cmdline,
+		StartTime: proc.StartTime,
+	}
+}
+
+// withProcess resolves the process from the pid path value and invokes the
+// function with the process state. If the process doesn't exist in the snapshotter,
+// the not found status is returned.
+func withProcess(psnap ps.Snapshotter, fn func(http.ResponseWriter, *pstypes.PS)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if psnap == nil {
+			writeError(w, http.StatusServiceUnavailable, fmt.Errorf("process snapshotter is not available"))
+			return
+		}
+		pid, err := strconv.ParseUint(r.PathValue("pid"), 10, 32)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid pid: %s", r.PathValue("pid")))
+			return
+		}
+		ok, proc := psnap.Find(uint32(pid))
+		if !ok || proc == nil {
+			writeError(w, http.StatusNotFound, fmt.Errorf("process %d not found", pid))
+			return
+		}
+		fn(w, proc)
+	})
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/rules"
+)
+
+// ErrRuleEngineDisabled is returned when rule endpoints are requested, but the rule engine is not running.
+var ErrRuleEngineDisabled = errors.New("rule engine is disabled")
+
+// compileSummary is the JSON representation of the ruleset compile result.
+type compileSummary struct {
+	NumberRules int       `json:"number_rules"`
+	UsedEvents  []string  `json:"used_events"`
+	Approvers   approvers `json:"approvers"`
+}
+
+// approvers contains approver predicates extracted from rule conditions indexed by operator.
+type approvers struct {
+	Keys        map[string][]string `json:"keys"`
+	Paths       map[string][]string `json:"paths"`
+	Extensions  map[string][]string `json:"extensions"`
+	Bases       map[string][]string `json:"bases"`
+	Executables map[string][]string `json:"executables"`
+}
+
+// rulesResponse contains the compile summary and the runtime state of all loaded rules.
+type rulesResponse struct {
+	Summary *compileSummary `json:"summary,omitempty"`
+	Rules   []rules.Rule    `json:"rules"`
+}
+
+func newCompileSummary(rs *config.RulesCompileResult) *compileSummary {
+	if rs == nil {
+		return nil
+	}
+	s := &compileSummary{
+		NumberRules: rs.NumberRules,
+		UsedEvents:  make([]string, 0, len(rs.UsedEvents)),
+		Approvers: approvers{
+			Keys:        rs.Approvers.Keys,
+			Paths:       rs.Approvers.Paths,
+			Extensions:  rs.Approvers.Extensions,
+			Bases:       rs.Approvers.Bases,
+			Executables: rs.Approvers.Executables,
+		},
+	}
+	for _, typ := range rs.UsedEvents {
+		s.UsedEvents = append(s.UsedEvents, typ.String())
+	}
+	return s
+}
+
+// Rules is the handler that lists all loaded rules along with the ruleset compile summary.
+func Rules(e *rules.Engine) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if e == nil {
+			writeError(w, http.StatusServiceUnavailable, ErrRuleEngineDisabled)
+			return
+		}
+		writeJSON(w, http.StatusOK, rulesResponse{
+			Summary: newCompileSummary(e.CompileResult()),
+			Rules:   e.Rules(),
+		})
+	})
+}
+
+// Rule is the handler that returns the state of the rule identified by the id or name path value.
+func Rule(e *rules.Engine) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if e == nil {
+			writeError(w, http.StatusServiceUnavailable, ErrRuleEngineDisabled)
+			return
+		}
+		rule, err := e.FindRule(r.PathValue("id"))
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, rule)
+	})
+}
+
+// EnableRule is the handler that enables or disables the rule at runtime
+// and responds with the updated rule state.
+func EnableRule(e *rules.Engine, enabled bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if e == nil {
+			writeError(w, http.StatusServiceUnavailable, ErrRuleEngineDisabled)
+			return
+		}
+		id := r.PathValue("id")
+		if err := e.EnableRule(id, enabled); err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		rule, err := e.FindRule(id)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, rule)
+	})
+}
+
+// SequencePartials is the handler that returns the live partials of the sequence rule.
+func SequencePartials(e *rules.Engine) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if e == nil {
+			writeError(w, http.StatusServiceUnavailable, ErrRuleEngineDisabled)
+			return
+		}
+		state, err := e.SequenceState(r.PathValue("id"))
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, state)
+	})
+}
@@ -0,0 +1,185 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/api/stream"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/websocket"
+)
+
+// keepaliveInterval determines how often the keepalive message is
+// sent to streaming clients. Dropped events counter is reported
+// along with the keepalive if it changed since the last report.
+var keepaliveInterval = time.Second * 15
+
+// Stream is the handler that pushes events matching the filter expression
+// to the client. The filter expression is given in the filter query parameter,
+// while the optional rate parameter can lower the max number of events per second
+// delivered to the client. Events are streamed over WebSocket if the client requests
+// the connection upgrade, or as Server-Sent Events otherwise.
+func Stream(hub *stream.Hub) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hub == nil {
+			writeError(w, http.StatusServiceUnavailable, errors.New("event streaming is disabled"))
+			return
+		}
+		var limit float64
+		if l := r.URL.Query().Get("rate"); l != "" {
+			var err error
+			limit, err = strconv.ParseFloat(l, 64)
+			if err != nil || limit < 0 {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid rate: %s", l))
+				return
+			}
+		}
+		client, err := hub.Subscribe(r.URL.Query().Get("filter"), limit)
+		switch {
+		case errors.Is(err, stream.ErrTooManyClients):
+			writeError(w, http.StatusServiceUnavailable, err)
+			return
+		case err != nil:
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		defer hub.Unsubscribe(client)
+
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			srv := websocket.Server{
+				// clients are authenticated by the token or
+				// the client certificate, so the origin
+				// verification is skipped
+				Handshake: func(*websocket.Config, *http.Request) error { return nil },
+				Handler: func(conn *websocket.Conn) {
+					streamWebSocket(conn, client)
+				},
+			}
+			srv.ServeHTTP(w, r)
+			return
+		}
+
+		streamSSE(w, r, client)
+	})
+}
+
+// streamSSE writes events in the Server-Sent Events format. Each
+// event is sent with the event type, while the number of dropped
+// events is reported with the dropped event type.
+func streamSSE(w http.ResponseWriter, r *http.Request, client *stream.Client) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported by the transport"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepaliveInterval)
+	defer ticker.Stop()
+
+	var dropped uint64
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case buf := <-client.Events():
+			if _, err := fmt.Fprintf(w, "event: event\ndata: %s\n\n", buf); err != nil {
+				return
+			}
+			// drain buffered events before flushing
+			for n := len(client.Events()); n > 0; n-- {
+				if _, err := fmt.Fprintf(w, "event: event\ndata: %s\n\n", <-client.Events()); err != nil {
+					return
+				}
+			}
+			flusher.Flush()
+		case <-ticker.C:
+			var err error
+			if d := client.Dropped(); d != dropped {
+				dropped = d
+				_, err = fmt.Fprintf(w, "event: dropped\ndata: {\"dropped\":%d}\n\n", dropped)
+			} else {
+				_, err = io.WriteString(w, ": keepalive\n\n")
+			}
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// streamWebSocket writes events as WebSocket text messages. Each
+// message is a JSON object with the type field designating whether
+// the message contains the event or the number of dropped events.
+func streamWebSocket(conn *websocket.Conn, client *stream.Client) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// the client is not expected to send any messages,
+	// but we have to read from the connection to detect
+	// when it's closed by the peer
+	go func() {
+		defer cancel()
+		var msg []byte
+		for {
+			if err := websocket.Message.Receive(conn, &msg); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(keepaliveInterval)
+	defer ticker.Stop()
+
+	var dropped uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case buf := <-client.Events():
+			msg := make([]byte, 0, len(buf)+26)
+			msg = append(msg, `{"type":"event","event":`...)
+			msg = append(msg, buf...)
+			msg = append(msg, '}')
+			if err := websocket.Message.Send(conn, string(msg)); err != nil {
+				log.Debugf("unable to send event to WebSocket client: %v", err)
+				return
+			}
+		case <-ticker.C:
+			if d := client.Dropped(); d != dropped {
+				dropped = d
+				if err := websocket.Message.Send(conn, fmt.Sprintf(`{"type":"dropped","dropped":%d}`, dropped)); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
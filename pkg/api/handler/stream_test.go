@@ -0,0 +1,123 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/api/stream"
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/event/params"
+	"github.com/rabbitstack/fibratus/pkg/ps"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func newStreamEvent(seq uint64, name string) *event.Event {
+	return &event.Event{
+		Seq:       seq,
+		Type:      event.CreateProcess,
+		Name:      "CreateProcess",
+		Category:  event.Process,
+		Timestamp: time.Now(),
+		PID:       1234,
+		PS:        &pstypes.PS{PID: 1234, Name: name},
+		Params: event.Params{
+			params.ProcessName: {Name: params.ProcessName, Type: params.UnicodeString, Value: name},
+		},
+		Metadata: make(map[event.MetadataKey]any),
+	}
+}
+
+func newStreamHub() *stream.Hub {
+	c := &config.Config{
+		API:     config.APIConfig{Stream: config.StreamConfig{BufferSize: 16}},
+		Filters: &config.Filters{},
+	}
+	return stream.NewHub(c, new(ps.SnapshotterMock), false)
+}
+
+func TestStreamSSE(t *testing.T) {
+	hub := newStreamHub()
+	srv := httptest.NewServer(Stream(hub))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?filter=" + url.QueryEscape("ps.name = 'cmd.exe'"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	require.Eventually(t, func() bool { return hub.NumClients() == 1 }, time.Second*5, time.Millisecond*10)
+
+	for i, name := range []string{"notepad.exe", "cmd.exe"} {
+		_, err := hub.ProcessEvent(newStreamEvent(uint64(i+1), name))
+		require.NoError(t, err)
+	}
+
+	r := bufio.NewReader(resp.Body)
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: event\n", line)
+	line, err = r.ReadString('\n')
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(line, "data: {"))
+	assert.Contains(t, line, `"seq":2`)
+}
+
+func TestStreamWebSocket(t *testing.T) {
+	hub := newStreamHub()
+	srv := httptest.NewServer(Stream(hub))
+	defer srv.Close()
+
+	u := "ws" + strings.TrimPrefix(srv.URL, "http") + "?filter=" + url.QueryEscape("ps.name = 'cmd.exe'")
+	conn, err := websocket.Dial(u, "", srv.URL)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Eventually(t, func() bool { return hub.NumClients() == 1 }, time.Second*5, time.Millisecond*10)
+
+	_, err = hub.ProcessEvent(newStreamEvent(1, "cmd.exe"))
+	require.NoError(t, err)
+
+	var msg string
+	require.NoError(t, websocket.Message.Receive(conn, &msg))
+	assert.True(t, strings.HasPrefix(msg, `{"type":"event","event":{`))
+	assert.Contains(t, msg, `"seq":1`)
+
+	require.NoError(t, conn.Close())
+	require.Eventually(t, func() bool { return hub.NumClients() == 0 }, time.Second*5, time.Millisecond*10)
+}
+
+func TestStreamInvalidFilter(t *testing.T) {
+	hub := newStreamHub()
+	var errResp errorResponse
+	require.Equal(t, http.StatusBadRequest, serve(t, Stream(hub), http.MethodGet, "/api/v1/events/stream?filter=ps.name+%3D", nil, &errResp))
+	assert.Contains(t, errResp.Error, "invalid filter expression")
+	require.Equal(t, http.StatusServiceUnavailable, serve(t, Stream(nil), http.MethodGet, "/api/v1/events/stream", nil, nil))
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"github.com/rabbitstack/fibratus/pkg/api/stream"
+	"github.com/rabbitstack/fibratus/pkg/history"
+	"github.com/rabbitstack/fibratus/pkg/ps"
+	"github.com/rabbitstack/fibratus/pkg/rules"
+)
+
+// Option customizes the components exposed by the API server.
+type Option func(*opts)
+
+type opts struct {
+	engine *rules.Engine
+	psnap  ps.Snapshotter
+	hub    *stream.Hub
+	buf    *history.Buffer
+}
+
+// WithRuleEngine exposes the rule engine state through the management endpoints.
+func WithRuleEngine(engine *rules.Engine) Option {
+	return func(o *opts) {
+		o.engine = engine
+	}
+}
+
+// WithProcessSnapshotter exposes the process snapshotter through the management endpoints.
+func WithProcessSnapshotter(psnap ps.Snapshotter) Option {
+	return func(o *opts) {
+		o.psnap = psnap
+	}
+}
+
+// WithStreamHub exposes the live event streaming endpoint backed by the streaming hub.
+func WithStreamHub(hub *stream.Hub) Option {
+	return func(o *opts) {
+		o.hub = hub
+	}
+}
+
+// WithEventHistory exposes recent events retained in the buffer through the management endpoints.
+func WithEventHistory(buf *history.Buffer) Option {
+	return func(o *opts) {
+		o.buf = buf
+	}
+}
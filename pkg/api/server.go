@@ -30,10 +30,13 @@ import (
 	"strings"
 )
 
-func setupServer(lis net.Listener, c *config.Config) {
+func setupServer(lis net.Listener, c *config.Config, o opts) {
 	mux := http.NewServeMux()
 	mux.Handle("/config", handler.Config(c))
 	mux.Handle("/debug/vars", expvar.Handler())
+	mux.Handle("/metrics", handler.Metrics())
+	mux.Handle("/healthz", handler.Health(c.Health))
+	mux.Handle("/readyz", handler.Ready(c.Health))
 
 	mux.HandleFunc("/debug/pprof/", pprof.Index)
 	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
@@ -41,6 +44,12 @@ func setupServer(lis net.Listener, c *config.Config) {
 		debug.FreeOSMemory()
 	})
 
+	if c.API.IsManagementEnabled() {
+		setupManagementEndpoints(mux, c.API, o)
+	} else {
+		log.Info("API management endpoints are disabled. Configure token or mutual TLS authentication to enable them")
+	}
+
 	srv := &http.Server{
 		Handler: mux,
 	}
@@ -54,3 +63,28 @@ func setupServer(lis net.Listener, c *config.Config) {
 		}
 	}()
 }
+
+// setupManagementEndpoints registers authenticated endpoints for
+// inspecting and controlling the rule engine, querying the process
+// snapshotter, fetching recent alerts and events, and streaming live events.
+func setupManagementEndpoints(mux *http.ServeMux, c config.APIConfig, o opts) {
+	handle := func(pattern string, h http.Handler) {
+		mux.Handle(pattern, authenticate(c, h))
+	}
+
+	handle("GET /api/v1/rules", handler.Rules(o.engine))
+	handle("GET /api/v1/rules/{id}", handler.Rule(o.engine))
+	handle("POST /api/v1/rules/{id}/enable", handler.EnableRule(o.engine, true))
+	handle("POST /api/v1/rules/{id}/disable", handler.EnableRule(o.engine, false))
+	handle("GET /api/v1/rules/{id}/partials", handler.SequencePartials(o.engine))
+
+	handle("GET /api/v1/ps/{pid}", handler.Process(o.psnap))
+	handle("GET /api/v1/ps/{pid}/tree", handler.ProcessTree(o.psnap))
+	handle("GET /api/v1/ps/{pid}/modules", handler.ProcessModules(o.psnap))
+	handle("GET /api/v1/ps/{pid}/handles", handler.ProcessHandles(o.psnap))
+
+	handle("GET /api/v1/alerts", handler.Alerts())
+
+	handle("GET /api/v1/events", handler.Events(o.buf))
+	handle("GET /api/v1/events/stream", handler.Stream(o.hub))
+}
@@ -19,8 +19,11 @@
 package api
 
 import (
+	"crypto/tls"
 	"fmt"
 	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/util/multierror"
+	tlsutil "github.com/rabbitstack/fibratus/pkg/util/tls"
 	"net"
 	"os/user"
 	"strings"
@@ -29,7 +32,13 @@ import (
 var listener net.Listener
 
 // StartServer starts the HTTP server with the specified configuration.
-func StartServer(c *config.Config) error {
+// Options determine which components are exposed through the management
+// endpoints.
+func StartServer(c *config.Config, options ...Option) error {
+	var o opts
+	for _, opt := range options {
+		opt(&o)
+	}
 	var err error
 	apiConfig := c.API
 	if strings.HasPrefix(apiConfig.Transport, `npipe:///`) {
@@ -54,7 +63,15 @@ func StartServer(c *config.Config) error {
 		return err
 	}
 
-	setupServer(listener, c)
+	if apiConfig.IsTLSEnabled() {
+		tlsConfig, err := tlsutil.MakeServerConfig(apiConfig.TLSCert, apiConfig.TLSKey, apiConfig.TLSCA)
+		if err != nil {
+			return multierror.Wrap(fmt.Errorf("invalid API server TLS config: %v", err), listener.Close())
+		}
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	setupServer(listener, c, o)
 
 	return nil
 }
@@ -0,0 +1,213 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stream
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/filter"
+	"github.com/rabbitstack/fibratus/pkg/ps"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+var (
+	// clientsCount represents the number of connected streaming clients
+	clientsCount = expvar.NewInt("api.stream.clients")
+	// eventsSent counts the number of events delivered to streaming clients
+	eventsSent = expvar.NewInt("api.stream.events.sent")
+	// eventsDropped counts dropped events by reason
+	eventsDropped = expvar.NewMap("api.stream.events.dropped")
+
+	// ErrTooManyClients is returned when the maximum number of streaming clients is reached.
+	ErrTooManyClients = errors.New("maximum number of streaming clients reached")
+	// ErrSequenceFilter is returned when the client submits a sequence expression.
+	ErrSequenceFilter = errors.New("sequence expressions are not supported in streaming filters")
+)
+
+const (
+	// dropReasonBackpressure denotes the event was dropped because the client buffer is full
+	dropReasonBackpressure = "backpressure"
+	// dropReasonRateLimit denotes the event was dropped because the client exceeded the rate limit
+	dropReasonRateLimit = "ratelimit"
+)
+
+// Hub is the event listener that fans out events to connected
+// streaming clients. Each client submits the filter expression
+// and only the events that satisfy the filter are delivered to
+// the client. Events are serialized once on the event processing
+// path and handed over to the client through a bounded buffer. If
+// the client is unable to drain the buffer fast enough, or exceeds
+// its rate limit, the events are dropped rather than blocking the
+// event flow.
+type Hub struct {
+	config  config.StreamConfig
+	cfg     *config.Config
+	psnap   ps.Snapshotter
+	enqueue bool
+
+	mu      sync.Mutex // serializes client subscriptions
+	clients atomic.Pointer[[]*Client]
+}
+
+// Client represents the streaming client subscription.
+type Client struct {
+	filter  filter.Filter
+	limiter *rate.Limiter
+	events  chan []byte
+	dropped atomic.Uint64
+}
+
+// Events returns the channel where serialized events are delivered.
+func (c *Client) Events() <-chan []byte { return c.events }
+
+// Dropped returns the number of events dropped for the client.
+func (c *Client) Dropped() uint64 { return c.dropped.Load() }
+
+func (c *Client) drop(reason string) {
+	c.dropped.Add(1)
+	eventsDropped.Add(reason, 1)
+}
+
+// NewHub creates a new streaming hub. The enqueue argument determines
+// if the hub, acting as the event listener, agrees to push events to
+// the output queue.
+func NewHub(cfg *config.Config, psnap ps.Snapshotter, enqueue bool) *Hub {
+	h := &Hub{
+		config:  cfg.API.Stream,
+		cfg:     cfg,
+		psnap:   psnap,
+		enqueue: enqueue,
+	}
+	h.clients.Store(&[]*Client{})
+	return h
+}
+
+// Subscribe registers a new streaming client. The expression is compiled
+// into the filter that is evaluated against every event. An empty expression
+// subscribes to all events. The rate argument can lower the per-client rate
+// limit configured for the hub.
+func (h *Hub) Subscribe(expr string, limit float64) (*Client, error) {
+	c := &Client{events: make(chan []byte, h.bufferSize())}
+	if expr != "" {
+		f := filter.New(expr, h.cfg, filter.WithPSnapshotter(h.psnap))
+		if err := f.Compile(); err != nil {
+			return nil, fmt.Errorf("invalid filter expression: %v", err)
+		}
+		if f.IsSequence() {
+			return nil, ErrSequenceFilter
+		}
+		c.filter = f
+	}
+
+	r := h.config.RateLimit
+	if limit > 0 && (r == 0 || limit < r) {
+		r = limit
+	}
+	if r > 0 {
+		burst := h.config.Burst
+		if burst <= 0 {
+			burst = max(int(r), 1)
+		}
+		c.limiter = rate.NewLimiter(rate.Limit(r), burst)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	clients := *h.clients.Load()
+	if h.config.MaxClients > 0 && len(clients) >= h.config.MaxClients {
+		return nil, ErrTooManyClients
+	}
+	// copy on write so that event processing
+	// path can traverse clients without locking
+	subs := make([]*Client, 0, len(clients)+1)
+	subs = append(subs, clients...)
+	subs = append(subs, c)
+	h.clients.Store(&subs)
+	clientsCount.Add(1)
+	log.Infof("streaming client subscribed with filter %q", expr)
+
+	return c, nil
+}
+
+// Unsubscribe removes the client subscription.
+func (h *Hub) Unsubscribe(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	clients := *h.clients.Load()
+	subs := make([]*Client, 0, len(clients))
+	for _, sub := range clients {
+		if sub != c {
+			subs = append(subs, sub)
+		}
+	}
+	if len(subs) == len(clients) {
+		return
+	}
+	h.clients.Store(&subs)
+	clientsCount.Add(-1)
+	log.Infof("streaming client unsubscribed. Dropped events: %d", c.Dropped())
+}
+
+// NumClients returns the number of subscribed clients.
+func (h *Hub) NumClients() int { return len(*h.clients.Load()) }
+
+// ProcessEvent delivers the event to all clients whose filter matches the event.
+func (h *Hub) ProcessEvent(e *event.Event) (bool, error) {
+	clients := *h.clients.Load()
+	if len(clients) == 0 {
+		return true, nil
+	}
+	var buf []byte
+	for _, c := range clients {
+		if c.filter != nil && !c.filter.Eval(e) {
+			continue
+		}
+		if c.limiter != nil && !c.limiter.Allow() {
+			c.drop(dropReasonRateLimit)
+			continue
+		}
+		if buf == nil {
+			buf = e.MarshalJSON()
+		}
+		select {
+		case c.events <- buf:
+			eventsSent.Add(1)
+		default:
+			c.drop(dropReasonBackpressure)
+		}
+	}
+	return true, nil
+}
+
+// CanEnqueue indicates if the hub can push events to the output queue.
+func (h *Hub) CanEnqueue() bool { return h.enqueue }
+
+func (h *Hub) bufferSize() int {
+	if h.config.BufferSize <= 0 {
+		return 1024
+	}
+	return h.config.BufferSize
+}
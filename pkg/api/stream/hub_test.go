@@ -0,0 +1,121 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/event/params"
+	"github.com/rabbitstack/fibratus/pkg/ps"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newConfig(stream config.StreamConfig) *config.Config {
+	return &config.Config{
+		API:     config.APIConfig{Stream: stream},
+		Filters: &config.Filters{},
+	}
+}
+
+func newEvent(seq uint64, name string) *event.Event {
+	return &event.Event{
+		Seq:       seq,
+		Type:      event.CreateProcess,
+		Name:      "CreateProcess",
+		Category:  event.Process,
+		Timestamp: time.Now(),
+		PID:       1234,
+		Tid:       2484,
+		PS:        &pstypes.PS{PID: 1234, Name: name},
+		Params: event.Params{
+			params.ProcessName: {Name: params.ProcessName, Type: params.UnicodeString, Value: name},
+		},
+		Metadata: make(map[event.MetadataKey]any),
+	}
+}
+
+func TestHubSubscribe(t *testing.T) {
+	h := NewHub(newConfig(config.StreamConfig{MaxClients: 1}), new(ps.SnapshotterMock), false)
+
+	_, err := h.Subscribe("evt.name = ", 0)
+	require.Error(t, err)
+
+	_, err = h.Subscribe("sequence |evt.name = 'CreateProcess'| |evt.name = 'CreateFile'|", 0)
+	require.ErrorIs(t, err, ErrSequenceFilter)
+
+	c, err := h.Subscribe("evt.name = 'CreateProcess'", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, h.NumClients())
+
+	_, err = h.Subscribe("", 0)
+	require.ErrorIs(t, err, ErrTooManyClients)
+
+	h.Unsubscribe(c)
+	assert.Equal(t, 0, h.NumClients())
+	// unsubscribing twice is a no-op
+	h.Unsubscribe(c)
+	assert.Equal(t, 0, h.NumClients())
+}
+
+func TestHubProcessEvent(t *testing.T) {
+	h := NewHub(newConfig(config.StreamConfig{BufferSize: 2}), new(ps.SnapshotterMock), false)
+	assert.False(t, h.CanEnqueue())
+
+	cmd, err := h.Subscribe("ps.name = 'cmd.exe'", 0)
+	require.NoError(t, err)
+	all, err := h.Subscribe("", 0)
+	require.NoError(t, err)
+
+	for i, name := range []string{"cmd.exe", "notepad.exe", "cmd.exe"} {
+		ok, err := h.ProcessEvent(newEvent(uint64(i+1), name))
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	require.Len(t, cmd.Events(), 2)
+	assert.Contains(t, string(<-cmd.Events()), `"seq":1`)
+	assert.Contains(t, string(<-cmd.Events()), `"seq":3`)
+	assert.Equal(t, uint64(0), cmd.Dropped())
+
+	// the third event overflows the buffer
+	require.Len(t, all.Events(), 2)
+	assert.Equal(t, uint64(1), all.Dropped())
+}
+
+func TestHubRateLimit(t *testing.T) {
+	h := NewHub(newConfig(config.StreamConfig{RateLimit: 1000, Burst: 1}), new(ps.SnapshotterMock), true)
+	assert.True(t, h.CanEnqueue())
+
+	// the client lowers the rate limit
+	c, err := h.Subscribe("", 0.001)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err := h.ProcessEvent(newEvent(uint64(i+1), "cmd.exe"))
+		require.NoError(t, err)
+	}
+
+	assert.Len(t, c.Events(), 1)
+	assert.Equal(t, uint64(2), c.Dropped())
+}
@@ -32,7 +32,7 @@ import (
 var errNoAlertsendersSection = errors.New("no alertsenders section in config")
 
 var errAlertsenderConfig = func(sender string, err error) error {
-	return fmt.Errorf("%s alert sender invalid config: %v", sender, err)
+	return keyError{key: "alertsenders." + sender, err: fmt.Errorf("%s alert sender invalid config: %v", sender, err)}
 }
 
 func (c *Config) tryLoadAlertSenders() error {
@@ -26,6 +26,15 @@ import (
 const (
 	transport = "api.transport"
 	timeout   = "api.timeout"
+	apiToken  = "api.token"
+	apiCert   = "api.tls-cert"
+	apiKey    = "api.tls-key"
+	apiCA     = "api.tls-ca"
+
+	streamMaxClients = "api.stream.max-clients"
+	streamBufferSize = "api.stream.buffer-size"
+	streamRateLimit  = "api.stream.rate-limit"
+	streamBurst      = "api.stream.burst"
 )
 
 // APIConfig contains API specific config options.
@@ -34,10 +43,51 @@ type APIConfig struct {
 	Transport string `json:"api.transport" yaml:"api.transport"`
 	// Timeout determines the timeout for the API server responses
 	Timeout time.Duration `json:"api.timeout" yaml:"api.timeout"`
+	// Token is the bearer token that authenticates requests to the management endpoints.
+	Token string `json:"api.token" yaml:"api.token"`
+	// TLSCert is the path to the server certificate file.
+	TLSCert string `json:"api.tls-cert" yaml:"api.tls-cert"`
+	// TLSKey is the path to the server private key file.
+	TLSKey string `json:"api.tls-key" yaml:"api.tls-key"`
+	// TLSCA is the path to the certificate authority file used to verify client certificates.
+	TLSCA string `json:"api.tls-ca" yaml:"api.tls-ca"`
+	// Stream contains options for live event streaming.
+	Stream StreamConfig `json:"api.stream" yaml:"api.stream"`
+}
+
+// StreamConfig contains options that control the live event streaming.
+type StreamConfig struct {
+	// MaxClients is the maximum number of concurrently connected streaming clients.
+	MaxClients int `json:"max-clients" yaml:"max-clients"`
+	// BufferSize is the number of events buffered for each client. Events
+	// are dropped when the client can't keep up with the buffered events.
+	BufferSize int `json:"buffer-size" yaml:"buffer-size"`
+	// RateLimit is the max number of events per second streamed to each client.
+	RateLimit float64 `json:"rate-limit" yaml:"rate-limit"`
+	// Burst is the number of events that can exceed the rate limit momentarily.
+	Burst int `json:"burst" yaml:"burst"`
 }
 
+// IsTLSEnabled determines if the API server accepts TLS connections.
+func (c APIConfig) IsTLSEnabled() bool { return c.TLSCert != "" && c.TLSKey != "" }
+
+// IsMutualTLSEnabled determines if the API server verifies client certificates.
+func (c APIConfig) IsMutualTLSEnabled() bool { return c.IsTLSEnabled() && c.TLSCA != "" }
+
+// IsManagementEnabled determines if the management endpoints are exposed.
+// Management endpoints require either token or mutual TLS authentication.
+func (c APIConfig) IsManagementEnabled() bool { return c.Token != "" || c.IsMutualTLSEnabled() }
+
 // initFromViper initializes API configuration from Viper.
 func (c *APIConfig) initFromViper(v *viper.Viper) {
 	c.Transport = v.GetString(transport)
 	c.Timeout = v.GetDuration(timeout)
+	c.Token = v.GetString(apiToken)
+	c.TLSCert = v.GetString(apiCert)
+	c.TLSKey = v.GetString(apiKey)
+	c.TLSCA = v.GetString(apiCA)
+	c.Stream.MaxClients = v.GetInt(streamMaxClients)
+	c.Stream.BufferSize = v.GetInt(streamBufferSize)
+	c.Stream.RateLimit = v.GetFloat64(streamRateLimit)
+	c.Stream.Burst = v.GetInt(streamBurst)
 }
@@ -0,0 +1,473 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/secrets"
+	"github.com/rabbitstack/fibratus/pkg/util/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity designates the severity of the configuration issue.
+type Severity string
+
+const (
+	// SeverityError designates issues that prevent the agent from starting or working correctly.
+	SeverityError Severity = "error"
+	// SeverityWarning designates issues that are likely configuration mistakes.
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is the problem found while checking the configuration.
+type Issue struct {
+	// File is the configuration file where the offending setting is declared.
+	File string `json:"file,omitempty"`
+	// Line is the line number of the offending setting.
+	Line int `json:"line,omitempty"`
+	// Column is the column number of the offending setting.
+	Column int `json:"column,omitempty"`
+	// Key is the dotted key of the offending setting.
+	Key string `json:"key,omitempty"`
+	// Severity is the issue severity.
+	Severity Severity `json:"severity"`
+	// Message describes the issue.
+	Message string `json:"message"`
+}
+
+// String returns the issue in the file:line:column: severity: key: message format.
+// The line and column are omitted if the position is unknown.
+func (i Issue) String() string {
+	var b strings.Builder
+	if i.File != "" {
+		b.WriteString(i.File)
+		if i.Line > 0 {
+			fmt.Fprintf(&b, ":%d", i.Line)
+		}
+		if i.Column > 0 {
+			fmt.Fprintf(&b, ":%d", i.Column)
+		}
+		b.WriteString(": ")
+	}
+	b.WriteString(string(i.Severity))
+	b.WriteString(": ")
+	if i.Key != "" {
+		b.WriteString(i.Key)
+		b.WriteString(": ")
+	}
+	b.WriteString(i.Message)
+	return b.String()
+}
+
+// keyError is raised when the configuration section
+// identified by the dotted key can't be decoded.
+type keyError struct {
+	key string
+	err error
+}
+
+func (e keyError) Error() string { return e.err.Error() }
+func (e keyError) Unwrap() error { return e.err }
+
+// position is the location of the setting in the configuration file.
+type position struct {
+	line, column int
+}
+
+// minPeriod is the smallest sane duration of periodic tasks
+const minPeriod = time.Millisecond
+
+// Check validates the configuration file along with the role profile and
+// drop-in files. Merged settings are validated against the schema, and then
+// checked for semantic errors. Output, transformer, and alert sender sections
+// must decode cleanly, rule and YARA rule paths must resolve, and durations
+// must be sane. Secret references that can't be resolved are reported as
+// warnings. Each issue is located by the file, line, and column of the
+// offending setting. The configuration file must be loaded before running
+// the check.
+func (c *Config) Check() ([]Issue, error) {
+	if c.layers == nil {
+		return nil, errors.New("configuration file is not loaded")
+	}
+	positions := make(map[string]map[string]position, len(c.layers.files))
+	for _, file := range c.layers.files {
+		pos, err := readPositions(file)
+		if err != nil {
+			return nil, err
+		}
+		positions[file] = pos
+	}
+	chk := &checker{layers: c.layers, positions: positions}
+
+	if err := chk.checkSchema(); err != nil {
+		return nil, err
+	}
+	if err := chk.checkSections(); err != nil {
+		return nil, err
+	}
+	chk.checkRulePaths(rulesFromPaths, "rule")
+	chk.checkRulePaths(macrosFromPaths, "macro")
+	chk.checkYaraPaths()
+	chk.checkDurations()
+	chk.checkSecrets()
+	chk.checkTimezone()
+	chk.checkPublicSuffixList()
+
+	sort.SliceStable(chk.issues, func(i, j int) bool {
+		a, b := chk.issues[i], chk.issues[j]
+		if a.File != b.File {
+			return chk.fileIndex(a.File) < chk.fileIndex(b.File)
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	return chk.issues, nil
+}
+
+// checker accumulates issues found in merged configuration layers.
+type checker struct {
+	layers *layers
+	// positions maps the configuration file to positions of dotted keys
+	positions map[string]map[string]position
+	issues    []Issue
+}
+
+func (c *checker) checkSchema() error {
+	r, err := validateSchema(configSchema, c.layers.settings)
+	if err != nil {
+		return err
+	}
+	errs := r.Errors()
+	keys := make([]string, len(errs))
+	for i, e := range errs {
+		key := e.Field()
+		if key == "(root)" {
+			key = ""
+		}
+		if e.Type() == "additional_property_not_allowed" {
+			if prop, ok := e.Details()["property"].(string); ok {
+				key = joinKey(key, prop)
+			}
+		}
+		keys[i] = key
+	}
+	for i, e := range errs {
+		// composite errors are redundant if the nested error pinpoints the setting
+		if compositeSchemaErrors[e.Type()] && hasNestedKey(keys, keys[i]) {
+			continue
+		}
+		c.add(keys[i], SeverityError, e.Description())
+	}
+	return nil
+}
+
+// compositeSchemaErrors are raised when combined or conditional subschemas don't validate
+var compositeSchemaErrors = map[string]bool{
+	"number_any_of":  true,
+	"number_one_of":  true,
+	"number_all_of":  true,
+	"condition_then": true,
+	"condition_else": true,
+}
+
+// hasNestedKey determines if any of the keys is nested under the parent key.
+func hasNestedKey(keys []string, parent string) bool {
+	for _, k := range keys {
+		if k != parent && (parent == "" || strings.HasPrefix(k, parent+".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSections decodes the output, transformer, and alert sender
+// sections. Secret references are masked prior to decoding, so
+// unresolvable secrets don't shadow structural errors.
+func (c *checker) checkSections() error {
+	scratch := NewWithOpts()
+	if err := scratch.viper.MergeConfigMap(maskRefs(c.layers.settings).(map[string]any)); err != nil {
+		return err
+	}
+	sections := []struct {
+		key  string
+		load func() error
+	}{
+		{"output", scratch.tryLoadOutput},
+		{"transformers", scratch.tryLoadTransformers},
+		{"alertsenders", scratch.tryLoadAlertSenders},
+	}
+	for _, s := range sections {
+		err := s.load()
+		if err == nil {
+			continue
+		}
+		key := s.key
+		var kerr keyError
+		if errors.As(err, &kerr) {
+			key = kerr.key
+		}
+		c.add(key, SeverityError, err.Error())
+	}
+	return nil
+}
+
+// checkRulePaths ensures each path pattern matches at least one rule or macro file.
+func (c *checker) checkRulePaths(key, kind string) {
+	paths, _ := lookupSetting(c.layers.settings, key).([]any)
+	for i, p := range paths {
+		pattern, ok := p.(string)
+		if !ok {
+			continue
+		}
+		k := key + "." + strconv.Itoa(i)
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			c.add(k, SeverityError, fmt.Sprintf("invalid %s path pattern %s: %v", kind, pattern, err))
+			continue
+		}
+		n := 0
+		for _, m := range matches {
+			if isValidExt(m) {
+				n++
+			}
+		}
+		if n == 0 {
+			c.add(k, SeverityError, fmt.Sprintf("no %s files match %s", kind, pattern))
+		}
+	}
+}
+
+// checkYaraPaths ensures YARA rule paths are accessible directories.
+func (c *checker) checkYaraPaths() {
+	if enabled, _ := lookupSetting(c.layers.settings, "yara.enabled").(bool); !enabled {
+		return
+	}
+	paths, _ := lookupSetting(c.layers.settings, "yara.rule.paths").([]any)
+	for i, p := range paths {
+		m, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		path, ok := m["path"].(string)
+		if !ok {
+			continue
+		}
+		k := "yara.rule.paths." + strconv.Itoa(i)
+		fi, err := os.Stat(path)
+		if err != nil {
+			c.add(k, SeverityError, fmt.Sprintf("cannot access yara rule path: %v", err))
+			continue
+		}
+		if !fi.IsDir() {
+			c.add(k, SeverityError, fmt.Sprintf("yara rule path %s is not a directory", path))
+		}
+	}
+}
+
+// checkDurations reports negative durations and periods
+// that are so short they would keep the CPU busy.
+func (c *checker) checkDurations() {
+	flat := make(map[string]any)
+	flattenSettings("", c.layers.settings, flat)
+	for key, v := range flat {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			continue
+		}
+		switch {
+		case d < 0:
+			c.add(key, SeverityError, fmt.Sprintf("duration %s is negative", s))
+		case d > 0 && d < minPeriod && (strings.HasSuffix(key, "period") || strings.HasSuffix(key, "interval")):
+			c.add(key, SeverityWarning, fmt.Sprintf("%s is shorter than %s", s, minPeriod))
+		}
+	}
+}
+
+// checkSecrets reports secret references that can't be resolved on this machine.
+func (c *checker) checkSecrets() {
+	flat := make(map[string]any)
+	flattenSettings("", c.layers.settings, flat)
+	for key, v := range flat {
+		s, ok := v.(string)
+		if !ok || !secrets.IsReference(s) {
+			continue
+		}
+		if _, err := secrets.Resolve(s); err != nil {
+			c.add(key, SeverityWarning, fmt.Sprintf("unable to resolve %s: %v", s, err))
+		}
+	}
+}
+
+// checkTimezone reports the filters time zone that can't be resolved.
+func (c *checker) checkTimezone() {
+	tz, ok := lookupSetting(c.layers.settings, timezone).(string)
+	if !ok || tz == "" {
+		return
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		c.add(timezone, SeverityError, fmt.Sprintf("unknown time zone %s", tz))
+	}
+}
+
+// checkPublicSuffixList reports the public suffix list file that can't be loaded.
+func (c *checker) checkPublicSuffixList() {
+	path, ok := lookupSetting(c.layers.settings, publicSuffixList).(string)
+	if !ok || path == "" {
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		c.add(publicSuffixList, SeverityError, fmt.Sprintf("unable to load public suffix list: %v", err))
+		return
+	}
+	defer f.Close()
+	if _, err := domain.Parse(f); err != nil {
+		c.add(publicSuffixList, SeverityError, fmt.Sprintf("unable to load public suffix list: %v", err))
+	}
+}
+
+// add records the issue located at the position of the key. If the key is not
+// declared in any of the files, the position of the closest parent is used.
+func (c *checker) add(key string, severity Severity, msg string) {
+	for _, i := range c.issues {
+		if i.Key == key && i.Message == msg {
+			return
+		}
+	}
+	issue := Issue{Key: key, Severity: severity, Message: msg}
+	for k := key; ; {
+		for i := len(c.layers.files) - 1; i >= 0; i-- {
+			file := c.layers.files[i]
+			if pos, ok := c.positions[file][k]; ok {
+				issue.File, issue.Line, issue.Column = file, pos.line, pos.column
+				c.issues = append(c.issues, issue)
+				return
+			}
+		}
+		n := strings.LastIndexByte(k, '.')
+		if n < 0 {
+			break
+		}
+		k = k[:n]
+	}
+	if len(c.layers.files) > 0 {
+		issue.File = c.layers.files[0]
+	}
+	c.issues = append(c.issues, issue)
+}
+
+func (c *checker) fileIndex(file string) int {
+	for i, f := range c.layers.files {
+		if f == file {
+			return i
+		}
+	}
+	return -1
+}
+
+// readPositions parses the configuration file and returns the line
+// and column of each dotted key. Sequence items are keyed by index.
+func readPositions(file string) (map[string]position, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		return nil, fmt.Errorf("couldn't read the config file %s: %v", file, err)
+	}
+	positions := make(map[string]position)
+	var walk func(prefix string, n *yaml.Node)
+	walk = func(prefix string, n *yaml.Node) {
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, c := range n.Content {
+				walk(prefix, c)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				k, v := n.Content[i], n.Content[i+1]
+				key := joinKey(prefix, strings.ToLower(k.Value))
+				positions[key] = position{line: k.Line, column: k.Column}
+				walk(key, v)
+			}
+		case yaml.SequenceNode:
+			for i, c := range n.Content {
+				key := prefix + "." + strconv.Itoa(i)
+				positions[key] = position{line: c.Line, column: c.Column}
+				walk(key, c)
+			}
+		}
+	}
+	walk("", &root)
+	return positions, nil
+}
+
+// lookupSetting returns the value of the dotted key from nested settings.
+func lookupSetting(settings map[string]any, key string) any {
+	var v any = settings
+	for _, k := range strings.Split(key, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[k]
+	}
+	return v
+}
+
+// maskRefs returns a copy of settings where secret references are masked.
+func maskRefs(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(val))
+		for k, v := range val {
+			m[k] = maskRefs(v)
+		}
+		return m
+	case []any:
+		s := make([]any, len(val))
+		for i, v := range val {
+			s[i] = maskRefs(v)
+		}
+		return s
+	case string:
+		if secrets.IsReference(val) {
+			return "********"
+		}
+		return val
+	default:
+		return v
+	}
+}
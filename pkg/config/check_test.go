@@ -0,0 +1,74 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	c := NewWithOpts()
+	require.NoError(t, c.TryLoadFile("_fixtures/check/fibratus.yml"))
+
+	issues, err := c.Check()
+	require.NoError(t, err)
+
+	base := "_fixtures/check/fibratus.yml"
+	dropIn := filepath.Join("_fixtures", "check", "conf.d", "10-amqp.yml")
+
+	var tests = []struct {
+		key      string
+		file     string
+		line     int
+		column   int
+		severity Severity
+	}{
+		{"aggregator.flush-timeout", base, 3, 3, SeverityError},
+		{"aggregator.flush-perio", base, 4, 3, SeverityError},
+		{"alertsenders.mail.to.0", base, 13, 9, SeverityError},
+		{"filters.rules.from-paths.0", base, 18, 9, SeverityError},
+		{"filters.timezone", base, 19, 3, SeverityError},
+		{"filters.public-suffix-list", base, 20, 3, SeverityError},
+		{"output", dropIn, 1, 1, SeverityError},
+		{"output.amqp.password", dropIn, 5, 5, SeverityWarning},
+	}
+
+	require.Len(t, issues, len(tests))
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			var issue *Issue
+			for i := range issues {
+				if issues[i].Key == tt.key {
+					issue = &issues[i]
+				}
+			}
+			require.NotNil(t, issue)
+			assert.Equal(t, tt.file, issue.File)
+			assert.Equal(t, tt.line, issue.Line)
+			assert.Equal(t, tt.column, issue.Column)
+			assert.Equal(t, tt.severity, issue.Severity)
+		})
+	}
+
+	assert.Equal(t, "_fixtures/check/fibratus.yml:3:3: error: aggregator.flush-timeout: duration -4s is negative", issues[0].String())
+}
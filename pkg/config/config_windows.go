@@ -19,9 +19,11 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
+	// embeds the time zone database for resolving
+	// the time zone in which filters are evaluated
+	_ "time/tzdata"
 
 	"github.com/rabbitstack/fibratus/internal/evasion"
 	"golang.org/x/sys/windows"
@@ -36,12 +38,20 @@ import (
 	replacet "github.com/rabbitstack/fibratus/pkg/aggregator/transformers/replace"
 	tagst "github.com/rabbitstack/fibratus/pkg/aggregator/transformers/tags"
 	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/filter/ql/functions"
+	healthconfig "github.com/rabbitstack/fibratus/pkg/health/config"
+	hconfig "github.com/rabbitstack/fibratus/pkg/history/config"
 	"github.com/rabbitstack/fibratus/pkg/outputs/amqp"
 	"github.com/rabbitstack/fibratus/pkg/outputs/elasticsearch"
+	"github.com/rabbitstack/fibratus/pkg/outputs/otlp"
+	"github.com/rabbitstack/fibratus/pkg/outputs/s3"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/rabbitstack/fibratus/pkg/secrets"
+	tracing "github.com/rabbitstack/fibratus/pkg/tracing/config"
+	"github.com/rabbitstack/fibratus/pkg/util/domain"
 	"github.com/rabbitstack/fibratus/pkg/util/log"
 	"github.com/rabbitstack/fibratus/pkg/util/multierror"
 	yara "github.com/rabbitstack/fibratus/pkg/yara/config"
-	"gopkg.in/yaml.v3"
 
 	renamet "github.com/rabbitstack/fibratus/pkg/aggregator/transformers/rename"
 	trimt "github.com/rabbitstack/fibratus/pkg/aggregator/transformers/trim"
@@ -66,6 +76,9 @@ import (
 const (
 	capFile                  = "cap.file"
 	configFile               = "config-file"
+	configProfile            = "config-profile"
+	configDir                = "config-dir"
+	keystorePath             = "secrets.keystore"
 	debugPrivilege           = "debug-privilege"
 	initHandleSnapshot       = "handle.init-snapshot"
 	enumerateHandles         = "handle.enumerate-handles"
@@ -130,9 +143,19 @@ type Config struct {
 	// Evasion controls the detection of evasion behaviours.
 	Evasion evasion.Config `json:"evasion" yaml:"evasion"`
 
-	flags *pflag.FlagSet
-	viper *viper.Viper
-	opts  *Options
+	// History controls the retention of recent events for triage.
+	History hconfig.Config `json:"history" yaml:"history"`
+
+	// Health contains thresholds that determine the health of agent components.
+	Health healthconfig.Config `json:"health" yaml:"health"`
+
+	// Tracing controls the self-tracing of the event processing pipeline.
+	Tracing tracing.Config `json:"tracing" yaml:"tracing"`
+
+	flags  *pflag.FlagSet
+	viper  *viper.Viper
+	opts   *Options
+	layers *layers
 }
 
 // Options determines which config flags are toggled depending on the command type.
@@ -225,6 +248,8 @@ func NewWithOpts(options ...Option) *Config {
 		elasticsearch.AddFlags(flagSet)
 		http.AddFlags(flagSet)
 		eventlog.AddFlags(flagSet)
+		otlp.AddFlags(flagSet)
+		s3.AddFlags(flagSet)
 		removet.AddFlags(flagSet)
 		replacet.AddFlags(flagSet)
 		renamet.AddFlags(flagSet)
@@ -241,8 +266,14 @@ func NewWithOpts(options ...Option) *Config {
 		pe.AddFlags(flagSet)
 	}
 
+	if opts.run || opts.capture || opts.replay {
+		healthconfig.AddFlags(flagSet)
+	}
+
 	if opts.run {
 		evasion.AddFlags(flagSet)
+		hconfig.AddFlags(flagSet)
+		tracing.AddFlags(flagSet)
 	}
 
 	c.addFlags()
@@ -283,9 +314,13 @@ func (c *Config) Init() error {
 	c.API.initFromViper(c.viper)
 	c.PE.InitFromViper(c.viper)
 	c.Aggregator.InitFromViper(c.viper)
+	if err := c.tryLoadSampling(); err != nil {
+		return err
+	}
 	c.Log.InitFromViper(c.viper)
 	c.Yara.InitFromViper(c.viper)
 	c.Filters.initFromViper(c.viper)
+	c.Health.InitFromViper(c.viper)
 
 	c.InitHandleSnapshot = c.viper.GetBool(initHandleSnapshot)
 	c.EnumerateHandles = c.viper.GetBool(enumerateHandles)
@@ -295,6 +330,27 @@ func (c *Config) Init() error {
 	c.ForwardMode = c.viper.GetBool(forwardMode)
 	c.CapFile = c.viper.GetString(capFile)
 
+	secrets.KeystorePath = c.viper.GetString(keystorePath)
+
+	if depth := c.viper.GetInt(processTreeDepth); depth > 0 {
+		pstypes.TreeDepth = depth
+	}
+	if size := c.viper.GetInt(maxHashFileSize); size > 0 {
+		functions.MaxHashFileSize = int64(size) * 1024 * 1024
+	}
+	if tz := c.viper.GetString(timezone); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return fmt.Errorf("invalid filters time zone: %v", err)
+		}
+		functions.Location = loc
+	}
+	if path := c.viper.GetString(publicSuffixList); path != "" {
+		if err := domain.Load(path); err != nil {
+			return fmt.Errorf("unable to load public suffix list: %v", err)
+		}
+	}
+
 	event.SerializeThreads = c.viper.GetBool(serializeThreads)
 	event.SerializeModules = c.viper.GetBool(serializeModules)
 	event.SerializeHandles = c.viper.GetBool(serializeHandles)
@@ -315,6 +371,8 @@ func (c *Config) Init() error {
 
 	if c.opts.run {
 		c.Evasion.InitFromViper(c.viper)
+		c.History.InitFromViper(c.viper)
+		c.Tracing.InitFromViper(c.viper)
 	}
 
 	return nil
@@ -328,34 +386,32 @@ func (c *Config) IsCaptureSet() bool { return c.CapFile != "" }
 func (c *Config) IsFilamentSet() bool { return c.Filament.Name != "" }
 
 // TryLoadFile attempts to load the configuration file from specified path on the file system.
+// The base configuration file is merged with the role profile and the drop-in files.
 func (c *Config) TryLoadFile(file string) error {
+	l, err := c.loadLayers(file)
+	if err != nil {
+		return err
+	}
+	c.layers = l
 	c.viper.SetConfigFile(file)
-	return c.viper.ReadInConfig()
+	return c.viper.MergeConfigMap(l.settings)
 }
 
 // Validate ensures that all configuration options provided by user have the expected values. It returns
 // a list of validation errors prefixed with the offending configuration property/flag.
 func (c *Config) Validate() error {
-	// we'll first validate the structure and values of the config file
-	file := c.viper.GetString(configFile)
-	var out interface{}
-	b, err := os.ReadFile(file)
-	if err != nil {
-		return err
-	}
-	switch filepath.Ext(file) {
-	case ".yaml", ".yml":
-		err = yaml.Unmarshal(b, &out)
-	case ".json":
-		err = json.Unmarshal(b, &out)
-	default:
-		return fmt.Errorf("%s is not a supported config file extension", filepath.Ext(file))
-	}
-	if err != nil {
-		return fmt.Errorf("couldn't read the config file: %v", err)
+	// we'll first validate the structure and values of the merged
+	// config files. The layers are read from the config file if
+	// it wasn't loaded before
+	l := c.layers
+	if l == nil {
+		var err error
+		l, err = c.loadLayers(c.File())
+		if err != nil {
+			return err
+		}
 	}
-	// validate config file content
-	valid, errs := validate(configSchema, out)
+	valid, errs := validate(configSchema, l.settings)
 	if !valid || len(errs) > 0 {
 		return fmt.Errorf("invalid config: %v", multierror.Wrap(errs...))
 	}
@@ -370,6 +426,9 @@ func (c *Config) Validate() error {
 // File returns the config file path.
 func (c *Config) File() string { return c.viper.GetString(configFile) }
 
+// Keystore returns the path of the encrypted keystore.
+func (c *Config) Keystore() string { return c.viper.GetString(keystorePath) }
+
 // SymbolPathsUTF16 returns the symbol paths as UTF16 string
 // suitable for use in the Debug Helper API functions.
 func (c *Config) SymbolPathsUTF16() *uint16 {
@@ -379,6 +438,9 @@ func (c *Config) SymbolPathsUTF16() *uint16 {
 
 func (c *Config) addFlags() {
 	c.flags.String(configFile, filepath.Join(os.Getenv("PROGRAMFILES"), "fibratus", "config", "fibratus.yml"), "Indicates the location of the configuration file")
+	c.flags.String(configProfile, "", "Specifies the role profile merged on top of the configuration file. The profile is either a path or the name of the file in the profiles directory next to the configuration file")
+	c.flags.String(keystorePath, secrets.DefaultKeystorePath(), "Specifies the path of the encrypted keystore used to resolve keystore:// secret references")
+	c.flags.String(configDir, "", "Specifies the drop-in directory whose files are merged on top of the configuration file and the profile in lexical order. Defaults to the conf.d directory next to the configuration file")
 	if c.opts.run {
 		c.flags.Bool(forwardMode, false, "Designates if event forwarding mode is engaged")
 	}
@@ -398,6 +460,10 @@ func (c *Config) addFlags() {
 		c.flags.StringSlice(macrosFromPaths, []string{filepath.Join(dir, "Macros", "*")}, "Comma-separated list of macro files")
 		c.flags.StringSlice(rulesFromURLs, []string{}, "Comma-separated list of rules URL resources")
 		c.flags.Bool(matchAll, true, "Indicates if the match all strategy is enabled for the rule engine. If the match all strategy is enabled, a single event can trigger multiple rules")
+		c.flags.Int(processTreeDepth, pstypes.TreeDepth, "Specifies the maximum number of ancestors visited when resolving the process tree root for ps.tree joins")
+		c.flags.String(publicSuffixList, "", "Specifies the path to the public suffix list file that overrides the list embedded in the binary")
+		c.flags.Int(maxHashFileSize, 8, "Specifies the maximum size in megabytes of the file hashed by the hash functions and fields. Larger files are not hashed")
+		c.flags.String(timezone, "", "Specifies the IANA time zone name, e.g. Europe/Madrid, in which time functions and event timestamp fields are evaluated. The local time zone is used by default")
 	}
 	if c.opts.capture {
 		c.flags.StringP(capFile, "o", "", "The path of the output cap file")
@@ -411,6 +477,14 @@ func (c *Config) addFlags() {
 	if c.opts.run || c.opts.replay || c.opts.capture || c.opts.stats {
 		c.flags.String(transport, `localhost:8080`, "Specifies the underlying transport protocol for the API HTTP server")
 		c.flags.Duration(timeout, time.Second*15, "Determines the timeout for the API server responses")
+		c.flags.String(apiToken, "", "Specifies the bearer token for authenticating requests to the API management endpoints")
+		c.flags.String(apiCert, "", "Represents the path of the certificate file for serving the API over TLS")
+		c.flags.String(apiKey, "", "Represents the path of the private key file for serving the API over TLS")
+		c.flags.String(apiCA, "", "Represents the path of the certificate authority file for verifying API client certificates")
+		c.flags.Int(streamMaxClients, 10, "Specifies the maximum number of concurrently connected event streaming clients")
+		c.flags.Int(streamBufferSize, 1024, "Specifies the number of events buffered for each streaming client")
+		c.flags.Float64(streamRateLimit, 1000, "Specifies the max number of events per second streamed to each client")
+		c.flags.Int(streamBurst, 0, "Specifies the number of events that can exceed the streaming rate limit momentarily")
 	}
 	if c.opts.run || c.opts.capture {
 		c.flags.Bool(initHandleSnapshot, false, "Indicates whether initial handle snapshot is built. This implies scanning the system handles table and producing an entry for each handle object")
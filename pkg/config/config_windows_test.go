@@ -121,3 +121,14 @@ func TestNewFromJsonFile(t *testing.T) {
 	assert.Equal(t, time.Millisecond*230, c.Aggregator.FlushPeriod)
 	assert.Equal(t, time.Second*8, c.Aggregator.FlushTimeout)
 }
+
+func TestValidateUnloadedFile(t *testing.T) {
+	c := NewWithOpts(WithRun())
+
+	err := c.flags.Parse([]string{"--config-file=_fixtures/invalid.yml"})
+	require.NoError(t, c.viper.BindPFlags(c.flags))
+	require.NoError(t, err)
+
+	// the config file is validated even if it wasn't loaded
+	require.Error(t, c.Validate())
+}
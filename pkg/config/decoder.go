@@ -19,7 +19,9 @@
 package config
 
 import (
+	"fmt"
 	"github.com/mitchellh/mapstructure"
+	"github.com/rabbitstack/fibratus/pkg/secrets"
 	"net"
 	"reflect"
 )
@@ -30,6 +32,7 @@ func decode(input, output interface{}) error {
 		Result:           output,
 		WeaklyTypedInput: true,
 		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			secretDecodeHook(),
 			mapstructure.StringToTimeDurationHookFunc(),
 			mapstructure.StringToSliceHookFunc(","),
 			ipSliceDecodeHook(),
@@ -62,3 +65,22 @@ func ipSliceDecodeHook() mapstructure.DecodeHookFunc {
 		return data, nil
 	}
 }
+
+// secretDecodeHook resolves secret references in string values,
+// so credentials are kept out of the configuration file.
+func secretDecodeHook() mapstructure.DecodeHookFunc {
+	return func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+		if from.Kind() != reflect.String {
+			return data, nil
+		}
+		s, ok := data.(string)
+		if !ok || !secrets.IsReference(s) {
+			return data, nil
+		}
+		secret, err := secrets.Resolve(s)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve %s: %v", s, err)
+		}
+		return secret, nil
+	}
+}
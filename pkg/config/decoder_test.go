@@ -0,0 +1,42 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+
+	"github.com/rabbitstack/fibratus/pkg/outputs/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeSecrets(t *testing.T) {
+	t.Setenv("FIBRATUS_AMQP_PASSWORD", "amqp-s3cr3t")
+
+	var c amqp.Config
+	require.NoError(t, decode(map[string]any{
+		"url":      "amqp://localhost:5672",
+		"username": "guest",
+		"password": "env://FIBRATUS_AMQP_PASSWORD",
+	}, &c))
+	assert.Equal(t, "guest", c.Username)
+	assert.Equal(t, "amqp-s3cr3t", c.Password)
+
+	require.Error(t, decode(map[string]any{"password": "env://FIBRATUS_UNDEFINED"}, &c))
+}
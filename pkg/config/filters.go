@@ -165,6 +165,9 @@ type ActionContext struct {
 	Events []*event.Event
 	// Filter represents the filter that matched the event
 	Filter *FilterConfig
+	// Context contains recent events of the offending
+	// process tree that preceded the matched events
+	Context []*event.Event
 }
 
 // UniquePids returns a set of process identifiers
@@ -310,11 +313,15 @@ func (r RulesCompileResult) String() string {
 }
 
 const (
-	rulesEnabled    = "filters.rules.enabled"
-	rulesFromPaths  = "filters.rules.from-paths"
-	rulesFromURLs   = "filters.rules.from-urls"
-	macrosFromPaths = "filters.macros.from-paths"
-	matchAll        = "filters.match-all"
+	rulesEnabled     = "filters.rules.enabled"
+	rulesFromPaths   = "filters.rules.from-paths"
+	rulesFromURLs    = "filters.rules.from-urls"
+	macrosFromPaths  = "filters.macros.from-paths"
+	matchAll         = "filters.match-all"
+	processTreeDepth = "filters.process-tree-depth"
+	timezone         = "filters.timezone"
+	publicSuffixList = "filters.public-suffix-list"
+	maxHashFileSize  = "filters.max-hash-file-size"
 )
 
 func (f *Filters) initFromViper(v *viper.Viper) {
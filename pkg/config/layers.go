@@ -0,0 +1,394 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rabbitstack/fibratus/pkg/secrets"
+	"gopkg.in/yaml.v3"
+)
+
+// configExts are the supported extensions of configuration files
+var configExts = []string{".yml", ".yaml", ".json"}
+
+// refRegexp matches well-formed, optionally escaped references to
+// environment variables or secret files in configuration values
+var refRegexp = regexp.MustCompile(`\$?\$\{(?:env:[A-Za-z_][A-Za-z0-9_]*(?::-[^}]*)?|file:[^}]+)\}`)
+
+// ErrEnvNotSet is raised when the configuration value references the undefined environment variable
+var ErrEnvNotSet = func(name, key, source string) error {
+	return fmt.Errorf("environment variable %s referenced by %s in %s is not set", name, key, source)
+}
+
+// Setting is the effective configuration setting annotated with the source of its value.
+type Setting struct {
+	// Key is the dotted setting key.
+	Key string `json:"key"`
+	// Value is the effective value of the setting.
+	Value any `json:"value"`
+	// Source is the configuration file, environment variable, flag, or default that set the value.
+	Source string `json:"source"`
+}
+
+// layers contains the result of merging configuration layers.
+type layers struct {
+	// files are the paths of loaded configuration files in the merge order
+	files []string
+	// settings are the merged settings
+	settings map[string]any
+	// sources maps the dotted key to the source that set the value
+	sources map[string]string
+}
+
+// Effective returns the settings the agent runs with. Settings are derived
+// from configuration layers referenced by this config, environment variables,
+// and default values. Values that are likely sensitive are redacted.
+func (c *Config) Effective() ([]Setting, error) {
+	eff := NewWithOpts(WithRun())
+	if err := eff.viper.BindPFlags(eff.flags); err != nil {
+		return nil, err
+	}
+	for _, key := range []string{configFile, configProfile, configDir} {
+		if err := eff.flags.Set(key, c.viper.GetString(key)); err != nil {
+			return nil, err
+		}
+	}
+	if err := eff.TryLoadFile(eff.File()); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	flat := make(map[string]any)
+	flattenSettings("", eff.viper.AllSettings(), flat)
+	settings := make([]Setting, 0, len(flat))
+	for key, value := range flat {
+		settings = append(settings, Setting{Key: key, Value: value, Source: eff.source(key)})
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	for i, s := range settings {
+		if strings.Contains(s.Source, "(file ") {
+			settings[i].Value = "********"
+			continue
+		}
+		if v, ok := s.Value.(string); ok {
+			settings[i].Value = secrets.Redact(s.Key, v)
+		}
+	}
+	return settings, nil
+}
+
+// source determines where the value of the setting originates from. The
+// precedence matches the precedence used by Viper to resolve the value.
+func (c *Config) source(key string) string {
+	if f := c.flags.Lookup(key); f != nil && f.Changed {
+		return "flag --" + key
+	}
+	env := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+	if _, ok := os.LookupEnv(env); ok {
+		return "env " + env
+	}
+	if c.layers != nil {
+		if source, ok := c.layers.sources[key]; ok {
+			return source
+		}
+	}
+	return "default"
+}
+
+// flattenSettings flattens nested settings into dotted keys.
+func flattenSettings(prefix string, settings map[string]any, out map[string]any) {
+	for k, v := range settings {
+		key := joinKey(prefix, k)
+		if m, ok := v.(map[string]any); ok && len(m) > 0 {
+			flattenSettings(key, m, out)
+			continue
+		}
+		out[key] = v
+	}
+}
+
+// loadLayers reads the base configuration file, the role profile, and the
+// drop-in files, and merges them in that order. Later layers override the
+// keys of preceding layers. Maps are merged recursively, while all other
+// values, including lists, are replaced.
+func (c *Config) loadLayers(file string) (*layers, error) {
+	l := &layers{settings: make(map[string]any), sources: make(map[string]string)}
+	if err := l.load(file); err != nil {
+		return nil, err
+	}
+
+	base := filepath.Dir(file)
+	if profile := c.viper.GetString(configProfile); profile != "" {
+		path, err := profilePath(base, profile)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.load(path); err != nil {
+			return nil, err
+		}
+	}
+
+	dir := c.viper.GetString(configDir)
+	explicit := dir != ""
+	if !explicit {
+		dir = filepath.Join(base, "conf.d")
+	}
+	files, err := dropIns(dir)
+	if err != nil {
+		if !explicit && os.IsNotExist(err) {
+			return l, nil
+		}
+		return nil, fmt.Errorf("unable to read drop-in directory %s: %v", dir, err)
+	}
+	for _, f := range files {
+		if err := l.load(f); err != nil {
+			return nil, err
+		}
+	}
+
+	return l, nil
+}
+
+// load reads the configuration file, expands references, and merges
+// the settings on top of the settings of preceding layers.
+func (l *layers) load(file string) error {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var settings map[string]any
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &settings)
+	case ".json":
+		err = json.Unmarshal(b, &settings)
+	default:
+		return fmt.Errorf("%s is not a supported config file extension", filepath.Ext(file))
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't read the config file %s: %v", file, err)
+	}
+	l.files = append(l.files, file)
+	if settings == nil {
+		return nil
+	}
+	sources := make(map[string]string)
+	expanded, err := expand("", settings, file, sources)
+	if err != nil {
+		return err
+	}
+	l.merge("", l.settings, expanded.(map[string]any), sources)
+	return nil
+}
+
+// merge recursively merges the src map into dst and records
+// the source of each overridden key.
+func (l *layers) merge(prefix string, dst, src map[string]any, sources map[string]string) {
+	for k, v := range src {
+		key := joinKey(prefix, k)
+		sm, srcIsMap := v.(map[string]any)
+		dm, dstIsMap := dst[k].(map[string]any)
+		if srcIsMap && dstIsMap {
+			l.merge(key, dm, sm, sources)
+			continue
+		}
+		// the value replaces the whole subtree
+		for s := range l.sources {
+			if s == key || strings.HasPrefix(s, key+".") {
+				delete(l.sources, s)
+			}
+		}
+		for s, source := range sources {
+			if s == key || strings.HasPrefix(s, key+".") {
+				l.sources[s] = source
+			}
+		}
+		dst[k] = v
+	}
+}
+
+// expand normalizes map keys to lowercase and expands environment
+// variable and secret file references in string values. Sources of
+// the leaf values are recorded along the way.
+func expand(key string, v any, file string, sources map[string]string) (any, error) {
+	switch val := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(val))
+		for k, v := range val {
+			k = strings.ToLower(k)
+			e, err := expand(joinKey(key, k), v, file, sources)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = e
+		}
+		return m, nil
+	case []any:
+		s := make([]any, len(val))
+		for i, v := range val {
+			e, err := expand(key, v, file, sources)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = e
+		}
+		sources[key] = file
+		return s, nil
+	case string:
+		e, refs, err := expandRefs(val, key, file)
+		if err != nil {
+			return nil, err
+		}
+		if len(refs) > 0 {
+			sources[key] = fmt.Sprintf("%s (%s)", file, strings.Join(refs, ", "))
+		} else {
+			sources[key] = file
+		}
+		return e, nil
+	default:
+		sources[key] = file
+		return v, nil
+	}
+}
+
+// expandRefs expands references in the configuration value. The following
+// references are supported:
+//
+//   - ${env:NAME} is replaced with the value of the NAME environment variable
+//   - ${env:NAME:-default} falls back to the default value if the variable is not set
+//   - ${file:path} is replaced with the contents of the file without trailing new lines
+//
+// Other dollar sign sequences, such as $$ or ${NAME}, are left untouched, so
+// values written for versions that didn't expand references keep their meaning.
+// The reference is escaped by doubling the dollar sign, e.g. $${env:NAME} yields
+// ${env:NAME}. If the whole value is a single environment variable reference that
+// resolves to an integer or boolean, the expanded value retains its type.
+func expandRefs(s, key, source string) (any, []string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil, nil
+	}
+	var (
+		refs []string
+		err  error
+	)
+	expanded := refRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		if strings.HasPrefix(m, "$$") {
+			return m[1:]
+		}
+		if err != nil {
+			return ""
+		}
+		ref := m[2 : len(m)-1]
+		if path, ok := strings.CutPrefix(ref, "file:"); ok {
+			b, ferr := os.ReadFile(path)
+			if ferr != nil {
+				err = fmt.Errorf("unable to read secret file referenced by %s in %s: %v", key, source, ferr)
+				return ""
+			}
+			refs = append(refs, "file "+path)
+			return strings.TrimRight(string(b), "\r\n")
+		}
+		name, def, hasDefault := strings.Cut(strings.TrimPrefix(ref, "env:"), ":-")
+		refs = append(refs, "env "+name)
+		if v, ok := os.LookupEnv(name); ok {
+			return v
+		}
+		if !hasDefault {
+			err = ErrEnvNotSet(name, key, source)
+			return ""
+		}
+		return def
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(refs) == 1 && strings.HasPrefix(refs[0], "env ") && refRegexp.FindString(s) == s {
+		return typed(expanded), refs, nil
+	}
+	return expanded, refs, nil
+}
+
+// typed converts integer and boolean literals to their native types.
+func typed(s string) any {
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if n, err := strconv.Atoi(s); err == nil && strconv.Itoa(n) == s {
+		return n
+	}
+	return s
+}
+
+// profilePath resolves the path of the role profile. The profile is either
+// the path to the configuration file, or the name of the file inside the
+// profiles directory that lives alongside the base configuration file.
+func profilePath(base, profile string) (string, error) {
+	if filepath.Ext(profile) != "" || strings.ContainsAny(profile, `/\`) {
+		if !filepath.IsAbs(profile) {
+			profile = filepath.Join(base, profile)
+		}
+		return profile, nil
+	}
+	for _, ext := range configExts {
+		path := filepath.Join(base, "profiles", profile+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("profile %s not found in %s", profile, filepath.Join(base, "profiles"))
+}
+
+// dropIns returns configuration files inside the drop-in directory sorted by name.
+func dropIns(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		for _, ext := range configExts {
+			if strings.EqualFold(filepath.Ext(e.Name()), ext) {
+				files = append(files, filepath.Join(dir, e.Name()))
+				break
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/outputs/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayeredConfig(t *testing.T) {
+	t.Setenv("AMQP_URL", "amqp://rabbitmq:5672")
+
+	c := NewWithOpts(WithRun())
+	err := c.flags.Parse([]string{"--config-file=_fixtures/layers/fibratus.yml", "--config-profile=dc"})
+	require.NoError(t, err)
+	require.NoError(t, c.viper.BindPFlags(c.flags))
+	require.NoError(t, c.TryLoadFile(c.GetConfigFile()))
+	require.NoError(t, c.Init())
+	require.NoError(t, c.Validate())
+
+	assert.Equal(t, []string{
+		"_fixtures/layers/fibratus.yml",
+		filepath.Join("_fixtures", "layers", "profiles", "dc.yml"),
+		filepath.Join("_fixtures", "layers", "conf.d", "10-amqp.yml"),
+		filepath.Join("_fixtures", "layers", "conf.d", "20-yara.yml"),
+	}, c.layers.files)
+
+	// base settings are overridden by the profile and drop-ins
+	assert.Equal(t, time.Millisecond*500, c.Aggregator.FlushPeriod)
+	assert.Equal(t, time.Second*4, c.Aggregator.FlushTimeout)
+	assert.True(t, c.Yara.Enabled)
+
+	require.IsType(t, amqp.Config{}, c.Output.Output)
+	amqpConfig := c.Output.Output.(amqp.Config)
+	assert.Equal(t, "amqp://rabbitmq:5672", amqpConfig.URL)
+	assert.Equal(t, time.Second*5, amqpConfig.Timeout)
+	assert.Equal(t, "amqp-s3cr3t", amqpConfig.Password)
+	assert.Equal(t, "$${host}", amqpConfig.RoutingKey)
+
+	assert.Equal(t, "_fixtures/layers/fibratus.yml", c.source("aggregator.flush-timeout"))
+	assert.Equal(t, filepath.Join("_fixtures", "layers", "profiles", "dc.yml"), c.source("aggregator.flush-period"))
+	assert.Equal(t, filepath.Join("_fixtures", "layers", "profiles", "dc.yml")+" (env AMQP_URL)", c.source("output.amqp.url"))
+	assert.Equal(t, "default", c.source("output.amqp.vhost"))
+
+	settings, err := c.Effective()
+	require.NoError(t, err)
+	for _, s := range settings {
+		switch s.Key {
+		case "output.amqp.password":
+			assert.Equal(t, "********", s.Value)
+			assert.Contains(t, s.Source, "10-amqp.yml (file _fixtures/layers/amqp.secret)")
+		case "yara.enabled":
+			assert.Equal(t, true, s.Value)
+			assert.Equal(t, filepath.Join("_fixtures", "layers", "conf.d", "20-yara.yml"), s.Source)
+		}
+	}
+}
+
+func TestExpandRefs(t *testing.T) {
+	t.Setenv("FIBRATUS_PORT", "9200")
+	t.Setenv("FIBRATUS_HOST", "localhost")
+
+	var tests = []struct {
+		s    string
+		want any
+		err  bool
+	}{
+		{"no refs", "no refs", false},
+		{"${env:FIBRATUS_PORT}", 9200, false},
+		{"http://${env:FIBRATUS_HOST}:${env:FIBRATUS_PORT}", "http://localhost:9200", false},
+		{"${env:FIBRATUS_UNDEFINED:-fallback}", "fallback", false},
+		{"$${env:FIBRATUS_HOST}", "${env:FIBRATUS_HOST}", false},
+		{"pa$word", "pa$word", false},
+		{"${env:FIBRATUS_UNDEFINED}", nil, true},
+		{"${file:_fixtures/layers/missing.secret}", nil, true},
+		// values that don't contain well-formed references are preserved
+		{"pa$$word", "pa$$word", false},
+		{"pa$${word}", "pa$${word}", false},
+		{"${FIBRATUS_HOST}", "${FIBRATUS_HOST}", false},
+		{"${env:}", "${env:}", false},
+		{`^C:\\Users\\[^\\]+\\${2}$`, `^C:\\Users\\[^\\]+\\${2}$`, false},
+		{"$$${env:FIBRATUS_HOST}", "$${env:FIBRATUS_HOST}", false},
+		{"pa$$word@${env:FIBRATUS_HOST}", "pa$$word@localhost", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			v, _, err := expandRefs(tt.s, "key", "fibratus.yml")
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, v)
+		})
+	}
+}
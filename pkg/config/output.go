@@ -32,13 +32,17 @@ import (
 	"github.com/rabbitstack/fibratus/pkg/outputs/elasticsearch"
 	"github.com/rabbitstack/fibratus/pkg/outputs/http"
 	"github.com/rabbitstack/fibratus/pkg/outputs/null"
+	"github.com/rabbitstack/fibratus/pkg/outputs/otlp"
+	"github.com/rabbitstack/fibratus/pkg/outputs/s3"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/sys/windows/svc"
 )
 
 var errNoOutputSection = errors.New("no output section in config")
 
-var errOutputConfig = func(output string, err error) error { return fmt.Errorf("%s output invalid config: %v", output, err) }
+var errOutputConfig = func(output string, err error) error {
+	return keyError{key: "output." + output, err: fmt.Errorf("%s output invalid config: %v", output, err)}
+}
 
 func (c *Config) tryLoadOutput() error {
 	output := c.viper.AllSettings()["output"]
@@ -125,6 +129,26 @@ func (c *Config) tryLoadOutput() error {
 				continue
 			}
 			c.Output.Type, c.Output.Output = outputs.Eventlog, eventlogConfig
+
+		case outputs.OTLP:
+			var otlpConfig otlp.Config
+			if err := decode(config, &otlpConfig); err != nil {
+				return errOutputConfig(typ, err)
+			}
+			if !otlpConfig.Enabled {
+				continue
+			}
+			c.Output.Type, c.Output.Output = outputs.OTLP, otlpConfig
+
+		case outputs.S3:
+			var s3Config s3.Config
+			if err := decode(config, &s3Config); err != nil {
+				return errOutputConfig(typ, err)
+			}
+			if !s3Config.Enabled {
+				continue
+			}
+			c.Output.Type, c.Output.Output = outputs.S3, s3Config
 		}
 	}
 
@@ -21,6 +21,7 @@ package config
 import (
 	"bytes"
 	"fmt"
+	"github.com/rabbitstack/fibratus/pkg/secrets"
 	"reflect"
 	"sort"
 	"strings"
@@ -43,10 +44,10 @@ func (c *Config) printMap(m map[string]interface{}) string {
 			buffer.WriteString(" ")
 			buffer.WriteString(k)
 			buffer.WriteString("=>")
-			if strings.Contains(k, "password") {
-				buffer.WriteString("********")
-			} else {
+			if _, ok := v.(map[string]interface{}); ok {
 				buffer.WriteString(val)
+			} else {
+				buffer.WriteString(secrets.Redact(k, val))
 			}
 		}
 	}
@@ -101,7 +102,11 @@ func (c *Config) Print() string {
 
 	// print the options
 	for _, kv := range sorted {
-		c.printLine(&buffer, maxKeyLen, kv.k, c.print(kv.v))
+		val := c.print(kv.v)
+		if _, ok := kv.v.(map[string]interface{}); !ok {
+			val = secrets.Redact(kv.k, val)
+		}
+		c.printLine(&buffer, maxKeyLen, kv.k, val)
 	}
 
 	return buffer.String()
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/rabbitstack/fibratus/pkg/aggregator"
+)
+
+var errSamplingConfig = func(err error) error {
+	return keyError{key: "aggregator.sampling", err: fmt.Errorf("invalid sampling policies: %v", err)}
+}
+
+// tryLoadSampling decodes the aggregator sampling policies.
+func (c *Config) tryLoadSampling() error {
+	agg := c.viper.AllSettings()["aggregator"]
+	if agg == nil {
+		return nil
+	}
+	mapping, ok := agg.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected map[string]interface{} type for aggregator but found %s", reflect.TypeOf(agg))
+	}
+	if mapping["sampling"] == nil {
+		return nil
+	}
+	var policies []aggregator.SamplingPolicy
+	if err := decode(mapping["sampling"], &policies); err != nil {
+		return errSamplingConfig(err)
+	}
+	c.Aggregator.Sampling = policies
+	return nil
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTryLoadSampling(t *testing.T) {
+	c := NewWithOpts(WithRun())
+	c.viper.Set("aggregator.sampling", []interface{}{
+		map[string]interface{}{
+			"name":       "file-io",
+			"events":     []interface{}{"ReadFile", "WriteFile"},
+			"rate-limit": 100,
+		},
+		map[string]interface{}{
+			"name":       "registry-queries",
+			"categories": "registry",
+			"dedup":      map[string]interface{}{"max": 5, "window": "1m"},
+		},
+	})
+	require.NoError(t, c.tryLoadSampling())
+	require.Len(t, c.Aggregator.Sampling, 2)
+	assert.Equal(t, []string{"ReadFile", "WriteFile"}, c.Aggregator.Sampling[0].Events)
+	assert.Equal(t, []string{"registry"}, c.Aggregator.Sampling[1].Categories)
+	assert.Equal(t, time.Minute, c.Aggregator.Sampling[1].Dedup.Window)
+
+	c = NewWithOpts(WithRun())
+	c.viper.Set("aggregator.sampling", []interface{}{
+		map[string]interface{}{"name": "file-io", "ratio": "half"},
+	})
+	err := c.tryLoadSampling()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid sampling policies")
+}
@@ -29,7 +29,9 @@ import (
 	"reflect"
 )
 
-var errTransformerConfig = func(t string, err error) error { return fmt.Errorf("%s transformer invalid config: %v", t, err) }
+var errTransformerConfig = func(t string, err error) error {
+	return keyError{key: "transformers." + t, err: fmt.Errorf("%s transformer invalid config: %v", t, err)}
+}
 
 func (c *Config) tryLoadTransformers() error {
 	transforms := c.viper.AllSettings()["transformers"]
@@ -25,21 +25,31 @@ import (
 )
 
 func validate(s string, m interface{}) (bool, []error) {
+	r, err := validateSchema(s, m)
+	if err != nil {
+		return false, []error{err}
+	}
+	errs := make([]error, len(r.Errors()))
+	for i, err := range r.Errors() {
+		errs[i] = errors.New(err.String())
+	}
+	return r.Valid(), errs
+}
+
+// validateSchema validates the value against the schema and returns
+// the validation result with the path to each offending field.
+func validateSchema(s string, m interface{}) (*gojsonschema.Result, error) {
 	converted, err := convertToStringKeysRecursive(m, "")
 	if err != nil {
-		return false, []error{fmt.Errorf("fail to convert keys to string: %v", err)}
+		return nil, fmt.Errorf("fail to convert keys to string: %v", err)
 	}
 	loader := gojsonschema.NewGoLoader(converted)
 	sc := gojsonschema.NewStringLoader(s)
 	r, err := gojsonschema.Validate(sc, loader)
 	if err != nil {
-		return false, []error{fmt.Errorf("fail to validate file through schema: %v", err)}
+		return nil, fmt.Errorf("fail to validate file through schema: %v", err)
 	}
-	errs := make([]error, len(r.Errors()))
-	for i, err := range r.Errors() {
-		errs[i] = errors.New(err.String())
-	}
-	return r.Valid(), errs
+	return r, nil
 }
 
 // convertToStringKeysRecursive ensures keys are converted to strings for jsonschema.
@@ -38,6 +38,27 @@ func TestValidate(t *testing.T) {
 		{text: `aggregator:
                  flush-perio: 20ms
                  flush-timeout: 1`, valid: false, errs: 2},
+		{text: `aggregator:
+                 rollup:
+                  enabled: true
+                  interval: 500ms`, valid: true},
+		{text: `aggregator:
+                 rollup:
+                  enabled: true
+                  interval: abcs`, valid: false, errs: 1},
+		{text: `aggregator:
+                 rollup:
+                  interval: 5h`, valid: false, errs: 1},
+		{text: `aggregator:
+                 sampling:
+                  - name: registry
+                    dedup:
+                     window: 1h`, valid: true},
+		{text: `aggregator:
+                 sampling:
+                  - name: registry
+                    dedup:
+                     window: abcs`, valid: false, errs: 1},
 
 		{text: `alertsenders:
                  mail: 
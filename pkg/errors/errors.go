@@ -19,13 +19,16 @@
 package errors
 
 import (
+	"errors"
 	"fmt"
 )
 
 var (
+	// ErrUnsupported is the sentinel error wrapped by the unsupported feature errors
+	ErrUnsupported = errors.New("unsupported feature")
 	// ErrFeatureUnsupported is thrown when a certain feature was not triggered via the build flag
 	ErrFeatureUnsupported = func(s string) error {
-		return fmt.Errorf("fibratus was compiled without %s support. Please compile with the '%s' build flag", s, s)
+		return &featureUnsupportedError{feature: s}
 	}
 
 	// ErrHTTPServerUnavailable signals that the HTTP server is not running on the specified transport
@@ -34,6 +37,17 @@ var (
 	}
 )
 
+// featureUnsupportedError is returned when the feature was not compiled in.
+type featureUnsupportedError struct {
+	feature string
+}
+
+func (e *featureUnsupportedError) Error() string {
+	return fmt.Sprintf("fibratus was compiled without %s support. Please compile with the '%s' build flag", e.feature, e.feature)
+}
+
+func (e *featureUnsupportedError) Unwrap() error { return ErrUnsupported }
+
 // ErrParamNotFound is the error is thrown when a parameter is not present in the list of parameters
 type ErrParamNotFound struct {
 	Name string
@@ -44,6 +44,8 @@ const (
 	YaraMatchesKey MetadataKey = "yara.matches"
 	// RuleNameKey identifies the rule that was triggered by the event
 	RuleNameKey MetadataKey = "rule.name"
+	// RuleSeverityKey designates the severity of the triggered rule
+	RuleSeverityKey MetadataKey = "rule.severity"
 	// RuleSequenceLink represents the join link values in sequence rules
 	RuleSequenceLinks MetadataKey = "rule.seq.links"
 	// RuleSequenceOOOKey the presence of this metadata key indicates the
@@ -51,6 +53,8 @@ const (
 	RuleSequenceOOOKey MetadataKey = "rule.seq.ooo"
 	// EvasionsKey represents the evasion behaviours detected on the event
 	EvasionsKey MetadataKey = "evasions"
+	// RollupKey designates the synthetic event that summarizes repeated events
+	RollupKey MetadataKey = "rollup"
 )
 
 func (key MetadataKey) String() string { return string(key) }
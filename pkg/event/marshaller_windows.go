@@ -421,8 +421,6 @@ func (e *Event) UnmarshalRaw(b []byte, ver capver.Version) error {
 	return nil
 }
 
-var js = newJSONStream()
-
 func writePsResources() bool {
 	return SerializeHandles || SerializeThreads || SerializeModules || SerializePE
 }
@@ -433,6 +431,11 @@ func (e *Event) MarshalJSON() []byte {
 		return []byte{}
 	}
 
+	// each invocation gets its own stream, so
+	// events can be safely serialized from
+	// multiple goroutines
+	js := newJSONStream()
+
 	// start of JSON
 	js.writeObjectStart()
 
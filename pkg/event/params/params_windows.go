@@ -299,4 +299,13 @@ const (
 	ThreadpoolTimerWindow = "window"
 	// ThreadpoolTimerAbsolute indicates if the timer is absolute or relative.
 	ThreadpoolTimerAbsolute = "absolute"
+
+	// RollupCount represents the number of events folded into the rollup summary.
+	RollupCount = "rollup_count"
+	// RollupBytes represents the total number of bytes transferred by the events folded into the rollup summary.
+	RollupBytes = "rollup_bytes"
+	// RollupFirstTimestamp represents the timestamp of the first event folded into the rollup summary.
+	RollupFirstTimestamp = "rollup_first_timestamp"
+	// RollupLastTimestamp represents the timestamp of the last event folded into the rollup summary.
+	RollupLastTimestamp = "rollup_last_timestamp"
 )
@@ -20,6 +20,9 @@ package event
 
 import (
 	"expvar"
+	"fmt"
+
+	"github.com/rabbitstack/fibratus/pkg/tracing"
 )
 
 // eventsEnqueued counts the number of events that are pushed to the queue
@@ -126,14 +129,22 @@ func (q *Queue) Push(e *Event) error {
 }
 
 func (q *Queue) push(e *Event) error {
+	span := tracing.Start(e.Seq, "event.push")
+	defer span.End()
+	if span != nil {
+		span.SetString("event.name", e.Name)
+		span.SetInt("event.pid", int64(e.PID))
+	}
+
 	var enqueue bool
 	if q.enqueueAlways {
 		enqueue = true
 	}
 
 	for _, listener := range q.listeners {
-		enq, err := listener.ProcessEvent(e)
+		enq, err := q.process(listener, e)
 		if err != nil {
+			span.SetError(err)
 			return err
 		}
 		if listener.CanEnqueue() && enq {
@@ -144,7 +155,23 @@ func (q *Queue) push(e *Event) error {
 	if enqueue || len(q.listeners) == 0 {
 		q.q <- e
 		eventsEnqueued.Add(1)
+		span.SetBool("enqueued", true)
 	}
 
 	return nil
 }
+
+// process invokes the listener and records the
+// listener span if the event is sampled for tracing.
+func (q *Queue) process(listener Listener, e *Event) (bool, error) {
+	span := tracing.Start(e.Seq, "listener.process")
+	if span == nil {
+		return listener.ProcessEvent(e)
+	}
+	defer span.End()
+	span.SetString("listener", fmt.Sprintf("%T", listener))
+	enq, err := listener.ProcessEvent(e)
+	span.SetBool("enqueue", enq)
+	span.SetError(err)
+	return enq, err
+}
@@ -522,7 +522,7 @@ func (f *filament) emitAlertFn(_, args cpython.PyArgs, kwargs cpython.PyKwargs)
 			tags,
 			alertsender.ParseSeverityFromString(sever),
 		)
-		if err := s.Send(alert); err != nil {
+		if err := alertsender.Send(s, alert); err != nil {
 			log.Warnf("unable to emit alert from filament: %v", err)
 		}
 	}
@@ -28,6 +28,7 @@ import (
 	"github.com/rabbitstack/fibratus/pkg/event"
 	"github.com/rabbitstack/fibratus/pkg/event/params"
 	"github.com/rabbitstack/fibratus/pkg/filter/fields"
+	"github.com/rabbitstack/fibratus/pkg/filter/ql/functions"
 )
 
 var (
@@ -67,6 +68,8 @@ const timeFmt = "15:04:05"
 const dateFmt = "2006-01-02"
 
 func (*evtAccessor) Get(f Field, evt *event.Event) (params.Value, error) {
+	// timestamp components are evaluated in the configured time zone
+	ts := functions.InLocation(evt.Timestamp)
 	switch f.Name {
 	case fields.EvtSeq, fields.KevtSeq:
 		return evt.Seq, nil
@@ -85,31 +88,35 @@ func (*evtAccessor) Get(f Field, evt *event.Event) (params.Value, error) {
 	case fields.EvtHost, fields.KevtHost:
 		return evt.Host, nil
 	case fields.EvtTime, fields.KevtTime:
-		return evt.Timestamp.Format(timeFmt), nil
+		return ts.Format(timeFmt), nil
 	case fields.EvtTimeHour, fields.KevtTimeHour:
-		return uint8(evt.Timestamp.Hour()), nil
+		return uint8(ts.Hour()), nil
 	case fields.EvtTimeMin, fields.KevtTimeMin:
-		return uint8(evt.Timestamp.Minute()), nil
+		return uint8(ts.Minute()), nil
 	case fields.EvtTimeSec, fields.KevtTimeSec:
-		return uint8(evt.Timestamp.Second()), nil
+		return uint8(ts.Second()), nil
 	case fields.EvtTimeNs, fields.KevtTimeNs:
-		return evt.Timestamp.UnixNano(), nil
+		return ts.UnixNano(), nil
+	case fields.EvtTimestamp:
+		return ts, nil
 	case fields.EvtDate, fields.KevtDate:
-		return evt.Timestamp.Format(dateFmt), nil
+		return ts.Format(dateFmt), nil
 	case fields.EvtDateDay, fields.KevtDateDay:
-		return uint8(evt.Timestamp.Day()), nil
+		return uint8(ts.Day()), nil
 	case fields.EvtDateMonth, fields.KevtDateMonth:
-		return uint8(evt.Timestamp.Month()), nil
+		return uint8(ts.Month()), nil
 	case fields.EvtDateTz, fields.KevtDateTz:
-		tz, _ := evt.Timestamp.Zone()
+		tz, _ := ts.Zone()
 		return tz, nil
 	case fields.EvtDateYear, fields.KevtDateYear:
-		return uint32(evt.Timestamp.Year()), nil
+		return uint32(ts.Year()), nil
 	case fields.EvtDateWeek, fields.KevtDateWeek:
-		_, week := evt.Timestamp.ISOWeek()
+		_, week := ts.ISOWeek()
 		return uint8(week), nil
 	case fields.EvtDateWeekday, fields.KevtDateWeekday:
-		return evt.Timestamp.Weekday().String(), nil
+		return ts.Weekday().String(), nil
+	case fields.EvtDateYearday:
+		return uint16(ts.YearDay()), nil
 	case fields.EvtNparams, fields.KevtNparams:
 		return uint64(evt.Params.Len()), nil
 	case fields.EvtArg, fields.KevtArg:
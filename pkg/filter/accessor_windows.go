@@ -36,6 +36,7 @@ import (
 	"github.com/rabbitstack/fibratus/pkg/event"
 	"github.com/rabbitstack/fibratus/pkg/event/params"
 	"github.com/rabbitstack/fibratus/pkg/filter/fields"
+	"github.com/rabbitstack/fibratus/pkg/filter/ql/functions"
 	"github.com/rabbitstack/fibratus/pkg/pe"
 	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
 )
@@ -45,6 +46,18 @@ var (
 	ErrPENil = errors.New("pe state is nil")
 )
 
+// hashFns maps the file hash fields to the functions that compute the digest.
+var hashFns = map[fields.Field]functions.Fn{
+	fields.PsPeSHA1:   functions.SHA1Fn,
+	fields.PsPeSHA256: functions.SHA256Fn,
+	fields.PsPeSsdeep: functions.SsdeepFn,
+	fields.PsPeTLSH:   functions.TLSHFn,
+	fields.FileSHA1:   functions.SHA1Fn,
+	fields.FileSHA256: functions.SHA256Fn,
+	fields.FileSsdeep: functions.SsdeepFn,
+	fields.FileTLSH:   functions.TLSHFn,
+}
+
 // GetAccessors initializes and returns all available accessors.
 func GetAccessors() []Accessor {
 	return []Accessor{
@@ -196,6 +209,22 @@ func (ps *psAccessor) Get(f Field, e *event.Event) (params.Value, error) {
 			return nil, ErrPsNil
 		}
 		return ps.UUID(), nil
+	case fields.PsTree:
+		if e.PS == nil {
+			return nil, ErrPsNil
+		}
+		// the optional argument overrides the default
+		// number of ancestors visited while resolving
+		// the root of the process tree
+		depth := pstypes.TreeDepth
+		if f.Arg != "" {
+			var err error
+			depth, err = strconv.Atoi(f.Arg)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return pstypes.TreeRoot(e.PS, depth).UUID(), nil
 	case fields.PsParentUUID:
 		ps := getParentPs(e)
 		if ps == nil {
@@ -743,6 +772,12 @@ func (l *fileAccessor) Get(f Field, e *event.Event) (params.Value, error) {
 			return file.IsExecutable, nil
 		}
 		return false, nil
+	case fields.FileSHA1, fields.FileSHA256, fields.FileSsdeep, fields.FileTLSH:
+		path := e.GetParamAsString(params.FilePath)
+		if path == "" {
+			return nil, nil
+		}
+		return functions.HashFile(hashFns[f.Name], path)
 	case fields.FilePID:
 		return e.Params.GetPid()
 	case fields.FileKey:
@@ -1066,6 +1101,16 @@ func (pa *peAccessor) Get(f Field, e *event.Event) (params.Value, error) {
 		return nil, nil
 	}
 
+	// file hashes are computed from the executable
+	// content and don't require parsing the PE
+	switch f.Name {
+	case fields.PsPeSHA1, fields.PsPeSHA256, fields.PsPeSsdeep, fields.PsPeTLSH:
+		if e.PS == nil || e.PS.Exe == "" {
+			return nil, nil
+		}
+		return functions.HashFile(hashFns[f.Name], e.PS.Exe)
+	}
+
 	var p *pe.PE
 	if e.PS != nil && e.PS.PE != nil {
 		p = e.PS.PE
@@ -1151,6 +1196,8 @@ func (pa *peAccessor) Get(f Field, e *event.Event) (params.Value, error) {
 		return p.IsTrusted(), nil
 	case fields.PeIsModified:
 		return p.IsModified, nil
+	case fields.PeLinkTime, fields.PsPeLinkTime:
+		return p.LinkTime, nil
 	case fields.PeCertIssuer, fields.PsSignatureIssuer:
 		if p.Cert == nil {
 			return nil, ErrPeNilCertificate
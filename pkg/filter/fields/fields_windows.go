@@ -115,6 +115,8 @@ const (
 	PsParentIsProtectedField Field = "ps.parent.is_protected"
 	// PsUUID represents the unique process identifier
 	PsUUID Field = "ps.uuid"
+	// PsTree represents the unique identifier of the process tree root ancestor
+	PsTree Field = "ps.tree"
 	// PsParentUUID represents the unique parent process identifier
 	PsParentUUID Field = "ps.parent.uuid"
 	// PsTokenIntegrityLevel represents the field that indicates the current process integrity level
@@ -152,7 +154,9 @@ const (
 	PsPeSymbols Field = "ps.pe.symbols"
 	// PeImports represents imported libraries (e.g. kernel32.dll)
 	PsPeImports Field = "ps.pe.imports"
-	// PeTimestamp is the PE build timestamp
+	// PsPeTimestamp is the PE build timestamp
+	//
+	// Deprecated: the field is not evaluated. Use PsPeLinkTime instead.
 	PsPeTimestamp Field = "ps.pe.timestamp"
 	// PeBaseAddress represents the base address when the binary is loaded
 	PsPeBaseAddress Field = "ps.pe.address.base"
@@ -182,6 +186,21 @@ const (
 	PsPeIsDotnet Field = "ps.pe.is_dotnet"
 	// PsPeIsModified is the field that indicates whether disk and in-memory PE headers differ
 	PsPeIsModified Field = "ps.pe.is_modified"
+	// PsPeLinkTime is the field that yields the time the image was created by the linker
+	PsPeLinkTime Field = "ps.pe.link_time"
+
+	// The hash fields read and hash the whole executable when they are evaluated.
+	// Executables larger than the filters.max-hash-file-size option are not hashed,
+	// and the digests are cached by the file path, size, and modification time.
+	//
+	// PsPeSHA1 is the field that yields the SHA-1 hash of the process executable
+	PsPeSHA1 Field = "ps.pe.sha1"
+	// PsPeSHA256 is the field that yields the SHA-256 hash of the process executable
+	PsPeSHA256 Field = "ps.pe.sha256"
+	// PsPeSsdeep is the field that yields the ssdeep fuzzy hash of the process executable
+	PsPeSsdeep Field = "ps.pe.ssdeep"
+	// PsPeTLSH is the field that yields the TLSH fuzzy hash of the process executable
+	PsPeTLSH Field = "ps.pe.tlsh"
 
 	// ThreadBasePrio is the base thread priority
 	ThreadBasePrio Field = "thread.prio"
@@ -267,7 +286,11 @@ const (
 	// PeImports represents imported libraries (e.g. kernel32.dll)
 	PeImports Field = "pe.imports"
 	// PeTimestamp is the PE build timestamp
+	//
+	// Deprecated: the field is not evaluated. Use PsPeLinkTime instead.
 	PeTimestamp Field = "pe.timestamp"
+	// PeLinkTime is the time the image was created by the linker
+	PeLinkTime Field = "pe.link_time"
 	// PeBaseAddress represents the base address when the binary is loaded
 	PeBaseAddress Field = "pe.address.base"
 	// PeEntrypoint is the address of the entrypoint function
@@ -339,6 +362,8 @@ const (
 	EvtTimeSec Field = "evt.time.s"
 	// EvtTimeNs is the nanosecond part of the event time
 	EvtTimeNs Field = "evt.time.ns"
+	// EvtTimestamp is the full event timestamp
+	EvtTimestamp Field = "evt.timestamp"
 	// EvtDate is the event date
 	EvtDate Field = "evt.date"
 	// EvtDateDay is the day of event date
@@ -353,6 +378,8 @@ const (
 	EvtDateWeek Field = "evt.date.week"
 	// EvtDateWeekday is the event week day
 	EvtDateWeekday Field = "evt.date.weekday"
+	// EvtDateYearday is the day of the year of event date
+	EvtDateYearday Field = "evt.date.yday"
 	// EvtName is the event name
 	EvtName Field = "evt.name"
 	// EvtCategory is the event category
@@ -483,6 +510,18 @@ const (
 	FileIsDriver Field = "file.is_driver"
 	// FileIsExecutable indicates if the created file is an executable
 	FileIsExecutable Field = "file.is_exec"
+
+	// The hash fields read the file on evaluation. They are subject to the same size
+	// limit and cache as the process executable hash fields (see PsPeSHA1).
+	//
+	// FileSHA1 represents the SHA-1 hash of the file content
+	FileSHA1 Field = "file.sha1"
+	// FileSHA256 represents the SHA-256 hash of the file content
+	FileSHA256 Field = "file.sha256"
+	// FileSsdeep represents the ssdeep fuzzy hash of the file content
+	FileSsdeep Field = "file.ssdeep"
+	// FileTLSH represents the TLSH fuzzy hash of the file content
+	FileTLSH Field = "file.tlsh"
 	// FilePID represents the field that denotes the process id performing file operations
 	FilePID Field = "file.pid"
 	// FileKey represents the field that uniquely identifies the file object.
@@ -925,6 +964,8 @@ var fields = map[Field]FieldInfo{
 	EvtDateTz:      {EvtDateTz, "time zone associated with the event timestamp", params.AnsiString, []string{"evt.date.tz = 'UTC'"}, nil, nil},
 	EvtDateWeek:    {EvtDateWeek, "week number within the year on which the event occurred", params.Uint8, []string{"evt.date.week = 2"}, nil, nil},
 	EvtDateWeekday: {EvtDateWeekday, "week day on which the event occurred", params.AnsiString, []string{"evt.date.weekday = 'Monday'"}, nil, nil},
+	EvtDateYearday: {EvtDateYearday, "day of the year on which the event occurred", params.Uint16, []string{"evt.date.yday = 365"}, nil, nil},
+	EvtTimestamp:   {EvtTimestamp, "event timestamp", params.Time, []string{"evt.timestamp - ps.pe.link_time < 1d", "evt.timestamp > '2024-05-01'"}, nil, nil},
 	EvtNparams:     {EvtNparams, "number of parameters", params.Int8, []string{"evt.nparams > 2"}, nil, nil},
 	EvtArg: {EvtArg, "event parameter", params.Object, []string{"evt.arg[cmdline] istartswith 'C:\\Windows'"}, nil, &Argument{Optional: false, Pattern: "[a-z0-9_]+", ValidationFunc: func(s string) bool {
 		for _, c := range s {
@@ -1011,6 +1052,7 @@ var fields = map[Field]FieldInfo{
 	PsAccessMaskNames:           {PsAccessMaskNames, "process desired access rights as a string list", params.Slice, []string{"ps.access.mask.names in ('SUSPEND_RESUME')"}, nil, nil},
 	PsAccessStatus:              {PsAccessStatus, "process access status", params.UnicodeString, []string{"ps.access.status = 'access is denied.'"}, nil, nil},
 	PsUUID:                      {PsUUID, "unique process identifier", params.Uint64, []string{"ps.uuid > 6000054355"}, nil, nil},
+	PsTree:                      {PsTree, "unique identifier of the process tree root ancestor", params.Uint64, []string{"ps.tree = 6000054355", "ps.tree[2] = 6000054355"}, nil, &Argument{Optional: true, Pattern: "[0-9]+", ValidationFunc: isNumber}},
 	PsParentUUID:                {PsParentUUID, "unique parent process identifier", params.Uint64, []string{"ps.parent.uuid > 6000054355"}, nil, nil},
 	PsIsWOW64Field:              {PsIsWOW64Field, "indicates if the process generating the event is a 32-bit process created in 64-bit Windows system", params.Bool, []string{"ps.is_wow64"}, nil, nil},
 	PsIsPackagedField:           {PsIsPackagedField, "indicates if the process generating the event is packaged with the MSIX technology", params.Bool, []string{"ps.is_packaged"}, nil, nil},
@@ -1063,6 +1105,11 @@ var fields = map[Field]FieldInfo{
 	PsPeIsDotnet:       {PsPeIsDotnet, "indicates if PE contains CLR data", params.Bool, []string{"ps.pe.is_dotnet"}, nil, nil},
 	PsPeAnomalies:      {PsPeAnomalies, "contains PE anomalies detected during parsing", params.Slice, []string{"ps.pe.anomalies in ('number of sections is 0')"}, nil, nil},
 	PsPeIsModified:     {PsPeIsModified, "indicates if disk and in-memory PE headers differ", params.Bool, []string{"ps.pe.is_modified"}, nil, nil},
+	PsPeLinkTime:       {PsPeLinkTime, "time the image was created by the linker", params.Time, []string{"evt.timestamp - ps.pe.link_time < 1d"}, nil, nil},
+	PsPeSHA1:           {PsPeSHA1, "SHA-1 hash of the process executable", params.AnsiString, []string{"ps.pe.sha1 = 'a9993e364706816aba3e25717850c26c9cd0d89d'"}, nil, nil},
+	PsPeSHA256:         {PsPeSHA256, "SHA-256 hash of the process executable", params.AnsiString, []string{"ps.pe.sha256 in ('ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad')"}, nil, nil},
+	PsPeSsdeep:         {PsPeSsdeep, "ssdeep fuzzy hash of the process executable", params.AnsiString, []string{"ssdeep_compare(ps.pe.ssdeep, '96:yNDH/iNQaSXRLmOSxu1aQP4iWgC8JbkiA5Ix:yNLaNQhSxEgVYkiA5Ix') > 80"}, nil, nil},
+	PsPeTLSH:           {PsPeTLSH, "TLSH fuzzy hash of the process executable", params.AnsiString, []string{"tlsh_compare(ps.pe.tlsh, 'T113E0C03943451700AE8321BFB119555BC058B5500A29979DFCD5C50F8841104C526761') < 50"}, nil, nil},
 
 	ThreadBasePrio:                                 {ThreadBasePrio, "scheduler priority of the thread", params.Int8, []string{"thread.prio = 5"}, nil, nil},
 	ThreadIOPrio:                                   {ThreadIOPrio, "I/O priority hint for scheduling I/O operations", params.Int8, []string{"thread.io.prio = 4"}, nil, nil},
@@ -1178,6 +1225,10 @@ var fields = map[Field]FieldInfo{
 	FileIsDLL:                       {FileIsDLL, "indicates if the created file is a DLL", params.Bool, []string{"file.is_dll'"}, nil, nil},
 	FileIsDriver:                    {FileIsDriver, "indicates if the created file is a driver", params.Bool, []string{"file.is_driver'"}, nil, nil},
 	FileIsExecutable:                {FileIsExecutable, "indicates if the created file is an executable", params.Bool, []string{"file.is_exec'"}, nil, nil},
+	FileSHA1:                        {FileSHA1, "SHA-1 hash of the file content", params.AnsiString, []string{"file.sha1 = 'a9993e364706816aba3e25717850c26c9cd0d89d'"}, nil, nil},
+	FileSHA256:                      {FileSHA256, "SHA-256 hash of the file content", params.AnsiString, []string{"file.sha256 in ('ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad')"}, nil, nil},
+	FileSsdeep:                      {FileSsdeep, "ssdeep fuzzy hash of the file content", params.AnsiString, []string{"ssdeep_compare(file.ssdeep, '96:yNDH/iNQaSXRLmOSxu1aQP4iWgC8JbkiA5Ix:yNLaNQhSxEgVYkiA5Ix') > 80"}, nil, nil},
+	FileTLSH:                        {FileTLSH, "TLSH fuzzy hash of the file content", params.AnsiString, []string{"tlsh_compare(file.tlsh, 'T113E0C03943451700AE8321BFB119555BC058B5500A29979DFCD5C50F8841104C526761') < 50"}, nil, nil},
 	FilePID:                         {FilePID, "denotes the process id performing file operation", params.PID, []string{"file.pid = 4"}, nil, nil},
 	FileKey:                         {FileKey, "uniquely identifies the file object", params.Uint64, []string{"file.key = 12446738026482168384"}, nil, nil},
 	FileInfoClass:                   {FileInfoClass, "identifies the file information class", params.Enum, []string{"file.info_class = 'Allocation'"}, nil, nil},
@@ -1251,6 +1302,7 @@ var fields = map[Field]FieldInfo{
 	PeCertAfter:      {PeCertAfter, "PE certificate expiration date", params.Time, []string{"pe.cert.after contains '2024-02-01 00:05:42 +0000 UTC'"}, &Deprecation{Since: "3.0.0", Fields: []Field{PsSignatureAfter}}, nil},
 	PeCertBefore:     {PeCertBefore, "PE certificate enrollment date", params.Time, []string{"pe.cert.before contains '2024-02-01 00:05:42 +0000 UTC'"}, &Deprecation{Since: "3.0.0", Fields: []Field{PsSignatureBefore}}, nil},
 	PeIsModified:     {PeIsModified, "indicates if disk and in-memory PE headers differ", params.Bool, []string{"pe.is_modified"}, &Deprecation{Since: "3.0.0", Fields: []Field{PsPeIsModified}}, nil},
+	PeLinkTime:       {PeLinkTime, "time the image was created by the linker", params.Time, []string{"evt.timestamp - pe.link_time < 1d"}, &Deprecation{Since: "3.0.0", Fields: []Field{PsPeLinkTime}}, nil},
 
 	MemBaseAddress:    {MemBaseAddress, "region base address", params.Address, []string{"mem.address = '211d13f2000'"}, nil, nil},
 	MemRegionSize:     {MemRegionSize, "region size", params.Uint64, []string{"mem.size > 438272"}, nil, nil},
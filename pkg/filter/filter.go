@@ -156,6 +156,8 @@ type filter struct {
 	// stringFields contains filter field names mapped to their string values
 	stringFields map[fields.Field][]string
 	hasFunctions bool
+	// matchers caches list matchers shared among filters
+	matchers *ql.MatcherCache
 }
 
 // Compile parsers the filter expression and builds a binary expression tree
@@ -197,6 +199,10 @@ func (f *filter) Compile() error {
 				f.addField(rhs.Field)
 				f.addBoundField(rhs)
 			}
+			// build hash sets and automatons for large lists
+			if list, ok := expr.RHS.(*ql.ListLiteral); ok {
+				list.Accelerate(expr.Op, f.matchers)
+			}
 		case *ql.Function:
 			f.hasFunctions = true
 			for _, arg := range expr.Args {
@@ -281,7 +287,7 @@ func (f *filter) evalBoundSequence(
 ) bool {
 	//  map all partials to their sequence aliases
 	maxSlots := len(partials[seqID])
-	aliasEvents := make(map[string][]*event.Event, seqID)
+	aliasEvents := make(map[string][]*event.Event, seqID+1)
 	for i := range seqID {
 		alias := f.seq.Expressions[i].Alias
 		if alias == "" {
@@ -292,6 +298,12 @@ func (f *filter) evalBoundSequence(
 			maxSlots = l
 		}
 	}
+	// the expression can reference its own alias to
+	// compare the current event with upstream events,
+	// e.g. $e2.evt.time.ns - $e1.evt.time.ns < 5s
+	if expr.Alias != "" {
+		aliasEvents[expr.Alias] = []*event.Event{e}
+	}
 
 	// retrieve or compute bound fields for this sequence expression
 	flds, ok := f.seqBoundFields[seqID]
@@ -305,15 +317,19 @@ func (f *filter) evalBoundSequence(
 		var evt *event.Event
 		for _, fld := range flds {
 			evts := aliasEvents[fld.BoundVar]
+			var bevt *event.Event
 			switch {
 			case len(evts) == 0:
 				continue
 			case slot >= len(evts):
 				// pick the latest event if all
 				// events for this slot are consumed
-				evt = evts[len(evts)-1]
+				bevt = evts[len(evts)-1]
 			default:
-				evt = evts[slot]
+				bevt = evts[slot]
+			}
+			if bevt != e {
+				evt = bevt
 			}
 
 			// extract bound variable value
@@ -321,7 +337,7 @@ func (f *filter) evalBoundSequence(
 			if accessor == nil {
 				continue
 			}
-			v, err := accessor.Get(fld.Field, evt)
+			v, err := accessor.Get(fld.Field, bevt)
 			if v == nil || err != nil {
 				if v == nil {
 					valuer[fld.Value] = defaultAccessorValue(fld.Field)
@@ -347,7 +363,9 @@ func (f *filter) evalBoundSequence(
 			}
 			hash := hashFields(values)
 			e.AddSequenceLink(hash)
-			evt.AddSequenceLink(hash)
+			if evt != nil {
+				evt.AddSequenceLink(hash)
+			}
 			return true
 		}
 	}
@@ -399,6 +417,28 @@ func (f *filter) evalSequence(
 	return match
 }
 
+// evalUnorderedSequence evaluates the expression of the unordered
+// sequence. Expressions are evaluated independently of each other,
+// and the join link is attached to the matching event, so that the
+// caller can correlate partials regardless of their order.
+func (f *filter) evalUnorderedSequence(
+	e *event.Event,
+	expr *ql.SequenceExpr,
+	valuer ql.MapValuer,
+) bool {
+	by := f.seq.By
+	if by == nil {
+		by = expr.By
+	}
+
+	match := ql.Eval(expr.Expr, valuer, f.hasFunctions)
+	if match && by != nil {
+		e.AddSequenceLink(makeSequenceLinkID(valuer, by))
+	}
+
+	return match
+}
+
 func (f *filter) EvalSequence(e *event.Event, valuerCache *ValuerCache, seqID int, partials map[int][]*event.Event, rawMatch bool) bool {
 	if f.seq == nil {
 		return false
@@ -416,6 +456,11 @@ func (f *filter) EvalSequence(e *event.Event, valuerCache *ValuerCache, seqID in
 		return ql.Eval(expr.Expr, valuer, f.hasFunctions)
 	}
 
+	if f.seq.IsAllOf {
+		// evaluate unordered sequences
+		return f.evalUnorderedSequence(e, &expr, valuer)
+	}
+
 	var match bool
 	if seqID >= 1 && expr.HasBoundFields() {
 		// evaluate bound field driven sequences
@@ -608,13 +653,16 @@ func (f *filter) checkBoundRefs() error {
 	return nil
 }
 
+// makeSequenceLinkID evaluates the join link expressions and produces the
+// link identifier. Single-valued links are used verbatim, while compound
+// links are hashed.
 func makeSequenceLinkID(valuer ql.MapValuer, link *ql.SequenceLink) any {
+	values := link.Eval(valuer)
 	if !link.IsCompound() {
-		return valuer[link.First()]
-	}
-	values := make([]any, 0, len(link.Fields))
-	for _, fld := range link.Fields {
-		values = append(values, valuer[fld.Value])
+		if len(values) == 0 {
+			return nil
+		}
+		return values[0]
 	}
 	return hashFields(values)
 }
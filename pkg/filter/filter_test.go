@@ -143,6 +143,24 @@ func TestMakeSequenceLinkID(t *testing.T) {
 			&ql.SequenceLink{Fields: []*ql.FieldLiteral{{Value: "ps.uuid"}}},
 			uint64(123232454234232132),
 		},
+		{ql.MapValuer{
+			"ps.uuid": uint64(123232454234232132),
+			"ps.exe":  "C:\\Windows\\System32\\cmd.exe"},
+			&ql.SequenceLink{
+				Fields: []*ql.FieldLiteral{{Value: "ps.exe", Field: fields.PsExe}},
+				Exprs:  []ql.Expr{&ql.Function{Name: "upper", Args: []ql.Expr{&ql.Function{Name: "base", Args: []ql.Expr{&ql.FieldLiteral{Value: "ps.exe", Field: fields.PsExe}}}}}},
+			},
+			"CMD.EXE",
+		},
+		{ql.MapValuer{
+			"ps.uuid": uint64(123232454234232132),
+			"ps.exe":  "C:\\Windows\\System32\\cmd.exe"},
+			&ql.SequenceLink{
+				Fields: []*ql.FieldLiteral{{Value: "ps.exe", Field: fields.PsExe}, {Value: "ps.uuid", Field: fields.PsUUID}},
+				Exprs:  []ql.Expr{&ql.Function{Name: "base", Args: []ql.Expr{&ql.FieldLiteral{Value: "ps.exe", Field: fields.PsExe}}}, &ql.FieldLiteral{Value: "ps.uuid", Field: fields.PsUUID}},
+			},
+			"636d642e65786544556ea343cfb501",
+		},
 	}
 
 	for _, tt := range tests {
@@ -268,6 +286,18 @@ func TestProcFilter(t *testing.T) {
 		{`ps.name ~= 'SVCHOST.exe'`, true},
 		{`ps.parent.cmdline = 'C:\\Windows\\system32\\svchost.exe -k RPCSS'`, true},
 		{`ps.cmdline = 'C:\\Windows\\System32\\svchost.exe -k DcomLaunch -p -s LSM'`, true},
+		{`cmdline_arg(ps.cmdline, '-k') = 'DcomLaunch'`, true},
+		{`cmdline_arg(ps.cmdline, '/S') = 'LSM'`, true},
+		{`cmdline_arg(ps.cmdline, '-enc') = ''`, true},
+		{`cmdline_arg(ps.cmdline, '-service|-s') = 'LSM'`, true},
+		{`levenshtein(ps.name, ('svch0st.exe', 'lsass.exe')) = 1`, true},
+		{`levenshtein(ps.name, ps.parent.name) > 2`, true},
+		{`jaro_winkler(ps.name, ('svhcost.exe', 'lsass.exe')) > 0.9`, true},
+		{`confusable(ps.name, ('svchost.exe', 'lsass.exe'))`, false},
+		{`confusable(ps.name, ('lsass.exe', 'svchоst.exe'))`, true},
+		{`has_bidi_override(ps.name)`, false},
+		{`utf16le_decode(b64decode('SQBFAFgA')) = 'IEX'`, true},
+		{`hexdecode(concat('4d', '5a')) = 'MZ'`, true},
 		{`ps.username = 'SYSTEM'`, true},
 		{`ps.domain = 'NT AUTHORITY'`, true},
 		{`ps.sid = 'S-1-5-18'`, true},
@@ -314,6 +344,9 @@ func TestProcFilter(t *testing.T) {
 		{`ps.ancestor[3] = ''`, true},
 		{`ps.ancestor intersects ('csrss.exe', 'services.exe', 'svchost.exe')`, true},
 		{`count(ps.ancestor, '*.exe') = 3`, true},
+		{`ps.tree = ps.uuid`, true},
+		{`ps.tree[0] = ps.uuid`, true},
+		{`ps.tree != ps.parent.uuid`, true},
 
 		{`foreach(ps._ancestors, $proc, $proc.name in ('csrss.exe', 'services.exe', 'System'))`, true},
 		{`foreach(ps._ancestors, $proc, $proc.name in ('csrss.exe', 'services.exe', 'System') and ps.is_packaged, ps.is_packaged)`, true},
@@ -350,6 +383,13 @@ func TestProcFilter(t *testing.T) {
 		{`foreach(ps._mmaps, $mmap, $mmap.path = 'C:\\Windows\\System32\\ucrtbase.dll' and $mmap.type = 'IMAGE')`, true},
 		{`foreach(ps._mmaps, $mmap, $mmap.address = '8415dd81bff2')`, true},
 		{`foreach(ps._mmaps, $mmap, $mmap.size = 4096)`, true},
+
+		{`ps.name iin ('cmd.exe', 'powershell.exe', 'pwsh.exe', 'wscript.exe', 'cscript.exe', 'mshta.exe', 'rundll32.exe', 'SVCHOST.EXE')`, true},
+		{`ps.name in ('cmd.exe', 'powershell.exe', 'pwsh.exe', 'wscript.exe', 'cscript.exe', 'mshta.exe', 'rundll32.exe', 'SVCHOST.EXE')`, false},
+		{`ps.cmdline icontains ('-enc', '-nop', 'bypass', 'iex', 'downloadstring', '/transfer', 'frombase64', 'dcomlaunch')`, true},
+		{`ps.cmdline contains ('-enc', '-nop', 'bypass', 'iex', 'downloadstring', '/transfer', 'frombase64', 'dcomlaunch')`, false},
+		{`ps.modules imatches ('*\\mimilib.dll', '*\\dbghelp.dll', '*\\vaultcli.dll', '*\\samlib.dll', '*\\wdigest.dll', '*\\kerberos.dll', '*\\msv1_0.dll', '?:\\WINDOWS\\*\\USER32.DLL')`, true},
+		{`ps.modules matches ('*\\mimilib.dll', '*\\dbghelp.dll', '*\\vaultcli.dll', '*\\samlib.dll', '*\\wdigest.dll', '*\\kerberos.dll', '*\\msv1_0.dll', '?:\\WINDOWS\\*\\USER32.DLL')`, false},
 	}
 
 	psnap := new(ps.SnapshotterMock)
@@ -683,6 +723,10 @@ func TestFileFilter(t *testing.T) {
 		{`is_abs(base(file.path))`, false},
 		{`file.path iin glob('C:\\Windows\\System32\\*.dll')`, true},
 		{`volume(file.path) = 'C:'`, true},
+		{`file.sha256 = sha256(file.path, true)`, true},
+		{`length(file.sha1) = 40`, true},
+		{`ssdeep_compare(file.ssdeep, ssdeep(file.path, true)) = 100`, true},
+		{`tlsh_compare(file.tlsh, tlsh(file.path, true)) = 0`, true},
 	}
 
 	for i, tt := range tests {
@@ -829,6 +873,10 @@ func TestEventFilter(t *testing.T) {
 
 		{`evt.date.d = 3 AND evt.date.m = 5 AND evt.time.s = 5 AND evt.time.m = 4 and evt.time.h = 15`, true},
 		{`evt.time = '15:04:05'`, true},
+		{`evt.date.yday = 123`, true},
+		{`evt.timestamp > '2011-05-01' and evt.timestamp < '2011-05-05'`, true},
+		{`hour(evt.timestamp) = 15 and weekday(evt.timestamp) = 'Tuesday'`, true},
+		{`date_diff(now(), evt.timestamp, 'd') > 365`, true},
 		{`concat(evt.name, evt.host, evt.nparams) = 'CreateFilearchrabbit5'`, true},
 		{`ltrim(evt.host, 'arch') = 'rabbit'`, true},
 		{`concat(ltrim(evt.name, 'Create'), evt.host) = 'Filearchrabbit'`, true},
@@ -1162,6 +1210,7 @@ func TestModuleFilter(t *testing.T) {
 
 func TestPEFilter(t *testing.T) {
 	evt := &event.Event{
+		Timestamp: time.Now(),
 		PS: &pstypes.PS{
 			Exe: filepath.Join(filepath.Join(os.Getenv("windir"), "System32", "notepad.exe")),
 			PE: &pe.PE{
@@ -1203,6 +1252,11 @@ func TestPEFilter(t *testing.T) {
 		{`ps.signature.subject icontains 'microsoft'`, true},
 		{`ps.signature.issuer icontains 'microsoft'`, true},
 		{`length(ps.signature.serial) > 0`, true},
+		{`evt.timestamp - ps.pe.link_time < 1d`, true},
+		{`ps.pe.link_time > '2020-01-01' and date_diff(evt.timestamp, ps.pe.link_time, 'h') = 0`, true},
+		{`ps.pe.sha256 = sha256(ps.exe, true)`, true},
+		{`ps.pe.sha1 = sha1(ps.exe, true)`, true},
+		{`length(ps.pe.ssdeep) > 0 and length(ps.pe.tlsh) = 72`, true},
 	}
 
 	for i, tt := range tests {
@@ -1340,6 +1394,11 @@ func TestDNSFilter(t *testing.T) {
 		{`dns.options in ('ADDRCONFIG', 'DUAL_ADDR')`, true},
 		{`dns.rcode = 'NOERROR'`, true},
 		{`dns.answers in ('incoming.telemetry.mozilla.org')`, true},
+		{`registered_domain(dns.name) = 'lencr.org' and tld(dns.name) = 'org'`, true},
+		{`subdomain_depth(dns.name) = 2`, true},
+		{`label_entropy(dns.name) < 3`, true},
+		{`dga_score(dns.name) > 0.6`, false},
+		{`url_parse(concat('https://', dns.name, '/ocsp'), 'host') = dns.name`, true},
 	}
 
 	for i, tt := range tests {
@@ -1578,6 +1637,33 @@ func BenchmarkFilterRun(b *testing.B) {
 	}
 }
 
+func BenchmarkFilterRunLargeList(b *testing.B) {
+	b.ReportAllocs()
+	names := make([]string, 500)
+	for i := range names {
+		names[i] = fmt.Sprintf("'tool%d.exe'", i)
+	}
+	f := New(`ps.name iin (`+strings.Join(names, ", ")+`) or ps.cmdline icontains (`+strings.Join(names, ", ")+`)`, cfg)
+	require.NoError(b, f.Compile())
+
+	pars := event.Params{
+		params.Cmdline:         {Name: params.Cmdline, Type: params.UnicodeString, Value: "C:\\Windows\\system32\\svchost.exe -k RPCSS"},
+		params.ProcessName:     {Name: params.ProcessName, Type: params.AnsiString, Value: "svchost.exe"},
+		params.ProcessID:       {Name: params.ProcessID, Type: params.Uint32, Value: uint32(1234)},
+		params.ProcessParentID: {Name: params.ProcessParentID, Type: params.Uint32, Value: uint32(345)},
+	}
+
+	evt := &event.Event{
+		Type:   event.CreateProcess,
+		Params: pars,
+		Name:   "CreateProcess",
+	}
+
+	for i := 0; i < b.N; i++ {
+		f.Eval(evt)
+	}
+}
+
 func getNtdllAddress(pid uint32) uintptr {
 	var moduleHandles [1024]windows.Handle
 	var cbNeeded uint32
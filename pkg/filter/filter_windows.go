@@ -29,7 +29,8 @@ import (
 )
 
 type opts struct {
-	psnap ps.Snapshotter
+	psnap    ps.Snapshotter
+	matchers *ql.MatcherCache
 }
 
 // Option defines the option supplied to the filter
@@ -42,6 +43,14 @@ func WithPSnapshotter(psnap ps.Snapshotter) Option {
 	}
 }
 
+// WithMatcherCache shares list matchers among filters compiled with the
+// same cache. If the cache is not given, each filter builds its own matchers.
+func WithMatcherCache(cache *ql.MatcherCache) Option {
+	return func(o *opts) {
+		o.matchers = cache
+	}
+}
+
 // New creates a new filter with the specified filter expression. The consumers must ensure
 // the expression is correctly parsed before executing the filter. This is achieved by calling the
 // `Compile` method after constructing the filter.
@@ -104,6 +113,7 @@ func New(expr string, config *config.Config, options ...Option) Filter {
 		stringFields:   make(map[fields.Field][]string),
 		boundFields:    make([]*ql.BoundFieldLiteral, 0),
 		seqBoundFields: make(map[int][]BoundField),
+		matchers:       opts.matchers,
 	}
 }
 
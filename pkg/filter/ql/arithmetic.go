@@ -0,0 +1,336 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ql
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// numberKind designates the type of the arithmetic operand.
+type numberKind uint8
+
+const (
+	// signed integers are all integers within the int64 range
+	signed numberKind = iota
+	// unsigned integers are integers exceeding the int64 range
+	unsigned
+	// decimal represents floating point numbers
+	decimal
+)
+
+// number is the operand of the arithmetic expression.
+type number struct {
+	kind numberKind
+	i    int64
+	u    uint64
+	f    float64
+}
+
+// toNumber converts the value to the arithmetic operand. Strings
+// holding decimal or hexadecimal integers, such as access masks,
+// are converted as well.
+func toNumber(v any) (number, bool) {
+	switch n := v.(type) {
+	case int:
+		return number{kind: signed, i: int64(n)}, true
+	case int8:
+		return number{kind: signed, i: int64(n)}, true
+	case int16:
+		return number{kind: signed, i: int64(n)}, true
+	case int32:
+		return number{kind: signed, i: int64(n)}, true
+	case int64:
+		return number{kind: signed, i: n}, true
+	case uint8:
+		return number{kind: signed, i: int64(n)}, true
+	case uint16:
+		return number{kind: signed, i: int64(n)}, true
+	case uint32:
+		return number{kind: signed, i: int64(n)}, true
+	case uint:
+		return fromUint(uint64(n)), true
+	case uint64:
+		return fromUint(n), true
+	case float32:
+		return number{kind: decimal, f: float64(n)}, true
+	case float64:
+		return number{kind: decimal, f: n}, true
+	case string:
+		return parseNumber(n)
+	case time.Time:
+		// timestamps are represented as nanoseconds
+		// elapsed since Unix epoch, so their difference
+		// can be compared against duration literals
+		if n.IsZero() {
+			return number{}, false
+		}
+		return number{kind: signed, i: n.UnixNano()}, true
+	}
+	return number{}, false
+}
+
+func fromUint(u uint64) number {
+	if u <= math.MaxInt64 {
+		return number{kind: signed, i: int64(u)}
+	}
+	return number{kind: unsigned, u: u}
+}
+
+func parseNumber(s string) (number, bool) {
+	if isHex(s) {
+		u, err := strconv.ParseUint(s[2:], 16, 64)
+		if err != nil {
+			return number{}, false
+		}
+		return fromUint(u), true
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return number{kind: signed, i: i}, true
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return fromUint(u), true
+	}
+	if strings.ContainsAny(s, ".eE") {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return number{kind: decimal, f: f}, true
+		}
+	}
+	return number{}, false
+}
+
+func (n number) float() float64 {
+	switch n.kind {
+	case unsigned:
+		return float64(n.u)
+	case decimal:
+		return n.f
+	default:
+		return float64(n.i)
+	}
+}
+
+func (n number) big() *big.Int {
+	if n.kind == unsigned {
+		return new(big.Int).SetUint64(n.u)
+	}
+	return big.NewInt(n.i)
+}
+
+// value returns the native representation of the number.
+func (n number) value() any {
+	switch n.kind {
+	case unsigned:
+		return n.u
+	case decimal:
+		return n.f
+	default:
+		return n.i
+	}
+}
+
+// evalArithmetic evaluates the arithmetic or bitwise operation. If either
+// of the operands is a decimal, the operation is carried out on floating
+// point numbers, and bitwise operations yield no value. Integer operations
+// are exact. The result is int64 if it fits into the signed range, or uint64
+// otherwise. Results that overflow both types, division by zero, and invalid
+// shift counts yield no value, which makes the enclosing comparison false.
+func evalArithmetic(op Token, lhs, rhs any, floatDivision bool) any {
+	l, ok := toNumber(lhs)
+	if !ok {
+		return nil
+	}
+	r, ok := toNumber(rhs)
+	if !ok {
+		return nil
+	}
+	if l.kind == decimal || r.kind == decimal || (op == Div && floatDivision) {
+		return evalDecimal(op, l.float(), r.float())
+	}
+	if l.kind == signed && r.kind == signed {
+		if v, ok := evalSigned(op, l.i, r.i); ok {
+			return v
+		}
+	}
+	// the operation overflows int64 or involves unsigned integers
+	return evalBig(op, l.big(), r.big())
+}
+
+// evalSigned evaluates the operation on signed integers. It returns false if
+// the operation overflows or can't be carried out within the int64 range.
+func evalSigned(op Token, a, b int64) (int64, bool) {
+	switch op {
+	case Add:
+		s := a + b
+		return s, (a^s)&(b^s) >= 0
+	case Sub:
+		s := a - b
+		return s, (a^b)&(a^s) >= 0
+	case Mul:
+		if a == 0 || b == 0 {
+			return 0, true
+		}
+		p := a * b
+		if (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) || p/b != a {
+			return 0, false
+		}
+		return p, true
+	case Div:
+		if b == 0 || (a == math.MinInt64 && b == -1) {
+			return 0, false
+		}
+		return a / b, true
+	case Mod:
+		if b == 0 {
+			return 0, false
+		}
+		if b == -1 {
+			return 0, true
+		}
+		return a % b, true
+	case BitAnd:
+		return a & b, true
+	case BitOr:
+		return a | b, true
+	case BitXor:
+		return a ^ b, true
+	case Shl:
+		if b < 0 || b >= 63 {
+			return 0, false
+		}
+		s := a << b
+		return s, s>>b == a
+	case Shr:
+		if b < 0 {
+			return 0, false
+		}
+		if b >= 63 {
+			if a < 0 {
+				return -1, true
+			}
+			return 0, true
+		}
+		return a >> b, true
+	}
+	return 0, false
+}
+
+// maxShift is the largest shift count accepted in integer shifts
+const maxShift = 128
+
+// evalBig evaluates the operation with arbitrary precision and
+// converts the result to int64 or uint64 if it fits either type.
+func evalBig(op Token, a, b *big.Int) any {
+	var z big.Int
+	switch op {
+	case Add:
+		z.Add(a, b)
+	case Sub:
+		z.Sub(a, b)
+	case Mul:
+		z.Mul(a, b)
+	case Div:
+		if b.Sign() == 0 {
+			return nil
+		}
+		z.Quo(a, b)
+	case Mod:
+		if b.Sign() == 0 {
+			return nil
+		}
+		z.Rem(a, b)
+	case BitAnd:
+		z.And(a, b)
+	case BitOr:
+		z.Or(a, b)
+	case BitXor:
+		z.Xor(a, b)
+	case Shl, Shr:
+		if b.Sign() < 0 || !b.IsInt64() {
+			return nil
+		}
+		n := uint(min(b.Int64(), maxShift))
+		if op == Shl {
+			z.Lsh(a, n)
+		} else {
+			z.Rsh(a, n)
+		}
+	default:
+		return nil
+	}
+	if z.IsInt64() {
+		return z.Int64()
+	}
+	if z.IsUint64() {
+		return z.Uint64()
+	}
+	return nil
+}
+
+// evalDecimal evaluates the operation on floating point numbers.
+func evalDecimal(op Token, a, b float64) any {
+	var v float64
+	switch op {
+	case Add:
+		v = a + b
+	case Sub:
+		v = a - b
+	case Mul:
+		v = a * b
+	case Div:
+		if b == 0 {
+			return nil
+		}
+		v = a / b
+	case Mod:
+		if b == 0 {
+			return nil
+		}
+		v = math.Mod(a, b)
+	default:
+		return nil
+	}
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return nil
+	}
+	return v
+}
+
+// isArithmeticExpr determines if the expression yields the result of an arithmetic operation.
+func isArithmeticExpr(expr Expr) bool {
+	switch e := expr.(type) {
+	case *BinaryExpr:
+		return e.Op.IsArithmetic()
+	case *ParenExpr:
+		return isArithmeticExpr(e.Expr)
+	}
+	return false
+}
+
+// normalizeNumber converts the value compared to the result
+// of the arithmetic operation to the native numeric type.
+func normalizeNumber(v any) any {
+	if n, ok := toNumber(v); ok {
+		return n.value()
+	}
+	return v
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ql
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArithmeticExpr(t *testing.T) {
+	var tests = []struct {
+		expr string
+		m    map[string]interface{}
+		want bool
+	}{
+		{"file.io.size * 8 > 1048576", map[string]interface{}{"file.io.size": uint32(262144)}, true},
+		{"file.io.size * 8 > 1048576", map[string]interface{}{"file.io.size": uint32(131072)}, false},
+		{"file.io.size % 4096 != 0", map[string]interface{}{"file.io.size": uint32(8192)}, false},
+		{"file.io.size % 4096 != 0", map[string]interface{}{"file.io.size": uint32(8193)}, true},
+		{"file.io.size / 2 = 3", map[string]interface{}{"file.io.size": uint32(7)}, true},
+		{"file.io.size - 10 = -3", map[string]interface{}{"file.io.size": uint32(7)}, true},
+		{"ps.pid + 2 * 3 = 10", map[string]interface{}{"ps.pid": uint32(4)}, true},
+		{"(ps.pid + 2) * 3 = 18", map[string]interface{}{"ps.pid": uint32(4)}, true},
+		{"-ps.pid = -4", map[string]interface{}{"ps.pid": uint32(4)}, true},
+		{"(thread.access.mask & 0x20) != 0", map[string]interface{}{"thread.access.mask": "0x1fffff"}, true},
+		{"(thread.access.mask & 0x20) != 0", map[string]interface{}{"thread.access.mask": "0x1400"}, false},
+		{"thread.access.mask & 0x1400 = 0x1400", map[string]interface{}{"thread.access.mask": "0x1fffff"}, true},
+		{"ps.pid | 1 = 5", map[string]interface{}{"ps.pid": uint32(4)}, true},
+		{"ps.pid ^ 0xff = 0xfb", map[string]interface{}{"ps.pid": uint32(4)}, true},
+		{"ps.pid << 2 = 16", map[string]interface{}{"ps.pid": uint32(4)}, true},
+		{"ps.pid >> 1 = 2", map[string]interface{}{"ps.pid": uint32(4)}, true},
+		{"ps.pid in (0x4, 0x8)", map[string]interface{}{"ps.pid": uint32(4)}, true},
+		{"mem.size * 1.5 > 6", map[string]interface{}{"mem.size": uint64(5)}, true},
+		{"mem.size / 2 = 2", map[string]interface{}{"mem.size": uint64(5)}, true},
+		// overflow promotes to unsigned integers
+		{"mem.size + 1 = 9223372036854775808", map[string]interface{}{"mem.size": uint64(math.MaxInt64)}, true},
+		// results exceeding the 64-bit range make the comparison false
+		{"mem.size * 2 > 0", map[string]interface{}{"mem.size": uint64(math.MaxUint64)}, false},
+		{"mem.size * 2 <= 0", map[string]interface{}{"mem.size": uint64(math.MaxUint64)}, false},
+		// division by zero makes the comparison false
+		{"file.io.size / 0 = 0", map[string]interface{}{"file.io.size": uint32(7)}, false},
+		{"file.io.size % 0 != 1", map[string]interface{}{"file.io.size": uint32(7)}, false},
+		// bitwise operations on decimals are undefined
+		{"mem.size * 1.5 & 1 = 1", map[string]interface{}{"mem.size": uint64(5)}, false},
+		// non-numeric operands
+		{"ps.name + 1 = 1", map[string]interface{}{"ps.name": "svchost.exe"}, false},
+		{"file.io.size * 8 > 1048576", map[string]interface{}{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			p := NewParser(tt.expr)
+			expr, err := p.ParseExpr()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, Eval(expr, tt.m, false))
+		})
+	}
+}
+
+func TestEvalArithmetic(t *testing.T) {
+	var tests = []struct {
+		op   Token
+		lhs  any
+		rhs  any
+		want any
+	}{
+		{Add, int64(3), int64(5), int64(8)},
+		{Sub, uint32(3), int64(5), int64(-2)},
+		{Add, int64(math.MaxInt64), int64(1), uint64(math.MaxInt64) + 1},
+		{Sub, int64(math.MinInt64), int64(1), nil},
+		{Mul, uint64(math.MaxUint64), int64(-1), nil},
+		{Div, int64(7), int64(2), int64(3)},
+		{Div, int64(7), int64(0), nil},
+		{Div, int64(math.MinInt64), int64(-1), uint64(math.MaxInt64) + 1},
+		{Mod, int64(-7), int64(3), int64(-1)},
+		{Div, float64(7), int64(2), float64(3.5)},
+		{Div, float64(7), float64(0), nil},
+		{BitAnd, "0x1fffff", int64(32), int64(32)},
+		{BitOr, uint8(1), uint16(2), int64(3)},
+		{BitXor, float64(1), int64(1), nil},
+		{Shl, int64(1), int64(63), uint64(1) << 63},
+		{Shl, int64(1), int64(64), nil},
+		{Shl, int64(1), int64(-1), nil},
+		{Shr, uint64(math.MaxUint64), int64(60), int64(15)},
+		{Add, "1.5", int64(1), float64(2.5)},
+		{Add, "svchost.exe", int64(1), nil},
+		{Add, nil, int64(1), nil},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, evalArithmetic(tt.op, tt.lhs, tt.rhs, false), "%v %s %v", tt.lhs, tt.op, tt.rhs)
+	}
+	assert.Equal(t, float64(3.5), evalArithmetic(Div, int64(7), int64(2), true))
+}
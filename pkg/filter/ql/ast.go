@@ -190,6 +190,10 @@ func (v *ValuerEval) Eval(expr Expr) interface{} {
 		return expr.Value
 	case *DecimalLiteral:
 		return expr.Value
+	case *DurationLiteral:
+		// durations are compared against
+		// time differences in nanoseconds
+		return int64(expr.Value)
 	case *ParenExpr:
 		return v.Eval(expr.Expr)
 	case *StringLiteral:
@@ -280,7 +284,25 @@ func (v *ValuerEval) evalBinaryExpr(expr *BinaryExpr) interface{} {
 			return true
 		}
 	}
+	// large lists are evaluated by the accelerated matcher
+	if list, ok := expr.RHS.(*ListLiteral); ok && list.matcher != nil && list.matcher.op == expr.Op {
+		if matched, ok := list.matcher.match(lhs); ok {
+			return matched
+		}
+	}
 	rhs := v.Eval(expr.RHS)
+	if expr.Op.IsArithmetic() {
+		return evalArithmetic(expr.Op, lhs, rhs, v.IntegerFloatDivision)
+	}
+	// the result of the arithmetic operation is either int64, uint64 or
+	// float64, so the other operand is converted to one of those types
+	if isArithmeticExpr(expr.LHS) {
+		rhs = normalizeNumber(rhs)
+	}
+	if isArithmeticExpr(expr.RHS) {
+		lhs = normalizeNumber(lhs)
+	}
+	lhs, rhs = normalizeTimes(expr.Op, lhs, rhs)
 	if lhs == nil && rhs != nil {
 		// when the LHS is nil and the RHS is a boolean, implicitly cast the
 		// nil to false.
@@ -1152,6 +1174,7 @@ func (v *ValuerEval) evalBinaryExpr(expr *BinaryExpr) interface{} {
 						}
 					}
 				}
+				return false
 			}
 			for _, val := range lhs {
 				if strings.EqualFold(val, s) {
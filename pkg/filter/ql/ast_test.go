@@ -0,0 +1,49 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSliceInExpr(t *testing.T) {
+	var tests = []struct {
+		expr string
+		m    map[string]interface{}
+		want bool
+	}{
+		{`ps.modules in ('kernel32.dll', 'ntdll.dll')`, map[string]interface{}{"ps.modules": []string{"user32.dll", "ntdll.dll"}}, true},
+		{`ps.modules in ('kernel32.dll', 'ntdll.dll')`, map[string]interface{}{"ps.modules": []string{"user32.dll", ""}}, false},
+		{`ps.modules iin ('kernel32.dll', 'ntdll.dll')`, map[string]interface{}{"ps.modules": []string{"user32.dll", "NTDLL.DLL"}}, true},
+		// empty slice elements must not match when no list element matches
+		{`ps.modules iin ('kernel32.dll', 'ntdll.dll')`, map[string]interface{}{"ps.modules": []string{"user32.dll", ""}}, false},
+		{`ps.modules iin ('kernel32.dll', 'ntdll.dll')`, map[string]interface{}{"ps.modules": []string{""}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := NewParser(tt.expr).ParseExpr()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, Eval(expr, tt.m, false))
+		})
+	}
+}
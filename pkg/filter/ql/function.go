@@ -56,32 +56,58 @@ var (
 )
 
 var funcs = map[string]FunctionDef{
-	functions.CIDRContainsFn.String(): &functions.CIDRContains{},
-	functions.MD5Fn.String():          &functions.MD5{},
-	functions.ConcatFn.String():       &functions.Concat{},
-	functions.LtrimFn.String():        &functions.Ltrim{},
-	functions.RtrimFn.String():        &functions.Rtrim{},
-	functions.LowerFn.String():        &functions.Lower{},
-	functions.UpperFn.String():        &functions.Upper{},
-	functions.ReplaceFn.String():      &functions.Replace{},
-	functions.SplitFn.String():        &functions.Split{},
-	functions.LengthFn.String():       &functions.Length{},
-	functions.IndexOfFn.String():      &functions.IndexOf{},
-	functions.SubstrFn.String():       &functions.Substr{},
-	functions.EntropyFn.String():      &functions.Entropy{},
-	functions.RegexFn.String():        functions.NewRegex(),
-	functions.IsMinidumpFn.String():   &functions.IsMinidump{},
-	functions.BaseFn.String():         &functions.Base{},
-	functions.DirFn.String():          &functions.Dir{},
-	functions.SymlinkFn.String():      &functions.Symlink{},
-	functions.ExtFn.String():          &functions.Ext{},
-	functions.GlobFn.String():         &functions.Glob{},
-	functions.IsAbsFn.String():        &functions.IsAbs{},
-	functions.VolumeFn.String():       &functions.Volume{},
-	functions.GetRegValueFn.String():  &functions.GetRegValue{},
-	functions.YaraFn.String():         &functions.Yara{},
-	functions.ForeachFn.String():      &Foreach{},
-	functions.CountFn.String():        &functions.Count{},
+	functions.CIDRContainsFn.String():      &functions.CIDRContains{},
+	functions.MD5Fn.String():               &functions.MD5{},
+	functions.ConcatFn.String():            &functions.Concat{},
+	functions.LtrimFn.String():             &functions.Ltrim{},
+	functions.RtrimFn.String():             &functions.Rtrim{},
+	functions.LowerFn.String():             &functions.Lower{},
+	functions.UpperFn.String():             &functions.Upper{},
+	functions.ReplaceFn.String():           &functions.Replace{},
+	functions.SplitFn.String():             &functions.Split{},
+	functions.LengthFn.String():            &functions.Length{},
+	functions.IndexOfFn.String():           &functions.IndexOf{},
+	functions.SubstrFn.String():            &functions.Substr{},
+	functions.EntropyFn.String():           &functions.Entropy{},
+	functions.RegexFn.String():             functions.NewRegex(),
+	functions.IsMinidumpFn.String():        &functions.IsMinidump{},
+	functions.BaseFn.String():              &functions.Base{},
+	functions.DirFn.String():               &functions.Dir{},
+	functions.SymlinkFn.String():           &functions.Symlink{},
+	functions.ExtFn.String():               &functions.Ext{},
+	functions.GlobFn.String():              &functions.Glob{},
+	functions.IsAbsFn.String():             &functions.IsAbs{},
+	functions.VolumeFn.String():            &functions.Volume{},
+	functions.GetRegValueFn.String():       &functions.GetRegValue{},
+	functions.YaraFn.String():              &functions.Yara{},
+	functions.ForeachFn.String():           &Foreach{},
+	functions.CountFn.String():             &functions.Count{},
+	functions.SHA1Fn.String():              &functions.CryptoHash{Fn: functions.SHA1Fn},
+	functions.SHA256Fn.String():            &functions.CryptoHash{Fn: functions.SHA256Fn},
+	functions.SsdeepFn.String():            &functions.Ssdeep{},
+	functions.SsdeepCompareFn.String():     &functions.SsdeepCompare{},
+	functions.TLSHFn.String():              &functions.TLSH{},
+	functions.TLSHCompareFn.String():       &functions.TLSHCompare{},
+	functions.B64DecodeFn.String():         &functions.B64Decode{},
+	functions.HexDecodeFn.String():         &functions.HexDecode{},
+	functions.URLDecodeFn.String():         &functions.URLDecode{},
+	functions.UTF16LEDecodeFn.String():     &functions.UTF16LEDecode{},
+	functions.DeflateDecompressFn.String(): &functions.DeflateDecompress{},
+	functions.CmdlineArgFn.String():        &functions.CmdlineArg{},
+	functions.NowFn.String():               &functions.Now{},
+	functions.HourFn.String():              &functions.Hour{},
+	functions.WeekdayFn.String():           &functions.Weekday{},
+	functions.DateDiffFn.String():          &functions.DateDiff{},
+	functions.LevenshteinFn.String():       &functions.Levenshtein{},
+	functions.JaroWinklerFn.String():       &functions.JaroWinkler{},
+	functions.ConfusableFn.String():        &functions.Confusable{},
+	functions.HasBidiOverrideFn.String():   &functions.HasBidiOverride{},
+	functions.RegisteredDomainFn.String():  &functions.RegisteredDomain{},
+	functions.TLDFn.String():               &functions.TLD{},
+	functions.SubdomainDepthFn.String():    &functions.SubdomainDepth{},
+	functions.LabelEntropyFn.String():      &functions.LabelEntropy{},
+	functions.DGAScoreFn.String():          &functions.DGAScore{},
+	functions.URLParseFn.String():          &functions.URLParse{},
 }
 
 // FunctionDef is the interface that all function definitions have to satisfy.
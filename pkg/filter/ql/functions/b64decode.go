@@ -0,0 +1,69 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"encoding/base64"
+	"strings"
+	"unicode"
+)
+
+// b64Encodings contains the base64 encodings tried in order when decoding.
+var b64Encodings = []*base64.Encoding{
+	base64.StdEncoding,
+	base64.RawStdEncoding,
+	base64.URLEncoding,
+	base64.RawURLEncoding,
+}
+
+// B64Decode decodes the base64 encoded string. Both the standard
+// and URL-safe alphabets are accepted, with or without padding.
+// White spaces in the encoded string are ignored.
+type B64Decode struct{}
+
+func (f B64Decode) Call(args []interface{}) (interface{}, bool) {
+	if len(args) < 1 {
+		return nil, false
+	}
+	s := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, string(parseBytes(0, args)))
+
+	for _, enc := range b64Encodings {
+		b, err := enc.DecodeString(s)
+		if err == nil {
+			return truncateDecoded(b), true
+		}
+	}
+	return nil, false
+}
+
+func (f B64Decode) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: B64DecodeFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "data", Types: []ArgType{Field, String, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+		},
+	}
+}
+
+func (f B64Decode) Name() Fn { return B64DecodeFn }
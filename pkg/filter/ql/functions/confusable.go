@@ -0,0 +1,55 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import "github.com/rabbitstack/fibratus/pkg/util/confusable"
+
+// Confusable determines if the string is visually confusable with,
+// but not equal to, the given string or any of the strings in the list.
+// For example, svchоst.exe spelled with the Cyrillic о character is
+// confusable with svchost.exe. The comparison is case-insensitive.
+type Confusable struct{}
+
+func (f Confusable) Call(args []interface{}) (interface{}, bool) {
+	if len(args) < 2 {
+		return false, false
+	}
+	s, ok := args[0].(string)
+	if !ok {
+		return false, false
+	}
+	for _, c := range parseStrings(1, args) {
+		if confusable.IsConfusable(s, c) {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+func (f Confusable) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: ConfusableFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "string", Types: []ArgType{Field, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+			{Keyword: "candidates", Types: []ArgType{String, Slice, Field, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+		},
+	}
+}
+
+func (f Confusable) Name() Fn { return ConfusableFn }
@@ -0,0 +1,60 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"hash"
+)
+
+// cryptoHashes maps the hash function to the constructor of the
+// cryptographic hash algorithm.
+var cryptoHashes = map[Fn]func() hash.Hash{
+	SHA1Fn:   sha1.New,
+	SHA256Fn: sha256.New,
+}
+
+// CryptoHash computes the hash of the given value with the cryptographic
+// hash algorithm designated by the function. If the optional file argument
+// is true, the value is treated as the file path, and the hash of the file
+// content is computed.
+type CryptoHash struct {
+	Fn Fn
+}
+
+func (f CryptoHash) Call(args []interface{}) (interface{}, bool) {
+	if _, ok := cryptoHashes[f.Fn]; !ok {
+		return nil, false
+	}
+	newDigester, _ := newDigester(f.Fn)
+	return hashCall(f.Fn, args, newDigester)
+}
+
+func (f CryptoHash) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: f.Fn,
+		Args: []FunctionArgDesc{
+			{Keyword: "data", Types: []ArgType{Field, String, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+			{Keyword: "file", Types: []ArgType{Bool}},
+		},
+	}
+}
+
+func (f CryptoHash) Name() Fn { return f.Fn }
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCryptoHashCall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte("abc"), 0644))
+
+	var tests = []struct {
+		fn     Fn
+		digest string
+	}{
+		{SHA1Fn, "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{SHA256Fn, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fn.String(), func(t *testing.T) {
+			call := CryptoHash{Fn: tt.fn}
+
+			for _, arg := range []interface{}{"abc", []byte("abc")} {
+				res, ok := call.Call([]interface{}{arg})
+				require.True(t, ok)
+				assert.Equal(t, tt.digest, res)
+			}
+
+			res, ok := call.Call([]interface{}{path, true})
+			require.True(t, ok)
+			assert.Equal(t, tt.digest, res)
+
+			_, ok = call.Call([]interface{}{filepath.Join(t.TempDir(), "nonexistent.txt"), true})
+			require.False(t, ok)
+
+			desc := call.Desc()
+			assert.Equal(t, tt.fn, desc.Name)
+			assert.Equal(t, desc.RequiredArgs(), 1)
+			assert.Len(t, desc.Args, 2)
+		})
+	}
+
+	_, ok := CryptoHash{Fn: MD5Fn}.Call([]interface{}{"abc"})
+	require.False(t, ok)
+}
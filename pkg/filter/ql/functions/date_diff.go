@@ -0,0 +1,79 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"strings"
+	"time"
+)
+
+// dateDiffUnits maps the unit names to durations.
+var dateDiffUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  time.Hour * 24,
+	"w":  time.Hour * 24 * 7,
+}
+
+// DateDiff computes the difference between two timestamps. By
+// default, the difference is expressed in nanoseconds, so it
+// can be compared against duration literals. If the unit argument
+// is given, the function returns the number of whole units, for
+// example, date_diff(evt.time.ns, ps.pe.link_time, 'd') > 30.
+type DateDiff struct{}
+
+func (f DateDiff) Call(args []interface{}) (interface{}, bool) {
+	if len(args) < 2 {
+		return nil, false
+	}
+	t1, ok := toTime(args[0])
+	if !ok {
+		return nil, false
+	}
+	t2, ok := toTime(args[1])
+	if !ok {
+		return nil, false
+	}
+	diff := t1.Sub(t2)
+	if len(args) == 2 {
+		return int64(diff), true
+	}
+	unit, ok := dateDiffUnits[strings.ToLower(parseString(2, args))]
+	if !ok {
+		return nil, false
+	}
+	return int64(diff / unit), true
+}
+
+func (f DateDiff) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: DateDiffFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "timestamp1", Types: timeArgTypes, Required: true},
+			{Keyword: "timestamp2", Types: timeArgTypes, Required: true},
+			{Keyword: "unit", Types: []ArgType{String}},
+		},
+	}
+}
+
+func (f DateDiff) Name() Fn { return DateDiffFn }
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDateDiff(t *testing.T) {
+	ts := time.Date(2011, 5, 3, 15, 4, 5, 0, time.UTC)
+	linkTime := ts.Add(-time.Hour * 50)
+
+	var tests = []struct {
+		args     []interface{}
+		expected interface{}
+	}{
+		{
+			[]interface{}{ts, linkTime},
+			int64(time.Hour * 50),
+		},
+		{
+			[]interface{}{ts, linkTime, "d"},
+			int64(2),
+		},
+		{
+			[]interface{}{linkTime, ts, "H"},
+			int64(-50),
+		},
+		{
+			[]interface{}{ts.UnixNano(), "2011-05-03T15:03:05Z", "s"},
+			int64(60),
+		},
+		{
+			[]interface{}{ts, linkTime, "fortnight"},
+			nil,
+		},
+		{
+			[]interface{}{ts, time.Time{}},
+			nil,
+		},
+		{
+			[]interface{}{ts},
+			nil,
+		},
+	}
+
+	for i, tt := range tests {
+		f := DateDiff{}
+		res, _ := f.Call(tt.args)
+		assert.Equal(t, tt.expected, res, fmt.Sprintf("%d. result mismatch: exp=%v got=%v", i, tt.expected, res))
+	}
+}
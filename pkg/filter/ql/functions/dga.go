@@ -0,0 +1,73 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import "github.com/rabbitstack/fibratus/pkg/util/domain"
+
+// LabelEntropy returns the highest Shannon entropy, in bits per
+// character, of the domain labels preceding the public suffix.
+type LabelEntropy struct{}
+
+func (f LabelEntropy) Call(args []interface{}) (interface{}, bool) {
+	if len(args) < 1 {
+		return nil, false
+	}
+	s, ok := args[0].(string)
+	if !ok {
+		return nil, false
+	}
+	return domain.LabelEntropy(s), true
+}
+
+func (f LabelEntropy) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: LabelEntropyFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "domain", Types: domainArgTypes, Required: true},
+		},
+	}
+}
+
+func (f LabelEntropy) Name() Fn { return LabelEntropyFn }
+
+// DGAScore rates, in the range from 0 to 1, how likely the registered
+// domain was produced by the domain generation algorithm.
+type DGAScore struct{}
+
+func (f DGAScore) Call(args []interface{}) (interface{}, bool) {
+	if len(args) < 1 {
+		return nil, false
+	}
+	s, ok := args[0].(string)
+	if !ok {
+		return nil, false
+	}
+	return domain.DGAScore(s), true
+}
+
+func (f DGAScore) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: DGAScoreFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "domain", Types: domainArgTypes, Required: true},
+		},
+	}
+}
+
+func (f DGAScore) Name() Fn { return DGAScoreFn }
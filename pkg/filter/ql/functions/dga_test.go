@@ -0,0 +1,45 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelEntropy(t *testing.T) {
+	res, ok := LabelEntropy{}.Call([]interface{}{"a8f3k2l9q0z1x7w4.t.example.com"})
+	require.True(t, ok)
+	assert.InDelta(t, 4.0, res, 0.001)
+
+	_, ok = LabelEntropy{}.Call([]interface{}{uint8(1)})
+	require.False(t, ok)
+}
+
+func TestDGAScore(t *testing.T) {
+	res, ok := DGAScore{}.Call([]interface{}{"kq3v9z7xj2.net"})
+	require.True(t, ok)
+	assert.Greater(t, res, 0.6)
+
+	res, ok = DGAScore{}.Call([]interface{}{"www.microsoft.com"})
+	require.True(t, ok)
+	assert.Less(t, res, 0.45)
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import "github.com/rabbitstack/fibratus/pkg/util/domain"
+
+// domainArgTypes are the argument types accepted by the domain functions.
+var domainArgTypes = []ArgType{Field, String, BoundField, BoundSegment, BareBoundVariable, Func}
+
+// RegisteredDomain returns the registered domain of the domain name, which
+// is the public suffix plus one label, e.g. bbc.co.uk for www.bbc.co.uk.
+type RegisteredDomain struct{}
+
+func (f RegisteredDomain) Call(args []interface{}) (interface{}, bool) {
+	if len(args) < 1 {
+		return nil, false
+	}
+	s, ok := args[0].(string)
+	if !ok {
+		return nil, false
+	}
+	return domain.Registered(s), true
+}
+
+func (f RegisteredDomain) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: RegisteredDomainFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "domain", Types: domainArgTypes, Required: true},
+		},
+	}
+}
+
+func (f RegisteredDomain) Name() Fn { return RegisteredDomainFn }
+
+// TLD returns the effective top-level domain of the domain
+// name as dictated by the public suffix list, e.g. co.uk for
+// www.bbc.co.uk.
+type TLD struct{}
+
+func (f TLD) Call(args []interface{}) (interface{}, bool) {
+	if len(args) < 1 {
+		return nil, false
+	}
+	s, ok := args[0].(string)
+	if !ok {
+		return nil, false
+	}
+	return domain.PublicSuffix(s), true
+}
+
+func (f TLD) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: TLDFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "domain", Types: domainArgTypes, Required: true},
+		},
+	}
+}
+
+func (f TLD) Name() Fn { return TLDFn }
+
+// SubdomainDepth returns the number of labels preceding the
+// registered domain, e.g. 2 for a.b.example.com.
+type SubdomainDepth struct{}
+
+func (f SubdomainDepth) Call(args []interface{}) (interface{}, bool) {
+	if len(args) < 1 {
+		return nil, false
+	}
+	s, ok := args[0].(string)
+	if !ok {
+		return nil, false
+	}
+	return domain.SubdomainDepth(s), true
+}
+
+func (f SubdomainDepth) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: SubdomainDepthFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "domain", Types: domainArgTypes, Required: true},
+		},
+	}
+}
+
+func (f SubdomainDepth) Name() Fn { return SubdomainDepthFn }
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import "github.com/rabbitstack/fibratus/pkg/util/confusable"
+
+// HasBidiOverride determines if the string contains the bidirectional
+// text control characters, such as the right-to-left override, that
+// are used to disguise the real file extension.
+type HasBidiOverride struct{}
+
+func (f HasBidiOverride) Call(args []interface{}) (interface{}, bool) {
+	if len(args) < 1 {
+		return false, false
+	}
+	s, ok := args[0].(string)
+	if !ok {
+		return false, false
+	}
+	return confusable.HasBidiControl(s), true
+}
+
+func (f HasBidiOverride) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: HasBidiOverrideFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "string", Types: []ArgType{Field, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+		},
+	}
+}
+
+func (f HasBidiOverride) Name() Fn { return HasBidiOverrideFn }
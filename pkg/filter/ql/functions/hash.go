@@ -0,0 +1,171 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/rabbitstack/fibratus/pkg/util/hashers"
+)
+
+// MaxHashFileSize designates the maximum size of the file in bytes
+// that is hashed by the hashing functions. Larger files are skipped.
+// Files are hashed synchronously during rule evaluation, so the limit
+// keeps hashing large files from stalling the event processing.
+var MaxHashFileSize int64 = 8 * 1024 * 1024
+
+var errHashFileTooLarge = errors.New("file is too large to be hashed")
+
+// digester is the hash function that renders the digest as a string.
+type digester interface {
+	io.Writer
+	Digest() (string, error)
+}
+
+// hexDigester renders the digest of the cryptographic hash function in hex.
+type hexDigester struct {
+	hash.Hash
+}
+
+func (d hexDigester) Digest() (string, error) { return hex.EncodeToString(d.Sum(nil)), nil }
+
+// ssdeepDigester adapts the ssdeep hash to the digester interface.
+type ssdeepDigester struct {
+	*hashers.Ssdeep
+}
+
+func (d ssdeepDigester) Digest() (string, error) { return d.Ssdeep.Digest(), nil }
+
+// newDigester returns the constructor of the digester for the given hash function.
+func newDigester(fn Fn) (func() digester, bool) {
+	switch fn {
+	case SsdeepFn:
+		return func() digester { return ssdeepDigester{hashers.NewSsdeep()} }, true
+	case TLSHFn:
+		return func() digester { return hashers.NewTLSH() }, true
+	}
+	newHash, ok := cryptoHashes[fn]
+	if !ok {
+		return nil, false
+	}
+	return func() digester { return hexDigester{newHash()} }, true
+}
+
+// HashFile computes the digest of the file content with the given
+// hash function. Digests are cached until the file is modified.
+func HashFile(fn Fn, path string) (string, error) {
+	newDigester, ok := newDigester(fn)
+	if !ok {
+		return "", fmt.Errorf("%s is not a hash function", fn)
+	}
+	return hashFile(fn, path, newDigester)
+}
+
+// fileHashKey identifies the cached file digest. The file size and the
+// modification time are part of the key, so the digest is recomputed
+// when the file is modified.
+type fileHashKey struct {
+	fn    Fn
+	path  string
+	size  int64
+	mtime int64
+}
+
+// fileHashes is the bounded cache of file digests.
+var fileHashes = expirable.NewLRU[fileHashKey, string](2048, nil, time.Hour)
+
+// hashCall computes the digest of the first argument. If the optional file
+// argument is true, the first argument is interpreted as the path of
+// the file whose content is hashed.
+func hashCall(fn Fn, args []interface{}, newDigester func() digester) (interface{}, bool) {
+	if len(args) < 1 {
+		return nil, false
+	}
+
+	var data []byte
+	switch v := args[0].(type) {
+	case []byte:
+		data = v
+	case string:
+		if len(args) > 1 && args[1] == true {
+			digest, err := hashFile(fn, v, newDigester)
+			if err != nil {
+				return nil, false
+			}
+			return digest, true
+		}
+		data = []byte(v)
+	default:
+		return nil, false
+	}
+
+	d := newDigester()
+	_, _ = d.Write(data)
+	digest, err := d.Digest()
+	if err != nil {
+		return nil, false
+	}
+	return digest, true
+}
+
+// hashFile computes the digest of the file content. The digest is
+// served from the cache if the file hasn't changed since last hashed.
+func hashFile(fn Fn, path string, newDigester func() digester) (string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !fi.Mode().IsRegular() {
+		return "", &os.PathError{Op: "hash", Path: path, Err: errors.New("not a regular file")}
+	}
+	if fi.Size() > MaxHashFileSize {
+		return "", errHashFileTooLarge
+	}
+
+	key := fileHashKey{fn: fn, path: strings.ToLower(path), size: fi.Size(), mtime: fi.ModTime().UnixNano()}
+	if digest, ok := fileHashes.Get(key); ok {
+		return digest, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	d := newDigester()
+	if _, err := io.Copy(d, io.LimitReader(f, MaxHashFileSize)); err != nil {
+		return "", err
+	}
+	digest, err := d.Digest()
+	if err != nil {
+		return "", err
+	}
+	fileHashes.Add(key, digest)
+
+	return digest, nil
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte("abc"), 0644))
+
+	newDigester := func() digester { return hexDigester{sha256.New()} }
+
+	digest, err := hashFile(SHA256Fn, path, newDigester)
+	require.NoError(t, err)
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", digest)
+
+	key := fileHashKey{fn: SHA256Fn, path: strings.ToLower(path)}
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	key.size, key.mtime = fi.Size(), fi.ModTime().UnixNano()
+	assert.True(t, fileHashes.Contains(key))
+
+	// modifying the file invalidates the cached digest
+	require.NoError(t, os.WriteFile(path, []byte("abcd"), 0644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+	digest, err = hashFile(SHA256Fn, path, newDigester)
+	require.NoError(t, err)
+	assert.Equal(t, "88d4266fd4e6338d13b845fcf289579d209c897823b9217da3e161936f031589", digest)
+
+	_, err = hashFile(SHA256Fn, filepath.Dir(path), newDigester)
+	require.Error(t, err)
+
+	digest, err = HashFile(SHA1Fn, path)
+	require.NoError(t, err)
+	assert.Equal(t, "81fe8bfe87576c3ecb22426f8e57847382917acf", digest)
+	_, err = HashFile(LowerFn, path)
+	require.Error(t, err)
+
+	maxSize := MaxHashFileSize
+	MaxHashFileSize = 2
+	defer func() { MaxHashFileSize = maxSize }()
+	_, err = hashFile(SHA1Fn, path, newDigester)
+	require.ErrorIs(t, err, errHashFileTooLarge)
+}
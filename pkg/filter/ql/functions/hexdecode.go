@@ -0,0 +1,54 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// HexDecode decodes the hex encoded string. The optional 0x prefix
+// and white spaces between hex digits are ignored.
+type HexDecode struct{}
+
+func (f HexDecode) Call(args []interface{}) (interface{}, bool) {
+	if len(args) < 1 {
+		return nil, false
+	}
+	s := strings.Join(strings.Fields(string(parseBytes(0, args))), "")
+	if len(s) > 1 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		s = s[2:]
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, false
+	}
+	return truncateDecoded(b), true
+}
+
+func (f HexDecode) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: HexDecodeFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "data", Types: []ArgType{Field, String, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+		},
+	}
+}
+
+func (f HexDecode) Name() Fn { return HexDecodeFn }
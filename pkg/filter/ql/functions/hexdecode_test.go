@@ -0,0 +1,46 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHexDecode(t *testing.T) {
+	var tests = []struct {
+		args     []interface{}
+		expected interface{}
+		ok       bool
+	}{
+		{[]interface{}{"68656c6c6f"}, "hello", true},
+		{[]interface{}{"0x68656C6C6F"}, "hello", true},
+		{[]interface{}{"68 65 6c 6c 6f"}, "hello", true},
+		{[]interface{}{"4d5a9"}, nil, false},
+		{[]interface{}{"zz"}, nil, false},
+	}
+
+	for i, tt := range tests {
+		f := HexDecode{}
+		res, ok := f.Call(tt.args)
+		assert.Equal(t, tt.ok, ok, i)
+		assert.Equal(t, tt.expected, res, i)
+	}
+}
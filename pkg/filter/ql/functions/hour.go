@@ -0,0 +1,48 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import "time"
+
+// Hour returns the hour within the day, in the range [0, 23],
+// of the given timestamp. The hour is evaluated in the configured
+// time zone. Without arguments, the current hour is returned.
+type Hour struct{}
+
+func (f Hour) Call(args []interface{}) (interface{}, bool) {
+	if len(args) == 0 {
+		return InLocation(time.Now()).Hour(), true
+	}
+	t, ok := toTime(args[0])
+	if !ok {
+		return nil, false
+	}
+	return InLocation(t).Hour(), true
+}
+
+func (f Hour) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: HourFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "timestamp", Types: timeArgTypes},
+		},
+	}
+}
+
+func (f Hour) Name() Fn { return HourFn }
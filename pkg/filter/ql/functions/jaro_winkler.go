@@ -0,0 +1,54 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import "github.com/rabbitstack/fibratus/pkg/util/strsim"
+
+// JaroWinkler computes the Jaro-Winkler similarity between two strings.
+// The similarity ranges from 0 to 1, where 1 designates identical strings.
+// If the second argument is a list, the function returns the highest
+// similarity to any of the strings in the list.
+type JaroWinkler struct{}
+
+func (f JaroWinkler) Call(args []interface{}) (interface{}, bool) {
+	if len(args) < 2 {
+		return nil, false
+	}
+	s, ok := args[0].(string)
+	if !ok {
+		return nil, false
+	}
+	candidates := parseStrings(1, args)
+	if len(candidates) == 0 {
+		return nil, false
+	}
+	return strsim.MaxJaroWinkler(s, candidates), true
+}
+
+func (f JaroWinkler) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: JaroWinklerFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "string", Types: []ArgType{Field, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+			{Keyword: "candidates", Types: []ArgType{String, Slice, Field, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+		},
+	}
+}
+
+func (f JaroWinkler) Name() Fn { return JaroWinklerFn }
@@ -0,0 +1,45 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJaroWinkler(t *testing.T) {
+	f := JaroWinkler{}
+
+	res, ok := f.Call([]interface{}{"svchost.exe", "svchost.exe"})
+	require.True(t, ok)
+	assert.Equal(t, float64(1), res)
+
+	res, ok = f.Call([]interface{}{"svhcost.exe", []string{"lsass.exe", "svchost.exe"}})
+	require.True(t, ok)
+	assert.Greater(t, res, 0.9)
+
+	res, ok = f.Call([]interface{}{"notepad.exe", "lsass.exe"})
+	require.True(t, ok)
+	assert.Less(t, res, 0.7)
+
+	_, ok = f.Call([]interface{}{"notepad.exe"})
+	require.False(t, ok)
+}
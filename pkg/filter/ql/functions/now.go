@@ -0,0 +1,37 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import "time"
+
+// Now returns the current timestamp as the number of
+// nanoseconds elapsed since Unix epoch. This makes it
+// possible to subtract timestamps and compare the result
+// against duration literals, e.g. now() - evt.time.ns > 5m.
+type Now struct{}
+
+func (f Now) Call(args []interface{}) (interface{}, bool) {
+	return time.Now().UnixNano(), true
+}
+
+func (f Now) Desc() FunctionDesc {
+	return FunctionDesc{Name: NowFn}
+}
+
+func (f Now) Name() Fn { return NowFn }
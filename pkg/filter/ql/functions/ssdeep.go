@@ -0,0 +1,70 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import "github.com/rabbitstack/fibratus/pkg/util/hashers"
+
+// Ssdeep computes the ssdeep fuzzy hash of the given value. If
+// the optional file argument is true, the value is treated as
+// the file path, and the fuzzy hash of the file content is computed.
+type Ssdeep struct{}
+
+func (f Ssdeep) Call(args []interface{}) (interface{}, bool) {
+	newDigester, _ := newDigester(SsdeepFn)
+	return hashCall(SsdeepFn, args, newDigester)
+}
+
+func (f Ssdeep) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: SsdeepFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "data", Types: []ArgType{Field, String, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+			{Keyword: "file", Types: []ArgType{Bool}},
+		},
+	}
+}
+
+func (f Ssdeep) Name() Fn { return SsdeepFn }
+
+// SsdeepCompare compares two ssdeep fuzzy hashes and returns
+// the similarity score in the range from 0 to 100.
+type SsdeepCompare struct{}
+
+func (f SsdeepCompare) Call(args []interface{}) (interface{}, bool) {
+	if len(args) < 2 {
+		return nil, false
+	}
+	score, err := hashers.SsdeepCompare(parseString(0, args), parseString(1, args))
+	if err != nil {
+		return nil, false
+	}
+	return score, true
+}
+
+func (f SsdeepCompare) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: SsdeepCompareFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "hash1", Types: []ArgType{Field, String, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+			{Keyword: "hash2", Types: []ArgType{Field, String, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+		},
+	}
+}
+
+func (f SsdeepCompare) Name() Fn { return SsdeepCompareFn }
@@ -0,0 +1,63 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSsdeepCall(t *testing.T) {
+	call := Ssdeep{}
+
+	data := bytes.Repeat([]byte("The quick brown fox jumps over the lazy dog. "), 200)
+	res, ok := call.Call([]interface{}{string(data)})
+	require.True(t, ok)
+	assert.Regexp(t, `^\d+:[A-Za-z0-9+/]*:[A-Za-z0-9+/]*$`, res)
+
+	path := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+
+	digest, ok := call.Call([]interface{}{path, true})
+	require.True(t, ok)
+	assert.Equal(t, res, digest)
+}
+
+func TestSsdeepCompareCall(t *testing.T) {
+	call := SsdeepCompare{}
+
+	digest, _ := Ssdeep{}.Call([]interface{}{string(bytes.Repeat([]byte("The quick brown fox jumps over the lazy dog. "), 200))})
+
+	res, ok := call.Call([]interface{}{digest, digest})
+	require.True(t, ok)
+	assert.Equal(t, 100, res)
+
+	_, ok = call.Call([]interface{}{digest, "invalid"})
+	require.False(t, ok)
+}
+
+func TestSsdeepDesc(t *testing.T) {
+	assert.Equal(t, Ssdeep{}.Desc().RequiredArgs(), 1)
+	assert.Equal(t, SsdeepCompare{}.Desc().RequiredArgs(), 2)
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"strings"
+	"time"
+)
+
+// Location is the time zone in which the time functions and the
+// event timestamp fields are evaluated. If not set, timestamps are
+// evaluated in the location they were captured with.
+var Location *time.Location
+
+// InLocation converts the time to the configured time zone.
+func InLocation(t time.Time) time.Time {
+	if Location == nil {
+		return t
+	}
+	return t.In(Location)
+}
+
+// timeLayouts contains the layouts for parsing timestamps given as strings.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// ParseTime parses the timestamp from the string. Timestamps
+// without the zone designator are interpreted in the configured
+// time zone.
+func ParseTime(s string) (time.Time, bool) {
+	loc := Location
+	if loc == nil {
+		loc = time.Local
+	}
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		t, err := time.ParseInLocation(layout, s, loc)
+		if err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// toTime converts the value to the timestamp. Integers are
+// interpreted as nanoseconds elapsed since Unix epoch, which
+// is the representation of the evt.time.ns field.
+func toTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, !t.IsZero()
+	case int64:
+		return time.Unix(0, t), true
+	case int:
+		return time.Unix(0, int64(t)), true
+	case uint64:
+		return time.Unix(0, int64(t)), true
+	case string:
+		return ParseTime(t)
+	}
+	return time.Time{}, false
+}
+
+// timeArgTypes are the argument types accepted by the time functions.
+var timeArgTypes = []ArgType{Field, String, Number, BoundField, BoundSegment, BareBoundVariable, Func, Expression}
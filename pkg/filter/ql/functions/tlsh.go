@@ -0,0 +1,72 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import "github.com/rabbitstack/fibratus/pkg/util/hashers"
+
+// TLSH computes the Trend Micro Locality Sensitive Hash of the given
+// value. If the optional file argument is true, the value is treated
+// as the file path, and the TLSH of the file content is computed. The
+// value must be at least 50 bytes long to produce the hash.
+type TLSH struct{}
+
+func (f TLSH) Call(args []interface{}) (interface{}, bool) {
+	newDigester, _ := newDigester(TLSHFn)
+	return hashCall(TLSHFn, args, newDigester)
+}
+
+func (f TLSH) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: TLSHFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "data", Types: []ArgType{Field, String, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+			{Keyword: "file", Types: []ArgType{Bool}},
+		},
+	}
+}
+
+func (f TLSH) Name() Fn { return TLSHFn }
+
+// TLSHCompare computes the distance between two TLSH hashes. Lower
+// distances denote more similar inputs, with 0 indicating identical
+// inputs.
+type TLSHCompare struct{}
+
+func (f TLSHCompare) Call(args []interface{}) (interface{}, bool) {
+	if len(args) < 2 {
+		return nil, false
+	}
+	diff, err := hashers.TLSHDiff(parseString(0, args), parseString(1, args))
+	if err != nil {
+		return nil, false
+	}
+	return diff, true
+}
+
+func (f TLSHCompare) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: TLSHCompareFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "hash1", Types: []ArgType{Field, String, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+			{Keyword: "hash2", Types: []ArgType{Field, String, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+		},
+	}
+}
+
+func (f TLSHCompare) Name() Fn { return TLSHCompareFn }
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tlshData = `Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod
+tempor incididunt ut labore et dolore magna aliqua. Ut enim ad minim veniam, quis nostrud
+exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat. Duis aute irure dolor
+in reprehenderit in voluptate velit esse cillum dolore eu fugiat nulla pariatur.`
+
+func TestTLSHCall(t *testing.T) {
+	call := TLSH{}
+
+	res, ok := call.Call([]interface{}{tlshData})
+	require.True(t, ok)
+	assert.Regexp(t, `^T1[0-9A-F]{70}$`, res)
+
+	path := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte(tlshData), 0644))
+
+	digest, ok := call.Call([]interface{}{path, true})
+	require.True(t, ok)
+	assert.Equal(t, res, digest)
+
+	// not enough data
+	_, ok = call.Call([]interface{}{"Lorem ipsum"})
+	require.False(t, ok)
+}
+
+func TestTLSHCompareCall(t *testing.T) {
+	call := TLSHCompare{}
+
+	h1, _ := TLSH{}.Call([]interface{}{tlshData})
+	h2, _ := TLSH{}.Call([]interface{}{strings.Replace(tlshData, "Lorem", "Lorum", 1)})
+
+	res, ok := call.Call([]interface{}{h1, h1})
+	require.True(t, ok)
+	assert.Equal(t, 0, res)
+
+	res, ok = call.Call([]interface{}{h1, h2})
+	require.True(t, ok)
+	assert.Less(t, res, 50)
+
+	_, ok = call.Call([]interface{}{h1, "invalid"})
+	require.False(t, ok)
+}
+
+func TestTLSHDesc(t *testing.T) {
+	assert.Equal(t, TLSH{}.Desc().RequiredArgs(), 1)
+	assert.Equal(t, TLSHCompare{}.Desc().RequiredArgs(), 2)
+}
@@ -76,8 +76,64 @@ const (
 	ForeachFn
 	// CountFn reprsents the COUNT function
 	CountFn
+	// SHA1Fn represents the SHA1 function
+	SHA1Fn
+	// SHA256Fn represents the SHA256 function
+	SHA256Fn
+	// SsdeepFn represents the SSDEEP function
+	SsdeepFn
+	// SsdeepCompareFn represents the SSDEEP_COMPARE function
+	SsdeepCompareFn
+	// TLSHFn represents the TLSH function
+	TLSHFn
+	// TLSHCompareFn represents the TLSH_COMPARE function
+	TLSHCompareFn
+	// B64DecodeFn represents the B64DECODE function
+	B64DecodeFn
+	// HexDecodeFn represents the HEXDECODE function
+	HexDecodeFn
+	// URLDecodeFn represents the URLDECODE function
+	URLDecodeFn
+	// UTF16LEDecodeFn represents the UTF16LE_DECODE function
+	UTF16LEDecodeFn
+	// DeflateDecompressFn represents the DEFLATE_DECOMPRESS function
+	DeflateDecompressFn
+	// CmdlineArgFn represents the CMDLINE_ARG function
+	CmdlineArgFn
+	// NowFn represents the NOW function
+	NowFn
+	// HourFn represents the HOUR function
+	HourFn
+	// WeekdayFn represents the WEEKDAY function
+	WeekdayFn
+	// DateDiffFn represents the DATE_DIFF function
+	DateDiffFn
+	// LevenshteinFn represents the LEVENSHTEIN function
+	LevenshteinFn
+	// JaroWinklerFn represents the JARO_WINKLER function
+	JaroWinklerFn
+	// ConfusableFn represents the CONFUSABLE function
+	ConfusableFn
+	// HasBidiOverrideFn represents the HAS_BIDI_OVERRIDE function
+	HasBidiOverrideFn
+	// RegisteredDomainFn represents the REGISTERED_DOMAIN function
+	RegisteredDomainFn
+	// TLDFn represents the TLD function
+	TLDFn
+	// SubdomainDepthFn represents the SUBDOMAIN_DEPTH function
+	SubdomainDepthFn
+	// LabelEntropyFn represents the LABEL_ENTROPY function
+	LabelEntropyFn
+	// DGAScoreFn represents the DGA_SCORE function
+	DGAScoreFn
+	// URLParseFn represents the URL_PARSE function
+	URLParseFn
 )
 
+// MaxDecodedSize designates the maximum size in bytes of the data
+// produced by decoding functions. The exceeding data is truncated.
+var MaxDecodedSize = 1 << 20
+
 // ArgType is the type alias for the argument value type.
 type ArgType uint8
 
@@ -232,6 +288,58 @@ func (f Fn) String() string {
 		return "FOREACH"
 	case CountFn:
 		return "COUNT"
+	case SHA1Fn:
+		return "SHA1"
+	case SHA256Fn:
+		return "SHA256"
+	case SsdeepFn:
+		return "SSDEEP"
+	case SsdeepCompareFn:
+		return "SSDEEP_COMPARE"
+	case TLSHFn:
+		return "TLSH"
+	case TLSHCompareFn:
+		return "TLSH_COMPARE"
+	case B64DecodeFn:
+		return "B64DECODE"
+	case HexDecodeFn:
+		return "HEXDECODE"
+	case URLDecodeFn:
+		return "URLDECODE"
+	case UTF16LEDecodeFn:
+		return "UTF16LE_DECODE"
+	case DeflateDecompressFn:
+		return "DEFLATE_DECOMPRESS"
+	case CmdlineArgFn:
+		return "CMDLINE_ARG"
+	case NowFn:
+		return "NOW"
+	case HourFn:
+		return "HOUR"
+	case WeekdayFn:
+		return "WEEKDAY"
+	case DateDiffFn:
+		return "DATE_DIFF"
+	case LevenshteinFn:
+		return "LEVENSHTEIN"
+	case JaroWinklerFn:
+		return "JARO_WINKLER"
+	case ConfusableFn:
+		return "CONFUSABLE"
+	case HasBidiOverrideFn:
+		return "HAS_BIDI_OVERRIDE"
+	case RegisteredDomainFn:
+		return "REGISTERED_DOMAIN"
+	case TLDFn:
+		return "TLD"
+	case SubdomainDepthFn:
+		return "SUBDOMAIN_DEPTH"
+	case LabelEntropyFn:
+		return "LABEL_ENTROPY"
+	case DGAScoreFn:
+		return "DGA_SCORE"
+	case URLParseFn:
+		return "URL_PARSE"
 	default:
 		return "UNDEFINED"
 	}
@@ -248,3 +356,41 @@ func parseString(index int, args []interface{}) string {
 	}
 	return s
 }
+
+// parseBytes yields a byte slice from the string or byte slice
+// value at the specific position in the args slice.
+func parseBytes(index int, args []interface{}) []byte {
+	if index > len(args)-1 {
+		return nil
+	}
+	switch v := args[index].(type) {
+	case string:
+		return []byte(v)
+	case []byte:
+		return v
+	}
+	return nil
+}
+
+// parseStrings yields the string slice from the string or the
+// string slice value at the specific position in the args slice.
+func parseStrings(index int, args []interface{}) []string {
+	if index > len(args)-1 {
+		return nil
+	}
+	switch v := args[index].(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	}
+	return nil
+}
+
+// truncateDecoded caps the decoded data to the maximum decoded size.
+func truncateDecoded(b []byte) string {
+	if len(b) > MaxDecodedSize {
+		b = b[:MaxDecodedSize]
+	}
+	return string(b)
+}
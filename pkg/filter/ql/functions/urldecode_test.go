@@ -0,0 +1,44 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLDecode(t *testing.T) {
+	var tests = []struct {
+		args     []interface{}
+		expected interface{}
+		ok       bool
+	}{
+		{[]interface{}{"http://evil.com/a%20b?c=%2Fd"}, "http://evil.com/a b?c=/d", true},
+		{[]interface{}{"aGVsbG8+d29ybGQ%3D"}, "aGVsbG8+d29ybGQ=", true},
+		{[]interface{}{"%zz"}, nil, false},
+	}
+
+	for i, tt := range tests {
+		f := URLDecode{}
+		res, ok := f.Call(tt.args)
+		assert.Equal(t, tt.ok, ok, i)
+		assert.Equal(t, tt.expected, res, i)
+	}
+}
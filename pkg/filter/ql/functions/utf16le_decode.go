@@ -0,0 +1,54 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"encoding/binary"
+
+	"github.com/rabbitstack/fibratus/pkg/util/utf16"
+)
+
+// UTF16LEDecode converts the UTF-16 little-endian encoded data to
+// the UTF-8 string. This is the encoding of PowerShell payloads
+// passed via the -EncodedCommand switch once base64 decoded. The
+// byte order mark is skipped and the decoding stops at the null
+// character.
+type UTF16LEDecode struct{}
+
+func (f UTF16LEDecode) Call(args []interface{}) (interface{}, bool) {
+	if len(args) < 1 {
+		return nil, false
+	}
+	b := parseBytes(0, args)
+	if len(b) > 1 && b[0] == 0xff && b[1] == 0xfe {
+		b = b[2:]
+	}
+	return truncateDecoded([]byte(utf16.BytesToString(b, binary.LittleEndian))), true
+}
+
+func (f UTF16LEDecode) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: UTF16LEDecodeFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "data", Types: []ArgType{Field, String, BoundField, BoundSegment, BareBoundVariable, Func}, Required: true},
+		},
+	}
+}
+
+func (f UTF16LEDecode) Name() Fn { return UTF16LEDecodeFn }
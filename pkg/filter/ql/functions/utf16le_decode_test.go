@@ -0,0 +1,44 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUTF16LEDecode(t *testing.T) {
+	var tests = []struct {
+		args     []interface{}
+		expected interface{}
+	}{
+		{[]interface{}{"I\x00E\x00X\x00"}, "IEX"},
+		{[]interface{}{[]byte{0xff, 0xfe, 'I', 0, 'E', 0, 'X', 0}}, "IEX"},
+		{[]interface{}{"I\x00E\x00X\x00\x00\x00garbage"}, "IEX"},
+		{[]interface{}{"\x3d\xd8\x00\xde"}, "\U0001F600"},
+	}
+
+	for i, tt := range tests {
+		f := UTF16LEDecode{}
+		res, ok := f.Call(tt.args)
+		assert.True(t, ok, i)
+		assert.Equal(t, tt.expected, res, i)
+	}
+}
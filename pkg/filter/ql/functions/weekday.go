@@ -0,0 +1,48 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import "time"
+
+// Weekday returns the name of the week day, e.g. Monday, of
+// the given timestamp. The week day is evaluated in the configured
+// time zone. Without arguments, the current week day is returned.
+type Weekday struct{}
+
+func (f Weekday) Call(args []interface{}) (interface{}, bool) {
+	if len(args) == 0 {
+		return InLocation(time.Now()).Weekday().String(), true
+	}
+	t, ok := toTime(args[0])
+	if !ok {
+		return nil, false
+	}
+	return InLocation(t).Weekday().String(), true
+}
+
+func (f Weekday) Desc() FunctionDesc {
+	return FunctionDesc{
+		Name: WeekdayFn,
+		Args: []FunctionArgDesc{
+			{Keyword: "timestamp", Types: timeArgTypes},
+		},
+	}
+}
+
+func (f Weekday) Name() Fn { return WeekdayFn }
@@ -0,0 +1,60 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functions
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeekday(t *testing.T) {
+	ts := time.Date(2011, 5, 3, 23, 4, 5, 0, time.UTC)
+
+	var tests = []struct {
+		args     []interface{}
+		expected interface{}
+	}{
+		{
+			[]interface{}{ts},
+			"Tuesday",
+		},
+		{
+			[]interface{}{"2011-05-08"},
+			"Sunday",
+		},
+		{
+			[]interface{}{uint8(1)},
+			nil,
+		},
+	}
+
+	for i, tt := range tests {
+		f := Weekday{}
+		res, _ := f.Call(tt.args)
+		assert.Equal(t, tt.expected, res, fmt.Sprintf("%d. result mismatch: exp=%v got=%v", i, tt.expected, res))
+	}
+
+	Location = time.FixedZone("UTC+2", 2*60*60)
+	defer func() { Location = nil }()
+	res, _ := Weekday{}.Call([]interface{}{ts})
+	assert.Equal(t, "Wednesday", res)
+}
@@ -90,6 +90,8 @@ func (s *scanner) scan() (tok Token, pos int, lit string) {
 	case '>':
 		if ch1, _ := s.r.read(); ch1 == '=' {
 			return Gte, pos, ""
+		} else if ch1 == '>' {
+			return Shr, pos, ""
 		}
 		s.r.unread()
 		return Gt, pos, ""
@@ -98,9 +100,25 @@ func (s *scanner) scan() (tok Token, pos int, lit string) {
 			return Lte, pos, ""
 		} else if ch1 == '>' {
 			return Neq, pos, ""
+		} else if ch1 == '<' {
+			return Shl, pos, ""
 		}
 		s.r.unread()
 		return Lt, pos, ""
+	case '+':
+		return Add, pos, ""
+	case '-':
+		return Sub, pos, ""
+	case '*':
+		return Mul, pos, ""
+	case '/':
+		return Div, pos, ""
+	case '%':
+		return Mod, pos, ""
+	case '&':
+		return BitAnd, pos, ""
+	case '^':
+		return BitXor, pos, ""
 	case '(':
 		return Lparen, pos, ""
 	case ')':
@@ -201,6 +219,23 @@ func (s *scanner) scanNumber() (tok Token, pos int, lit string) {
 	// Read as many digits as possible.
 	_, _ = buf.WriteString(s.scanDigits())
 
+	// Read as a hexadecimal integer if the number starts with 0x or 0X.
+	if buf.String() == "0" {
+		if ch0, _ := s.r.read(); ch0 == 'x' || ch0 == 'X' {
+			_, _ = buf.WriteRune(ch0)
+			digits := s.scanHexDigits()
+			_, _ = buf.WriteString(digits)
+			if ch1, _ := s.r.read(); digits == "" || isIdentChar(ch1) {
+				s.r.unread()
+				_, _ = buf.WriteString(scanBareIdent(s.r))
+				return Illegal, pos, buf.String()
+			}
+			s.r.unread()
+			return Integer, pos, buf.String()
+		}
+		s.r.unread()
+	}
+
 	// If next code points are a full stop and digit then consume them.
 	isDecimal := false
 	if ch0, _ := s.r.read(); ch0 == '.' {
@@ -301,6 +336,20 @@ func (s *scanner) scanDigits() string {
 	return buf.String()
 }
 
+// scanHexDigits consumes a contiguous series of hexadecimal digits.
+func (s *scanner) scanHexDigits() string {
+	var buf bytes.Buffer
+	for {
+		ch, _ := s.r.read()
+		if !isHexDigit(ch) {
+			s.r.unread()
+			break
+		}
+		_, _ = buf.WriteRune(ch)
+	}
+	return buf.String()
+}
+
 // scanBareIdent reads bare identifier from a rune reader.
 func scanBareIdent(r io.RuneScanner) string {
 	// Read every ident character into the buffer.
@@ -519,6 +568,11 @@ func isLetter(ch rune) bool {
 // isDigit returns true if the rune is a digit.
 func isDigit(ch rune) bool { return ch >= '0' && ch <= '9' }
 
+// isHexDigit returns true if the rune is a hexadecimal digit.
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
+
 // isIdentChar returns true if the rune can be used in an unquoted identifier. $ rune is for special PE section names (e.g. .debug$ | .tls$)
 func isIdentChar(ch rune) bool {
 	return isLetter(ch) || isDigit(ch) || ch == '_' || ch == '.' || ch == '$'
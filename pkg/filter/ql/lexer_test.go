@@ -59,6 +59,17 @@ func TestScanner(t *testing.T) {
 		{s: `,`, tok: Comma},
 		{s: `|`, tok: Pipe},
 
+		// arithmetic and bitwise operators
+		{s: `+`, tok: Add},
+		{s: `-`, tok: Sub},
+		{s: `*`, tok: Mul},
+		{s: `/`, tok: Div},
+		{s: `%`, tok: Mod},
+		{s: `&`, tok: BitAnd},
+		{s: `^`, tok: BitXor},
+		{s: `<<`, tok: Shl},
+		{s: `>>`, tok: Shr},
+
 		// identifiers
 		{s: `foo`, tok: Ident, lit: `foo`},
 		{s: `_foo`, tok: Ident, lit: `_foo`},
@@ -78,6 +89,9 @@ func TestScanner(t *testing.T) {
 
 		// numbers
 		{s: "6.2323", tok: Decimal, lit: "6.2323"},
+		{s: "0x20", tok: Integer, lit: "0x20"},
+		{s: "0X1fFF", tok: Integer, lit: "0X1fFF"},
+		{s: "0xg", tok: Illegal, lit: "0xg"},
 	}
 
 	for i, tt := range tests {
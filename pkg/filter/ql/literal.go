@@ -58,6 +58,11 @@ type DecimalLiteral struct {
 	Value float64
 }
 
+// DurationLiteral represents the time duration literal.
+type DurationLiteral struct {
+	Value time.Duration
+}
+
 // BoolLiteral represents the logical true/false literal.
 type BoolLiteral struct {
 	Value bool
@@ -119,6 +124,10 @@ func (d DecimalLiteral) String() string {
 	return strconv.FormatFloat(d.Value, 'e', -1, 64)
 }
 
+func (d DurationLiteral) String() string {
+	return d.Value.String()
+}
+
 func (b BoolLiteral) String() string {
 	return strconv.FormatBool(b.Value)
 }
@@ -138,6 +147,8 @@ func (b BareBoundVariableLiteral) String() string {
 // ListLiteral represents a list of tag key literals.
 type ListLiteral struct {
 	Values []string
+	// matcher accelerates the evaluation of large lists
+	matcher *listMatcher
 }
 
 // String returns a string representation of the literal.
@@ -395,15 +406,40 @@ func (e *SequenceExpr) HasBoundFields() bool {
 // a collection of fields that are used to
 // build the sequence join link.
 type SequenceLink struct {
+	// Fields contains all fields referenced in the join link.
 	Fields []*FieldLiteral
+	// Exprs contains link expressions, which are either fields or
+	// function calls. If empty, each field is the link expression.
+	Exprs []Expr
 }
 
 // IsCompound indicates if the sequence expression
 // uses multiple fields for the join link.
 func (l *SequenceLink) IsCompound() bool {
+	if len(l.Exprs) > 0 {
+		return len(l.Exprs) > 1
+	}
 	return len(l.Fields) > 1
 }
 
+// Eval evaluates link expressions against the map valuer and
+// returns their values in the order they appear in the link.
+func (l *SequenceLink) Eval(m map[string]interface{}) []any {
+	if len(l.Exprs) == 0 {
+		values := make([]any, 0, len(l.Fields))
+		for _, fld := range l.Fields {
+			values = append(values, m[fld.Value])
+		}
+		return values
+	}
+	eval := ValuerEval{Valuer: MultiValuer(MapValuer(m), FunctionValuer{m})}
+	values := make([]any, 0, len(l.Exprs))
+	for _, expr := range l.Exprs {
+		values = append(values, eval.Eval(expr))
+	}
+	return values
+}
+
 // First returns the first field if the link is not compound.
 func (l *SequenceLink) First() string {
 	if len(l.Fields) == 1 {
@@ -417,7 +453,14 @@ type Sequence struct {
 	MaxSpan     time.Duration
 	By          *SequenceLink
 	Expressions []SequenceExpr
+	// IsUnordered indicates if the events matching the
+	// sequence expressions can arrive out-of-order.
 	IsUnordered bool
+	// IsAllOf indicates the sequence declared with the unordered
+	// modifier matches when all expressions have matched for the
+	// same join key, regardless of the order in which the events
+	// occurred.
+	IsAllOf bool
 }
 
 // IsConstrained determines if the sequence has the global or per-expression `BY` statement.
@@ -441,6 +484,16 @@ func (s *Sequence) init() {
 	s.IsUnordered = len(sources) > 1
 }
 
+// hasAliases determines if any of the sequence expressions is aliased.
+func (s Sequence) hasAliases() bool {
+	for _, expr := range s.Expressions {
+		if expr.Alias != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func (s Sequence) impairBy() bool {
 	b := make(map[bool]int, len(s.Expressions))
 	for _, expr := range s.Expressions {
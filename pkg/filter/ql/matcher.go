@@ -0,0 +1,327 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ql
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rabbitstack/fibratus/pkg/util/ahocorasick"
+	"github.com/rabbitstack/fibratus/pkg/util/wildcard"
+	"github.com/zeebo/xxh3"
+)
+
+// accelerationThreshold is the minimum number of list elements
+// for which the list is worth accelerating. Smaller lists are
+// evaluated faster by the linear scan.
+const accelerationThreshold = 8
+
+// MatcherCache contains list matchers shared among expressions
+// with identical lists. Rules referencing the same macro list
+// end up using a single hash set or automaton. The cache should
+// be scoped to a single rules compilation, so the matchers are
+// released along with the filters when the rules are replaced.
+type MatcherCache struct {
+	sync.Mutex
+	m map[matcherKey][]*listMatcher
+}
+
+// NewMatcherCache creates an empty list matcher cache.
+func NewMatcherCache() *MatcherCache {
+	return &MatcherCache{m: make(map[matcherKey][]*listMatcher)}
+}
+
+type matcherKey struct {
+	op  Token
+	sum uint64
+	n   int
+}
+
+// listMatcher evaluates the string operator against large lists
+// without scanning every list element. The in/iin operators are
+// backed by hash sets, while contains/icontains operators use the
+// Aho-Corasick automaton. For the matches/imatches operators, the
+// automaton is built from the longest literal fragment of each
+// pattern, and only patterns whose fragments were found in the
+// input are verified by the wildcard matcher.
+type listMatcher struct {
+	op     Token
+	values []string
+
+	set map[string]struct{}
+	ac  *ahocorasick.Matcher
+
+	// fragments maps the automaton pattern to the
+	// indices of wildcard patterns sharing it
+	fragments [][]int
+	// always contains patterns without literal fragments
+	// which are always verified by the wildcard matcher
+	always []int
+	seen   sync.Pool
+}
+
+// Accelerate builds the hash set or the Aho-Corasick automaton for
+// evaluating the operator against the list. The matcher is only built
+// for lists with enough elements to outperform the linear scan, and
+// for the in, iin, contains, icontains, matches, and imatches
+// operators. Lists with identical elements share the same matcher
+// if the cache is given.
+func (s *ListLiteral) Accelerate(op Token, cache *MatcherCache) {
+	if len(s.Values) < accelerationThreshold {
+		return
+	}
+	switch op {
+	case In, IIn, Contains, IContains, Matches, IMatches:
+	default:
+		return
+	}
+
+	if cache == nil {
+		s.matcher = newListMatcher(op, s.Values)
+		return
+	}
+
+	h := xxh3.New()
+	for _, v := range s.Values {
+		_, _ = h.WriteString(v)
+		_, _ = h.Write([]byte{0})
+	}
+	key := matcherKey{op: op, sum: h.Sum64(), n: len(s.Values)}
+
+	cache.Lock()
+	defer cache.Unlock()
+	for _, m := range cache.m[key] {
+		if slices.Equal(m.values, s.Values) {
+			s.matcher = m
+			return
+		}
+	}
+	s.matcher = newListMatcher(op, s.Values)
+	cache.m[key] = append(cache.m[key], s.matcher)
+}
+
+func newListMatcher(op Token, values []string) *listMatcher {
+	m := &listMatcher{op: op, values: values}
+	switch op {
+	case In, IIn:
+		m.set = make(map[string]struct{}, len(values))
+		for _, v := range values {
+			if op == IIn {
+				v = string(appendFold(nil, v))
+			}
+			m.set[v] = struct{}{}
+		}
+	case Contains:
+		m.ac = ahocorasick.New(values, false)
+	case IContains:
+		patterns := make([]string, len(values))
+		for i, v := range values {
+			patterns[i] = strings.ToLower(v)
+		}
+		m.ac = ahocorasick.New(patterns, true)
+	case Matches, IMatches:
+		ids := make(map[string]int)
+		patterns := make([]string, 0, len(values))
+		for i, v := range values {
+			frag := literalFragment(v, op == IMatches)
+			if frag == "" {
+				m.always = append(m.always, i)
+				continue
+			}
+			if op == IMatches {
+				frag = strings.ToLower(frag)
+			}
+			id, ok := ids[frag]
+			if !ok {
+				id = len(patterns)
+				ids[frag] = id
+				patterns = append(patterns, frag)
+				m.fragments = append(m.fragments, nil)
+			}
+			m.fragments[id] = append(m.fragments[id], i)
+		}
+		m.ac = ahocorasick.New(patterns, op == IMatches)
+		n := (len(patterns) + 63) / 64
+		m.seen.New = func() any {
+			seen := make([]uint64, n)
+			return &seen
+		}
+	}
+	return m
+}
+
+// match evaluates the operator against the string or the string
+// slice. In the latter case, any slice element satisfying the
+// operator yields a match. The second return value is false if
+// the matcher can't evaluate the value.
+func (m *listMatcher) match(val any) (bool, bool) {
+	switch v := val.(type) {
+	case string:
+		return m.matchString(v), true
+	case []string:
+		for _, s := range v {
+			if m.matchString(s) {
+				return true, true
+			}
+		}
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+func (m *listMatcher) matchString(s string) bool {
+	switch m.op {
+	case In:
+		_, ok := m.set[s]
+		return ok
+	case IIn:
+		var buf [128]byte
+		_, ok := m.set[string(appendFold(buf[:0], s))]
+		return ok
+	case Contains:
+		return m.ac.Contains(s)
+	case IContains:
+		if !isASCII(s) {
+			s = strings.ToLower(s)
+		}
+		return m.ac.Contains(s)
+	case Matches, IMatches:
+		return m.matchWildcard(s)
+	}
+	return false
+}
+
+func (m *listMatcher) matchWildcard(s string) bool {
+	caseSensitive := m.op == Matches
+	for _, i := range m.always {
+		if wildcard.Match(m.values[i], s, caseSensitive) {
+			return true
+		}
+	}
+	// the automaton only folds ASCII letters, whereas the
+	// wildcard matcher folds any Unicode letter
+	if !caseSensitive && !isASCII(s) {
+		for _, pat := range m.values {
+			if wildcard.Match(pat, s, false) {
+				return true
+			}
+		}
+		return false
+	}
+
+	seen := m.seen.Get().(*[]uint64)
+	defer func() {
+		clear(*seen)
+		m.seen.Put(seen)
+	}()
+
+	var matched bool
+	m.ac.Each(s, func(frag int) bool {
+		// each fragment may occur multiple times in
+		// the input, but patterns are verified once
+		word, bit := frag/64, uint64(1)<<(frag%64)
+		if (*seen)[word]&bit != 0 {
+			return true
+		}
+		(*seen)[word] |= bit
+		for _, i := range m.fragments[frag] {
+			if wildcard.Match(m.values[i], s, caseSensitive) {
+				matched = true
+				return false
+			}
+		}
+		return true
+	})
+
+	return matched
+}
+
+// literalFragment returns the longest run of literal characters
+// in the wildcard pattern. Every string matching the pattern must
+// contain this fragment. For case-insensitive patterns, only ASCII
+// characters are considered because non-ASCII characters may fold
+// to ASCII letters. If the fragment can't be determined reliably,
+// the empty string is returned.
+func literalFragment(pattern string, ignoreCase bool) string {
+	if !utf8.ValidString(pattern) || strings.ContainsRune(pattern, utf8.RuneError) {
+		return ""
+	}
+	var frag string
+	start := 0
+	for i := 0; i <= len(pattern); i++ {
+		if i < len(pattern) && pattern[i] != '*' && pattern[i] != '?' &&
+			(!ignoreCase || pattern[i] < utf8.RuneSelf) {
+			continue
+		}
+		if i-start > len(frag) {
+			frag = pattern[start:i]
+		}
+		start = i + 1
+	}
+	return frag
+}
+
+// appendFold appends the case-folded string to dst. Two strings
+// are equal under Unicode case-folding, as determined by the
+// strings.EqualFold function, if their folded forms are identical.
+func appendFold(dst []byte, s string) []byte {
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if 'A' <= c && c <= 'Z' {
+				c += 'a' - 'A'
+			}
+			dst = append(dst, c)
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		dst = utf8.AppendRune(dst, foldRune(r))
+		i += size
+	}
+	return dst
+}
+
+// foldRune maps the rune to the smallest rune of its case-folding
+// orbit. ASCII upper-case letters are further mapped to lower-case.
+func foldRune(r rune) rune {
+	m := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < m {
+			m = f
+		}
+	}
+	if 'A' <= m && m <= 'Z' {
+		m += 'a' - 'A'
+	}
+	return m
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
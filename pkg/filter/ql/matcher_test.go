@@ -0,0 +1,216 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ql
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// list builds the list literal from the given values padded
+// with dummy values to reach the acceleration threshold.
+func list(values ...string) string {
+	var b strings.Builder
+	b.WriteString("(")
+	for i := 0; i < accelerationThreshold; i++ {
+		fmt.Fprintf(&b, "'dummy%d.dll', ", i)
+	}
+	for i, v := range values {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "'%s'", v)
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+func accelerate(expr Expr) {
+	WalkFunc(expr, func(n Node) {
+		if expr, ok := n.(*BinaryExpr); ok {
+			if list, ok := expr.RHS.(*ListLiteral); ok {
+				list.Accelerate(expr.Op, nil)
+			}
+		}
+	})
+}
+
+func TestAcceleratedListExpr(t *testing.T) {
+	var tests = []struct {
+		expr string
+		m    map[string]interface{}
+		want bool
+	}{
+		{"ps.name in " + list("svchost.exe", "lsass.exe"), map[string]interface{}{"ps.name": "lsass.exe"}, true},
+		{"ps.name in " + list("svchost.exe", "lsass.exe"), map[string]interface{}{"ps.name": "LSASS.exe"}, false},
+		{"ps.name iin " + list("svchost.exe", "lsass.exe"), map[string]interface{}{"ps.name": "LSASS.exe"}, true},
+		{"ps.name iin " + list("svchost.exe", "lsass.exe"), map[string]interface{}{"ps.name": "csrss.exe"}, false},
+		{"ps.name iin " + list("ſvchost.exe"), map[string]interface{}{"ps.name": "SVCHOST.EXE"}, true},
+		{"ps.name iin " + list("ΣΊΣΥΦΟΣ.exe"), map[string]interface{}{"ps.name": "σίσυφος.exe"}, true},
+		{"ps.name not iin " + list("svchost.exe", "lsass.exe"), map[string]interface{}{"ps.name": "csrss.exe"}, true},
+		{"ps.modules in " + list("kernel32.dll", "ntdll.dll"), map[string]interface{}{"ps.modules": []string{"user32.dll", "ntdll.dll"}}, true},
+		{"ps.modules iin " + list("kernel32.dll", "ntdll.dll"), map[string]interface{}{"ps.modules": []string{"user32.dll", "NTDLL.DLL"}}, true},
+		{"ps.modules iin " + list("kernel32.dll", "ntdll.dll"), map[string]interface{}{"ps.modules": []string{"user32.dll", ""}}, false},
+		{"ps.cmdline contains " + list("-enc", "/transfer"), map[string]interface{}{"ps.cmdline": "bitsadmin.exe /transfer job"}, true},
+		{"ps.cmdline contains " + list("-enc", "/transfer"), map[string]interface{}{"ps.cmdline": "bitsadmin.exe /TRANSFER job"}, false},
+		{"ps.cmdline icontains " + list("-enc", "/transfer"), map[string]interface{}{"ps.cmdline": "bitsadmin.exe /TRANSFER job"}, true},
+		{"ps.cmdline icontains " + list("-enc", "/transfer"), map[string]interface{}{"ps.cmdline": "powershell.exe -nop"}, false},
+		{"ps.cmdline icontains " + list("kill"), map[string]interface{}{"ps.cmdline": "taskkill.exe /f"}, true},
+		{"ps.cmdline icontains " + list("kill"), map[string]interface{}{"ps.cmdline": "tas\u212Akill.exe /f"}, true},
+		{"ps.cmdline icontains " + list("ДРОППЕР"), map[string]interface{}{"ps.cmdline": "c:\\temp\\дроппер.exe"}, true},
+		{"ps.modules icontains " + list("mimi"), map[string]interface{}{"ps.modules": []string{"user32.dll", "MIMILIB.dll"}}, true},
+		{"ps.exe matches " + list("C:\\Windows\\*\\svchost.exe", "C:\\Temp\\*.exe"), map[string]interface{}{"ps.exe": "C:\\Windows\\System32\\svchost.exe"}, true},
+		{"ps.exe matches " + list("C:\\Windows\\*\\svchost.exe", "C:\\Temp\\*.exe"), map[string]interface{}{"ps.exe": "C:\\WINDOWS\\System32\\svchost.exe"}, false},
+		{"ps.exe imatches " + list("C:\\Windows\\*\\svchost.exe", "C:\\Temp\\*.exe"), map[string]interface{}{"ps.exe": "C:\\WINDOWS\\System32\\svchost.exe"}, true},
+		{"ps.exe imatches " + list("C:\\Windows\\*\\svchost.exe", "C:\\Temp\\*.exe"), map[string]interface{}{"ps.exe": "C:\\Windows\\System32\\lsass.exe"}, false},
+		{"ps.exe imatches " + list("?:\\Temp\\*.exe"), map[string]interface{}{"ps.exe": "D:\\TEMP\\\u0161pijun.exe"}, true},
+		{"ps.exe imatches " + list("?:\\ProgramData\\*\\tas\u212Ak.exe"), map[string]interface{}{"ps.exe": "C:\\ProgramData\\Updater\\task.exe"}, true},
+		{"ps.exe imatches " + list("*"), map[string]interface{}{"ps.exe": "C:\\Windows\\notepad.exe"}, true},
+		{"ps.exe matches " + list("C:\\*\\*\\*.exe", "*\\svchost.???"), map[string]interface{}{"ps.exe": "C:\\Windows\\svchost.exe"}, true},
+		{"ps.modules imatches " + list("*\\mimi*.dll"), map[string]interface{}{"ps.modules": []string{"C:\\Windows\\user32.dll", "C:\\Temp\\MIMILIB.dll"}}, true},
+		{"ps.name iin " + list("svchost.exe"), map[string]interface{}{"ps.name": nil}, false},
+		{"ps.pid in " + list("123"), map[string]interface{}{"ps.pid": uint32(123)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			p := NewParser(tt.expr)
+			expr, err := p.ParseExpr()
+			require.NoError(t, err)
+			linear := Eval(expr, tt.m, false)
+			accelerate(expr)
+			assert.Equal(t, tt.want, linear)
+			assert.Equal(t, tt.want, Eval(expr, tt.m, false))
+		})
+	}
+}
+
+func TestAccelerateSharedMatcher(t *testing.T) {
+	values := strings.Split("cmd.exe,powershell.exe,pwsh.exe,wscript.exe,cscript.exe,mshta.exe,rundll32.exe,regsvr32.exe", ",")
+
+	cache := NewMatcherCache()
+	l1 := &ListLiteral{Values: values}
+	l2 := &ListLiteral{Values: append([]string(nil), values...)}
+	l3 := &ListLiteral{Values: values}
+	l1.Accelerate(IIn, cache)
+	l2.Accelerate(IIn, cache)
+	l3.Accelerate(IContains, cache)
+	require.NotNil(t, l1.matcher)
+	assert.Same(t, l1.matcher, l2.matcher)
+	assert.NotSame(t, l1.matcher, l3.matcher)
+
+	// matchers are not shared across caches
+	l6 := &ListLiteral{Values: values}
+	l6.Accelerate(IIn, NewMatcherCache())
+	require.NotNil(t, l6.matcher)
+	assert.NotSame(t, l1.matcher, l6.matcher)
+	l7 := &ListLiteral{Values: values}
+	l7.Accelerate(IIn, nil)
+	assert.NotSame(t, l1.matcher, l7.matcher)
+
+	// small lists and unsupported operators are not accelerated
+	l4 := &ListLiteral{Values: values[:2]}
+	l4.Accelerate(IIn, cache)
+	assert.Nil(t, l4.matcher)
+	l5 := &ListLiteral{Values: values}
+	l5.Accelerate(Startswith, cache)
+	assert.Nil(t, l5.matcher)
+}
+
+func TestLiteralFragment(t *testing.T) {
+	var tests = []struct {
+		pattern    string
+		ignoreCase bool
+		frag       string
+	}{
+		{"C:\\Windows\\*\\svchost.exe", false, "\\svchost.exe"},
+		{"*\\mimi*.dll", false, "\\mimi"},
+		{"?:\\Temp\\*", false, ":\\Temp\\"},
+		{"*", false, ""},
+		{"*?*", false, ""},
+		{"C:\\Users\\*\\Ωmega.exe", false, "\\Ωmega.exe"},
+		{"C:\\Users\\*\\Ωmega.exe", true, "C:\\Users\\"},
+		{"C:\\\xff\\*", false, ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.frag, literalFragment(tt.pattern, tt.ignoreCase), tt.pattern)
+	}
+}
+
+func TestAppendFold(t *testing.T) {
+	var tests = []struct {
+		a, b string
+	}{
+		{"svchost.exe", "SVCHOST.EXE"},
+		{"ſvchost.exe", "svchost.exe"},
+		{"\u212Aernel32.dll", "KERNEL32.DLL"},
+		{"ΣΊΣΥΦΟΣ", "σίσυφος"},
+		{"ΣΊΣΥΦΟΣ", "σίσυφοσ"},
+		{"lsass.exe", "lsass.exe "},
+		{"\xff", "\xfe"},
+		{"straße", "STRASSE"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, strings.EqualFold(tt.a, tt.b), string(appendFold(nil, tt.a)) == string(appendFold(nil, tt.b)), "%s -> %s", tt.a, tt.b)
+	}
+}
+
+func BenchmarkListOperators(b *testing.B) {
+	var tests = []struct {
+		op    string
+		value string
+		f     func(i int) string
+	}{
+		{"iin", "C:\\Windows\\System32\\svchost.exe", func(i int) string { return fmt.Sprintf("C:\\Tools\\Malware%d.exe", i) }},
+		{"icontains", "C:\\Windows\\System32\\svchost.exe -k netsvcs -p -s Schedule", func(i int) string { return fmt.Sprintf("-payload%d", i) }},
+		{"imatches", "C:\\Windows\\System32\\svchost.exe", func(i int) string { return fmt.Sprintf("?:\\Tools\\*\\malware%d.exe", i) }},
+	}
+
+	for _, tt := range tests {
+		for _, n := range []int{10, 100, 1000} {
+			values := make([]string, n)
+			for i := range values {
+				values[i] = "'" + tt.f(i) + "'"
+			}
+			m := map[string]interface{}{"ps.exe": tt.value}
+
+			for _, accelerated := range []bool{false, true} {
+				expr, err := NewParser("ps.exe " + tt.op + " (" + strings.Join(values, ", ") + ")").ParseExpr()
+				require.NoError(b, err)
+				name := "linear"
+				if accelerated {
+					accelerate(expr)
+					name = "accelerated"
+				}
+				b.Run(fmt.Sprintf("%s-%s-%d", tt.op, name, n), func(b *testing.B) {
+					b.ReportAllocs()
+					for i := 0; i < b.N; i++ {
+						Eval(expr, m, false)
+					}
+				})
+			}
+		}
+	}
+}
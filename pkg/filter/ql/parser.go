@@ -23,6 +23,7 @@ package ql
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net"
 	"strconv"
 	"strings"
@@ -38,6 +39,10 @@ type Parser struct {
 	s    *bufScanner
 	c    *config.Filters
 	expr string
+	// seq indicates if the sequence is being parsed
+	seq bool
+	// depth is the nesting level of parenthesized expressions and function calls
+	depth int
 }
 
 // NewParser builds a new parser instance from the expression string.
@@ -54,11 +59,20 @@ func NewParserWithConfig(expr string, config *config.Filters) *Parser {
 // statements and time frame constraints. This method assumes the SEQUENCE token
 // has already been consumed.
 func (p *Parser) ParseSequence() (*Sequence, error) {
+	p.seq = true
 	seq := &Sequence{}
 	var exprs []SequenceExpr
 
-	// parse optional max span
+	// parse optional unordered modifier
 	tok, _, _ := p.scanIgnoreWhitespace()
+	if tok == Unordered {
+		seq.IsAllOf = true
+	} else {
+		p.unscan()
+	}
+
+	// parse optional max span
+	tok, _, _ = p.scanIgnoreWhitespace()
 	if tok == MaxSpan {
 		var err error
 		seq.MaxSpan, err = p.parseDuration()
@@ -75,37 +89,10 @@ func (p *Parser) ParseSequence() (*Sequence, error) {
 	// parse optional global link
 	tok, _, _ = p.scanIgnoreWhitespace()
 	if tok == By {
-		tok, pos, lit := p.scanIgnoreWhitespace()
-		if !fields.IsField(lit) {
-			return nil, newParseError(tokstr(tok, lit), []string{"field"}, pos, p.expr)
-		}
-		var err error
-		field, err := p.parseField(lit)
+		seqLink, err := p.parseSequenceLink()
 		if err != nil {
 			return nil, err
 		}
-
-		seqLink := &SequenceLink{Fields: []*FieldLiteral{field}}
-
-		// handle multiple join fields separated by comma
-		for {
-			if tok, _, _ := p.scanIgnoreWhitespace(); tok != Comma {
-				p.unscan()
-				break
-			}
-
-			tok, pos, lit := p.scanIgnoreWhitespace()
-			if !fields.IsField(lit) {
-				return nil, newParseError(tokstr(tok, lit), []string{"field"}, pos, p.expr)
-			}
-			field, err := p.parseField(lit)
-			if err != nil {
-				return nil, err
-			}
-
-			seqLink.Fields = append(seqLink.Fields, field)
-		}
-
 		seq.By = seqLink
 	} else {
 		p.unscan()
@@ -129,6 +116,9 @@ func (p *Parser) ParseSequence() (*Sequence, error) {
 			if seq.incompatibleConstraints() {
 				return nil, fmt.Errorf("%s: sequence mixes global and per-expression 'by' statements", p.expr)
 			}
+			if seq.IsAllOf && seq.hasAliases() {
+				return nil, fmt.Errorf("%s: unordered sequences don't support aliases", p.expr)
+			}
 
 			seq.init()
 
@@ -155,35 +145,10 @@ func (p *Parser) ParseSequence() (*Sequence, error) {
 		tok, _, _ = p.scanIgnoreWhitespace()
 		switch tok {
 		case By:
-			tok, pos, lit := p.scanIgnoreWhitespace()
-			if !fields.IsField(lit) {
-				return nil, newParseError(tokstr(tok, lit), []string{"field"}, pos, p.expr)
-			}
-			field, err := p.parseField(lit)
+			seqLink, err := p.parseSequenceLink()
 			if err != nil {
 				return nil, err
 			}
-
-			seqLink := &SequenceLink{Fields: []*FieldLiteral{field}}
-
-			// handle multiple join fields separated by comma
-			for {
-				if tok, _, _ := p.scanIgnoreWhitespace(); tok != Comma {
-					p.unscan()
-					break
-				}
-
-				tok, pos, lit := p.scanIgnoreWhitespace()
-				if !fields.IsField(lit) {
-					return nil, newParseError(tokstr(tok, lit), []string{"field"}, pos, p.expr)
-				}
-				field, err := p.parseField(lit)
-				if err != nil {
-					return nil, err
-				}
-
-				seqLink.Fields = append(seqLink.Fields, field)
-			}
 			seqexpr = SequenceExpr{Expr: expr, By: seqLink}
 		case As:
 			tok, pos, lit := p.scanIgnoreWhitespace()
@@ -202,6 +167,51 @@ func (p *Parser) ParseSequence() (*Sequence, error) {
 	}
 }
 
+// parseSequenceLink parses the comma-separated list of fields or function
+// calls that make up the sequence join link. Functions are used to normalize
+// the join values, e.g. by base(file.path). This method assumes the BY token
+// has been consumed.
+func (p *Parser) parseSequenceLink() (*SequenceLink, error) {
+	seqLink := &SequenceLink{}
+
+	for {
+		tok, pos, lit := p.scanIgnoreWhitespace()
+		if tok != Ident {
+			return nil, newParseError(tokstr(tok, lit), []string{"field", "function"}, pos, p.expr)
+		}
+
+		switch {
+		case fields.IsField(lit):
+			field, err := p.parseField(lit)
+			if err != nil {
+				return nil, err
+			}
+			seqLink.Fields = append(seqLink.Fields, field)
+			seqLink.Exprs = append(seqLink.Exprs, field)
+		default:
+			if tok, _, _ := p.scan(); tok != Lparen {
+				return nil, newParseError(lit, []string{"field", "function"}, pos, p.expr)
+			}
+			fn, err := p.parseFunction(lit)
+			if err != nil {
+				return nil, err
+			}
+			WalkFunc(fn, func(n Node) {
+				if field, ok := n.(*FieldLiteral); ok {
+					seqLink.Fields = append(seqLink.Fields, field)
+				}
+			})
+			seqLink.Exprs = append(seqLink.Exprs, fn)
+		}
+
+		// handle multiple join expressions separated by comma
+		if tok, _, _ := p.scanIgnoreWhitespace(); tok != Comma {
+			p.unscan()
+			return seqLink, nil
+		}
+	}
+}
+
 // IsSequence checks whether the expression given to the parser is a sequence.
 func (p *Parser) IsSequence() bool {
 	tok, _, _ := p.scanIgnoreWhitespace()
@@ -214,19 +224,29 @@ func (p *Parser) IsSequence() bool {
 
 // ParseExpr parses an expression by building the binary expression tree.
 func (p *Parser) ParseExpr() (Expr, error) {
-	var err error
-	root := &BinaryExpr{}
-	// parse a non-binary expression type to start. This variable will always be
-	// the root of the expression tree.
-	root.RHS, err = p.parseUnaryExpr()
+	// parse a non-binary expression type to start. This expression will always
+	// be the leftmost operand of the expression tree.
+	expr, err := p.parseUnaryExpr()
 	if err != nil {
 		return nil, err
 	}
+	return p.parseBinaryExpr(expr)
+}
+
+// parseBinaryExpr builds the binary expression tree whose leftmost operand
+// is the given expression. This method assumes the operand has been consumed.
+func (p *Parser) parseBinaryExpr(expr Expr) (Expr, error) {
+	root := &BinaryExpr{RHS: expr}
 
 	// loop over operations and unary exprs and build a tree based on precedence.
 	for {
 		// if the next token is NOT an operator then return the expression.
 		op, pos, lit := p.scanIgnoreWhitespace()
+		// the pipe delimits sequence expressions, unless it
+		// appears inside parentheses or function arguments
+		if op == Pipe && (!p.seq || p.depth > 0) {
+			op = BitOr
+		}
 		if !op.isOperator() {
 			p.unscan()
 			if op != EOF && op != Rparen && op != Comma && op != Pipe {
@@ -247,7 +267,7 @@ func (p *Parser) ParseExpr() (Expr, error) {
 		if op == Not {
 			// handle infix negation
 			op1, pos, lit := p.scanIgnoreWhitespace()
-			if !op1.isOperator() {
+			if !op1.isOperator() || op1.IsArithmetic() {
 				return nil, newParseError(tokstr(op1, lit), []string{"operator"}, pos, p.expr)
 			}
 			rhs, err := p.parseUnaryExpr()
@@ -291,8 +311,10 @@ func (p *Parser) ParseExpr() (Expr, error) {
 func (p *Parser) parseUnaryExpr() (Expr, error) {
 	// If the first token is a LPAREN then parse it as its own grouped expression.
 	if tok, _, _ := p.scanIgnoreWhitespace(); tok == Lparen {
+		p.depth++
+		defer func() { p.depth-- }()
 		// parse a comma-separated list if this looks like a list
-		tagKeys, err := p.parseList()
+		tagKeys, first, err := p.parseList()
 		if err != nil {
 			p.unscan()
 			// if it fails, try to parse the grouped expression
@@ -309,6 +331,22 @@ func (p *Parser) parseUnaryExpr() (Expr, error) {
 
 		// Expect an RPAREN at the end of list
 		if tok, pos, lit := p.scanIgnoreWhitespace(); tok != Rparen {
+			// the grouped expression starting with a literal, e.g. (8 * file.io.size)
+			if len(tagKeys) == 1 && (tok.isOperator() || tok == Pipe) {
+				p.unscan()
+				lhs, err := p.parseLiteral(first, pos, tagKeys[0])
+				if err != nil {
+					return nil, err
+				}
+				expr, err := p.parseBinaryExpr(lhs)
+				if err != nil {
+					return nil, err
+				}
+				if tok, pos, lit := p.scanIgnoreWhitespace(); tok != Rparen {
+					return nil, newParseError(tokstr(tok, lit), []string{"')'"}, pos, p.expr)
+				}
+				return &ParenExpr{Expr: expr}, nil
+			}
 			return nil, newParseError(tokstr(tok, lit), []string{"')'"}, pos, p.expr)
 		}
 
@@ -330,6 +368,31 @@ func (p *Parser) parseUnaryExpr() (Expr, error) {
 		return &NotExpr{Expr: expr}, nil
 	}
 
+	// handle unary minus
+	p.unscan()
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok == Sub {
+		expr, err := p.parseUnaryExpr()
+		if err != nil {
+			return nil, err
+		}
+		switch e := expr.(type) {
+		case *IntegerLiteral:
+			return &IntegerLiteral{Value: -e.Value}, nil
+		case *DecimalLiteral:
+			return &DecimalLiteral{Value: -e.Value}, nil
+		case *DurationLiteral:
+			return &DurationLiteral{Value: -e.Value}, nil
+		case *UnsignedLiteral:
+			if e.Value != 1<<63 {
+				return nil, &ParseError{Message: "integer out of range", Pos: pos}
+			}
+			return &IntegerLiteral{Value: math.MinInt64}, nil
+		case *StringLiteral, *IPLiteral, *BoolLiteral, *ListLiteral:
+			return nil, newParseError(tokstr(tok, lit), []string{"number", "field", "function", "("}, pos, p.expr)
+		}
+		return &BinaryExpr{Op: Sub, LHS: &IntegerLiteral{Value: 0}, RHS: expr}, nil
+	}
+
 	p.unscan()
 
 	tok, pos, lit := p.scanIgnoreWhitespace()
@@ -362,10 +425,8 @@ func (p *Parser) parseUnaryExpr() (Expr, error) {
 			// unscan ident
 			p.unscan()
 		}
-	case IP:
-		return &IPLiteral{Value: net.ParseIP(lit)}, nil
-	case Str:
-		return &StringLiteral{Value: lit}, nil
+	case IP, Str, Integer:
+		return p.parseLiteral(tok, pos, lit)
 	case BoundVar:
 		n := strings.Index(lit, ".")
 		if n == -1 {
@@ -390,17 +451,12 @@ func (p *Parser) parseUnaryExpr() (Expr, error) {
 		return nil, newParseError(tokstr(tok, lit), []string{"field/segment after bound ref"}, pos+n, p.expr)
 	case True, False:
 		return &BoolLiteral{Value: tok == True}, nil
-	case Integer:
-		v, err := strconv.ParseInt(lit, 10, 64)
+	case Duration:
+		d, err := parseDuration(lit)
 		if err != nil {
-			// The literal may be too large to fit into an int64. If it is, use an unsigned integer.
-			// The check for negative numbers is handled somewhere else so this should always be a positive number.
-			if v, err := strconv.ParseUint(lit, 10, 64); err == nil {
-				return &UnsignedLiteral{Value: v}, nil
-			}
-			return nil, &ParseError{Message: "unable to parse integer", Pos: pos}
+			return nil, &ParseError{Message: err.Error(), Pos: pos}
 		}
-		return &IntegerLiteral{Value: v}, nil
+		return &DurationLiteral{Value: d}, nil
 	case Decimal:
 		v, err := strconv.ParseFloat(lit, 64)
 		if err != nil {
@@ -420,6 +476,37 @@ func (p *Parser) parseUnaryExpr() (Expr, error) {
 	return nil, newParseError(tokstr(tok, lit), expectations, pos, p.expr)
 }
 
+// parseLiteral parses the string, IP address, or integer literal.
+func (p *Parser) parseLiteral(tok Token, pos int, lit string) (Expr, error) {
+	switch tok {
+	case IP:
+		return &IPLiteral{Value: net.ParseIP(lit)}, nil
+	case Str:
+		return &StringLiteral{Value: lit}, nil
+	case Integer:
+		base := 10
+		if isHex(lit) {
+			base, lit = 16, lit[2:]
+		}
+		v, err := strconv.ParseInt(lit, base, 64)
+		if err != nil {
+			// The literal may be too large to fit into an int64. If it is, use an unsigned integer.
+			// Negative numbers are handled by the unary minus so this should always be a positive number.
+			if v, err := strconv.ParseUint(lit, base, 64); err == nil {
+				return &UnsignedLiteral{Value: v}, nil
+			}
+			return nil, &ParseError{Message: "unable to parse integer", Pos: pos}
+		}
+		return &IntegerLiteral{Value: v}, nil
+	}
+	return nil, newParseError(tokstr(tok, lit), []string{"string", "number", "ip"}, pos, p.expr)
+}
+
+// isHex determines if the integer literal is in hexadecimal notation.
+func isHex(lit string) bool {
+	return len(lit) > 2 && lit[0] == '0' && (lit[1] == 'x' || lit[1] == 'X')
+}
+
 // parseField parses the field and its argument. This method
 // assumes the field name has been consumed.
 func (p *Parser) parseField(name string) (*FieldLiteral, error) {
@@ -462,36 +549,52 @@ func (p *Parser) parseField(name string) (*FieldLiteral, error) {
 }
 
 // parseList parses the list of strings. This method assumes the
-// LPAREN token has been consumed.
-func (p *Parser) parseList() ([]string, error) {
-	tok, pos, lit := p.scanIgnoreWhitespace()
-	if tok != Str && tok != IP && tok != Integer {
-		return []string{}, newParseError(tokstr(tok, lit), []string{"identifier"}, pos, p.expr)
+// LPAREN token has been consumed. Along with the list, the token
+// of the first list element is returned.
+func (p *Parser) parseList() ([]string, Token, error) {
+	first, pos, lit := p.scanIgnoreWhitespace()
+	if first != Str && first != IP && first != Integer {
+		return []string{}, first, newParseError(tokstr(first, lit), []string{"identifier"}, pos, p.expr)
 	}
-	idents := []string{lit}
+	idents := []string{listValue(first, lit)}
 
 	// parse remaining identifiers
 	for {
 		if tok, _, _ := p.scanIgnoreWhitespace(); tok != Comma {
 			p.unscan()
-			return idents, nil
+			return idents, first, nil
 		}
 
 		tok, pos, lit := p.scanIgnoreWhitespace()
 		if tok != Str && tok != IP && tok != Integer {
-			return []string{}, newParseError(tokstr(tok, lit), []string{"identifier"}, pos, p.expr)
+			return []string{}, first, newParseError(tokstr(tok, lit), []string{"identifier"}, pos, p.expr)
 		}
 
-		idents = append(idents, lit)
+		idents = append(idents, listValue(tok, lit))
 	}
 }
 
+// listValue converts hexadecimal integers in lists to the decimal notation.
+func listValue(tok Token, lit string) string {
+	if tok != Integer || !isHex(lit) {
+		return lit
+	}
+	v, err := strconv.ParseUint(lit[2:], 16, 64)
+	if err != nil {
+		return lit
+	}
+	return strconv.FormatUint(v, 10)
+}
+
 // parseFunction parses a function call. This method assumes
 // the function name and LPAREN have been consumed.
 func (p *Parser) parseFunction(name string) (*Function, error) {
 	name = strings.ToLower(name)
 	args := make([]Expr, 0)
 
+	p.depth++
+	defer func() { p.depth-- }()
+
 	// If there's a right paren then just return immediately.
 	// This is the case for functions without arguments
 	if tok, _, _ := p.scan(); tok == Rparen {
@@ -21,6 +21,7 @@ package ql
 import (
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"testing"
 	"time"
@@ -60,7 +61,7 @@ func TestParser(t *testing.T) {
 		{expr: "ps.none = 'cmd.exe'", err: errors.New("ps.none = 'cmd.exe'\n╭^\n|\n|\n╰─────────────────── expected field, bound field, string, number, bool, ip, function")},
 
 		{expr: "ps.name = 'cmd.exe' AND ps.name IN ('exe') ps.name", err: errors.New("ps.name = 'cmd.exe' AND ps.name IN ('exe') ps.name\n╭──────────────────────────────────────────^\n|\n|\n╰─────────────────── expected operator, ')', ',', '|'")},
-		{expr: "ip_cidr(net.dip) = '24'", err: errors.New("ip_cidr function is undefined. Did you mean one of BASE|CIDR_CONTAINS|CONCAT|COUNT|DIR|ENTROPY|EXT|FOREACH|GET_REG_VALUE|GLOB|INDEXOF|IS_ABS|IS_MINIDUMP|LENGTH|LOWER|LTRIM|MD5|REGEX|REPLACE|RTRIM|SPLIT|SUBSTR|UNDEFINED|UPPER|VOLUME|YARA?")},
+		{expr: "ip_cidr(net.dip) = '24'", err: errors.New("ip_cidr function is undefined. Did you mean one of B64DECODE|BASE|CIDR_CONTAINS|CMDLINE_ARG|CONCAT|CONFUSABLE|COUNT|DATE_DIFF|DEFLATE_DECOMPRESS|DGA_SCORE|DIR|ENTROPY|EXT|FOREACH|GET_REG_VALUE|GLOB|HAS_BIDI_OVERRIDE|HEXDECODE|HOUR|INDEXOF|IS_ABS|IS_MINIDUMP|JARO_WINKLER|LABEL_ENTROPY|LENGTH|LEVENSHTEIN|LOWER|LTRIM|MD5|NOW|REGEX|REGISTERED_DOMAIN|REPLACE|RTRIM|SHA1|SHA256|SPLIT|SSDEEP|SSDEEP_COMPARE|SUBDOMAIN_DEPTH|SUBSTR|TLD|TLSH|TLSH_COMPARE|UNDEFINED|UPPER|URLDECODE|URL_PARSE|UTF16LE_DECODE|VOLUME|WEEKDAY|YARA?")},
 
 		{expr: "ps.name = 'cmd.exe' and not cidr_contains(net.sip, '172.14.0.0')"},
 		{expr: "ps.name = 'cmd.exe' and ps.exe not imatches '?:\\\\Windows'"},
@@ -144,6 +145,84 @@ func TestParseUnaryExpr(t *testing.T) {
 	}
 }
 
+func TestParseArithmeticExpr(t *testing.T) {
+	var tests = []struct {
+		expr       string
+		err        string
+		assertions func(t *testing.T, e Expr)
+	}{
+		{"file.io.size * 8 > 1048576", "", func(t *testing.T, e Expr) {
+			expr := e.(*BinaryExpr)
+			assert.Equal(t, Gt, expr.Op)
+			require.IsType(t, &BinaryExpr{}, expr.LHS)
+			assert.Equal(t, Mul, expr.LHS.(*BinaryExpr).Op)
+		}},
+		{"ps.pid + 2 * 3 = 10", "", func(t *testing.T, e Expr) {
+			expr := e.(*BinaryExpr)
+			assert.Equal(t, Eq, expr.Op)
+			lhs := expr.LHS.(*BinaryExpr)
+			assert.Equal(t, Add, lhs.Op)
+			require.IsType(t, &BinaryExpr{}, lhs.RHS)
+			assert.Equal(t, Mul, lhs.RHS.(*BinaryExpr).Op)
+		}},
+		{"ps.pid << 2 | 1 = 9", "", func(t *testing.T, e Expr) {
+			expr := e.(*BinaryExpr)
+			lhs := expr.LHS.(*BinaryExpr)
+			assert.Equal(t, BitOr, lhs.Op)
+			assert.Equal(t, Shl, lhs.LHS.(*BinaryExpr).Op)
+		}},
+		{"(8 * file.io.size) >= 4096", "", func(t *testing.T, e Expr) {
+			expr := e.(*BinaryExpr)
+			require.IsType(t, &ParenExpr{}, expr.LHS)
+			assert.Equal(t, Mul, expr.LHS.(*ParenExpr).Expr.(*BinaryExpr).Op)
+		}},
+		{"(thread.access.mask & 0x20) != 0", "", func(t *testing.T, e Expr) {
+			expr := e.(*BinaryExpr)
+			rhs := expr.LHS.(*ParenExpr).Expr.(*BinaryExpr).RHS
+			require.IsType(t, &IntegerLiteral{}, rhs)
+			assert.Equal(t, int64(32), rhs.(*IntegerLiteral).Value)
+		}},
+		{"ps.pid in (0x4, 8)", "", func(t *testing.T, e Expr) {
+			expr := e.(*BinaryExpr)
+			require.IsType(t, &ListLiteral{}, expr.RHS)
+			assert.Equal(t, []string{"4", "8"}, expr.RHS.(*ListLiteral).Values)
+		}},
+		{"ps.pid = -1", "", func(t *testing.T, e Expr) {
+			expr := e.(*BinaryExpr)
+			require.IsType(t, &IntegerLiteral{}, expr.RHS)
+			assert.Equal(t, int64(-1), expr.RHS.(*IntegerLiteral).Value)
+		}},
+		{"-ps.pid < 0", "", func(t *testing.T, e Expr) {
+			expr := e.(*BinaryExpr)
+			require.IsType(t, &BinaryExpr{}, expr.LHS)
+			assert.Equal(t, Sub, expr.LHS.(*BinaryExpr).Op)
+		}},
+		{"ps.pid = -9223372036854775808", "", func(t *testing.T, e Expr) {
+			expr := e.(*BinaryExpr)
+			assert.Equal(t, int64(math.MinInt64), expr.RHS.(*IntegerLiteral).Value)
+		}},
+		{"ps.pid = -'svchost.exe'", "expected number, field, function, (", nil},
+		{"ps.pid = 0xzz", "expected field, bound field, string, number, bool, ip, function", nil},
+		{"ps.pid not * 2 = 4", "expected operator", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			p := NewParser(tt.expr)
+			expr, err := p.ParseExpr()
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.assertions != nil {
+				tt.assertions(t, expr)
+			}
+		})
+	}
+}
+
 func TestExpandMacros(t *testing.T) {
 	var tests = []struct {
 		c            *config.Filters
@@ -269,6 +348,22 @@ func TestParseSequence(t *testing.T) {
 			time.Duration(0),
 			false,
 		},
+		{
+			`|evt.name = 'OpenProcess' and (ps.access.mask | 0x400) != 0|
+			 |evt.name = 'CreateFile' and file.io.size * 8 > 1048576|
+			`,
+			nil,
+			time.Duration(0),
+			false,
+		},
+		{
+			`|evt.name = 'OpenProcess' and ps.access.mask | 0x400 != 0|
+			 |evt.name = 'CreateFile'|
+			`,
+			errors.New("expected |"),
+			time.Duration(0),
+			false,
+		},
 		{
 			`|evt.name = 'CreateProcess'| by ps.exe
 			 |evt.name = 'CreateFile'| by file.name
@@ -294,6 +389,47 @@ func TestParseSequence(t *testing.T) {
 			time.Duration(0),
 			true,
 		},
+		{
+			`|evt.name = 'CreateFile'| by lower(base(file.path))
+			 |evt.name = 'CreateProcess'| by lower(ps.name)
+			`,
+			nil,
+			time.Duration(0),
+			true,
+		},
+		{
+			`|evt.name = 'CreateFile'| by base(file.path), ps.uuid
+			 |evt.name = 'CreateProcess'| by ps.name, ps.uuid
+			`,
+			nil,
+			time.Duration(0),
+			true,
+		},
+		{
+			`|evt.name = 'CreateFile'| by 'file.path'
+			 |evt.name = 'CreateProcess'| by ps.name
+			`,
+			errors.New("expected field, function"),
+			time.Duration(0),
+			true,
+		},
+		{
+			`|evt.name = 'CreateFile'| by basename
+			 |evt.name = 'CreateProcess'| by ps.name
+			`,
+			errors.New("expected field, function"),
+			time.Duration(0),
+			true,
+		},
+		{
+			`maxspan 1m
+			 |evt.name = 'CreateFile'| as e1
+			 |evt.name = 'CreateProcess' and base($e1.file.path) = ps.name and $e2.evt.time.ns - $e1.evt.time.ns < 5s| as e2
+			`,
+			nil,
+			time.Minute,
+			false,
+		},
 		{
 			`|evt.name = 'CreateProcess'| by ps.exe, 
 			 |evt.name = 'CreateFile'| by file.name, ps.uuid
@@ -418,6 +554,72 @@ func TestParseSequence(t *testing.T) {
 	}
 }
 
+func TestParseSequenceLink(t *testing.T) {
+	p := NewParser(`|evt.name = 'CreateFile'| by lower(base(file.path)), ps.uuid
+	 |evt.name = 'CreateProcess'| by lower(ps.name), ps.uuid
+	`)
+	seq, err := p.ParseSequence()
+	require.NoError(t, err)
+
+	by := seq.Expressions[0].By
+	require.NotNil(t, by)
+	assert.True(t, by.IsCompound())
+	require.Len(t, by.Exprs, 2)
+	assert.IsType(t, &Function{}, by.Exprs[0])
+	assert.IsType(t, &FieldLiteral{}, by.Exprs[1])
+	require.Len(t, by.Fields, 2)
+	assert.Equal(t, "file.path", by.Fields[0].Value)
+	assert.Equal(t, "ps.uuid", by.Fields[1].Value)
+
+	values := by.Eval(map[string]interface{}{"file.path": "C:\\Temp\\Dropper.EXE", "ps.uuid": uint64(10)})
+	assert.Equal(t, []any{"dropper.exe", uint64(10)}, values)
+}
+
+func TestParseDurationLiteral(t *testing.T) {
+	p := NewParser(`evt.time.ns - 1000 < 5s`)
+	expr, err := p.ParseExpr()
+	require.NoError(t, err)
+	assert.True(t, Eval(expr, map[string]interface{}{"evt.time.ns": int64(4 * time.Second)}, false))
+	assert.False(t, Eval(expr, map[string]interface{}{"evt.time.ns": int64(6 * time.Second)}, false))
+
+	p = NewParser(`evt.time.ns > -1m30s`)
+	expr, err = p.ParseExpr()
+	require.NoError(t, err)
+	require.IsType(t, &DurationLiteral{}, expr.(*BinaryExpr).RHS)
+	assert.Equal(t, -90*time.Second, expr.(*BinaryExpr).RHS.(*DurationLiteral).Value)
+}
+
+func TestParseUnorderedSequence(t *testing.T) {
+	p := NewParser(`unordered
+	 maxspan 5m
+	 by ps.uuid
+	 |evt.name = 'RegSetValue'|
+	 |evt.name = 'CreateFile'|
+	`)
+	seq, err := p.ParseSequence()
+	require.NoError(t, err)
+	assert.True(t, seq.IsAllOf)
+	assert.Equal(t, time.Minute*5, seq.MaxSpan)
+	assert.True(t, seq.IsConstrained())
+
+	p = NewParser(`maxspan 5m
+	 |evt.name = 'RegSetValue'| by ps.uuid
+	 |evt.name = 'CreateFile'| by ps.uuid
+	`)
+	seq, err = p.ParseSequence()
+	require.NoError(t, err)
+	assert.False(t, seq.IsAllOf)
+
+	p = NewParser(`unordered
+	 maxspan 5m
+	 |evt.name = 'RegSetValue'| as e1
+	 |evt.name = 'CreateFile' and file.path = $e1.registry.value|
+	`)
+	_, err = p.ParseSequence()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unordered sequences don't support aliases")
+}
+
 func TestIsSequenceUnordered(t *testing.T) {
 	var tests = []struct {
 		expr        string
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ql
+
+import (
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/filter/ql/functions"
+)
+
+// normalizeTimes converts timestamp operands to values the binary
+// expression evaluator can compare. Timestamps are converted to
+// nanoseconds elapsed since Unix epoch. When the timestamp is compared
+// to a string with relational operators, the string is parsed as the
+// timestamp. For the rest of string operators, the timestamp is
+// converted to its string representation.
+func normalizeTimes(op Token, lhs, rhs any) (any, any) {
+	lt, lok := lhs.(time.Time)
+	rt, rok := rhs.(time.Time)
+	switch {
+	case lok && rok:
+		return unixNano(lt), unixNano(rt)
+	case lok:
+		return normalizeTime(op, lt, rhs)
+	case rok:
+		v, t := normalizeTime(op, rt, lhs)
+		return t, v
+	}
+	return lhs, rhs
+}
+
+// normalizeTime converts the timestamp and the other operand
+// depending on the type of the other operand.
+func normalizeTime(op Token, t time.Time, v any) (any, any) {
+	s, ok := v.(string)
+	if !ok {
+		return unixNano(t), v
+	}
+	switch op {
+	case Eq, Neq, Lt, Lte, Gt, Gte:
+		if ts, ok := functions.ParseTime(s); ok {
+			return unixNano(t), ts.UnixNano()
+		}
+	}
+	return t.String(), s
+}
+
+// unixNano returns the timestamp as nanoseconds elapsed
+// since Unix epoch or nil if the timestamp is zero.
+func unixNano(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UnixNano()
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/filter/ql/functions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeExpr(t *testing.T) {
+	ts, err := time.Parse(time.RFC3339, "2011-05-03T15:04:05Z")
+	require.NoError(t, err)
+
+	var tests = []struct {
+		expr string
+		m    map[string]interface{}
+		want bool
+	}{
+		{"evt.timestamp - ps.pe.link_time < 1d", map[string]interface{}{"evt.timestamp": ts, "ps.pe.link_time": ts.Add(-time.Hour * 12)}, true},
+		{"evt.timestamp - ps.pe.link_time < 1d", map[string]interface{}{"evt.timestamp": ts, "ps.pe.link_time": ts.Add(-time.Hour * 72)}, false},
+		{"evt.timestamp - ps.pe.link_time < 1d", map[string]interface{}{"evt.timestamp": ts, "ps.pe.link_time": time.Time{}}, false},
+		{"evt.timestamp > ps.pe.link_time", map[string]interface{}{"evt.timestamp": ts, "ps.pe.link_time": ts.Add(-time.Hour)}, true},
+		{"evt.timestamp = ps.pe.link_time", map[string]interface{}{"evt.timestamp": ts, "ps.pe.link_time": ts}, true},
+		{"evt.timestamp > '2011-05-01'", map[string]interface{}{"evt.timestamp": ts}, true},
+		{"evt.timestamp < '2011-05-01T00:00:00Z'", map[string]interface{}{"evt.timestamp": ts}, false},
+		{"evt.timestamp = '2011-05-03T15:04:05Z'", map[string]interface{}{"evt.timestamp": ts}, true},
+		{"evt.timestamp startswith '2011-05-03'", map[string]interface{}{"evt.timestamp": ts}, true},
+		{"evt.timestamp > evt.time.ns - 1m", map[string]interface{}{"evt.timestamp": ts, "evt.time.ns": ts.UnixNano()}, true},
+		{"now() - evt.timestamp > 1w", map[string]interface{}{"evt.timestamp": ts}, true},
+		{"date_diff(evt.timestamp, ps.pe.link_time, 'h') = 12", map[string]interface{}{"evt.timestamp": ts, "ps.pe.link_time": ts.Add(-time.Hour * 12)}, true},
+		{"date_diff(evt.timestamp, ps.pe.link_time) < 1d", map[string]interface{}{"evt.timestamp": ts, "ps.pe.link_time": ts.Add(-time.Hour * 12)}, true},
+		{"hour(evt.timestamp) >= 9 and hour(evt.timestamp) < 18", map[string]interface{}{"evt.timestamp": ts}, true},
+		{"weekday(evt.timestamp) in ('Saturday', 'Sunday')", map[string]interface{}{"evt.timestamp": ts}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			p := NewParser(tt.expr)
+			expr, err := p.ParseExpr()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, Eval(expr, tt.m, true))
+		})
+	}
+}
+
+func TestTimeExprLocation(t *testing.T) {
+	ts, err := time.Parse(time.RFC3339, "2011-05-03T23:04:05Z")
+	require.NoError(t, err)
+
+	functions.Location = time.FixedZone("UTC+2", 2*60*60)
+	defer func() { functions.Location = nil }()
+
+	p := NewParser("hour(evt.timestamp) = 1 and weekday(evt.timestamp) = 'Wednesday'")
+	expr, err := p.ParseExpr()
+	require.NoError(t, err)
+	assert.True(t, Eval(expr, map[string]interface{}{"evt.timestamp": ts}, true))
+}
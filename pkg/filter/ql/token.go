@@ -70,6 +70,16 @@ const (
 	Lte         // <=
 	Gt          // >
 	Gte         // >=
+	Add         // +
+	Sub         // -
+	Mul         // *
+	Div         // /
+	Mod         // %
+	BitAnd      // &
+	BitOr       // |
+	BitXor      // ^
+	Shl         // <<
+	Shr         // >>
 	opEnd
 
 	Lparen   // (
@@ -80,10 +90,11 @@ const (
 	LBracket // [
 	RBracket // ]
 
-	Seq     // SEQUENCE
-	MaxSpan // MAXSPAN
-	By      // BY
-	As      // AS
+	Seq       // SEQUENCE
+	MaxSpan   // MAXSPAN
+	By        // BY
+	As        // AS
+	Unordered // UNORDERED
 )
 
 var keywords map[string]Token
@@ -93,7 +104,7 @@ func init() {
 	for _, tok := range []Token{And, Or, Contains, IContains, In,
 		IIn, Not, Startswith, IStartswith, Endswith, IEndswith,
 		Matches, IMatches, Fuzzy, IFuzzy, Fuzzynorm, IFuzzynorm,
-		Intersects, IIntersects, Seq, MaxSpan, By, As} {
+		Intersects, IIntersects, Seq, MaxSpan, By, As, Unordered} {
 		keywords[strings.ToLower(tokens[tok])] = tok
 	}
 	keywords["true"] = True
@@ -146,6 +157,17 @@ var tokens = [...]string{
 	Gt:  ">",
 	Gte: ">=",
 
+	Add:    "+",
+	Sub:    "-",
+	Mul:    "*",
+	Div:    "/",
+	Mod:    "%",
+	BitAnd: "&",
+	BitOr:  "|",
+	BitXor: "^",
+	Shl:    "<<",
+	Shr:    ">>",
+
 	Lparen:   "(",
 	Rparen:   ")",
 	Comma:    ",",
@@ -154,15 +176,19 @@ var tokens = [...]string{
 	LBracket: "[",
 	RBracket: "]",
 
-	Seq:     "SEQUENCE",
-	MaxSpan: "MAXSPAN",
-	By:      "BY",
-	As:      "AS",
+	Seq:       "SEQUENCE",
+	MaxSpan:   "MAXSPAN",
+	By:        "BY",
+	As:        "AS",
+	Unordered: "UNORDERED",
 }
 
 // isOperator determines whether the current token is an operator.
 func (tok Token) isOperator() bool { return tok > opBeg && tok < opEnd }
 
+// IsArithmetic determines whether the current token is an arithmetic or bitwise operator.
+func (tok Token) IsArithmetic() bool { return tok >= Add && tok <= Shr }
+
 // String returns the string representation of the token.
 func (tok Token) String() string {
 	if tok >= 0 && tok < Token(len(tokens)) {
@@ -185,6 +211,18 @@ func (tok Token) precedence() int {
 	case In, IIn, Contains, IContains, Startswith, IStartswith, Endswith, IEndswith,
 		Matches, IMatches, Fuzzy, IFuzzy, Fuzzynorm, IFuzzynorm, Intersects, IIntersects:
 		return 5
+	case BitOr:
+		return 6
+	case BitXor:
+		return 7
+	case BitAnd:
+		return 8
+	case Shl, Shr:
+		return 9
+	case Add, Sub:
+		return 10
+	case Mul, Div, Mod:
+		return 11
 	}
 	return 0
 }
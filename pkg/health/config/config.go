@@ -0,0 +1,66 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	eventsourceStall     = "health.eventsource-stall"
+	aggregatorStall      = "health.aggregator-stall"
+	outputStall          = "health.output-stall"
+	maxQueueLag          = "health.max-queue-lag"
+	maxAlertsenderErrors = "health.max-alertsender-errors"
+)
+
+// Config contains the thresholds that determine the health of agent components.
+type Config struct {
+	// EventsourceStall is the period without consumed events after which the event source is deemed unhealthy.
+	EventsourceStall time.Duration `json:"eventsource-stall" yaml:"eventsource-stall"`
+	// AggregatorStall is the period without flushes after which the aggregator is deemed unhealthy.
+	AggregatorStall time.Duration `json:"aggregator-stall" yaml:"aggregator-stall"`
+	// OutputStall is the period without successful publishes after which the output is deemed unhealthy.
+	OutputStall time.Duration `json:"output-stall" yaml:"output-stall"`
+	// MaxQueueLag is the max delay between the event occurrence and its flush to outputs.
+	MaxQueueLag time.Duration `json:"max-queue-lag" yaml:"max-queue-lag"`
+	// MaxAlertsenderErrors is the number of consecutive alert sender failures after which the sender is deemed unhealthy.
+	MaxAlertsenderErrors int `json:"max-alertsender-errors" yaml:"max-alertsender-errors"`
+}
+
+// InitFromViper initializes health config from Viper.
+func (c *Config) InitFromViper(v *viper.Viper) {
+	c.EventsourceStall = v.GetDuration(eventsourceStall)
+	c.AggregatorStall = v.GetDuration(aggregatorStall)
+	c.OutputStall = v.GetDuration(outputStall)
+	c.MaxQueueLag = v.GetDuration(maxQueueLag)
+	c.MaxAlertsenderErrors = v.GetInt(maxAlertsenderErrors)
+}
+
+// AddFlags adds health config flags to the set.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Duration(eventsourceStall, time.Minute*5, "Specifies the period without consumed events after which the event source is deemed unhealthy")
+	flags.Duration(aggregatorStall, time.Minute, "Specifies the period without flushes after which the aggregator is deemed unhealthy")
+	flags.Duration(outputStall, time.Minute*5, "Specifies the period without successful publishes after which the output is deemed unhealthy")
+	flags.Duration(maxQueueLag, time.Second*30, "Specifies the max delay between the event occurrence and its flush to outputs before the queue is deemed degraded")
+	flags.Int(maxAlertsenderErrors, 3, "Specifies the number of consecutive alert sender failures after which the sender is deemed unhealthy")
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package health evaluates the health and readiness of agent components.
+// Components register checks that are evaluated against the configured
+// thresholds each time the health report is requested.
+package health
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/rabbitstack/fibratus/pkg/health/config"
+)
+
+// Status represents the health status of the component.
+type Status string
+
+const (
+	// Healthy indicates the component operates normally.
+	Healthy Status = "healthy"
+	// Degraded indicates the component operates with reduced functionality.
+	Degraded Status = "degraded"
+	// Unhealthy indicates the component is not operational.
+	Unhealthy Status = "unhealthy"
+)
+
+// severity returns the ordinal used to determine the worst status.
+func (s Status) severity() int {
+	switch s {
+	case Degraded:
+		return 1
+	case Unhealthy:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Component represents the health state of the agent component.
+type Component struct {
+	// Name identifies the component.
+	Name string `json:"name"`
+	// Status is the health status of the component.
+	Status Status `json:"status"`
+	// Ready indicates if the component is initialized and ready to do its work.
+	Ready bool `json:"ready"`
+	// Message explains why the component is not healthy.
+	Message string `json:"message,omitempty"`
+	// Details contains component specific diagnostics.
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// Report is the health report of all registered components.
+type Report struct {
+	// Status is the worst status of all components.
+	Status Status `json:"status"`
+	// Ready indicates if all components are ready and none of them is unhealthy.
+	Ready bool `json:"ready"`
+	// Components contains the health state of each component.
+	Components []Component `json:"components"`
+}
+
+// Check evaluates the health of the component against the thresholds.
+type Check func(c config.Config) Component
+
+var checks = struct {
+	sync.RWMutex
+	m map[string]Check
+}{m: make(map[string]Check)}
+
+// Register registers the component health check. If the check
+// with the same name is already registered, it is overridden.
+func Register(name string, check Check) {
+	checks.Lock()
+	defer checks.Unlock()
+	checks.m[name] = check
+}
+
+// Unregister removes the component health check.
+func Unregister(name string) {
+	checks.Lock()
+	defer checks.Unlock()
+	delete(checks.m, name)
+}
+
+// Evaluate runs all registered checks and builds the health report.
+// Components are sorted by name.
+func Evaluate(c config.Config) Report {
+	checks.RLock()
+	defer checks.RUnlock()
+
+	report := Report{Status: Healthy, Ready: true, Components: make([]Component, 0, len(checks.m))}
+	for name, check := range checks.m {
+		comp := check(c)
+		comp.Name = name
+		if comp.Status == "" {
+			comp.Status = Healthy
+		}
+		if comp.Status.severity() > report.Status.severity() {
+			report.Status = comp.Status
+		}
+		if !comp.Ready || comp.Status == Unhealthy {
+			report.Ready = false
+		}
+		report.Components = append(report.Components, comp)
+	}
+	sort.Slice(report.Components, func(i, j int) bool { return report.Components[i].Name < report.Components[j].Name })
+
+	return report
+}
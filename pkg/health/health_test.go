@@ -0,0 +1,126 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/health/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	c := config.Config{MaxQueueLag: time.Second}
+
+	report := Evaluate(c)
+	assert.Equal(t, Healthy, report.Status)
+	assert.True(t, report.Ready)
+	assert.Empty(t, report.Components)
+
+	Register("output", func(config.Config) Component { return Component{Status: Healthy, Ready: false} })
+	Register("aggregator", func(config.Config) Component { return Component{Ready: true} })
+	Register("queue", func(c config.Config) Component {
+		return Component{Status: Degraded, Ready: true, Message: "lag exceeds " + c.MaxQueueLag.String()}
+	})
+	defer func() {
+		Unregister("output")
+		Unregister("aggregator")
+		Unregister("queue")
+	}()
+
+	report = Evaluate(c)
+	assert.Equal(t, Degraded, report.Status)
+	assert.False(t, report.Ready)
+	require.Len(t, report.Components, 3)
+	assert.Equal(t, "aggregator", report.Components[0].Name)
+	assert.Equal(t, Healthy, report.Components[0].Status)
+	assert.Equal(t, "output", report.Components[1].Name)
+	assert.Equal(t, "queue", report.Components[2].Name)
+	assert.Equal(t, "lag exceeds 1s", report.Components[2].Message)
+
+	Register("output", func(config.Config) Component { return Component{Status: Unhealthy, Ready: true} })
+	report = Evaluate(c)
+	assert.Equal(t, Unhealthy, report.Status)
+	assert.False(t, report.Ready)
+
+	Unregister("output")
+	report = Evaluate(c)
+	assert.Equal(t, Degraded, report.Status)
+	assert.True(t, report.Ready)
+}
+
+func TestProbe(t *testing.T) {
+	var p Probe
+	assert.False(t, p.State().IsFailing())
+
+	p.Failure(errors.New("connection refused"))
+	p.Failure(errors.New("connection reset"))
+	state := p.State()
+	assert.True(t, state.IsFailing())
+	assert.Equal(t, 2, state.ConsecutiveFailures)
+	assert.Equal(t, uint64(2), state.Failures)
+	assert.Equal(t, "connection reset", state.LastError)
+	assert.False(t, state.FailingSince.IsZero())
+	assert.Equal(t, "connection reset", state.Details()["last_error"])
+
+	p.Success()
+	state = p.State()
+	assert.False(t, state.IsFailing())
+	assert.True(t, state.FailingSince.IsZero())
+	assert.Equal(t, uint64(2), state.Failures)
+	assert.NotContains(t, state.Details(), "consecutive_failures")
+}
+
+func TestMerge(t *testing.T) {
+	now := time.Now()
+
+	healthy := ProbeState{LastSuccess: now, LastFailure: now.Add(-time.Minute), LastError: "timeout", Failures: 1}
+	failing := ProbeState{
+		LastSuccess:         now.Add(-time.Minute * 5),
+		LastFailure:         now.Add(time.Second),
+		FailingSince:        now.Add(-time.Minute * 4),
+		LastError:           "connection refused",
+		ConsecutiveFailures: 3,
+		Failures:            3,
+	}
+
+	state := Merge(healthy, failing)
+	assert.True(t, state.IsFailing())
+	assert.Equal(t, "connection refused", state.LastError)
+	assert.Equal(t, uint64(4), state.Failures)
+	assert.Equal(t, now, state.LastSuccess)
+	assert.Equal(t, now.Add(-time.Minute*4), state.FailingSince)
+
+	failing.LastFailure = now.Add(-time.Second)
+	state = Merge(healthy, failing)
+	assert.False(t, state.IsFailing())
+}
+
+func TestActivity(t *testing.T) {
+	a := NewActivity()
+	a.changed = time.Now().Add(-time.Minute)
+
+	assert.True(t, a.Observe(0) >= time.Minute)
+	assert.True(t, a.Observe(10) < time.Second)
+	a.changed = time.Now().Add(-time.Minute)
+	assert.True(t, a.Observe(10) >= time.Minute)
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// Probe records the outcome of operations carried out by the component,
+// such as publishing the batch to the output or sending the alert.
+type Probe struct {
+	mu    sync.RWMutex
+	state ProbeState
+}
+
+// ProbeState is the snapshot of the probe state.
+type ProbeState struct {
+	// LastSuccess is the time of the last successful operation.
+	LastSuccess time.Time
+	// LastFailure is the time of the last failed operation.
+	LastFailure time.Time
+	// FailingSince is the time of the first failure after the last successful operation.
+	FailingSince time.Time
+	// LastError is the error of the last failed operation.
+	LastError string
+	// ConsecutiveFailures is the number of failed operations since the last successful operation.
+	ConsecutiveFailures int
+	// Failures is the total number of failed operations.
+	Failures uint64
+}
+
+// Success records the successful operation.
+func (p *Probe) Success() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.state.LastSuccess = time.Now()
+	p.state.FailingSince = time.Time{}
+	p.state.ConsecutiveFailures = 0
+}
+
+// Failure records the failed operation.
+func (p *Probe) Failure(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	if p.state.ConsecutiveFailures == 0 {
+		p.state.FailingSince = now
+	}
+	p.state.LastFailure = now
+	p.state.LastError = err.Error()
+	p.state.ConsecutiveFailures++
+	p.state.Failures++
+}
+
+// State returns the snapshot of the probe state.
+func (p *Probe) State() ProbeState {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.state
+}
+
+// IsFailing determines if the last operation failed.
+func (s ProbeState) IsFailing() bool { return s.ConsecutiveFailures > 0 }
+
+// Details returns the probe state as component details.
+func (s ProbeState) Details() map[string]any {
+	details := map[string]any{"failures": s.Failures}
+	if !s.LastSuccess.IsZero() {
+		details["last_success"] = s.LastSuccess
+	}
+	if !s.LastFailure.IsZero() {
+		details["last_failure"] = s.LastFailure
+		details["last_error"] = s.LastError
+	}
+	if s.ConsecutiveFailures > 0 {
+		details["consecutive_failures"] = s.ConsecutiveFailures
+	}
+	return details
+}
+
+// Merge combines the states of probes that track the same component,
+// for example, multiple clients of the same output.
+func Merge(states ...ProbeState) ProbeState {
+	var merged ProbeState
+	for _, s := range states {
+		if s.LastSuccess.After(merged.LastSuccess) {
+			merged.LastSuccess = s.LastSuccess
+		}
+		if s.LastFailure.After(merged.LastFailure) {
+			merged.LastFailure = s.LastFailure
+			merged.LastError = s.LastError
+		}
+		merged.Failures += s.Failures
+	}
+	// the component is failing if the most recent
+	// operation across all probes failed
+	if merged.LastFailure.After(merged.LastSuccess) {
+		for _, s := range states {
+			if !s.IsFailing() {
+				continue
+			}
+			merged.ConsecutiveFailures += s.ConsecutiveFailures
+			if merged.FailingSince.IsZero() || s.FailingSince.Before(merged.FailingSince) {
+				merged.FailingSince = s.FailingSince
+			}
+		}
+	}
+	return merged
+}
+
+// Activity tracks the progress of the monotonically increasing
+// counter, such as the number of consumed events, to determine
+// for how long the component has been idle.
+type Activity struct {
+	mu      sync.Mutex
+	value   int64
+	changed time.Time
+}
+
+// NewActivity creates a new activity tracker.
+func NewActivity() *Activity {
+	return &Activity{changed: time.Now()}
+}
+
+// Observe records the current counter value and returns
+// the time elapsed since the counter value last changed.
+func (a *Activity) Observe(value int64) time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := time.Now()
+	if value != a.value {
+		a.value = value
+		a.changed = now
+	}
+	return now.Sub(a.changed)
+}
@@ -0,0 +1,445 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package history
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/rabbitstack/fibratus/pkg/callstack"
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/event/params"
+	"github.com/rabbitstack/fibratus/pkg/filter"
+	hconfig "github.com/rabbitstack/fibratus/pkg/history/config"
+	"github.com/rabbitstack/fibratus/pkg/ps"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+)
+
+var (
+	// eventsCount represents the number of events retained in the buffer
+	eventsCount = expvar.NewInt("history.events")
+	// eventsBytes represents the approximate memory occupied by retained events
+	eventsBytes = expvar.NewInt("history.bytes")
+	// eventsEvicted counts evicted events by reason
+	eventsEvicted = expvar.NewMap("history.events.evicted")
+
+	// ErrSequenceFilter is returned when the query contains a sequence expression.
+	ErrSequenceFilter = errors.New("sequence expressions are not supported in history queries")
+)
+
+const (
+	// evictReasonCount denotes the event was evicted because the max number of events was reached
+	evictReasonCount = "count"
+	// evictReasonMemory denotes the event was evicted because the memory cap was reached
+	evictReasonMemory = "memory"
+	// evictReasonAge denotes the event was evicted because it is older than the max age
+	evictReasonAge = "age"
+)
+
+// maxAncestry limits the number of ancestors visited when
+// resolving if the process descends from the offending process
+const maxAncestry = 32
+
+var (
+	eventOverhead = int(unsafe.Sizeof(event.Event{}))
+	paramOverhead = int(unsafe.Sizeof(event.Param{})) + 48
+	frameOverhead = int(unsafe.Sizeof(callstack.Frame{}))
+)
+
+// entry is the retained event along with the
+// process identifier and its approximate size.
+type entry struct {
+	evt  *event.Event
+	uuid uint64
+	size int
+}
+
+// Query specifies the criteria for looking up retained events.
+type Query struct {
+	// Filter is the filter expression the events have to satisfy.
+	Filter string
+	// PID restricts the events to the ones generated by the process
+	// identifier. Zero value matches events from all processes.
+	PID uint32
+	// UUID restricts the events to the ones generated by the process
+	// with the given unique identifier.
+	UUID uint64
+	// From is the lower bound of the event timestamp.
+	From time.Time
+	// To is the upper bound of the event timestamp.
+	To time.Time
+	// Limit is the max number of the most recent events returned.
+	Limit int
+}
+
+// Buffer is the event listener that retains the most recent events
+// in the bounded ring. The number of retained events is limited by
+// the max number of events, the approximate memory the events occupy
+// and their age. When any limit is reached, the oldest events are
+// evicted. Retained events are indexed by process identifier, process
+// unique identifier, and time, so the surrounding activity of the
+// alert can be looked up after the events are already gone to outputs.
+//
+// The buffer stores a detached copy of each event, since the original
+// event is mutated by transformers once it leaves the listener chain.
+type Buffer struct {
+	config  hconfig.Config
+	cfg     *config.Config
+	psnap   ps.Snapshotter
+	enqueue bool
+
+	mu    sync.RWMutex
+	ring  []*entry
+	head  int
+	n     int
+	size  int
+	pids  map[uint32][]*entry
+	uuids map[uint64][]*entry
+
+	// qmu serializes filter evaluation since
+	// filter accessors may lazily enrich events
+	qmu sync.Mutex
+}
+
+// NewBuffer creates a new recent events buffer. The enqueue argument
+// determines if the buffer, acting as the event listener, agrees to
+// push events to the output queue.
+func NewBuffer(cfg *config.Config, psnap ps.Snapshotter, enqueue bool) *Buffer {
+	c := cfg.History
+	if c.MaxEvents <= 0 {
+		c.MaxEvents = 100000
+	}
+	return &Buffer{
+		config:  c,
+		cfg:     cfg,
+		psnap:   psnap,
+		enqueue: enqueue,
+		ring:    make([]*entry, c.MaxEvents),
+		pids:    make(map[uint32][]*entry),
+		uuids:   make(map[uint64][]*entry),
+	}
+}
+
+// ProcessEvent stores the copy of the event in the buffer.
+func (b *Buffer) ProcessEvent(e *event.Event) (bool, error) {
+	ent := &entry{evt: snapshot(e)}
+	ent.size = sizeOf(ent.evt)
+	if e.PS != nil {
+		ent.uuid = e.PS.UUID()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.config.MaxAge > 0 {
+		for b.n > 0 && e.Timestamp.Sub(b.at(0).evt.Timestamp) > b.config.MaxAge {
+			b.evict(evictReasonAge)
+		}
+	}
+	for b.n > 0 && b.n >= len(b.ring) {
+		b.evict(evictReasonCount)
+	}
+	if maxMem := b.config.MaxMemoryBytes(); maxMem > 0 {
+		for b.n > 0 && b.size+ent.size > maxMem {
+			b.evict(evictReasonMemory)
+		}
+	}
+
+	b.ring[(b.head+b.n)%len(b.ring)] = ent
+	b.n++
+	b.size += ent.size
+	b.pids[ent.evt.PID] = append(b.pids[ent.evt.PID], ent)
+	if ent.uuid != 0 {
+		b.uuids[ent.uuid] = append(b.uuids[ent.uuid], ent)
+	}
+	eventsCount.Add(1)
+	eventsBytes.Add(int64(ent.size))
+
+	return true, nil
+}
+
+// CanEnqueue indicates if the buffer can push events to the output queue.
+func (b *Buffer) CanEnqueue() bool { return b.enqueue }
+
+// Len returns the number of retained events.
+func (b *Buffer) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.n
+}
+
+// Size returns the approximate memory in bytes occupied by retained events.
+func (b *Buffer) Size() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.size
+}
+
+// Query returns retained events that satisfy the query criteria in
+// chronological order. If the limit is given, only the most recent
+// events up to the limit are returned.
+func (b *Buffer) Query(q Query) ([]*event.Event, error) {
+	var f filter.Filter
+	if q.Filter != "" {
+		f = filter.New(q.Filter, b.cfg, filter.WithPSnapshotter(b.psnap))
+		if err := f.Compile(); err != nil {
+			return nil, fmt.Errorf("invalid filter expression: %v", err)
+		}
+		if f.IsSequence() {
+			return nil, ErrSequenceFilter
+		}
+	}
+
+	candidates := b.candidates(q)
+
+	b.qmu.Lock()
+	defer b.qmu.Unlock()
+
+	evts := make([]*event.Event, 0)
+	for i := len(candidates) - 1; i >= 0; i-- {
+		ent := candidates[i]
+		e := ent.evt
+		if !q.From.IsZero() && e.Timestamp.Before(q.From) {
+			continue
+		}
+		if !q.To.IsZero() && e.Timestamp.After(q.To) {
+			continue
+		}
+		if q.PID != 0 && e.PID != q.PID {
+			continue
+		}
+		if q.UUID != 0 && ent.uuid != q.UUID {
+			continue
+		}
+		if f != nil && !f.Eval(e) {
+			continue
+		}
+		evts = append(evts, e)
+		if q.Limit > 0 && len(evts) == q.Limit {
+			break
+		}
+	}
+	slices.Reverse(evts)
+
+	return evts, nil
+}
+
+// ProcessTree returns up to n most recent events generated by the
+// process tree of the given events. The process tree consists of
+// processes that produced the events, their ancestors and descendants.
+// The given events are excluded from the result.
+func (b *Buffer) ProcessTree(evts []*event.Event, n int) []*event.Event {
+	if n <= 0 {
+		return nil
+	}
+
+	procs := make(map[uint64]bool)
+	tree := make(map[uint64]bool)
+	seqs := make(map[uint64]bool)
+	for _, e := range evts {
+		seqs[e.Seq] = true
+		if e.PS == nil {
+			continue
+		}
+		procs[e.PS.UUID()] = true
+		tree[e.PS.UUID()] = true
+		pstypes.Walk(func(proc *pstypes.PS) { tree[proc.UUID()] = true }, e.PS)
+	}
+	if len(tree) == 0 {
+		return nil
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	ctx := make([]*event.Event, 0, n)
+	for i := b.n - 1; i >= 0 && len(ctx) < n; i-- {
+		ent := b.at(i)
+		if seqs[ent.evt.Seq] {
+			continue
+		}
+		if tree[ent.uuid] || descends(ent.evt.PS, procs) {
+			ctx = append(ctx, ent.evt)
+		}
+	}
+	slices.Reverse(ctx)
+
+	return ctx
+}
+
+// candidates returns the entries that may satisfy the query. Index lists
+// are used when the query is restricted to the process. Otherwise, the time
+// range narrows the ring section. The ring is ordered by event arrival time
+// which closely follows the event timestamp.
+func (b *Buffer) candidates(q Query) []*entry {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	switch {
+	case q.UUID != 0:
+		return slices.Clone(b.uuids[q.UUID])
+	case q.PID != 0:
+		return slices.Clone(b.pids[q.PID])
+	}
+
+	start, end := 0, b.n
+	if !q.From.IsZero() {
+		start = sort.Search(b.n, func(i int) bool { return !b.at(i).evt.Timestamp.Before(q.From) })
+	}
+	if !q.To.IsZero() {
+		end = sort.Search(b.n, func(i int) bool { return b.at(i).evt.Timestamp.After(q.To) })
+	}
+	if start >= end {
+		return nil
+	}
+	entries := make([]*entry, 0, end-start)
+	for i := start; i < end; i++ {
+		entries = append(entries, b.at(i))
+	}
+	return entries
+}
+
+// at returns the i-th oldest entry in the ring.
+func (b *Buffer) at(i int) *entry { return b.ring[(b.head+i)%len(b.ring)] }
+
+// evict removes the oldest entry from the ring and indices. Since events
+// are evicted in arrival order, the evicted entry is always at the head of
+// its index lists.
+func (b *Buffer) evict(reason string) {
+	ent := b.ring[b.head]
+	b.ring[b.head] = nil
+	b.head = (b.head + 1) % len(b.ring)
+	b.n--
+	b.size -= ent.size
+
+	pid := ent.evt.PID
+	if entries := b.pids[pid]; len(entries) > 1 {
+		entries[0] = nil
+		b.pids[pid] = entries[1:]
+	} else {
+		delete(b.pids, pid)
+	}
+	if ent.uuid != 0 {
+		if entries := b.uuids[ent.uuid]; len(entries) > 1 {
+			entries[0] = nil
+			b.uuids[ent.uuid] = entries[1:]
+		} else {
+			delete(b.uuids, ent.uuid)
+		}
+	}
+
+	eventsCount.Add(-1)
+	eventsBytes.Add(-int64(ent.size))
+	eventsEvicted.Add(reason, 1)
+}
+
+// descends determines if the process descends from any of the given processes.
+func descends(proc *pstypes.PS, procs map[uint64]bool) bool {
+	if proc == nil {
+		return false
+	}
+	parent := proc.Parent
+	for depth := 0; parent != nil && depth < maxAncestry; depth++ {
+		if procs[parent.UUID()] {
+			return true
+		}
+		parent = parent.Parent
+	}
+	return false
+}
+
+// snapshot makes the copy of the event that is detached
+// from parameter and metadata changes applied to the
+// original event.
+func snapshot(e *event.Event) *event.Event {
+	evt := &event.Event{
+		Seq:         e.Seq,
+		Timestamp:   e.Timestamp,
+		PID:         e.PID,
+		Tid:         e.Tid,
+		Evasions:    e.Evasions,
+		Type:        e.Type,
+		CPU:         e.CPU,
+		Name:        e.Name,
+		Category:    e.Category,
+		Description: e.Description,
+		Host:        e.Host,
+		Params:      make(event.Params, len(e.Params)),
+		Metadata:    maps.Clone(e.Metadata),
+		PS:          e.PS,
+		Callstack:   e.Callstack,
+	}
+	for name, param := range e.Params {
+		p := *param
+		evt.Params[name] = &p
+	}
+	if evt.Metadata == nil {
+		evt.Metadata = make(event.Metadata)
+	}
+	return evt
+}
+
+// sizeOf approximates the memory occupied by the event. Process
+// state is shared among events and thus not accounted.
+func sizeOf(e *event.Event) int {
+	size := eventOverhead + len(e.Name) + len(e.Description) + len(e.Host)
+	for _, p := range e.Params {
+		size += paramOverhead + len(p.Name) + valueSize(p.Value)
+	}
+	for k, v := range e.Metadata {
+		size += 48 + len(k)
+		if s, ok := v.(string); ok {
+			size += len(s)
+		} else {
+			size += 16
+		}
+	}
+	for _, frame := range e.Callstack {
+		size += frameOverhead + len(frame.Symbol) + len(frame.Module)
+	}
+	return size
+}
+
+func valueSize(v params.Value) int {
+	switch val := v.(type) {
+	case string:
+		return len(val)
+	case []string:
+		n := 0
+		for _, s := range val {
+			n += 16 + len(s)
+		}
+		return n
+	case []byte:
+		return len(val)
+	case net.IP:
+		return len(val)
+	default:
+		return 8
+	}
+}
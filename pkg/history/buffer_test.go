@@ -0,0 +1,234 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package history
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/event/params"
+	hconfig "github.com/rabbitstack/fibratus/pkg/history/config"
+	"github.com/rabbitstack/fibratus/pkg/ps"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Now()
+
+func newConfig(c hconfig.Config) *config.Config {
+	return &config.Config{
+		History: c,
+		Filters: &config.Filters{},
+	}
+}
+
+func newProc(pid uint32, name string, parent *pstypes.PS) *pstypes.PS {
+	return &pstypes.PS{PID: pid, Name: name, Parent: parent, StartTime: now.Add(-time.Duration(pid) * time.Hour)}
+}
+
+func newEvent(seq uint64, ts time.Time, proc *pstypes.PS, file string) *event.Event {
+	return &event.Event{
+		Seq:       seq,
+		Type:      event.CreateFile,
+		Name:      "CreateFile",
+		Category:  event.File,
+		Timestamp: ts,
+		PID:       proc.PID,
+		Tid:       2484,
+		PS:        proc,
+		Params: event.Params{
+			params.FilePath: {Name: params.FilePath, Type: params.UnicodeString, Value: file},
+		},
+		Metadata: make(map[event.MetadataKey]any),
+	}
+}
+
+func seqs(evts []*event.Event) []uint64 {
+	s := make([]uint64, 0, len(evts))
+	for _, e := range evts {
+		s = append(s, e.Seq)
+	}
+	return s
+}
+
+func TestBufferEviction(t *testing.T) {
+	proc := newProc(1234, "cmd.exe", nil)
+
+	var tests = []struct {
+		name     string
+		config   hconfig.Config
+		evts     func() []*event.Event
+		expected []uint64
+	}{
+		{
+			"max events",
+			hconfig.Config{MaxEvents: 3},
+			func() []*event.Event {
+				evts := make([]*event.Event, 0)
+				for i := 1; i <= 5; i++ {
+					evts = append(evts, newEvent(uint64(i), now, proc, "C:\\Windows\\notepad.exe"))
+				}
+				return evts
+			},
+			[]uint64{3, 4, 5},
+		},
+		{
+			"max memory",
+			hconfig.Config{MaxEvents: 100, MaxMemory: 1},
+			func() []*event.Event {
+				evts := make([]*event.Event, 0)
+				for i := 1; i <= 5; i++ {
+					evts = append(evts, newEvent(uint64(i), now, proc, strings.Repeat("A", 300*1024)))
+				}
+				return evts
+			},
+			[]uint64{3, 4, 5},
+		},
+		{
+			"max age",
+			hconfig.Config{MaxEvents: 100, MaxAge: time.Minute},
+			func() []*event.Event {
+				return []*event.Event{
+					newEvent(1, now.Add(-time.Minute*5), proc, "C:\\Windows\\notepad.exe"),
+					newEvent(2, now.Add(-time.Second*30), proc, "C:\\Windows\\notepad.exe"),
+					newEvent(3, now, proc, "C:\\Windows\\notepad.exe"),
+				}
+			},
+			[]uint64{2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBuffer(newConfig(tt.config), new(ps.SnapshotterMock), false)
+			for _, e := range tt.evts() {
+				_, err := b.ProcessEvent(e)
+				require.NoError(t, err)
+			}
+			assert.Equal(t, len(tt.expected), b.Len())
+			evts, err := b.Query(Query{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, seqs(evts))
+			if tt.config.MaxMemory > 0 {
+				assert.LessOrEqual(t, b.Size(), tt.config.MaxMemoryBytes())
+			}
+		})
+	}
+}
+
+func TestBufferQuery(t *testing.T) {
+	b := NewBuffer(newConfig(hconfig.Config{MaxEvents: 100}), new(ps.SnapshotterMock), false)
+	assert.False(t, b.CanEnqueue())
+
+	cmd := newProc(1234, "cmd.exe", nil)
+	notepad := newProc(5678, "notepad.exe", nil)
+
+	evts := []*event.Event{
+		newEvent(1, now.Add(-time.Minute*10), cmd, "C:\\Windows\\System32\\kernel32.dll"),
+		newEvent(2, now.Add(-time.Minute*8), notepad, "C:\\Temp\\notes.txt"),
+		newEvent(3, now.Add(-time.Minute*6), cmd, "C:\\Temp\\payload.exe"),
+		newEvent(4, now.Add(-time.Minute*4), notepad, "C:\\Temp\\notes.txt"),
+		newEvent(5, now.Add(-time.Minute*2), cmd, "C:\\Temp\\payload.dll"),
+	}
+	for _, e := range evts {
+		_, err := b.ProcessEvent(e)
+		require.NoError(t, err)
+	}
+
+	var tests = []struct {
+		name     string
+		q        Query
+		expected []uint64
+	}{
+		{"all", Query{}, []uint64{1, 2, 3, 4, 5}},
+		{"limit", Query{Limit: 2}, []uint64{4, 5}},
+		{"pid", Query{PID: 1234}, []uint64{1, 3, 5}},
+		{"uuid", Query{UUID: notepad.UUID()}, []uint64{2, 4}},
+		{"time range", Query{From: now.Add(-time.Minute * 7), To: now.Add(-time.Minute * 3)}, []uint64{3, 4}},
+		{"filter", Query{Filter: "file.path istartswith 'C:\\\\Temp\\\\payload'"}, []uint64{3, 5}},
+		{"filter and pid", Query{Filter: "file.path = 'C:\\\\Temp\\\\notes.txt'", PID: 1234}, []uint64{}},
+		{"pid and time range", Query{PID: 1234, From: now.Add(-time.Minute * 7)}, []uint64{3, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evts, err := b.Query(tt.q)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, seqs(evts))
+		})
+	}
+
+	_, err := b.Query(Query{Filter: "file.path = "})
+	require.Error(t, err)
+	_, err = b.Query(Query{Filter: "sequence |evt.name = 'CreateProcess'| |evt.name = 'CreateFile'|"})
+	require.ErrorIs(t, err, ErrSequenceFilter)
+}
+
+func TestBufferSnapshot(t *testing.T) {
+	b := NewBuffer(newConfig(hconfig.Config{MaxEvents: 10}), new(ps.SnapshotterMock), true)
+	assert.True(t, b.CanEnqueue())
+
+	e := newEvent(1, now, newProc(1234, "cmd.exe", nil), "C:\\Temp\\payload.exe")
+	_, err := b.ProcessEvent(e)
+	require.NoError(t, err)
+
+	// transformers mutate the event once it leaves the listener chain
+	e.Params.Remove(params.FilePath)
+	e.AddMeta("tag", "malware")
+
+	evts, err := b.Query(Query{})
+	require.NoError(t, err)
+	require.Len(t, evts, 1)
+	assert.Equal(t, "C:\\Temp\\payload.exe", evts[0].GetParamAsString(params.FilePath))
+	assert.False(t, evts[0].ContainsMeta("tag"))
+}
+
+func TestBufferProcessTree(t *testing.T) {
+	b := NewBuffer(newConfig(hconfig.Config{MaxEvents: 100}), new(ps.SnapshotterMock), false)
+
+	explorer := newProc(1000, "explorer.exe", nil)
+	cmd := newProc(2000, "cmd.exe", explorer)
+	powershell := newProc(3000, "powershell.exe", cmd)
+	calc := newProc(4000, "calc.exe", explorer)
+	svchost := newProc(5000, "svchost.exe", nil)
+
+	evts := []*event.Event{
+		newEvent(1, now, explorer, "C:\\Users\\admin\\Desktop"),
+		newEvent(2, now, svchost, "C:\\Windows\\System32\\config"),
+		newEvent(3, now, cmd, "C:\\Temp\\run.bat"),
+		newEvent(4, now, calc, "C:\\Windows\\System32\\calc.exe"),
+		newEvent(5, now, powershell, "C:\\Temp\\payload.ps1"),
+		newEvent(6, now, cmd, "C:\\Temp\\payload.exe"),
+	}
+	for _, e := range evts {
+		_, err := b.ProcessEvent(e)
+		require.NoError(t, err)
+	}
+
+	// the offending event is produced by cmd.exe. The process tree
+	// contains explorer.exe as the ancestor, and powershell.exe as
+	// the descendant, whereas the calc.exe sibling is not included
+	assert.Equal(t, []uint64{1, 3, 5}, seqs(b.ProcessTree(evts[5:], 10)))
+	assert.Equal(t, []uint64{3, 5}, seqs(b.ProcessTree(evts[5:], 2)))
+	assert.Empty(t, b.ProcessTree(evts[5:], 0))
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	enabled      = "history.enabled"
+	maxEvents    = "history.max-events"
+	maxMemory    = "history.max-memory"
+	maxAge       = "history.max-age"
+	alertContext = "history.alert-context"
+)
+
+// Config contains the settings that influence the behaviour of the recent events buffer.
+type Config struct {
+	// Enabled indicates if recent events are retained in the buffer.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// MaxEvents is the maximum number of events retained in the buffer.
+	MaxEvents int `json:"max-events" yaml:"max-events"`
+	// MaxMemory is the approximate amount of memory, in megabytes, the retained events can occupy.
+	MaxMemory int `json:"max-memory" yaml:"max-memory"`
+	// MaxAge determines for how long the events are retained in the buffer.
+	MaxAge time.Duration `json:"max-age" yaml:"max-age"`
+	// AlertContext is the number of recent events of the offending process
+	// tree attached to rule alerts. Zero disables the alert context.
+	AlertContext int `json:"alert-context" yaml:"alert-context"`
+}
+
+// InitFromViper initializes recent events buffer config from Viper.
+func (c *Config) InitFromViper(v *viper.Viper) {
+	c.Enabled = v.GetBool(enabled)
+	c.MaxEvents = v.GetInt(maxEvents)
+	c.MaxMemory = v.GetInt(maxMemory)
+	c.MaxAge = v.GetDuration(maxAge)
+	c.AlertContext = v.GetInt(alertContext)
+}
+
+// MaxMemoryBytes returns the memory cap in bytes.
+func (c Config) MaxMemoryBytes() int { return c.MaxMemory * 1024 * 1024 }
+
+// AddFlags adds recent events buffer config flags to the set.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool(enabled, false, "Indicates if recent events are retained in the in-memory buffer for triage")
+	flags.Int(maxEvents, 100000, "Specifies the maximum number of events retained in the buffer")
+	flags.Int(maxMemory, 128, "Specifies the approximate amount of memory, in megabytes, the retained events can occupy")
+	flags.Duration(maxAge, time.Minute*10, "Determines for how long the events are retained in the buffer")
+	flags.Int(alertContext, 20, "Specifies the number of recent events of the offending process tree attached to rule alerts")
+}
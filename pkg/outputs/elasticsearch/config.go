@@ -41,6 +41,14 @@ const (
 	esTemplateName        = "output.elasticsearch.template-name"
 	esTemplateConfig      = "output.elasticsearch.template-config"
 	esGzipCompression     = "output.elasticsearch.gzip-compression"
+	esAlertsIndexName     = "output.elasticsearch.alerts-index-name"
+	esComposableTemplate  = "output.elasticsearch.composable-template"
+	esDataStream          = "output.elasticsearch.data-stream"
+	esEventsDataStream    = "output.elasticsearch.events-data-stream"
+	esAlertsDataStream    = "output.elasticsearch.alerts-data-stream"
+	esILMPolicyName       = "output.elasticsearch.ilm-policy-name"
+	esILMPolicy           = "output.elasticsearch.ilm-policy"
+	esECS                 = "output.elasticsearch.ecs"
 )
 
 // Config contains the options for tweaking the output behaviour.
@@ -78,6 +86,29 @@ type Config struct {
 	TemplateConfig string `mapstructure:"template-config"`
 	// GzipCompression specifies if gzip compression is enabled.
 	GzipCompression bool `mapstructure:"gzip-compression"`
+	// AlertsIndexName represents the target index for events that triggered any of the rules. If empty,
+	// all events are written to the index specified by the IndexName option.
+	AlertsIndexName string `mapstructure:"alerts-index-name"`
+	// ComposableTemplate indicates if the composable index template is created instead of the legacy template.
+	ComposableTemplate bool `mapstructure:"composable-template"`
+	// DataStream indicates if events are written to data streams instead of indices.
+	DataStream bool `mapstructure:"data-stream"`
+	// EventsDataStream is the name of the data stream for raw events.
+	EventsDataStream string `mapstructure:"events-data-stream"`
+	// AlertsDataStream is the name of the data stream for events that triggered any of the rules.
+	AlertsDataStream string `mapstructure:"alerts-data-stream"`
+	// ILMPolicyName is the name of the index lifecycle management policy attached to the index template.
+	ILMPolicyName string `mapstructure:"ilm-policy-name"`
+	// ILMPolicy contains the full JSON body of the index lifecycle management policy.
+	ILMPolicy string `mapstructure:"ilm-policy"`
+	// ECS indicates if events are mapped to Elastic Common Schema documents.
+	ECS bool `mapstructure:"ecs"`
+}
+
+// useComposableTemplate determines whether the composable index template
+// is required. Data streams can only be backed by composable templates.
+func (c Config) useComposableTemplate() bool {
+	return c.ComposableTemplate || c.DataStream
 }
 
 // AddFlags registers persistent flags.
@@ -98,4 +129,12 @@ func AddFlags(flags *pflag.FlagSet) {
 	flags.String(esIndexName, "fibratus", "Represents the target index for kernel events. It allows time specifiers to create indices per time frame")
 	flags.String(esTemplateConfig, "", "Contains the full JSON body of the index template")
 	flags.Bool(esGzipCompression, false, "Specifies if gzip compression is enabled")
+	flags.String(esAlertsIndexName, "", "Represents the target index for events that triggered any of the rules. It allows time specifiers to create indices per time frame")
+	flags.Bool(esComposableTemplate, false, "Indicates if the composable index template is created instead of the legacy index template")
+	flags.Bool(esDataStream, false, "Indicates if events are written to data streams instead of indices")
+	flags.String(esEventsDataStream, "logs-fibratus.events-default", "Specifies the name of the data stream for raw events")
+	flags.String(esAlertsDataStream, "logs-fibratus.alerts-default", "Specifies the name of the data stream for events that triggered any of the rules")
+	flags.String(esILMPolicyName, "", "Specifies the name of the index lifecycle management policy attached to the index template")
+	flags.String(esILMPolicy, "", "Contains the full JSON body of the index lifecycle management policy")
+	flags.Bool(esECS, false, "Indicates if events are mapped to Elastic Common Schema (ECS) documents")
 }
@@ -0,0 +1,238 @@
+/*
+ * Copyright 2019-2020 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package elasticsearch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/event/params"
+)
+
+// ecsVersion is the version of the Elastic Common Schema the documents conform to
+const ecsVersion = "8.11.0"
+
+// ecsCategories maps event categories to ECS event categories
+var ecsCategories = map[event.Category][]string{
+	event.File:     {"file"},
+	event.Registry: {"registry"},
+	event.Net:      {"network"},
+	event.Process:  {"process"},
+	event.Thread:   {"process"},
+	event.Module:   {"library"},
+	event.Driver:   {"driver"},
+}
+
+// ecsTypes maps event names to ECS event types
+var ecsTypes = map[string][]string{
+	"CreateProcess":      {"start"},
+	"TerminateProcess":   {"end"},
+	"CreateThread":       {"start"},
+	"TerminateThread":    {"end"},
+	"CreateFile":         {"creation"},
+	"WriteFile":          {"change"},
+	"ReadFile":           {"access"},
+	"DeleteFile":         {"deletion"},
+	"RenameFile":         {"change"},
+	"SetFileInformation": {"change"},
+	"LoadModule":         {"start"},
+	"UnloadModule":       {"end"},
+	"RegCreateKey":       {"creation"},
+	"RegDeleteKey":       {"deletion"},
+	"RegSetValue":        {"change"},
+	"RegDeleteValue":     {"deletion"},
+	"RegOpenKey":         {"access"},
+	"RegQueryKey":        {"access"},
+	"RegQueryValue":      {"access"},
+	"ConnectTCPv4":       {"connection", "start"},
+	"ConnectTCPv6":       {"connection", "start"},
+	"AcceptTCPv4":        {"connection", "start"},
+	"AcceptTCPv6":        {"connection", "start"},
+	"DisconnectTCPv4":    {"connection", "end"},
+	"DisconnectTCPv6":    {"connection", "end"},
+	"QueryDns":           {"protocol"},
+	"ReplyDns":           {"protocol"},
+}
+
+// toECS maps the event to the Elastic Common Schema document. Fields without
+// the ECS counterpart are retained under the fibratus namespace.
+func toECS(evt *event.Event) map[string]any {
+	doc := map[string]any{
+		"@timestamp": evt.Timestamp.UTC().Format(time.RFC3339Nano),
+		"message":    evt.Description,
+		"ecs":        map[string]any{"version": ecsVersion},
+		"host": map[string]any{
+			"name":     evt.Host,
+			"hostname": evt.Host,
+			"os":       map[string]any{"family": "windows", "type": "windows"},
+		},
+	}
+
+	e := map[string]any{
+		"kind":     "event",
+		"action":   evt.Name,
+		"code":     evt.Name,
+		"module":   "fibratus",
+		"dataset":  "fibratus." + string(evt.Category),
+		"provider": "fibratus",
+		"sequence": evt.Seq,
+		"created":  evt.Timestamp.UTC().Format(time.RFC3339Nano),
+	}
+	if cats, ok := ecsCategories[evt.Category]; ok {
+		e["category"] = cats
+	}
+	if types, ok := ecsTypes[evt.Name]; ok {
+		e["type"] = types
+	} else {
+		e["type"] = []string{"info"}
+	}
+	if isAlert(evt) {
+		e["kind"] = "alert"
+		doc["rule"] = map[string]any{"name": evt.GetMetaAsString(event.RuleNameKey)}
+	}
+	doc["event"] = e
+
+	// process and user fields
+	proc := map[string]any{
+		"pid":    evt.PID,
+		"thread": map[string]any{"id": evt.Tid},
+	}
+	if ps := evt.PS; ps != nil {
+		proc["name"] = ps.Name
+		proc["executable"] = ps.Exe
+		proc["command_line"] = ps.Cmdline
+		proc["args"] = ps.Args
+		proc["working_directory"] = ps.Cwd
+		if !ps.StartTime.IsZero() {
+			proc["start"] = ps.StartTime.UTC().Format(time.RFC3339Nano)
+		}
+		parent := map[string]any{"pid": ps.Ppid}
+		if ps.Parent != nil {
+			parent["name"] = ps.Parent.Name
+			parent["executable"] = ps.Parent.Exe
+			parent["command_line"] = ps.Parent.Cmdline
+		}
+		proc["parent"] = parent
+		doc["user"] = map[string]any{
+			"id":     ps.SID,
+			"name":   ps.Username,
+			"domain": ps.Domain,
+		}
+	}
+	doc["process"] = proc
+
+	switch evt.Category {
+	case event.File, event.Module:
+		if path := evt.GetParamAsString(params.FilePath); path != "" {
+			file := map[string]any{"path": path}
+			if n := strings.LastIndexByte(path, '\\'); n >= 0 {
+				file["directory"] = path[:n]
+				file["name"] = path[n+1:]
+			} else {
+				file["name"] = path
+			}
+			if n := strings.LastIndexByte(path, '.'); n >= 0 && n > strings.LastIndexByte(path, '\\') {
+				file["extension"] = strings.ToLower(path[n+1:])
+			}
+			if size, err := evt.Params.GetUint32(params.FileIoSize); err == nil {
+				file["size"] = size
+			}
+			doc["file"] = file
+		}
+	case event.Registry:
+		if path := evt.GetParamAsString(params.RegPath); path != "" {
+			reg := map[string]any{"path": path}
+			if n := strings.IndexByte(path, '\\'); n >= 0 {
+				reg["hive"] = path[:n]
+				reg["key"] = path[n+1:]
+			}
+			if evt.Params.Contains(params.RegValue) {
+				reg["data"] = map[string]any{
+					"type":    evt.GetParamAsString(params.RegValueType),
+					"strings": []string{evt.GetParamAsString(params.RegValue)},
+				}
+			}
+			doc["registry"] = reg
+		}
+	case event.Net:
+		if ip, err := evt.Params.GetIP(params.NetSIP); err == nil {
+			sport, _ := evt.Params.GetUint16(params.NetSport)
+			doc["source"] = map[string]any{"ip": ip.String(), "port": sport}
+		}
+		if ip, err := evt.Params.GetIP(params.NetDIP); err == nil {
+			dport, _ := evt.Params.GetUint16(params.NetDport)
+			doc["destination"] = map[string]any{"ip": ip.String(), "port": dport}
+		}
+		network := make(map[string]any)
+		switch {
+		case strings.Contains(evt.Name, "TCP"):
+			network["transport"] = "tcp"
+		case strings.Contains(evt.Name, "UDP"):
+			network["transport"] = "udp"
+		}
+		switch {
+		case strings.HasSuffix(evt.Name, "v4"):
+			network["type"] = "ipv4"
+		case strings.HasSuffix(evt.Name, "v6"):
+			network["type"] = "ipv6"
+		}
+		switch {
+		case strings.HasPrefix(evt.Name, "Send"), strings.HasPrefix(evt.Name, "Connect"):
+			network["direction"] = "egress"
+		case strings.HasPrefix(evt.Name, "Recv"), strings.HasPrefix(evt.Name, "Accept"):
+			network["direction"] = "ingress"
+		}
+		if size, err := evt.Params.GetUint32(params.NetSize); err == nil {
+			network["bytes"] = size
+		}
+		if strings.HasSuffix(evt.Name, "Dns") {
+			network["protocol"] = "dns"
+			doc["dns"] = map[string]any{
+				"question": map[string]any{
+					"name": evt.GetParamAsString(params.DNSName),
+					"type": evt.GetParamAsString(params.DNSRR),
+				},
+			}
+		}
+		if len(network) > 0 {
+			doc["network"] = network
+		}
+	}
+
+	// retain the raw parameters and turn metadata into labels
+	pars := make(map[string]string, len(evt.Params))
+	for name, par := range evt.Params {
+		pars[name] = par.String()
+	}
+	labels := make(map[string]string)
+	for k, v := range evt.Metadata {
+		if k == event.RuleNameKey || k == event.RuleSequenceLinks {
+			continue
+		}
+		labels[strings.ReplaceAll(k.String(), ".", "_")] = fmt.Sprintf("%v", v)
+	}
+	if len(labels) > 0 {
+		doc["labels"] = labels
+	}
+	doc["fibratus"] = map[string]any{"params": pars}
+
+	return doc
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2019-2020 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package elasticsearch
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/event/params"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToECS(t *testing.T) {
+	batch := getBatch()
+	doc := toECS(batch.Events[0])
+
+	assert.Equal(t, "2018-05-03T15:04:05.323Z", doc["@timestamp"])
+	evt := doc["event"].(map[string]any)
+	assert.Equal(t, "event", evt["kind"])
+	assert.Equal(t, "CreateFile", evt["action"])
+	assert.Equal(t, []string{"file"}, evt["category"])
+	assert.Equal(t, []string{"creation"}, evt["type"])
+
+	file := doc["file"].(map[string]any)
+	assert.Equal(t, "user32.dll", file["name"])
+	assert.Equal(t, "dll", file["extension"])
+	assert.Equal(t, "\\Device\\HarddiskVolume2\\Windows\\system32", file["directory"])
+
+	proc := doc["process"].(map[string]any)
+	assert.Equal(t, "firefox.exe", proc["name"])
+	assert.Equal(t, uint32(6304), proc["parent"].(map[string]any)["pid"])
+	assert.Equal(t, "S-1-1-18", doc["user"].(map[string]any)["id"])
+
+	labels := doc["labels"].(map[string]string)
+	assert.Equal(t, "bar", labels["foo"])
+
+	ts, _ := time.Parse(time.RFC3339, "2018-05-03T15:04:05.323Z")
+	e := &event.Event{
+		Type:      event.SendTCPv4,
+		Name:      "SendTCPv4",
+		Category:  event.Net,
+		Timestamp: ts,
+		PID:       859,
+		Params: event.Params{
+			params.NetDport: {Name: params.NetDport, Type: params.Uint16, Value: uint16(443)},
+			params.NetSport: {Name: params.NetSport, Type: params.Uint16, Value: uint16(43123)},
+			params.NetSIP:   {Name: params.NetSIP, Type: params.IPv4, Value: net.ParseIP("127.0.0.1")},
+			params.NetDIP:   {Name: params.NetDIP, Type: params.IPv4, Value: net.ParseIP("216.58.201.174")},
+			params.NetSize:  {Name: params.NetSize, Type: params.Uint32, Value: uint32(512)},
+		},
+		Metadata: map[event.MetadataKey]any{event.RuleNameKey: "Outbound connection to suspicious host"},
+		PS:       &pstypes.PS{Name: "powershell.exe"},
+	}
+	doc = toECS(e)
+
+	assert.Equal(t, "alert", doc["event"].(map[string]any)["kind"])
+	assert.Equal(t, "Outbound connection to suspicious host", doc["rule"].(map[string]any)["name"])
+	require.Contains(t, doc, "destination")
+	assert.Equal(t, "216.58.201.174", doc["destination"].(map[string]any)["ip"])
+	assert.Equal(t, uint16(443), doc["destination"].(map[string]any)["port"])
+	network := doc["network"].(map[string]any)
+	assert.Equal(t, "tcp", network["transport"])
+	assert.Equal(t, "ipv4", network["type"])
+	assert.Equal(t, "egress", network["direction"])
+	assert.Equal(t, uint32(512), network["bytes"])
+	assert.NotContains(t, doc, "labels")
+}
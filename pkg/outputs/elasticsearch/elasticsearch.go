@@ -30,11 +30,15 @@ import (
 	"github.com/rabbitstack/fibratus/pkg/util/tls"
 	log "github.com/sirupsen/logrus"
 	"net/http"
+	"time"
 )
 
 // minElasticVersion is the minimal supported Elasticsearch version
 var minElasticVersion, _ = version.NewVersion("5.5")
 
+// minDataStreamVersion is the minimal Elasticsearch version that supports data streams
+var minDataStreamVersion, _ = version.NewVersion("7.9")
+
 var (
 	// totalBulkedDocs contains the number of total bulked docs
 	totalBulkedDocs = expvar.NewInt("elasticsearch.total.bulked.docs")
@@ -42,6 +46,8 @@ var (
 	committedDocs = expvar.NewInt("elasticsearch.committed.docs")
 	// failedDocs counts the number of docs that failed to commit to Elasticsearch
 	failedDocs = expvar.NewInt("elasticsearch.failed.docs")
+	// failedDocsReasons counts failed docs per error type reported in bulk responses
+	failedDocsReasons = expvar.NewMap("elasticsearch.failed.docs.reasons")
 )
 
 type elasticsearch struct {
@@ -125,27 +131,15 @@ func (e *elasticsearch) Connect() error {
 	if v.LessThan(minElasticVersion) {
 		return fmt.Errorf("required at least Elasticsearch %s but found version %s", minElasticVersion.String(), ver)
 	}
+	if e.config.useComposableTemplate() && v.LessThan(minDataStreamVersion) {
+		return fmt.Errorf("data streams and composable templates require at least Elasticsearch %s but found version %s", minDataStreamVersion.String(), ver)
+	}
 
 	e.client = client
 	e.index.client = client
 
 	bulkProcessor, err := client.BulkProcessor().
-		After(func(executionId int64, requests []elastic.BulkableRequest, response *elastic.BulkResponse, err error) {
-			if err != nil {
-				log.Errorf("failed to execute bulk: %s", err)
-				return
-			}
-
-			if response.Errors {
-				log.Errorf("failed to insert %d documents", len(response.Failed()))
-				for i, fail := range response.Failed() {
-					failedDocs.Add(1)
-					log.Errorf("failed to insert document %d: %v", i, fail.Error)
-				}
-				return
-			}
-			committedDocs.Add(int64(len(requests)))
-		}).
+		After(e.afterBulk).
 		FlushInterval(e.config.FlushPeriod).
 		Workers(e.config.BulkWorkers).
 		Do(context.Background())
@@ -153,6 +147,10 @@ func (e *elasticsearch) Connect() error {
 		return fmt.Errorf("couldn't create Elasticsearch bulk processor: %v", err)
 	}
 
+	err = e.index.putILMPolicy()
+	if err != nil {
+		return err
+	}
 	err = e.index.putTemplate()
 	if err != nil {
 		return err
@@ -176,15 +174,73 @@ func (e *elasticsearch) Publish(batch *event.Batch) error {
 		// create the bulk index request for each event in the batch.
 		// We already have a valid JSON body, so just pass the raw
 		// JSON message as request document
-		e.bulkProcessor.Add(newBulkIndexRequest(indexName, evt))
+		e.bulkProcessor.Add(e.newBulkIndexRequest(indexName, evt))
 		totalBulkedDocs.Add(1)
 	}
 	return nil
 }
 
-func newBulkIndexRequest(indexName string, evt *event.Event) *elastic.BulkIndexRequest {
+func (e *elasticsearch) newBulkIndexRequest(indexName string, evt *event.Event) *elastic.BulkIndexRequest {
+	req := elastic.NewBulkIndexRequest().Index(indexName)
+	if e.config.DataStream {
+		// data streams are append-only and only accept the create operation
+		req.OpType("create")
+	}
+	if e.config.ECS {
+		return req.Doc(toECS(evt))
+	}
 	kjson := evt.MarshalJSON()
-	return elastic.NewBulkIndexRequest().Index(indexName).Doc(json.RawMessage(kjson))
+	if e.config.DataStream {
+		// data streams require the @timestamp field
+		kjson = withTimestamp(kjson, evt.Timestamp)
+	}
+	return req.Doc(json.RawMessage(kjson))
+}
+
+// withTimestamp injects the @timestamp field into the JSON document.
+func withTimestamp(doc []byte, ts time.Time) []byte {
+	if len(doc) < 2 || doc[0] != '{' {
+		return doc
+	}
+	b := make([]byte, 0, len(doc)+48)
+	b = append(b, `{"@timestamp":"`...)
+	b = ts.UTC().AppendFormat(b, time.RFC3339Nano)
+	b = append(b, '"')
+	if doc[1] != '}' {
+		b = append(b, ',')
+	}
+	return append(b, doc[1:]...)
+}
+
+// afterBulk is invoked after the bulk request is committed. It
+// reports the errors of each document that failed to index.
+func (e *elasticsearch) afterBulk(executionID int64, requests []elastic.BulkableRequest, response *elastic.BulkResponse, err error) {
+	if err != nil {
+		failedDocs.Add(int64(len(requests)))
+		log.Errorf("failed to execute bulk %d: %s", executionID, err)
+		return
+	}
+	if response == nil || !response.Errors {
+		committedDocs.Add(int64(len(requests)))
+		return
+	}
+
+	failed := response.Failed()
+	committedDocs.Add(int64(len(requests) - len(failed)))
+	log.Errorf("failed to insert %d out of %d documents in bulk %d", len(failed), len(requests), executionID)
+	for _, item := range failed {
+		failedDocs.Add(1)
+		if item.Error == nil {
+			failedDocsReasons.Add("unknown", 1)
+			log.Errorf("failed to insert document into %s: status %d", item.Index, item.Status)
+			continue
+		}
+		failedDocsReasons.Add(item.Error.Type, 1)
+		log.Errorf("failed to insert document into %s: status %d, %s: %s", item.Index, item.Status, item.Error.Type, item.Error.Reason)
+		if item.Error.CausedBy != nil {
+			log.Errorf("failed to insert document into %s: caused by %v", item.Index, item.Error.CausedBy)
+		}
+	}
 }
 
 func (e *elasticsearch) Close() error {
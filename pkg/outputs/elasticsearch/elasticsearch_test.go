@@ -21,12 +21,14 @@ package elasticsearch
 import (
 	"bytes"
 	"encoding/json"
+	"expvar"
 	"github.com/rabbitstack/fibratus/pkg/util/va"
 	"golang.org/x/sys/windows"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -157,6 +159,123 @@ func TestElasticsearchPublish(t *testing.T) {
 	assert.Equal(t, int64(0), failedDocs.Value())
 }
 
+func TestElasticsearchPublishDataStream(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		policy   []byte
+		template []byte
+		bulk     []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer r.Body.Close()
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/_ilm/policy/fibratus"):
+			if r.Method == http.MethodGet {
+				http.Error(w, `{"error":{"type":"resource_not_found_exception"},"status":404}`, http.StatusNotFound)
+				return
+			}
+			policy = body
+			_, _ = w.Write([]byte(`{"acknowledged":true}`))
+		case strings.HasPrefix(r.URL.Path, "/_index_template/fibratus"):
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			template = body
+			_, _ = w.Write([]byte(`{"acknowledged":true}`))
+		case strings.Contains(r.URL.Path, "_bulk"):
+			bulk = body
+			response := elastic.BulkResponse{
+				Took:   1,
+				Errors: true,
+				Items: []map[string]*elastic.BulkResponseItem{
+					{"create": {Index: "logs-fibratus.events-default", Status: http.StatusCreated}},
+					{"create": {Index: "logs-fibratus.events-default", Status: http.StatusCreated}},
+					{"create": {Index: "logs-fibratus.alerts-default", Status: http.StatusBadRequest, Error: &elastic.ErrorDetails{Type: "mapper_parsing_exception", Reason: "failed to parse field"}}},
+				},
+			}
+			resp, err := json.Marshal(&response)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			_, _ = w.Write(resp)
+		default:
+			ping := elastic.PingResult{
+				Name: "es",
+			}
+			ping.Version.Number = "8.11.0"
+			resp, err := json.Marshal(&ping)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			_, _ = w.Write(resp)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := Config{
+		Servers:          []string{srv.URL},
+		Healthcheck:      false,
+		FlushPeriod:      time.Millisecond * 250,
+		TemplateName:     "fibratus",
+		DataStream:       true,
+		EventsDataStream: "logs-fibratus.events-default",
+		AlertsDataStream: "logs-fibratus.alerts-default",
+		ILMPolicyName:    "fibratus",
+		ECS:              true,
+	}
+
+	es := &elasticsearch{
+		config: cfg,
+		index:  index{config: cfg},
+	}
+
+	require.NoError(t, es.Connect())
+
+	committed, failed := committedDocs.Value(), failedDocs.Value()
+
+	batch := getBatch()
+	batch.Events[2].AddMeta(event.RuleNameKey, "Suspicious DLL access")
+	require.NoError(t, es.Publish(batch))
+
+	time.Sleep(time.Millisecond * 450)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.True(t, bytes.Contains(policy, []byte("rollover")))
+	assert.True(t, bytes.Contains(policy, []byte("max_size")))
+	assert.False(t, bytes.Contains(policy, []byte("max_primary_shard_size")))
+
+	var tmpl map[string]any
+	require.NoError(t, json.Unmarshal(template, &tmpl))
+	assert.Contains(t, tmpl, "data_stream")
+	assert.Equal(t, []any{"logs-fibratus.events-default", "logs-fibratus.alerts-default"}, tmpl["index_patterns"])
+
+	assert.True(t, bytes.Contains(bulk, []byte(`"create"`)))
+	assert.True(t, bytes.Contains(bulk, []byte("logs-fibratus.alerts-default")))
+	assert.True(t, bytes.Contains(bulk, []byte(`"@timestamp"`)))
+	assert.True(t, bytes.Contains(bulk, []byte(`"kind":"alert"`)))
+
+	assert.Equal(t, committed+2, committedDocs.Value())
+	assert.Equal(t, failed+1, failedDocs.Value())
+	assert.Equal(t, int64(1), failedDocsReasons.Get("mapper_parsing_exception").(*expvar.Int).Value())
+}
+
+func TestWithTimestamp(t *testing.T) {
+	ts, _ := time.Parse(time.RFC3339, "2018-05-03T15:04:05.323Z")
+	assert.Equal(t, `{"@timestamp":"2018-05-03T15:04:05.323Z","seq":1}`, string(withTimestamp([]byte(`{"seq":1}`), ts)))
+	assert.Equal(t, `{"@timestamp":"2018-05-03T15:04:05.323Z"}`, string(withTimestamp([]byte(`{}`), ts)))
+}
+
 func getBatch() *event.Batch {
 	ts, _ := time.Parse(time.RFC3339, "2018-05-03T15:04:05.323Z")
 
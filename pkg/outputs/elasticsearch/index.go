@@ -19,14 +19,14 @@
 package elasticsearch
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"github.com/olivere/elastic/v7"
-	"github.com/rabbitstack/fibratus/pkg/event"
-	"html/template"
+	"net/http"
 	"strings"
 	"time"
+
+	"github.com/olivere/elastic/v7"
+	"github.com/rabbitstack/fibratus/pkg/event"
 )
 
 type index struct {
@@ -34,29 +34,87 @@ type index struct {
 	client *elastic.Client
 }
 
+// patterns returns the index patterns covered by the index template.
+func (i index) patterns() []string {
+	if i.config.DataStream {
+		patterns := []string{i.config.EventsDataStream}
+		if i.config.AlertsDataStream != "" && i.config.AlertsDataStream != i.config.EventsDataStream {
+			patterns = append(patterns, i.config.AlertsDataStream)
+		}
+		return patterns
+	}
+	// get the index pattern for the template
+	pattern := func(name string) string {
+		if strings.Contains(name, "%") {
+			name = name[0:strings.Index(name, "%")]
+		}
+		return name + "*"
+	}
+	patterns := []string{pattern(i.config.IndexName)}
+	if i.config.AlertsIndexName != "" && pattern(i.config.AlertsIndexName) != patterns[0] {
+		patterns = append(patterns, pattern(i.config.AlertsIndexName))
+	}
+	return patterns
+}
+
+// putILMPolicy creates the index lifecycle management policy if it doesn't exist.
+func (i index) putILMPolicy() error {
+	if i.config.ILMPolicyName == "" {
+		return nil
+	}
+	ctx := context.Background()
+
+	_, err := i.client.XPackIlmGetLifecycle().Policy(i.config.ILMPolicyName).Do(ctx)
+	if err == nil {
+		return nil
+	}
+	if !elastic.IsNotFound(err) {
+		return fmt.Errorf("unable to check the existence of the %q ILM policy: %v", i.config.ILMPolicyName, err)
+	}
+
+	policy := i.config.ILMPolicy
+	if policy == "" {
+		policy = ilmPolicy(i.config.DataStream)
+	}
+	_, err = i.client.XPackIlmPutLifecycle().Policy(i.config.ILMPolicyName).BodyString(policy).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to create the %q ILM policy: %v", i.config.ILMPolicyName, err)
+	}
+
+	return nil
+}
+
 // putTemplate creates the index template.
 func (i index) putTemplate() error {
 	if i.config.TemplateName == "" {
 		return nil
 	}
-	// get the index pattern for the template
-	indexPattern := i.config.IndexName
-	if strings.Contains(indexPattern, "%") {
-		indexPattern = indexPattern[0:strings.Index(indexPattern, "%")]
-	}
 
-	var b bytes.Buffer
+	var body []byte
 	if i.config.TemplateConfig != "" {
-		b.WriteString(i.config.TemplateConfig)
+		body = []byte(i.config.TemplateConfig)
 	} else {
-		// expand the Go template
-		tmpl := template.Must(template.New("template").Parse(indexTemplate))
-		err := tmpl.Execute(&b, templateInfo{IndexPattern: indexPattern + "*"})
+		info := templateInfo{
+			IndexPatterns: i.patterns(),
+			ILMPolicy:     i.config.ILMPolicyName,
+			DataStream:    i.config.DataStream,
+			ECS:           i.config.ECS,
+		}
+		var err error
+		if i.config.useComposableTemplate() {
+			body, err = info.composable()
+		} else {
+			body, err = info.legacy()
+		}
 		if err != nil {
 			return err
 		}
 	}
 
+	if i.config.useComposableTemplate() {
+		return i.putComposableTemplate(body)
+	}
+
 	ctx := context.Background()
 
 	exists, err := i.client.IndexTemplateExists(i.config.TemplateName).Do(ctx)
@@ -67,7 +125,7 @@ func (i index) putTemplate() error {
 		return nil
 	}
 	// create index template
-	_, err = i.client.IndexPutTemplate(i.config.TemplateName).BodyJson(b.String()).Do(ctx)
+	_, err = i.client.IndexPutTemplate(i.config.TemplateName).BodyString(string(body)).Do(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to create index for the %q template: %v", i.config.TemplateName, err)
 	}
@@ -75,21 +133,70 @@ func (i index) putTemplate() error {
 	return nil
 }
 
-// getName creates an index name by replacing specifiers to create time frame indices. If no time specifiers are
-// used this method returns a fixed index name.
+// putComposableTemplate creates the composable index template via the
+// _index_template API. The client lacks the builder for this API, so
+// the raw request is performed instead.
+func (i index) putComposableTemplate(body []byte) error {
+	ctx := context.Background()
+	path := "/_index_template/" + i.config.TemplateName
+
+	resp, err := i.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method:       http.MethodHead,
+		Path:         path,
+		IgnoreErrors: []int{http.StatusNotFound},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to check the existence of the %q composable template: %v", i.config.TemplateName, err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	_, err = i.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: http.MethodPut,
+		Path:   path,
+		Body:   string(body),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create the %q composable template: %v", i.config.TemplateName, err)
+	}
+
+	return nil
+}
+
+// getName resolves the target index or data stream name for the event. Events
+// that triggered any of the rules are routed to the alerts index/data stream if
+// it is configured. Index names can contain time specifiers to create indices
+// per time frame. If no time specifiers are used this method returns a fixed
+// index name.
 func (i index) getName(evt *event.Event) string {
+	alert := isAlert(evt)
+	if i.config.DataStream {
+		if alert && i.config.AlertsDataStream != "" {
+			return i.config.AlertsDataStream
+		}
+		return i.config.EventsDataStream
+	}
 	indexName := i.config.IndexName
+	if alert && i.config.AlertsIndexName != "" {
+		indexName = i.config.AlertsIndexName
+	}
 	if !strings.Contains(indexName, "%") {
 		return indexName
 	}
-	return i.replace(evt.Timestamp)
+	return replace(indexName, evt.Timestamp)
+}
+
+// isAlert determines if the event triggered any of the rules.
+func isAlert(evt *event.Event) bool {
+	return evt.ContainsMeta(event.RuleNameKey)
 }
 
-func (i index) replace(timestamp time.Time) string {
+func replace(indexName string, timestamp time.Time) string {
 	return strings.NewReplacer(
 		"%Y", timestamp.UTC().Format("2006"),
 		"%y", timestamp.UTC().Format("06"),
 		"%m", timestamp.UTC().Format("01"),
 		"%d", timestamp.UTC().Format("02"),
-		"%H", timestamp.UTC().Format("15")).Replace(i.config.IndexName)
+		"%H", timestamp.UTC().Format("15")).Replace(indexName)
 }
@@ -48,3 +48,22 @@ func TestProduceIndexName(t *testing.T) {
 	indexName = i.getName(&event.Event{Timestamp: ts})
 	assert.Equal(t, "fibratus-events", indexName)
 }
+
+func TestRouteAlerts(t *testing.T) {
+	ts, _ := time.Parse(time.RFC3339, "2011-05-03T15:04:05.323Z")
+	alert := &event.Event{Timestamp: ts, Metadata: map[event.MetadataKey]any{event.RuleNameKey: "LSASS memory dumping"}}
+
+	i := index{config: Config{IndexName: "fibratus-%Y-%m", AlertsIndexName: "fibratus-alerts-%Y"}}
+	assert.Equal(t, "fibratus-2011-05", i.getName(&event.Event{Timestamp: ts}))
+	assert.Equal(t, "fibratus-alerts-2011", i.getName(alert))
+	assert.Equal(t, []string{"fibratus-*", "fibratus-alerts-*"}, i.patterns())
+
+	i = index{config: Config{IndexName: "fibratus"}}
+	assert.Equal(t, "fibratus", i.getName(alert))
+	assert.Equal(t, []string{"fibratus*"}, i.patterns())
+
+	i = index{config: Config{DataStream: true, EventsDataStream: "logs-fibratus.events-default", AlertsDataStream: "logs-fibratus.alerts-default"}}
+	assert.Equal(t, "logs-fibratus.events-default", i.getName(&event.Event{Timestamp: ts}))
+	assert.Equal(t, "logs-fibratus.alerts-default", i.getName(alert))
+	assert.Equal(t, []string{"logs-fibratus.events-default", "logs-fibratus.alerts-default"}, i.patterns())
+}
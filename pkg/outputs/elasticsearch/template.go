@@ -18,65 +18,328 @@
 
 package elasticsearch
 
+import (
+	"encoding/json"
+)
+
+// templateInfo contains the attributes for building the index template.
 type templateInfo struct {
-	IndexPattern string
+	// IndexPatterns are the index or data stream name patterns the template applies to
+	IndexPatterns []string
+	// ILMPolicy is the name of the lifecycle policy attached to the backing indices
+	ILMPolicy string
+	// DataStream indicates if the template enables data streams
+	DataStream bool
+	// ECS indicates if the template uses Elastic Common Schema mappings
+	ECS bool
+}
+
+// settings builds the index settings for the template.
+func (t templateInfo) settings() map[string]any {
+	settings := map[string]any{
+		"refresh_interval":   "5s",
+		"number_of_shards":   1,
+		"number_of_replicas": 1,
+	}
+	if t.ILMPolicy != "" {
+		settings["lifecycle"] = map[string]any{"name": t.ILMPolicy}
+	}
+	return map[string]any{"index": settings}
 }
 
-const indexTemplate = `
+// mappings returns either native or ECS document mappings.
+func (t templateInfo) mappings() json.RawMessage {
+	if t.ECS {
+		return json.RawMessage(ecsMappings)
+	}
+	return json.RawMessage(mappings)
+}
+
+// legacy builds the body of the legacy index template.
+func (t templateInfo) legacy() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"index_patterns": t.IndexPatterns,
+		"settings":       t.settings(),
+		"mappings":       t.mappings(),
+	})
+}
+
+// composable builds the body of the composable index template.
+func (t templateInfo) composable() ([]byte, error) {
+	tmpl := map[string]any{
+		"index_patterns": t.IndexPatterns,
+		"priority":       200,
+		"template": map[string]any{
+			"settings": t.settings(),
+			"mappings": t.mappings(),
+		},
+		"_meta": map[string]any{"managed_by": "fibratus"},
+	}
+	if t.DataStream {
+		tmpl["data_stream"] = map[string]any{}
+	}
+	return json.Marshal(tmpl)
+}
+
+// ilmPolicy returns the default lifecycle policy. Backing indices of data streams
+// are rolled over in the hot phase, while regular indices are only deleted after
+// they reach the retention age. The rollover is conditioned on the max_size
+// threshold as the max_primary_shard_size condition requires Elasticsearch 7.13,
+// whereas data streams are supported starting from version 7.9.
+func ilmPolicy(dataStream bool) string {
+	if dataStream {
+		return dataStreamILMPolicy
+	}
+	return indexILMPolicy
+}
+
+const dataStreamILMPolicy = `
 {
-	"index_patterns": [ "{{ .IndexPattern }}" ],
-	"settings": {
-		"index": {
-			"refresh_interval": "5s",
-			"number_of_shards": 1,
-			"number_of_replicas": 1
-		}
-	},
-	"mappings": {
-		"properties": {
-			"seq": { "type": "long" },
-			"pid": { "type": "long" },
-			"tid": { "type": "long" },
-			"cpu": { "type": "short" },
-
-			"name": { "type": "keyword" },
-			"category": { "type": "keyword" },
-			"description": { "type": "text" },
-			"host": { "type": "keyword" },
-
-			"timestamp": { "type": "date" },
-
-			"params": { 
-				"type": "nested",
-			    "properties": {
-					"dip": { "type": "ip" },
-					"sip": { "type": "ip" }
+	"policy": {
+		"phases": {
+			"hot": {
+				"actions": {
+					"rollover": {
+						"max_size": "50gb",
+						"max_age": "30d"
+					}
 				}
 			},
-			
-			"ps": {
-				"type": "nested",
-			    "properties": {
-					"pid": { "type": "long" },
-					"ppid": { "type": "long" },
-					"name": { "type": "keyword" },
-					"comm": { "type": "text" },
-					"exe": { "type": "text" },
-					"cwd": { "type": "text" },
-					"sid": { "type": "keyword" },
-					"sessionid": { "type": "short" },
-					"handles": {
-						"type": "nested",
-						"properties": {
-							"name": { "type": "text" },
-							"type": { "type": "text" },
-							"id": 	{ "type": "long" },
-							"object": { "type": "keyword" }
-						}
+			"delete": {
+				"min_age": "90d",
+				"actions": {
+					"delete": {}
+				}
+			}
+		}
+	}
+}
+`
+
+const indexILMPolicy = `
+{
+	"policy": {
+		"phases": {
+			"delete": {
+				"min_age": "90d",
+				"actions": {
+					"delete": {}
+				}
+			}
+		}
+	}
+}
+`
+
+const mappings = `
+{
+	"properties": {
+		"@timestamp": { "type": "date" },
+
+		"seq": { "type": "long" },
+		"pid": { "type": "long" },
+		"tid": { "type": "long" },
+		"cpu": { "type": "short" },
+
+		"name": { "type": "keyword" },
+		"category": { "type": "keyword" },
+		"description": { "type": "text" },
+		"host": { "type": "keyword" },
+
+		"timestamp": { "type": "date" },
+
+		"params": {
+			"type": "nested",
+			"properties": {
+				"dip": { "type": "ip" },
+				"sip": { "type": "ip" }
+			}
+		},
+
+		"ps": {
+			"type": "nested",
+			"properties": {
+				"pid": { "type": "long" },
+				"ppid": { "type": "long" },
+				"name": { "type": "keyword" },
+				"comm": { "type": "text" },
+				"exe": { "type": "text" },
+				"cwd": { "type": "text" },
+				"sid": { "type": "keyword" },
+				"sessionid": { "type": "short" },
+				"handles": {
+					"type": "nested",
+					"properties": {
+						"name": { "type": "text" },
+						"type": { "type": "text" },
+						"id": 	{ "type": "long" },
+						"object": { "type": "keyword" }
+					}
+				}
+			}
+		}
+	}
+}
+`
+
+const ecsMappings = `
+{
+	"dynamic_templates": [
+		{
+			"labels": {
+				"path_match": "labels.*",
+				"mapping": { "type": "keyword" }
+			}
+		}
+	],
+	"properties": {
+		"@timestamp": { "type": "date" },
+		"message": { "type": "text" },
+		"tags": { "type": "keyword" },
+		"labels": { "type": "object" },
+
+		"ecs": {
+			"properties": {
+				"version": { "type": "keyword" }
+			}
+		},
+
+		"event": {
+			"properties": {
+				"kind": { "type": "keyword" },
+				"category": { "type": "keyword" },
+				"type": { "type": "keyword" },
+				"action": { "type": "keyword" },
+				"code": { "type": "keyword" },
+				"module": { "type": "keyword" },
+				"dataset": { "type": "keyword" },
+				"provider": { "type": "keyword" },
+				"outcome": { "type": "keyword" },
+				"sequence": { "type": "long" },
+				"created": { "type": "date" },
+				"start": { "type": "date" },
+				"end": { "type": "date" }
+			}
+		},
+
+		"host": {
+			"properties": {
+				"name": { "type": "keyword" },
+				"hostname": { "type": "keyword" },
+				"os": {
+					"properties": {
+						"family": { "type": "keyword" },
+						"type": { "type": "keyword" }
+					}
+				}
+			}
+		},
+
+		"process": {
+			"properties": {
+				"pid": { "type": "long" },
+				"name": { "type": "keyword" },
+				"executable": { "type": "keyword" },
+				"command_line": { "type": "wildcard" },
+				"args": { "type": "keyword" },
+				"working_directory": { "type": "keyword" },
+				"start": { "type": "date" },
+				"thread": {
+					"properties": {
+						"id": { "type": "long" }
+					}
+				},
+				"parent": {
+					"properties": {
+						"pid": { "type": "long" },
+						"name": { "type": "keyword" },
+						"executable": { "type": "keyword" },
+						"command_line": { "type": "wildcard" }
+					}
+				}
+			}
+		},
+
+		"user": {
+			"properties": {
+				"id": { "type": "keyword" },
+				"name": { "type": "keyword" },
+				"domain": { "type": "keyword" }
+			}
+		},
+
+		"file": {
+			"properties": {
+				"path": { "type": "keyword" },
+				"name": { "type": "keyword" },
+				"extension": { "type": "keyword" },
+				"directory": { "type": "keyword" },
+				"size": { "type": "long" }
+			}
+		},
+
+		"registry": {
+			"properties": {
+				"path": { "type": "keyword" },
+				"hive": { "type": "keyword" },
+				"key": { "type": "keyword" },
+				"value": { "type": "keyword" },
+				"data": {
+					"properties": {
+						"type": { "type": "keyword" },
+						"strings": { "type": "wildcard" }
 					}
 				}
 			}
-			
+		},
+
+		"source": {
+			"properties": {
+				"ip": { "type": "ip" },
+				"port": { "type": "long" }
+			}
+		},
+
+		"destination": {
+			"properties": {
+				"ip": { "type": "ip" },
+				"port": { "type": "long" }
+			}
+		},
+
+		"network": {
+			"properties": {
+				"transport": { "type": "keyword" },
+				"type": { "type": "keyword" },
+				"direction": { "type": "keyword" },
+				"protocol": { "type": "keyword" },
+				"bytes": { "type": "long" }
+			}
+		},
+
+		"dns": {
+			"properties": {
+				"question": {
+					"properties": {
+						"name": { "type": "keyword" },
+						"type": { "type": "keyword" }
+					}
+				}
+			}
+		},
+
+		"rule": {
+			"properties": {
+				"name": { "type": "keyword" },
+				"id": { "type": "keyword" },
+				"ruleset": { "type": "keyword" }
+			}
+		},
+
+		"fibratus": {
+			"properties": {
+				"params": { "type": "flattened" }
+			}
 		}
 	}
 }
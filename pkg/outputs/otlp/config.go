@@ -0,0 +1,79 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package otlp
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+)
+
+const (
+	otlpEnabled      = "output.otlp.enabled"
+	otlpProtocol     = "output.otlp.protocol"
+	otlpEndpoint     = "output.otlp.endpoint"
+	otlpTimeout      = "output.otlp.timeout"
+	otlpEnableGzip   = "output.otlp.enable-gzip"
+	otlpMaxBatchSize = "output.otlp.max-batch-size"
+)
+
+// Protocol is the alias for the OTLP transport protocol.
+type Protocol string
+
+const (
+	// HTTP sends protobuf-encoded log records over HTTP.
+	HTTP Protocol = "http"
+	// GRPC sends log records through the gRPC logs service.
+	GRPC Protocol = "grpc"
+)
+
+// Config contains the options for tweaking the OTLP output behaviour.
+type Config struct {
+	outputs.TLSConfig
+	// Enabled determines whether OTLP output is enabled.
+	Enabled bool `mapstructure:"enabled"`
+	// Protocol is the transport protocol used to export log records.
+	Protocol Protocol `mapstructure:"protocol"`
+	// Endpoint is the collector endpoint. For the HTTP protocol, this is the full
+	// URL of the logs intake, while for the gRPC protocol it is the host:port pair.
+	Endpoint string `mapstructure:"endpoint"`
+	// Timeout represents the timeout for the export requests.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// Headers contains a list of additional headers/metadata sent on each export request.
+	Headers map[string]string `mapstructure:"headers"`
+	// EnableGzip specifies whether the gzip compression is enabled.
+	EnableGzip bool `mapstructure:"enable-gzip"`
+	// MaxBatchSize is the max number of log records in a single export request.
+	MaxBatchSize int `mapstructure:"max-batch-size"`
+	// ResourceAttributes contains additional attributes attached to every resource.
+	ResourceAttributes map[string]string `mapstructure:"resource-attributes"`
+}
+
+// AddFlags registers persistent flags for the OTLP output.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool(otlpEnabled, false, "Determines whether the OTLP output is enabled")
+	flags.String(otlpProtocol, string(HTTP), "The transport protocol used to export log records. Possible values are http and grpc")
+	flags.String(otlpEndpoint, "http://localhost:4318/v1/logs", "The collector endpoint. For gRPC protocol, the endpoint is specified as host:port")
+	flags.Duration(otlpTimeout, time.Second*10, "Represents the timeout for the export requests")
+	flags.Bool(otlpEnableGzip, true, "Indicates whether the gzip compression is enabled")
+	flags.Int(otlpMaxBatchSize, 512, "The max number of log records in a single export request")
+	outputs.AddTLSFlags(flags, outputs.OTLP)
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	cryptotls "crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/rabbitstack/fibratus/pkg/util/tls"
+	"github.com/rabbitstack/fibratus/pkg/util/version"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	grpcgzip "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// userAgentHeader represents the value of the User-Agent header
+var userAgentHeader = version.ProductToken()
+
+// protobufContentType is the content type of OTLP/HTTP binary protobuf payloads
+const protobufContentType = "application/x-protobuf"
+
+// httpExporter sends binary protobuf encoded export requests over HTTP.
+type httpExporter struct {
+	client *http.Client
+	config Config
+}
+
+func newHTTPExporter(config Config) (*httpExporter, error) {
+	tlsConfig, err := tls.MakeConfig(config.TLSCert, config.TLSKey, config.TLSCA, config.TLSInsecureSkipVerify)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS config: %v", err)
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+			Proxy:           http.ProxyFromEnvironment,
+		},
+		Timeout: config.Timeout,
+	}
+	return &httpExporter{client: client, config: config}, nil
+}
+
+func (e *httpExporter) export(ctx context.Context, r *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	buf, err := proto.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.config.EnableGzip {
+		var bb bytes.Buffer
+		gz := gzip.NewWriter(&bb)
+		if _, err := gz.Write(buf); err != nil {
+			return nil, err
+		}
+		if err := gz.Close(); err != nil {
+			return nil, err
+		}
+		buf = bb.Bytes()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.config.Endpoint, bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgentHeader)
+	req.Header.Set("Content-Type", protobufContentType)
+	if e.config.EnableGzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range e.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("otlp export failed with %d status code: %s", resp.StatusCode, string(body))
+	}
+
+	var res collogspb.ExportLogsServiceResponse
+	if resp.Header.Get("Content-Type") == protobufContentType {
+		if err := proto.Unmarshal(body, &res); err != nil {
+			return nil, fmt.Errorf("unable to decode otlp export response: %v", err)
+		}
+	}
+	return &res, nil
+}
+
+func (e *httpExporter) close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// grpcExporter sends export requests through the gRPC logs service.
+type grpcExporter struct {
+	conn   *grpc.ClientConn
+	client collogspb.LogsServiceClient
+	config Config
+}
+
+func newGRPCExporter(config Config) (*grpcExporter, error) {
+	tlsConfig, err := tls.MakeConfig(config.TLSCert, config.TLSKey, config.TLSCA, config.TLSInsecureSkipVerify)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS config: %v", err)
+	}
+	if tlsConfig == nil && config.TLSInsecureSkipVerify {
+		tlsConfig = &cryptotls.Config{InsecureSkipVerify: true}
+	}
+
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUserAgent(userAgentHeader),
+	}
+	if config.EnableGzip {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(grpcgzip.Name)))
+	}
+
+	conn, err := grpc.NewClient(config.Endpoint, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create otlp grpc client: %v", err)
+	}
+	return &grpcExporter{conn: conn, client: collogspb.NewLogsServiceClient(conn), config: config}, nil
+}
+
+func (e *grpcExporter) export(ctx context.Context, r *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	if len(e.config.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(e.config.Headers))
+	}
+	return e.client.Export(ctx, r)
+}
+
+func (e *grpcExporter) close() error {
+	return e.conn.Close()
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package otlp
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/alertsender"
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/event/params"
+	"github.com/rabbitstack/fibratus/pkg/util/version"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+// scopeName is the name of the instrumentation scope that emits log records
+const scopeName = "fibratus"
+
+// resourceKey uniquely identifies the resource producing the log records.
+type resourceKey struct {
+	host string
+	pid  uint32
+}
+
+// newExportRequest builds the logs export request from the given events. Log
+// records are grouped in resources that identify the host and the process
+// that produced the event.
+func newExportRequest(evts []*event.Event, attrs map[string]string) *collogspb.ExportLogsServiceRequest {
+	req := &collogspb.ExportLogsServiceRequest{}
+	scopes := make(map[resourceKey]*logspb.ScopeLogs)
+	observed := uint64(time.Now().UnixNano())
+
+	for _, evt := range evts {
+		key := resourceKey{host: evt.Host, pid: evt.PID}
+		scope, ok := scopes[key]
+		if !ok {
+			scope = &logspb.ScopeLogs{
+				Scope: &commonpb.InstrumentationScope{Name: scopeName, Version: version.Get()},
+			}
+			scopes[key] = scope
+			req.ResourceLogs = append(req.ResourceLogs, &logspb.ResourceLogs{
+				Resource:  newResource(evt, attrs),
+				ScopeLogs: []*logspb.ScopeLogs{scope},
+			})
+		}
+		scope.LogRecords = append(scope.LogRecords, newLogRecord(evt, observed))
+	}
+
+	return req
+}
+
+// newResource builds the resource with the host and process attributes.
+func newResource(evt *event.Event, attrs map[string]string) *resourcepb.Resource {
+	res := &resourcepb.Resource{
+		Attributes: []*commonpb.KeyValue{
+			stringAttr("service.name", "fibratus"),
+			stringAttr("service.version", version.Get()),
+			stringAttr("host.name", evt.Host),
+			stringAttr("os.type", "windows"),
+			intAttr("process.pid", int64(evt.PID)),
+		},
+	}
+	if ps := evt.PS; ps != nil {
+		res.Attributes = append(res.Attributes,
+			stringAttr("process.executable.name", ps.Name),
+			stringAttr("process.executable.path", ps.Exe),
+			stringAttr("process.command_line", ps.Cmdline),
+			intAttr("process.parent_pid", int64(ps.Ppid)),
+		)
+		if ps.Username != "" {
+			res.Attributes = append(res.Attributes, stringAttr("process.owner", ps.Domain+`\`+ps.Username))
+		}
+	}
+	for k, v := range attrs {
+		res.Attributes = append(res.Attributes, stringAttr(k, v))
+	}
+	return res
+}
+
+// newLogRecord maps the event to the log record. Event parameters
+// are stored in attributes prefixed with the event.params namespace
+// and metadata tags are copied verbatim.
+func newLogRecord(evt *event.Event, observed uint64) *logspb.LogRecord {
+	body := evt.Description
+	if body == "" {
+		body = evt.Name
+	}
+	sevnum, sevtext := severity(evt)
+	rec := &logspb.LogRecord{
+		TimeUnixNano:         uint64(evt.Timestamp.UnixNano()),
+		ObservedTimeUnixNano: observed,
+		SeverityNumber:       sevnum,
+		SeverityText:         sevtext,
+		Body:                 &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: body}},
+		Attributes: []*commonpb.KeyValue{
+			stringAttr("event.name", evt.Name),
+			stringAttr("event.category", string(evt.Category)),
+			intAttr("event.seq", int64(evt.Seq)),
+			intAttr("event.cpu", int64(evt.CPU)),
+			intAttr("thread.id", int64(evt.Tid)),
+		},
+	}
+	for _, par := range evt.Params {
+		rec.Attributes = append(rec.Attributes, &commonpb.KeyValue{Key: "event.params." + par.Name, Value: paramValue(par)})
+	}
+	for k, v := range evt.Metadata {
+		rec.Attributes = append(rec.Attributes, stringAttr(k.String(), fmt.Sprintf("%v", v)))
+	}
+	return rec
+}
+
+// severity determines the log record severity. Regular events are
+// logged with the informational severity, while events that triggered
+// the rule derive the severity level from the rule.
+func severity(evt *event.Event) (logspb.SeverityNumber, string) {
+	if !evt.ContainsMeta(event.RuleNameKey) {
+		return logspb.SeverityNumber_SEVERITY_NUMBER_INFO, "INFO"
+	}
+	if !evt.ContainsMeta(event.RuleSeverityKey) {
+		return logspb.SeverityNumber_SEVERITY_NUMBER_WARN, "WARN"
+	}
+	switch alertsender.ParseSeverityFromString(evt.GetMetaAsString(event.RuleSeverityKey)) {
+	case alertsender.High:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_ERROR, "ERROR"
+	case alertsender.Critical:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_FATAL, "FATAL"
+	default:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_WARN, "WARN"
+	}
+}
+
+// paramValue converts the event parameter to the attribute value.
+// Plain numeric and boolean parameters retain their native types,
+// whereas the rest of parameters is rendered as string.
+func paramValue(par *event.Param) *commonpb.AnyValue {
+	switch par.Type {
+	case params.Int8, params.Uint8, params.Int16, params.Uint16,
+		params.Int32, params.Uint32, params.Int64, params.Uint64:
+		switch v := par.Value.(type) {
+		case uint8:
+			return intValue(int64(v))
+		case uint16:
+			return intValue(int64(v))
+		case uint32:
+			return intValue(int64(v))
+		case uint64:
+			if v <= math.MaxInt64 {
+				return intValue(int64(v))
+			}
+		case int8:
+			return intValue(int64(v))
+		case int16:
+			return intValue(int64(v))
+		case int32:
+			return intValue(int64(v))
+		case int64:
+			return intValue(v)
+		}
+	case params.Bool:
+		if v, ok := par.Value.(bool); ok {
+			return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v}}
+		}
+	}
+	return stringValue(par.String())
+}
+
+func stringValue(s string) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: s}}
+}
+
+func intValue(n int64) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: n}}
+}
+
+func stringAttr(k, v string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: k, Value: stringValue(v)}
+}
+
+func intAttr(k string, v int64) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: k, Value: intValue(v)}
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package otlp
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+)
+
+var (
+	// exportedRecords counts the number of log records accepted by the collector
+	exportedRecords = expvar.NewInt("output.otlp.exported.records")
+	// rejectedRecords counts the number of log records the collector refused to accept
+	rejectedRecords = expvar.NewInt("output.otlp.rejected.records")
+	// exportErrors counts the number of failed export requests
+	exportErrors = expvar.NewInt("output.otlp.export.errors")
+)
+
+// exporter sends log records to the collector over the specific transport.
+type exporter interface {
+	export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error)
+	close() error
+}
+
+type otlp struct {
+	config   Config
+	exporter exporter
+}
+
+func init() {
+	outputs.Register(outputs.OTLP, initOTLP)
+}
+
+func initOTLP(config outputs.Config) (outputs.OutputGroup, error) {
+	cfg, ok := config.Output.(Config)
+	if !ok {
+		return outputs.Fail(outputs.ErrInvalidConfig(outputs.OTLP, config.Output))
+	}
+	if cfg.Endpoint == "" {
+		return outputs.Fail(fmt.Errorf("otlp endpoint is required"))
+	}
+	if cfg.MaxBatchSize <= 0 {
+		cfg.MaxBatchSize = 512
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = time.Second * 10
+	}
+	return outputs.Success(&otlp{config: cfg}), nil
+}
+
+func (o *otlp) Connect() error {
+	var err error
+	switch o.config.Protocol {
+	case HTTP, "":
+		o.exporter, err = newHTTPExporter(o.config)
+	case GRPC:
+		o.exporter, err = newGRPCExporter(o.config)
+	default:
+		return fmt.Errorf("unsupported otlp protocol: %s", o.config.Protocol)
+	}
+	return err
+}
+
+func (o *otlp) Close() error {
+	if o.exporter == nil {
+		return nil
+	}
+	return o.exporter.close()
+}
+
+// Publish converts events in the batch to log records and exports them
+// to the collector. If the batch exceeds the max batch size, it is split
+// into multiple export requests.
+func (o *otlp) Publish(batch *event.Batch) error {
+	evts := batch.Events
+	for len(evts) > 0 {
+		n := min(len(evts), o.config.MaxBatchSize)
+		if err := o.export(evts[:n]); err != nil {
+			return err
+		}
+		evts = evts[n:]
+	}
+	return nil
+}
+
+func (o *otlp) export(evts []*event.Event) error {
+	ctx, cancel := context.WithTimeout(context.Background(), o.config.Timeout)
+	defer cancel()
+	resp, err := o.exporter.export(ctx, newExportRequest(evts, o.config.ResourceAttributes))
+	if err != nil {
+		exportErrors.Add(1)
+		return err
+	}
+	var rejected int64
+	if ps := resp.GetPartialSuccess(); ps != nil {
+		rejected = ps.GetRejectedLogRecords()
+	}
+	rejectedRecords.Add(rejected)
+	exportedRecords.Add(int64(len(evts)) - rejected)
+	if rejected > 0 {
+		return fmt.Errorf("otlp collector rejected %d log records: %s", rejected, resp.GetPartialSuccess().GetErrorMessage())
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package otlp
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/event/params"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+func newEvents() []*event.Event {
+	return []*event.Event{
+		{
+			Type:        event.CreateFile,
+			Name:        "CreateFile",
+			Category:    event.File,
+			Seq:         1,
+			Tid:         2484,
+			PID:         859,
+			Host:        "archrabbit",
+			Description: "Creates or opens a new file, directory, I/O device, pipe, console",
+			Timestamp:   time.Now(),
+			Params: event.Params{
+				params.FilePath:   {Name: params.FilePath, Type: params.UnicodeString, Value: `C:\Windows\system32\user32.dll`},
+				params.FileIoSize: {Name: params.FileIoSize, Type: params.Uint32, Value: uint32(4096)},
+				params.FileIsDLL:  {Name: params.FileIsDLL, Type: params.Bool, Value: false},
+			},
+			Metadata: map[event.MetadataKey]any{"foo": "bar"},
+			PS: &pstypes.PS{
+				PID:      859,
+				Ppid:     2345,
+				Name:     "cmd.exe",
+				Exe:      `C:\Windows\system32\cmd.exe`,
+				Cmdline:  `C:\Windows\system32\cmd.exe /c dir`,
+				Username: "admin",
+				Domain:   "ARCHRABBIT",
+			},
+		},
+		{
+			Type:      event.CreateFile,
+			Name:      "CreateFile",
+			Category:  event.File,
+			Seq:       2,
+			PID:       859,
+			Host:      "archrabbit",
+			Timestamp: time.Now(),
+			Params: event.Params{
+				params.FilePath: {Name: params.FilePath, Type: params.UnicodeString, Value: `C:\Windows\system32\kernel32.dll`},
+			},
+			Metadata: map[event.MetadataKey]any{event.RuleNameKey: "Suspicious DLL access", event.RuleSeverityKey: "high"},
+		},
+		{
+			Type:      event.CreateFile,
+			Name:      "CreateFile",
+			Category:  event.File,
+			Seq:       3,
+			PID:       1234,
+			Host:      "archrabbit",
+			Timestamp: time.Now(),
+			Params: event.Params{
+				params.FilePath: {Name: params.FilePath, Type: params.UnicodeString, Value: `C:\Windows\system32\ntdll.dll`},
+			},
+			Metadata: map[event.MetadataKey]any{event.RuleNameKey: "Suspicious DLL access"},
+		},
+	}
+}
+
+func attrs(kvs []*commonpb.KeyValue) map[string]*commonpb.AnyValue {
+	m := make(map[string]*commonpb.AnyValue, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestNewExportRequest(t *testing.T) {
+	req := newExportRequest(newEvents(), map[string]string{"deployment.environment": "prod"})
+	require.Len(t, req.ResourceLogs, 2)
+
+	res := attrs(req.ResourceLogs[0].Resource.Attributes)
+	assert.Equal(t, "archrabbit", res["host.name"].GetStringValue())
+	assert.Equal(t, int64(859), res["process.pid"].GetIntValue())
+	assert.Equal(t, int64(2345), res["process.parent_pid"].GetIntValue())
+	assert.Equal(t, `C:\Windows\system32\cmd.exe`, res["process.executable.path"].GetStringValue())
+	assert.Equal(t, `C:\Windows\system32\cmd.exe /c dir`, res["process.command_line"].GetStringValue())
+	assert.Equal(t, `ARCHRABBIT\admin`, res["process.owner"].GetStringValue())
+	assert.Equal(t, "prod", res["deployment.environment"].GetStringValue())
+
+	require.Len(t, req.ResourceLogs[0].ScopeLogs, 1)
+	recs := req.ResourceLogs[0].ScopeLogs[0].LogRecords
+	require.Len(t, recs, 2)
+
+	assert.Equal(t, logspb.SeverityNumber_SEVERITY_NUMBER_INFO, recs[0].SeverityNumber)
+	assert.Equal(t, "Creates or opens a new file, directory, I/O device, pipe, console", recs[0].Body.GetStringValue())
+	rec := attrs(recs[0].Attributes)
+	assert.Equal(t, "CreateFile", rec["event.name"].GetStringValue())
+	assert.Equal(t, `C:\Windows\system32\user32.dll`, rec["event.params.file_path"].GetStringValue())
+	assert.Equal(t, int64(4096), rec["event.params.io_size"].GetIntValue())
+	assert.False(t, rec["event.params.is_dll"].GetBoolValue())
+	assert.Equal(t, "bar", rec["foo"].GetStringValue())
+
+	assert.Equal(t, logspb.SeverityNumber_SEVERITY_NUMBER_ERROR, recs[1].SeverityNumber)
+	assert.Equal(t, "CreateFile", recs[1].Body.GetStringValue())
+	assert.Equal(t, "Suspicious DLL access", attrs(recs[1].Attributes)["rule.name"].GetStringValue())
+
+	recs = req.ResourceLogs[1].ScopeLogs[0].LogRecords
+	require.Len(t, recs, 1)
+	assert.Equal(t, logspb.SeverityNumber_SEVERITY_NUMBER_WARN, recs[0].SeverityNumber)
+}
+
+func TestInitOTLPDefaults(t *testing.T) {
+	out, err := initOTLP(outputs.Config{Type: outputs.OTLP, Output: Config{Endpoint: "localhost:4317"}})
+	require.NoError(t, err)
+	require.Len(t, out.Clients, 1)
+	o := out.Clients[0].(*otlp)
+	assert.Equal(t, 512, o.config.MaxBatchSize)
+	assert.Equal(t, time.Second*10, o.config.Timeout)
+
+	_, err = initOTLP(outputs.Config{Type: outputs.OTLP, Output: Config{}})
+	require.Error(t, err)
+}
+
+func TestOTLPHTTPPublish(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []*collogspb.ExportLogsServiceRequest
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/logs", r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "secret", r.Header.Get("Api-Key"))
+
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body, err := io.ReadAll(gz)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var req collogspb.ExportLogsServiceRequest
+		if err := proto.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		requests = append(requests, &req)
+		mu.Unlock()
+
+		resp, _ := proto.Marshal(&collogspb.ExportLogsServiceResponse{})
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		_, _ = w.Write(resp)
+	}))
+	defer srv.Close()
+
+	c := Config{
+		Enabled:      true,
+		Protocol:     HTTP,
+		Endpoint:     srv.URL + "/v1/logs",
+		Timeout:      time.Second * 5,
+		EnableGzip:   true,
+		MaxBatchSize: 2,
+		Headers:      map[string]string{"api-key": "secret"},
+	}
+
+	out, err := initOTLP(outputs.Config{Type: outputs.OTLP, Output: c})
+	require.NoError(t, err)
+	require.Len(t, out.Clients, 1)
+	client := out.Clients[0]
+	require.NoError(t, client.Connect())
+	defer client.Close()
+
+	require.NoError(t, client.Publish(event.NewBatch(newEvents()...)))
+
+	mu.Lock()
+	defer mu.Unlock()
+	// the batch is split into two export requests
+	require.Len(t, requests, 2)
+	var n int
+	for _, req := range requests {
+		for _, rl := range req.ResourceLogs {
+			for _, sl := range rl.ScopeLogs {
+				assert.Equal(t, "fibratus", sl.Scope.Name)
+				n += len(sl.LogRecords)
+			}
+		}
+	}
+	assert.Equal(t, 3, n)
+}
+
+func TestOTLPHTTPPublishFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "collector unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := &otlp{config: Config{Endpoint: srv.URL, Timeout: time.Second, MaxBatchSize: 10}}
+	require.NoError(t, client.Connect())
+	require.Error(t, client.Publish(event.NewBatch(newEvents()...)))
+}
+
+type logsReceiver struct {
+	collogspb.UnimplementedLogsServiceServer
+	mu       sync.Mutex
+	requests []*collogspb.ExportLogsServiceRequest
+	md       metadata.MD
+}
+
+func (r *logsReceiver) Export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.md, _ = metadata.FromIncomingContext(ctx)
+	return &collogspb.ExportLogsServiceResponse{
+		PartialSuccess: &collogspb.ExportLogsPartialSuccess{},
+	}, nil
+}
+
+func TestOTLPGRPCPublish(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	receiver := &logsReceiver{}
+	srv := grpc.NewServer()
+	collogspb.RegisterLogsServiceServer(srv, receiver)
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Stop()
+
+	c := Config{
+		Enabled:      true,
+		Protocol:     GRPC,
+		Endpoint:     l.Addr().String(),
+		Timeout:      time.Second * 5,
+		EnableGzip:   true,
+		MaxBatchSize: 512,
+		Headers:      map[string]string{"api-key": "secret"},
+	}
+
+	out, err := initOTLP(outputs.Config{Type: outputs.OTLP, Output: c})
+	require.NoError(t, err)
+	client := out.Clients[0]
+	require.NoError(t, client.Connect())
+	defer client.Close()
+
+	require.NoError(t, client.Publish(event.NewBatch(newEvents()...)))
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	require.Len(t, receiver.requests, 1)
+	assert.Len(t, receiver.requests[0].ResourceLogs, 2)
+	assert.Equal(t, []string{"secret"}, receiver.md.Get("api-key"))
+}
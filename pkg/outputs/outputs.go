@@ -51,6 +51,10 @@ const (
 	Eventlog
 	// Null is the null output.
 	Null
+	// OTLP denotes the OpenTelemetry logs output.
+	OTLP
+	// S3 denotes the S3-compatible object storage archive output.
+	S3
 	// Unknown is an undefined output type.
 	Unknown
 )
@@ -70,6 +74,10 @@ func (t Type) String() string {
 		return "eventlog"
 	case Null:
 		return "null"
+	case OTLP:
+		return "otlp"
+	case S3:
+		return "s3"
 	default:
 		return "unknown"
 	}
@@ -90,6 +98,10 @@ func TypeFromString(s string) Type {
 		return Eventlog
 	case "null":
 		return Null
+	case "otlp":
+		return OTLP
+	case "s3":
+		return S3
 	default:
 		return Unknown
 	}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+)
+
+const (
+	s3Enabled          = "output.s3.enabled"
+	s3Endpoint         = "output.s3.endpoint"
+	s3Region           = "output.s3.region"
+	s3Bucket           = "output.s3.bucket"
+	s3Prefix           = "output.s3.prefix"
+	s3AccessKey        = "output.s3.access-key"
+	s3SecretKey        = "output.s3.secret-key"
+	s3SessionToken     = "output.s3.session-token"
+	s3Secure           = "output.s3.secure"
+	s3PathStyle        = "output.s3.path-style"
+	s3Format           = "output.s3.format"
+	s3EnableGzip       = "output.s3.enable-gzip"
+	s3PartSize         = "output.s3.part-size"
+	s3MaxObjectSize    = "output.s3.max-object-size"
+	s3RolloverInterval = "output.s3.rollover-interval"
+	s3StateDir         = "output.s3.state-dir"
+	s3Timeout          = "output.s3.timeout"
+)
+
+// minPartSize is the minimum size of the multipart upload part, except the last one
+const minPartSize = 5 * 1024 * 1024
+
+// Format is the alias for the archived object format.
+type Format string
+
+const (
+	// NDJSON stores events as newline-delimited JSON documents.
+	NDJSON Format = "ndjson"
+	// Parquet stores events in the columnar Apache Parquet format.
+	Parquet Format = "parquet"
+)
+
+// Config contains the options for tweaking the S3 output behaviour.
+type Config struct {
+	outputs.TLSConfig
+	// Enabled determines whether S3 output is enabled.
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoint is the host:port address of the S3-compatible object storage.
+	Endpoint string `mapstructure:"endpoint"`
+	// Region is the bucket region.
+	Region string `mapstructure:"region"`
+	// Bucket is the name of the bucket where objects are archived.
+	Bucket string `mapstructure:"bucket"`
+	// Prefix is prepended to the key of every archived object.
+	Prefix string `mapstructure:"prefix"`
+	// AccessKey is the access key identifier.
+	AccessKey string `mapstructure:"access-key"`
+	// SecretKey is the secret access key.
+	SecretKey string `mapstructure:"secret-key"`
+	// SessionToken is the optional session token for temporary credentials.
+	SessionToken string `mapstructure:"session-token"`
+	// Secure indicates whether the connection to object storage is established over TLS.
+	Secure bool `mapstructure:"secure"`
+	// PathStyle forces the path-style bucket addressing that is usually required by MinIO.
+	PathStyle bool `mapstructure:"path-style"`
+	// Format determines the format of the archived objects.
+	Format Format `mapstructure:"format"`
+	// EnableGzip specifies whether NDJSON objects are compressed with gzip.
+	EnableGzip bool `mapstructure:"enable-gzip"`
+	// PartSize is the size of the multipart upload part.
+	PartSize int64 `mapstructure:"part-size"`
+	// MaxObjectSize is the object size after which the object is finalized and the new one is started.
+	MaxObjectSize int64 `mapstructure:"max-object-size"`
+	// RolloverInterval is the max amount of time the object remains open for writing.
+	RolloverInterval time.Duration `mapstructure:"rollover-interval"`
+	// StateDir is the directory where the upload state and pending data are persisted.
+	StateDir string `mapstructure:"state-dir"`
+	// Timeout represents the timeout for object storage requests.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+func (c Config) validate() error {
+	if c.Endpoint == "" {
+		return fmt.Errorf("s3 endpoint is required")
+	}
+	if c.Bucket == "" {
+		return fmt.Errorf("s3 bucket is required")
+	}
+	if c.Format != NDJSON && c.Format != Parquet {
+		return fmt.Errorf("unsupported s3 object format: %s", c.Format)
+	}
+	if c.PartSize < minPartSize {
+		return fmt.Errorf("s3 part size must be at least %d bytes", minPartSize)
+	}
+	if c.MaxObjectSize < c.PartSize {
+		return fmt.Errorf("s3 max object size can't be lower than the part size")
+	}
+	if c.StateDir == "" {
+		return fmt.Errorf("s3 state directory is required")
+	}
+	return nil
+}
+
+// ext returns the extension of the archived object.
+func (c Config) ext() string {
+	switch {
+	case c.Format == Parquet:
+		return ".parquet"
+	case c.EnableGzip:
+		return ".ndjson.gz"
+	default:
+		return ".ndjson"
+	}
+}
+
+// AddFlags registers persistent flags for the S3 output.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool(s3Enabled, false, "Determines whether the S3 output is enabled")
+	flags.String(s3Endpoint, "s3.amazonaws.com", "The host:port address of the S3-compatible object storage")
+	flags.String(s3Region, "", "The bucket region")
+	flags.String(s3Bucket, "", "The name of the bucket where events are archived")
+	flags.String(s3Prefix, "fibratus", "The prefix prepended to the key of every archived object")
+	flags.String(s3AccessKey, "", "The access key identifier")
+	flags.String(s3SecretKey, "", "The secret access key")
+	flags.String(s3SessionToken, "", "The optional session token for temporary credentials")
+	flags.Bool(s3Secure, true, "Indicates whether the connection to object storage is established over TLS")
+	flags.Bool(s3PathStyle, false, "Forces the path-style bucket addressing that is usually required by MinIO")
+	flags.String(s3Format, string(NDJSON), "The format of the archived objects. Possible values are ndjson and parquet")
+	flags.Bool(s3EnableGzip, true, "Indicates whether NDJSON objects are compressed with gzip")
+	flags.Int64(s3PartSize, 8*1024*1024, "The size of the multipart upload part in bytes. Must be at least 5MB")
+	flags.Int64(s3MaxObjectSize, 128*1024*1024, "The object size in bytes after which the object is finalized and the new one is started")
+	flags.Duration(s3RolloverInterval, time.Minute*15, "The max amount of time the object remains open for writing")
+	flags.String(s3StateDir, filepath.Join(os.Getenv("ProgramData"), "Fibratus", "s3"), "The directory where the upload state and pending data are persisted")
+	flags.Duration(s3Timeout, time.Second*30, "Represents the timeout for object storage requests")
+	outputs.AddTLSFlags(flags, outputs.S3)
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// stateFile is the name of the file that keeps the upload state
+const stateFile = "state.json"
+
+// partition identifies the host/date/hour slot of archived events.
+type partition struct {
+	Host string    `json:"host"`
+	Hour time.Time `json:"hour"`
+}
+
+func newPartition(host string, ts time.Time) partition {
+	return partition{Host: host, Hour: ts.UTC().Truncate(time.Hour)}
+}
+
+// prefix returns the object key prefix in the Hive-style partitioning layout.
+func (p partition) prefix() string {
+	return fmt.Sprintf("host=%s/date=%s/hour=%02d", p.Host, p.Hour.Format("2006-01-02"), p.Hour.Hour())
+}
+
+// object represents the archived object that is being written. Events
+// are appended to the local spool file until the spool grows enough to
+// be uploaded as the multipart upload part. The object state is persisted,
+// so the upload can be resumed after restart.
+type object struct {
+	Partition partition `json:"partition"`
+	Key       string    `json:"key"`
+	UploadID  string    `json:"upload-id"`
+	Parts     []part    `json:"parts"`
+	// Size is the total number of bytes written to the object
+	Size int64 `json:"size"`
+	// Spooled is the number of bytes in the spool file pending upload
+	Spooled int64     `json:"spooled"`
+	Created time.Time `json:"created"`
+	Spool   string    `json:"spool"`
+
+	f *os.File
+}
+
+func newObject(config Config, p partition, now time.Time) *object {
+	id := strconv.FormatInt(now.UnixNano(), 10)
+	return &object{
+		Partition: p,
+		Key:       path.Join(config.Prefix, p.prefix(), id+config.ext()),
+		Created:   now,
+		Spool:     id + ".spool",
+	}
+}
+
+// open opens the spool file and discards any data beyond the
+// last persisted spool offset, which could be left over if the
+// process terminated before the state was saved.
+func (o *object) open(dir string) error {
+	f, err := os.OpenFile(filepath.Join(dir, o.Spool), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := f.Truncate(o.Spooled); err != nil {
+		_ = f.Close()
+		return err
+	}
+	o.f = f
+	return nil
+}
+
+// write appends data to the spool file.
+func (o *object) write(b []byte) error {
+	n, err := o.f.Write(b)
+	o.Spooled += int64(n)
+	o.Size += int64(n)
+	return err
+}
+
+// reader returns the reader of the spooled data.
+func (o *object) reader() io.ReadSeeker {
+	return io.NewSectionReader(o.f, 0, o.Spooled)
+}
+
+// reset discards the spooled data once it was uploaded.
+func (o *object) reset() error {
+	if err := o.f.Truncate(0); err != nil {
+		return err
+	}
+	o.Spooled = 0
+	return nil
+}
+
+// remove closes and deletes the spool file.
+func (o *object) remove(dir string) error {
+	if o.f != nil {
+		_ = o.f.Close()
+	}
+	err := os.Remove(filepath.Join(dir, o.Spool))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// state is the persistent state of all objects being written.
+type state struct {
+	Objects []*object `json:"objects"`
+}
+
+func loadState(dir string) (*state, error) {
+	b, err := os.ReadFile(filepath.Join(dir, stateFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &state{}, nil
+		}
+		return nil, err
+	}
+	var s state
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("corrupted s3 upload state: %v", err)
+	}
+	return &s, nil
+}
+
+// save atomically persists the state by replacing the state file.
+func (s *state) save(dir string) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, stateFile+".tmp")
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, stateFile))
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/rabbitstack/fibratus/pkg/event"
+)
+
+// rowGroupSize is the number of records written to the Parquet file at once
+const rowGroupSize = 1024
+
+// record is the flattened event representation stored in Parquet files.
+type record struct {
+	Seq         uint64            `json:"seq" parquet:"seq"`
+	Timestamp   time.Time         `json:"timestamp" parquet:"timestamp,timestamp(nanosecond)"`
+	Host        string            `json:"host" parquet:"host,dict"`
+	PID         uint32            `json:"pid" parquet:"pid"`
+	Tid         uint32            `json:"tid" parquet:"tid"`
+	CPU         uint32            `json:"cpu" parquet:"cpu"`
+	Name        string            `json:"name" parquet:"name,dict"`
+	Category    string            `json:"category" parquet:"category,dict"`
+	Description string            `json:"description" parquet:"description,dict"`
+	Params      map[string]string `json:"params" parquet:"params"`
+	Metadata    map[string]string `json:"metadata" parquet:"metadata"`
+	PS          *process          `json:"ps,omitempty" parquet:"ps,optional"`
+}
+
+// process contains the subset of process state attributes stored in Parquet files.
+type process struct {
+	PID      uint32 `json:"pid" parquet:"pid"`
+	Ppid     uint32 `json:"ppid" parquet:"ppid"`
+	Name     string `json:"name" parquet:"name"`
+	Exe      string `json:"exe" parquet:"exe"`
+	Cmdline  string `json:"cmdline" parquet:"cmdline"`
+	Cwd      string `json:"cwd" parquet:"cwd"`
+	SID      string `json:"sid" parquet:"sid"`
+	Username string `json:"username" parquet:"username"`
+	Domain   string `json:"domain" parquet:"domain"`
+}
+
+func newRecord(evt *event.Event) record {
+	r := record{
+		Seq:         evt.Seq,
+		Timestamp:   evt.Timestamp,
+		Host:        evt.Host,
+		PID:         evt.PID,
+		Tid:         evt.Tid,
+		CPU:         uint32(evt.CPU),
+		Name:        evt.Name,
+		Category:    string(evt.Category),
+		Description: evt.Description,
+		Params:      make(map[string]string, len(evt.Params)),
+		Metadata:    make(map[string]string, len(evt.Metadata)),
+	}
+	for _, par := range evt.Params {
+		r.Params[par.Name] = par.String()
+	}
+	for k, v := range evt.Metadata {
+		r.Metadata[k.String()] = fmt.Sprintf("%v", v)
+	}
+	if ps := evt.PS; ps != nil {
+		r.PS = &process{
+			PID:      ps.PID,
+			Ppid:     ps.Ppid,
+			Name:     ps.Name,
+			Exe:      ps.Exe,
+			Cmdline:  ps.Cmdline,
+			Cwd:      ps.Cwd,
+			SID:      ps.SID,
+			Username: ps.Username,
+			Domain:   ps.Domain,
+		}
+	}
+	return r
+}
+
+// writeParquet converts the spooled JSON records to the Parquet file.
+func writeParquet(w io.Writer, spool io.Reader) error {
+	pw := parquet.NewGenericWriter[record](w, parquet.Compression(&parquet.Zstd))
+	rows := make([]record, 0, rowGroupSize)
+	br := bufio.NewReader(spool)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			var r record
+			// the last record may be partially written if the
+			// process terminated abruptly, in which case it is skipped
+			if jerr := json.Unmarshal(line, &r); jerr == nil {
+				rows = append(rows, r)
+			} else if err == nil {
+				return fmt.Errorf("corrupted spool record: %v", jerr)
+			}
+		}
+		if len(rows) == rowGroupSize || (err != nil && len(rows) > 0) {
+			if _, err := pw.Write(rows); err != nil {
+				return err
+			}
+			rows = rows[:0]
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return pw.Close()
+}
@@ -0,0 +1,405 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	"github.com/rabbitstack/fibratus/pkg/util/multierror"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// uploadedObjects counts the number of finalized objects
+	uploadedObjects = expvar.NewInt("output.s3.uploaded.objects")
+	// uploadedParts counts the number of uploaded multipart upload parts
+	uploadedParts = expvar.NewInt("output.s3.uploaded.parts")
+	// uploadedBytes counts the number of bytes transferred to object storage
+	uploadedBytes = expvar.NewInt("output.s3.uploaded.bytes")
+	// uploadErrors counts the number of failed object storage requests
+	uploadErrors = expvar.NewInt("output.s3.upload.errors")
+)
+
+const (
+	// rolloverCheckInterval determines how often open objects are checked for the time-based rollover
+	rolloverCheckInterval = time.Second * 10
+	// partitionGrace is the amount of time after the partition hour ends
+	// during which late events are still appended to the open object
+	partitionGrace = time.Minute
+)
+
+type s3 struct {
+	mu      sync.Mutex
+	config  Config
+	store   store
+	objects map[partition]*object
+	now     func() time.Time
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func init() {
+	outputs.Register(outputs.S3, initS3)
+}
+
+func initS3(config outputs.Config) (outputs.OutputGroup, error) {
+	cfg, ok := config.Output.(Config)
+	if !ok {
+		return outputs.Fail(outputs.ErrInvalidConfig(outputs.S3, config.Output))
+	}
+	if err := cfg.validate(); err != nil {
+		return outputs.Fail(err)
+	}
+	return outputs.Success(newS3(cfg, nil)), nil
+}
+
+func newS3(config Config, store store) *s3 {
+	return &s3{
+		config:  config,
+		store:   store,
+		objects: make(map[partition]*object),
+		now:     time.Now,
+		quit:    make(chan struct{}),
+	}
+}
+
+// Connect initializes the object storage client and resumes
+// uploads of objects that were being written before restart.
+func (s *s3) Connect() error {
+	if err := os.MkdirAll(s.config.StateDir, 0o700); err != nil {
+		return fmt.Errorf("unable to create s3 state directory: %v", err)
+	}
+	if s.store == nil {
+		var err error
+		s.store, err = newMinioStore(s.config)
+		if err != nil {
+			return err
+		}
+	}
+	if err := s.resume(); err != nil {
+		return err
+	}
+
+	s.wg.Add(1)
+	go s.rollover()
+
+	return nil
+}
+
+// Close finalizes all open objects.
+func (s *s3) Close() error {
+	close(s.quit)
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	errs := make([]error, 0)
+	for _, o := range s.objects {
+		if err := s.finalize(o); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, s.saveState())
+	return multierror.Wrap(errs...)
+}
+
+// Publish appends events to the objects in the corresponding partitions.
+// Spooled data is uploaded as soon as it reaches the part size, and the
+// object is finalized when it grows beyond the max object size.
+func (s *s3) Publish(batch *event.Batch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bufs := make(map[partition]*bytes.Buffer)
+	for _, evt := range batch.Events {
+		p := newPartition(evt.Host, evt.Timestamp)
+		buf, ok := bufs[p]
+		if !ok {
+			buf = &bytes.Buffer{}
+			bufs[p] = buf
+		}
+		if err := s.encode(buf, evt); err != nil {
+			return err
+		}
+	}
+
+	for p, buf := range bufs {
+		o, err := s.object(p)
+		if err != nil {
+			return err
+		}
+		if err := s.append(o, buf.Bytes()); err != nil {
+			return err
+		}
+		if s.config.Format == NDJSON && o.Spooled >= s.config.PartSize {
+			if err := s.uploadPart(o); err != nil {
+				return err
+			}
+		}
+		if o.Size >= s.config.MaxObjectSize {
+			if err := s.finalize(o); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.saveState()
+}
+
+func (s *s3) encode(w io.Writer, evt *event.Event) error {
+	var b []byte
+	switch s.config.Format {
+	case Parquet:
+		var err error
+		b, err = json.Marshal(newRecord(evt))
+		if err != nil {
+			return err
+		}
+	default:
+		b = evt.MarshalJSON()
+	}
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+	_, err := w.Write([]byte{'\n'})
+	return err
+}
+
+// append writes data to the object spool. If the gzip compression
+// is enabled, each write produces a separate gzip member. Concatenated
+// gzip members form a valid gzip stream, so the spool can be uploaded
+// in parts without keeping the compressor state across restarts.
+func (s *s3) append(o *object, data []byte) error {
+	if s.config.Format == NDJSON && s.config.EnableGzip {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(data); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	}
+	return o.write(data)
+}
+
+// object returns the open object for the partition or starts a new one.
+func (s *s3) object(p partition) (*object, error) {
+	if o, ok := s.objects[p]; ok {
+		return o, nil
+	}
+	o := newObject(s.config, p, s.now())
+	if err := o.open(s.config.StateDir); err != nil {
+		return nil, err
+	}
+	s.objects[p] = o
+	return o, nil
+}
+
+func (s *s3) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), s.config.Timeout)
+}
+
+// uploadPart uploads the spooled data as the next part of the multipart upload.
+func (s *s3) uploadPart(o *object) error {
+	return s.upload(o, o.reader(), o.Spooled, func() error { return o.reset() })
+}
+
+func (s *s3) upload(o *object, r io.ReadSeeker, size int64, uploaded func() error) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	if o.UploadID == "" {
+		id, err := s.store.createUpload(ctx, o.Key)
+		if err != nil {
+			uploadErrors.Add(1)
+			return fmt.Errorf("unable to create multipart upload for %s object: %v", o.Key, err)
+		}
+		o.UploadID = id
+	}
+	p, err := s.store.uploadPart(ctx, o.Key, o.UploadID, len(o.Parts)+1, r, size)
+	if err != nil {
+		uploadErrors.Add(1)
+		return fmt.Errorf("unable to upload part %d of %s object: %v", len(o.Parts)+1, o.Key, err)
+	}
+	o.Parts = append(o.Parts, p)
+	uploadedParts.Add(1)
+	uploadedBytes.Add(size)
+	if err := uploaded(); err != nil {
+		return err
+	}
+	// persist the state right away, so the uploaded
+	// part is not lost if the process is terminated
+	return s.saveState()
+}
+
+// finalize uploads the remaining data and completes the multipart upload.
+func (s *s3) finalize(o *object) error {
+	var err error
+	switch s.config.Format {
+	case Parquet:
+		err = s.finalizeParquet(o)
+	default:
+		if o.Spooled > 0 {
+			err = s.uploadPart(o)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(o.Parts) > 0 {
+		ctx, cancel := s.context()
+		defer cancel()
+		if err := s.store.completeUpload(ctx, o.Key, o.UploadID, o.Parts); err != nil {
+			uploadErrors.Add(1)
+			return fmt.Errorf("unable to complete multipart upload for %s object: %v", o.Key, err)
+		}
+		uploadedObjects.Add(1)
+		log.Debugf("%s object archived in %d part(s)", o.Key, len(o.Parts))
+	}
+
+	delete(s.objects, o.Partition)
+	return o.remove(s.config.StateDir)
+}
+
+// finalizeParquet converts the spooled records to the Parquet file
+// and uploads the file in parts. Parquet files keep the metadata in
+// the footer, so the object can only be uploaded when it is finalized.
+func (s *s3) finalizeParquet(o *object) error {
+	if o.Spooled == 0 {
+		return nil
+	}
+	f, err := os.CreateTemp(s.config.StateDir, "*.parquet")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	if err := writeParquet(f, o.reader()); err != nil {
+		return fmt.Errorf("unable to write parquet file for %s object: %v", o.Key, err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	// parts uploaded before the interruption are skipped
+	var off int64
+	for _, p := range o.Parts {
+		off += p.Size
+	}
+	for off < fi.Size() {
+		size := min(s.config.PartSize, fi.Size()-off)
+		if err := s.upload(o, io.NewSectionReader(f, off, size), size, func() error { return nil }); err != nil {
+			return err
+		}
+		off += size
+	}
+	return nil
+}
+
+// resume restores objects from the persisted state. If the multipart
+// upload of the object is no longer available, the upload is restarted
+// under the new object key.
+func (s *s3) resume() error {
+	st, err := loadState(s.config.StateDir)
+	if err != nil {
+		return err
+	}
+	for _, o := range st.Objects {
+		if err := o.open(s.config.StateDir); err != nil {
+			return fmt.Errorf("unable to open spool for %s object: %v", o.Key, err)
+		}
+		if o.UploadID != "" {
+			ctx, cancel := s.context()
+			ok, err := s.store.hasUpload(ctx, o.Key, o.UploadID)
+			cancel()
+			if err != nil {
+				return fmt.Errorf("unable to resume upload of %s object: %v", o.Key, err)
+			}
+			if !ok {
+				log.Warnf("multipart upload of %s object is gone. %d part(s) lost", o.Key, len(o.Parts))
+				n := newObject(s.config, o.Partition, s.now())
+				o.Key, o.UploadID, o.Parts, o.Size = n.Key, "", nil, o.Spooled
+			}
+		}
+		s.objects[o.Partition] = o
+		log.Infof("resumed %s object upload with %d part(s)", o.Key, len(o.Parts))
+	}
+	return nil
+}
+
+// rollover periodically finalizes objects that have been open for longer
+// than the rollover interval or whose partition hour has passed.
+func (s *s3) rollover() {
+	defer s.wg.Done()
+	tick := time.NewTicker(rolloverCheckInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			s.mu.Lock()
+			if err := s.rolloverObjects(); err != nil {
+				log.Errorf("s3 object rollover failed: %v", err)
+			}
+			s.mu.Unlock()
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+func (s *s3) rolloverObjects() error {
+	now := s.now()
+	errs := make([]error, 0)
+	for _, o := range s.objects {
+		expired := s.config.RolloverInterval > 0 && now.Sub(o.Created) >= s.config.RolloverInterval
+		if !expired && now.Sub(o.Partition.Hour) < time.Hour+partitionGrace {
+			continue
+		}
+		if err := s.finalize(o); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, s.saveState())
+	return multierror.Wrap(errs...)
+}
+
+func (s *s3) saveState() error {
+	st := &state{Objects: make([]*object, 0, len(s.objects))}
+	for _, o := range s.objects {
+		st.Objects = append(st.Objects, o)
+	}
+	return st.save(s.config.StateDir)
+}
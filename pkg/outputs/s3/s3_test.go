@@ -0,0 +1,288 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+	"github.com/minio/minio-go/v7"
+	"github.com/parquet-go/parquet-go"
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/event/params"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeS3 starts the fake S3 server. The server speaks TLS
+// because the MinIO client relies on streaming signatures for
+// plain HTTP uploads, which are not understood by the fake server.
+func newFakeS3(t *testing.T) (Config, func()) {
+	backend := s3mem.New()
+	require.NoError(t, backend.CreateBucket("archive"))
+	srv := httptest.NewTLSServer(gofakes3.New(backend).Server())
+
+	c := Config{
+		Enabled:          true,
+		Endpoint:         strings.TrimPrefix(srv.URL, "https://"),
+		Bucket:           "archive",
+		Prefix:           "fibratus",
+		AccessKey:        "minio",
+		SecretKey:        "minio123",
+		Secure:           true,
+		PathStyle:        true,
+		Format:           NDJSON,
+		EnableGzip:       true,
+		PartSize:         1024,
+		MaxObjectSize:    1024 * 1024,
+		RolloverInterval: time.Minute * 15,
+		StateDir:         t.TempDir(),
+		Timeout:          time.Second * 5,
+	}
+	c.TLSInsecureSkipVerify = true
+
+	return c, srv.Close
+}
+
+func newEvents(n int, ts time.Time) []*event.Event {
+	evts := make([]*event.Event, n)
+	for i := 0; i < n; i++ {
+		evts[i] = &event.Event{
+			Type:        event.CreateFile,
+			Name:        "CreateFile",
+			Category:    event.File,
+			Seq:         uint64(i),
+			PID:         859,
+			Tid:         2484,
+			Host:        "archrabbit",
+			Description: "Creates or opens a new file, directory, I/O device, pipe, console",
+			Timestamp:   ts,
+			Params: event.Params{
+				params.FilePath: {Name: params.FilePath, Type: params.UnicodeString, Value: `C:\Windows\system32\user32.dll`},
+			},
+			Metadata: map[event.MetadataKey]any{"foo": "bar"},
+			PS: &pstypes.PS{
+				PID:  859,
+				Ppid: 2345,
+				Name: "cmd.exe",
+				Exe:  `C:\Windows\system32\cmd.exe`,
+			},
+		}
+	}
+	return evts
+}
+
+// objects fetches all archived objects.
+func objects(t *testing.T, s *s3) map[string][]byte {
+	core := s.store.(*minioStore).core
+	res, err := core.ListObjectsV2("archive", "", "", "", "", 1000)
+	require.NoError(t, err)
+	objs := make(map[string][]byte)
+	for _, o := range res.Contents {
+		r, _, _, err := core.GetObject(context.Background(), "archive", o.Key, minio.GetObjectOptions{})
+		require.NoError(t, err)
+		b, err := io.ReadAll(r)
+		require.NoError(t, err)
+		r.Close()
+		objs[o.Key] = b
+	}
+	return objs
+}
+
+func ndjsonLines(t *testing.T, b []byte) int {
+	gz, err := gzip.NewReader(bytes.NewReader(b))
+	require.NoError(t, err)
+	var n int
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var m map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &m))
+		n++
+	}
+	require.NoError(t, scanner.Err())
+	return n
+}
+
+func TestConfigValidate(t *testing.T) {
+	c := Config{Endpoint: "localhost:9000", Bucket: "archive", Format: NDJSON, PartSize: minPartSize, MaxObjectSize: minPartSize * 2, StateDir: t.TempDir()}
+	require.NoError(t, c.validate())
+
+	c.Format = "csv"
+	require.Error(t, c.validate())
+	c.Format = Parquet
+	c.PartSize = 1024
+	require.Error(t, c.validate())
+
+	_, err := initS3(outputs.Config{Type: outputs.S3, Output: Config{Bucket: "archive"}})
+	require.Error(t, err)
+}
+
+func TestPartition(t *testing.T) {
+	ts := time.Date(2024, 5, 14, 13, 45, 12, 0, time.UTC)
+	p := newPartition("archrabbit", ts)
+	assert.Equal(t, "host=archrabbit/date=2024-05-14/hour=13", p.prefix())
+
+	o := newObject(Config{Prefix: "fibratus", Format: NDJSON, EnableGzip: true}, p, ts)
+	assert.True(t, strings.HasPrefix(o.Key, "fibratus/host=archrabbit/date=2024-05-14/hour=13/"))
+	assert.True(t, strings.HasSuffix(o.Key, ".ndjson.gz"))
+}
+
+func TestPublishNDJSON(t *testing.T) {
+	c, stop := newFakeS3(t)
+	defer stop()
+
+	s := newS3(c, nil)
+	require.NoError(t, s.Connect())
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, s.Publish(event.NewBatch(newEvents(20, now)...)))
+	}
+	// events from the previous hour land in a different partition
+	require.NoError(t, s.Publish(event.NewBatch(newEvents(10, now.Add(-time.Hour))...)))
+
+	require.Len(t, s.objects, 2)
+	o := s.objects[newPartition("archrabbit", now)]
+	require.NotNil(t, o)
+	assert.NotEmpty(t, o.UploadID)
+	assert.True(t, len(o.Parts) > 1)
+
+	require.NoError(t, s.Close())
+	assert.Len(t, s.objects, 0)
+
+	objs := objects(t, s)
+	require.Len(t, objs, 2)
+	var n int
+	for key, b := range objs {
+		assert.True(t, strings.HasSuffix(key, ".ndjson.gz"))
+		n += ndjsonLines(t, b)
+	}
+	assert.Equal(t, 110, n)
+}
+
+func TestPublishRollover(t *testing.T) {
+	c, stop := newFakeS3(t)
+	defer stop()
+	c.MaxObjectSize = 4096
+
+	s := newS3(c, nil)
+	require.NoError(t, s.Connect())
+
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, s.Publish(event.NewBatch(newEvents(20, now)...)))
+	}
+
+	// the time-based rollover finalizes the rest of objects
+	s.now = func() time.Time { return now.Add(c.RolloverInterval) }
+	require.NoError(t, s.rolloverObjects())
+	assert.Len(t, s.objects, 0)
+
+	objs := objects(t, s)
+	assert.True(t, len(objs) > 1)
+	var n int
+	for _, b := range objs {
+		n += ndjsonLines(t, b)
+	}
+	assert.Equal(t, 200, n)
+	require.NoError(t, s.Close())
+}
+
+func TestPublishResume(t *testing.T) {
+	c, stop := newFakeS3(t)
+	defer stop()
+
+	s := newS3(c, nil)
+	require.NoError(t, s.Connect())
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.Publish(event.NewBatch(newEvents(20, now)...)))
+	}
+	o := s.objects[newPartition("archrabbit", now)]
+	require.NotNil(t, o)
+	key, uploadID, parts := o.Key, o.UploadID, len(o.Parts)
+	require.True(t, parts > 0)
+
+	// simulate abrupt termination by abandoning the
+	// output without finalizing the open objects
+	close(s.quit)
+	s.wg.Wait()
+	for _, o := range s.objects {
+		require.NoError(t, o.f.Close())
+	}
+
+	s1 := newS3(c, nil)
+	require.NoError(t, s1.Connect())
+	require.Len(t, s1.objects, 1)
+	o = s1.objects[newPartition("archrabbit", now)]
+	require.NotNil(t, o)
+	assert.Equal(t, key, o.Key)
+	assert.Equal(t, uploadID, o.UploadID)
+	assert.Len(t, o.Parts, parts)
+
+	require.NoError(t, s1.Publish(event.NewBatch(newEvents(20, now)...)))
+	require.NoError(t, s1.Close())
+
+	objs := objects(t, s1)
+	require.Len(t, objs, 1)
+	assert.Equal(t, 80, ndjsonLines(t, objs[key]))
+}
+
+func TestPublishParquet(t *testing.T) {
+	c, stop := newFakeS3(t)
+	defer stop()
+	c.Format = Parquet
+
+	s := newS3(c, nil)
+	require.NoError(t, s.Connect())
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, s.Publish(event.NewBatch(newEvents(20, now)...)))
+	}
+	require.NoError(t, s.Close())
+
+	objs := objects(t, s)
+	require.Len(t, objs, 1)
+	for key, b := range objs {
+		assert.True(t, strings.HasSuffix(key, ".parquet"))
+		rows, err := parquet.Read[record](bytes.NewReader(b), int64(len(b)))
+		require.NoError(t, err)
+		require.Len(t, rows, 100)
+		assert.Equal(t, "CreateFile", rows[0].Name)
+		assert.Equal(t, `C:\Windows\system32\user32.dll`, rows[0].Params[params.FilePath])
+		assert.Equal(t, "bar", rows[0].Metadata["foo"])
+		require.NotNil(t, rows[0].PS)
+		assert.Equal(t, "cmd.exe", rows[0].PS.Name)
+	}
+}
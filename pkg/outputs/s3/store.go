@@ -0,0 +1,136 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	cryptotls "crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/rabbitstack/fibratus/pkg/util/tls"
+)
+
+// part describes the uploaded object part.
+type part struct {
+	Number int    `json:"number"`
+	ETag   string `json:"etag"`
+	Size   int64  `json:"size"`
+}
+
+// store abstracts the multipart upload operations of the object storage.
+type store interface {
+	createUpload(ctx context.Context, key string) (string, error)
+	// uploadPart uploads the part of the multipart upload. The reader
+	// is consumed twice to compute the payload checksums beforehand.
+	uploadPart(ctx context.Context, key, uploadID string, n int, r io.ReadSeeker, size int64) (part, error)
+	completeUpload(ctx context.Context, key, uploadID string, parts []part) error
+	abortUpload(ctx context.Context, key, uploadID string) error
+	// hasUpload checks whether the multipart upload is still in progress.
+	hasUpload(ctx context.Context, key, uploadID string) (bool, error)
+}
+
+// minioStore implements the store on top of the MinIO client
+// that talks to any S3-compatible object storage.
+type minioStore struct {
+	core   *minio.Core
+	bucket string
+}
+
+func newMinioStore(config Config) (*minioStore, error) {
+	tlsConfig, err := tls.MakeConfig(config.TLSCert, config.TLSKey, config.TLSCA, config.TLSInsecureSkipVerify)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS config: %v", err)
+	}
+	if tlsConfig == nil && config.TLSInsecureSkipVerify {
+		tlsConfig = &cryptotls.Config{InsecureSkipVerify: true}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	opts := &minio.Options{
+		Creds:     credentials.NewStaticV4(config.AccessKey, config.SecretKey, config.SessionToken),
+		Secure:    config.Secure,
+		Region:    config.Region,
+		Transport: transport,
+	}
+	if config.PathStyle {
+		opts.BucketLookup = minio.BucketLookupPath
+	}
+	core, err := minio.NewCore(config.Endpoint, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &minioStore{core: core, bucket: config.Bucket}, nil
+}
+
+func (s *minioStore) createUpload(ctx context.Context, key string) (string, error) {
+	return s.core.NewMultipartUpload(ctx, s.bucket, key, minio.PutObjectOptions{})
+}
+
+func (s *minioStore) uploadPart(ctx context.Context, key, uploadID string, n int, r io.ReadSeeker, size int64) (part, error) {
+	md5sum, sha256sum := md5.New(), sha256.New()
+	if _, err := io.Copy(io.MultiWriter(md5sum, sha256sum), r); err != nil {
+		return part{}, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return part{}, err
+	}
+	opts := minio.PutObjectPartOptions{
+		Md5Base64: base64.StdEncoding.EncodeToString(md5sum.Sum(nil)),
+		Sha256Hex: hex.EncodeToString(sha256sum.Sum(nil)),
+	}
+	p, err := s.core.PutObjectPart(ctx, s.bucket, key, uploadID, n, r, size, opts)
+	if err != nil {
+		return part{}, err
+	}
+	return part{Number: p.PartNumber, ETag: p.ETag, Size: size}, nil
+}
+
+func (s *minioStore) completeUpload(ctx context.Context, key, uploadID string, parts []part) error {
+	completed := make([]minio.CompletePart, len(parts))
+	for i, p := range parts {
+		completed[i] = minio.CompletePart{PartNumber: p.Number, ETag: p.ETag}
+	}
+	_, err := s.core.CompleteMultipartUpload(ctx, s.bucket, key, uploadID, completed, minio.PutObjectOptions{})
+	return err
+}
+
+func (s *minioStore) abortUpload(ctx context.Context, key, uploadID string) error {
+	return s.core.AbortMultipartUpload(ctx, s.bucket, key, uploadID)
+}
+
+func (s *minioStore) hasUpload(ctx context.Context, key, uploadID string) (bool, error) {
+	_, err := s.core.ListObjectParts(ctx, s.bucket, key, uploadID, 0, 1)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchUpload" {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -18,6 +18,8 @@
 
 package types
 
+import "strings"
+
 // Visitor is the type definition for the function that is
 // invoked on each ancestor visit walk.
 type Visitor func(*PS)
@@ -36,3 +38,41 @@ func Walk(v Visitor, ps *PS) {
 		Walk(v, ps.Parent)
 	}
 }
+
+// TreeDepth designates the maximum number of ancestors visited
+// when resolving the root of the process tree.
+var TreeDepth = 4
+
+// treeBoundaries contains system processes that spawn unrelated
+// process trees. The tree root resolution never climbs past them.
+var treeBoundaries = map[string]bool{
+	"system":       true,
+	"smss.exe":     true,
+	"csrss.exe":    true,
+	"wininit.exe":  true,
+	"winlogon.exe": true,
+	"services.exe": true,
+	"svchost.exe":  true,
+	"userinit.exe": true,
+	"explorer.exe": true,
+}
+
+// TreeRoot returns the root ancestor of the process tree the given
+// process belongs to. It climbs the parent chain visiting at most
+// depth ancestors, and stops before reaching any of the system
+// processes that spawn unrelated trees, such as services.exe or
+// explorer.exe. If no eligible ancestor exists, the process itself
+// is the root of the tree.
+func TreeRoot(ps *PS, depth int) *PS {
+	if ps == nil {
+		return nil
+	}
+	root := ps
+	for i := 0; i < depth && root.Parent != nil; i++ {
+		if treeBoundaries[strings.ToLower(root.Parent.Name)] {
+			break
+		}
+		root = root.Parent
+	}
+	return root
+}
@@ -67,6 +67,33 @@ func TestVisit(t *testing.T) {
 	}
 }
 
+func TestTreeRoot(t *testing.T) {
+	explorer := &PS{Name: "explorer.exe"}
+	winword := &PS{Name: "WINWORD.EXE", Parent: explorer}
+	cmd := &PS{Name: "cmd.exe", Parent: winword}
+	powershell := &PS{Name: "powershell.exe", Parent: cmd}
+	rundll32 := &PS{Name: "rundll32.exe", Parent: powershell}
+	notepad := &PS{Name: "notepad.exe"}
+
+	var tests = []struct {
+		proc  *PS
+		depth int
+		want  *PS
+	}{
+		{nil, 4, nil},
+		{rundll32, 4, winword},
+		{rundll32, 2, cmd},
+		{rundll32, 0, rundll32},
+		{winword, 4, winword},
+		{explorer, 4, explorer},
+		{notepad, 4, notepad},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, TreeRoot(tt.proc, tt.depth))
+	}
+}
+
 func TestPSArgs(t *testing.T) {
 	ps := New(
 		233,
@@ -36,30 +36,34 @@ func Alert(ctx *config.ActionContext, title string, text string, severity string
 	}
 	log.Infof("sending alert: [%s]. Text: %s Event(s): %s", title, text, b.String())
 
+	alert := alertsender.NewAlert(
+		title,
+		text,
+		tags,
+		alertsender.ParseSeverityFromString(severity),
+	)
+
+	alert.ID = ctx.Filter.ID
+	alert.Events = ctx.Events
+	alert.Context = ctx.Context
+	alert.Labels = ctx.Filter.Labels
+	alert.Description = ctx.Filter.Description
+
+	alertsender.Record(alert)
+
 	senders := alertsender.FindAll()
 	if len(senders) == 0 {
 		return fmt.Errorf("no alertsenders registered. Alert won't be sent")
 	}
 
 	for _, sender := range senders {
-		alert := alertsender.NewAlert(
-			title,
-			text,
-			tags,
-			alertsender.ParseSeverityFromString(severity),
-		)
-
-		alert.ID = ctx.Filter.ID
-		alert.Events = ctx.Events
-		alert.Labels = ctx.Filter.Labels
-		alert.Description = ctx.Filter.Description
-
+		alert := alert
 		// strip markdown if not supported by the sender
 		if !sender.SupportsMarkdown() {
 			alert.Text = markdown.Strip(alert.Text)
 		}
 
-		err := sender.Send(alert)
+		err := alertsender.Send(sender, alert)
 		if err != nil {
 			return fmt.Errorf("unable to emit alert from rule via [%s] sender: %v", sender.Type(), err)
 		}
@@ -82,6 +82,9 @@ func (c *compiler) compile() (map[*config.FilterConfig]filter.Filter, *config.Ru
 	}
 
 	filters := make(map[*config.FilterConfig]filter.Filter)
+	// list matchers are shared among rules of this compilation
+	// and released together with filters when rules are replaced
+	matchers := ql.NewMatcherCache()
 
 	for _, f := range c.config.GetFilters() {
 		if f.IsDisabled() {
@@ -92,7 +95,7 @@ func (c *compiler) compile() (map[*config.FilterConfig]filter.Filter, *config.Ru
 		filtersCount.Add(1)
 
 		// compile the filter
-		fltr := filter.New(f.Condition, c.config, filter.WithPSnapshotter(c.psnap))
+		fltr := filter.New(f.Condition, c.config, filter.WithPSnapshotter(c.psnap), filter.WithMatcherCache(matchers))
 		err := fltr.Compile()
 		if err != nil {
 			return nil, nil, ErrInvalidFilter(f.Name, err)
@@ -22,14 +22,19 @@ import (
 	"expvar"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rabbitstack/fibratus/pkg/config"
 	"github.com/rabbitstack/fibratus/pkg/event"
 	"github.com/rabbitstack/fibratus/pkg/filter"
 	"github.com/rabbitstack/fibratus/pkg/filter/fields"
+	"github.com/rabbitstack/fibratus/pkg/health"
+	"github.com/rabbitstack/fibratus/pkg/history"
 	"github.com/rabbitstack/fibratus/pkg/ps"
 	"github.com/rabbitstack/fibratus/pkg/rules/action"
+	"github.com/rabbitstack/fibratus/pkg/tracing"
+	"github.com/rabbitstack/fibratus/pkg/util/histogram"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -43,6 +48,8 @@ var (
 	sequenceGcInterval = time.Minute
 
 	filterMatches = expvar.NewMap("filter.matches")
+	// evaluationLatency measures the time it takes to evaluate the ruleset against the event
+	evaluationLatency = histogram.New("rules.evaluation.seconds", histogram.LatencyBuckets)
 
 	ErrRuleAction = func(rule string, err error) error {
 		return fmt.Errorf("fail to execute action for %q rule: %v", rule, err)
@@ -64,8 +71,15 @@ type Engine struct {
 	scavenger *time.Ticker
 
 	compiler *compiler
+	// rules contains all indexed filters in the order they were compiled
+	rules []*compiledFilter
+	// rs stores the result of the ruleset compilation
+	rs *config.RulesCompileResult
 
 	matchFunc RuleMatchFunc
+
+	// history retains recent events that are attached to alerts
+	history *history.Buffer
 }
 
 type ruleMatch struct {
@@ -76,6 +90,8 @@ type compiledFilter struct {
 	filter filter.Filter
 	config *config.FilterConfig
 	ss     *sequenceState
+	// disabled indicates if the rule was disabled at runtime
+	disabled atomic.Bool
 }
 
 // filterset contains compiled filters indexed by event type and category.
@@ -188,6 +204,8 @@ func (e *Engine) Compile() (*config.RulesCompileResult, error) {
 			continue
 		}
 
+		e.rules = append(e.rules, fltr)
+
 		// traverse all event name or category fields and determine
 		// the event type from the filter field name expression.
 		// We end up with a map of rules indexed by event type
@@ -207,6 +225,10 @@ func (e *Engine) Compile() (*config.RulesCompileResult, error) {
 		}
 	}
 
+	e.rs = rs
+
+	health.Register("rules", e.health)
+
 	return rs, nil
 }
 
@@ -214,6 +236,12 @@ func (e *Engine) RegisterMatchFunc(fn RuleMatchFunc) {
 	e.matchFunc = fn
 }
 
+// RegisterEventHistory sets the recent events buffer. When set, recent
+// events of the offending process tree are attached to alerts as context.
+func (e *Engine) RegisterEventHistory(h *history.Buffer) {
+	e.history = h
+}
+
 func (*Engine) CanEnqueue() bool { return true }
 
 // ProcessEvent processes the system event against compiled filters.
@@ -234,7 +262,14 @@ func (e *Engine) ProcessEvent(evt *event.Event) (bool, error) {
 		}
 	}
 
+	start := time.Now()
+	defer evaluationLatency.Since(start)
+
+	span := tracing.Start(evt.Seq, "rules.eval")
+	defer span.End()
+
 	filters := e.filters.collect(evt)
+	span.SetInt("rules.candidates", int64(len(filters)))
 
 	// acquire valuer cache
 	valuer := filter.AcquireValuerCache()
@@ -243,6 +278,9 @@ func (e *Engine) ProcessEvent(evt *event.Event) (bool, error) {
 	// assert event against compiled ruleset
 	var matches bool
 	for _, f := range filters {
+		if f.disabled.Load() {
+			continue
+		}
 		match := f.eval(evt, valuer)
 		if !match {
 			continue
@@ -253,7 +291,7 @@ func (e *Engine) ProcessEvent(evt *event.Event) (bool, error) {
 		} else {
 			e.appendMatch(f.config, evt)
 		}
-		err := e.processActions()
+		err := e.processActions(evt.Seq)
 		if err != nil {
 			log.Errorf("unable to execute rule action: %v", err)
 		}
@@ -275,8 +313,10 @@ func (e *Engine) ProcessEvent(evt *event.Event) (bool, error) {
 // Sending an alert is an implicit action
 // carried out each time there is a rule
 // match. Other actions are executed if
-// declared in the rule definition.
-func (e *Engine) processActions() error {
+// declared in the rule definition. The seq
+// identifies the event that triggered rule
+// matches and is used to trace actions.
+func (e *Engine) processActions(seq uint64) error {
 	defer e.clearMatches()
 	e.mmu.Lock()
 	defer e.mmu.Unlock()
@@ -285,7 +325,11 @@ func (e *Engine) processActions() error {
 		f, evts := m.ctx.Filter, m.ctx.Events
 		filterMatches.Add(f.Name, 1)
 		log.Debugf("[%s] rule matched", f.Name)
+		span := tracing.Start(seq, "action.alert")
+		span.SetString("rule", f.Name)
 		err := action.Alert(m.ctx, f.Name, filter.InterpolateFields(f.Output, evts), f.Severity, f.Tags)
+		span.SetError(err)
+		span.End()
 		if err != nil {
 			return ErrRuleAction(f.Name, err)
 		}
@@ -299,12 +343,22 @@ func (e *Engine) processActions() error {
 			switch t := act.(type) {
 			case config.KillAction:
 				log.Infof("executing kill action: pids=%v rule=%s", m.ctx.UniquePids(), f.Name)
-				if err := action.Kill(m.ctx.UniquePids()); err != nil {
+				span := tracing.Start(seq, "action.kill")
+				span.SetString("rule", f.Name)
+				err := action.Kill(m.ctx.UniquePids())
+				span.SetError(err)
+				span.End()
+				if err != nil {
 					return ErrRuleAction(f.Name, err)
 				}
 			case config.IsolateAction:
 				log.Infof("executing isolate action: rule=%s", f.Name)
-				if err := action.Isolate(t.Whitelist); err != nil {
+				span := tracing.Start(seq, "action.isolate")
+				span.SetString("rule", f.Name)
+				err := action.Isolate(t.Whitelist)
+				span.SetError(err)
+				span.End()
+				if err != nil {
 					return ErrRuleAction(f.Name, err)
 				}
 			}
@@ -317,6 +371,9 @@ func (e *Engine) processActions() error {
 func (e *Engine) appendMatch(f *config.FilterConfig, evts ...*event.Event) {
 	for _, evt := range evts {
 		evt.AddMeta(event.RuleNameKey, f.Name)
+		if f.Severity != "" {
+			evt.AddMeta(event.RuleSeverityKey, f.Severity)
+		}
 		for k, v := range f.Labels {
 			evt.AddMeta(event.MetadataKey(k), v)
 		}
@@ -325,6 +382,9 @@ func (e *Engine) appendMatch(f *config.FilterConfig, evts ...*event.Event) {
 		Events: evts,
 		Filter: f,
 	}
+	if e.history != nil {
+		ctx.Context = e.history.ProcessTree(evts, e.config.History.AlertContext)
+	}
 	e.mmu.Lock()
 	defer e.mmu.Unlock()
 	e.matches = append(e.matches, &ruleMatch{ctx: ctx})
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"github.com/rabbitstack/fibratus/pkg/health"
+	hconfig "github.com/rabbitstack/fibratus/pkg/health/config"
+)
+
+// health evaluates the rule engine health. The rule engine
+// is degraded if none of the rules is loaded or all rules
+// were disabled at runtime.
+func (e *Engine) health(hconfig.Config) health.Component {
+	var disabled int
+	for _, f := range e.rules {
+		if f.disabled.Load() {
+			disabled++
+		}
+	}
+	comp := health.Component{
+		Status: health.Healthy,
+		Ready:  true,
+		Details: map[string]any{
+			"rules":     len(e.rules),
+			"disabled":  disabled,
+			"sequences": len(e.sequences),
+		},
+	}
+	switch {
+	case len(e.rules) == 0:
+		comp.Status = health.Degraded
+		comp.Message = "no rules loaded"
+	case disabled == len(e.rules):
+		comp.Status = health.Degraded
+		comp.Message = "all rules are disabled"
+	}
+	return comp
+}
@@ -21,6 +21,7 @@ package rules
 import (
 	"context"
 	"expvar"
+	"fmt"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -387,6 +388,70 @@ func (s *sequenceState) clearLocked() {
 	s.clear()
 }
 
+// reset discards all partials and moves the state
+// machine back to the initial state. The locks are
+// acquired in the same order as in the max span
+// deadline callback.
+func (s *sequenceState) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.smu.Lock()
+	defer s.smu.Unlock()
+	for _, span := range s.spanDeadlines {
+		span.Stop()
+	}
+	// pending expression states are expired first
+	// since they can only transition to the initial
+	// state via one of the meta states
+	if _, ok := s.currentState().(int); ok && !s.isInitialState() {
+		if err := s.expireTransition(); err != nil {
+			log.Warnf("expire transition failed: %v", err)
+		}
+	}
+	if !s.isInitialState() {
+		if err := s.fsm.Fire(resetTransition); err != nil {
+			log.Warnf("unable to transition to initial state: %v", err)
+		}
+	}
+	s.mmu.Lock()
+	defer s.mmu.Unlock()
+	s.clear()
+}
+
+// snapshot returns the current state of the sequence and
+// the summary of all partials stored in expression slots.
+func (s *sequenceState) snapshot() *SequenceState {
+	state := &SequenceState{
+		Rule:     s.name,
+		State:    fmt.Sprintf("%v", s.currentState()),
+		MaxSpan:  s.maxSpan,
+		Partials: make([]SequencePartial, 0, len(s.exprs)),
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for idx := range s.seq.Expressions {
+		partial := SequencePartial{
+			Index:  idx,
+			Expr:   s.exprs[idx],
+			Events: make([]PartialEvent, 0, len(s.partials[idx])),
+		}
+		for _, e := range s.partials[idx] {
+			evt := PartialEvent{
+				Seq:       e.Seq,
+				Name:      e.Name,
+				Timestamp: e.Timestamp,
+				PID:       e.PID,
+			}
+			if e.PS != nil {
+				evt.Process = e.PS.Name
+			}
+			partial.Events = append(partial.Events, evt)
+		}
+		state.Partials = append(state.Partials, partial)
+	}
+	return state
+}
+
 // next determines whether the next expression in the
 // sequence should be evaluated. The expression is evaluated
 // if all its upstream sequence expression produced a match and
@@ -412,14 +477,16 @@ func (s *sequenceState) next(seqID int) bool {
 
 func (s *sequenceState) scheduleMaxSpanDeadline(seqID fsm.State, maxSpan time.Duration) {
 	t := time.AfterFunc(maxSpan, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.smu.Lock()
+		defer s.smu.Unlock()
+		// the state is checked under locks since the
+		// sequence could have been reset meanwhile
 		inState, _ := s.fsm.IsInState(seqID)
 		if inState {
 			log.Debugf("max span of %v exceded for expression [%s] of sequence [%s]", maxSpan, s.expr(seqID), s.name)
 			s.inDeadline.Store(true)
-			s.mu.Lock()
-			defer s.mu.Unlock()
-			s.smu.Lock()
-			defer s.smu.Unlock()
 			// transitions to deadline state
 			err := s.cancelTransition(seqID)
 			if err != nil {
@@ -437,6 +504,9 @@ func (s *sequenceState) scheduleMaxSpanDeadline(seqID fsm.State, maxSpan time.Du
 }
 
 func (s *sequenceState) evalSequence(e *event.Event, v *filter.ValuerCache) bool {
+	if s.seq.IsAllOf {
+		return s.evalUnorderedSequence(e, v)
+	}
 	for i, expr := range s.seq.Expressions {
 		// only try to evaluate the expression
 		// if upstream expressions have matched
@@ -557,6 +627,133 @@ func (s *sequenceState) evalSequence(e *event.Event, v *filter.ValuerCache) bool
 	return false
 }
 
+// evalUnorderedSequence evaluates the event against all expressions
+// of the unordered sequence. The event is stored in the partials of
+// every slot whose expression it matches. The sequence matches when
+// each of the remaining slots holds a distinct partial that shares
+// the join link with the event, and all participating events occurred
+// within the max span.
+func (s *sequenceState) evalUnorderedSequence(e *event.Event, v *filter.ValuerCache) bool {
+	slots := make([]int, 0, len(s.seq.Expressions))
+	for i, expr := range s.seq.Expressions {
+		s.mu.RLock()
+		matches := expr.IsEvaluable(e) && s.filter.EvalSequence(e, v, i, s.partials, false)
+		s.mu.RUnlock()
+		if !matches {
+			continue
+		}
+		s.addPartial(i, e, false)
+		slots = append(slots, i)
+	}
+	if len(slots) == 0 {
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var matches map[int]*event.Event
+	for _, slot := range slots {
+		matches = s.correlate(slot, e)
+		if matches != nil {
+			break
+		}
+	}
+
+	s.advance(matches != nil)
+	if matches == nil {
+		return false
+	}
+
+	s.mmu.Lock()
+	defer s.mmu.Unlock()
+	for seqID, evt := range matches {
+		s.matches[seqID] = evt
+	}
+
+	return true
+}
+
+// advance drives the state machine of the unordered sequence. The
+// current state denotes the number of expressions holding partials,
+// so the max span deadline is rescheduled whenever a new expression
+// matches, and partials are discarded through the deadline and
+// expired states in the same way as for ordered sequences. If the
+// sequence matched, the state machine is moved to the terminal state
+// and reset. The caller must hold the partials lock.
+func (s *sequenceState) advance(matched bool) {
+	s.smu.Lock()
+	defer s.smu.Unlock()
+
+	var n int
+	for seqID := range s.seq.Expressions {
+		if len(s.partials[seqID]) > 0 {
+			n++
+		}
+	}
+	n = min(n, len(s.seq.Expressions)-1)
+
+	for {
+		seqID, ok := s.currentState().(int)
+		if !ok || (!matched && seqID >= n) {
+			break
+		}
+		if err := s.fsm.Fire(matchTransition); err != nil {
+			matchTransitionErrors.Add(1)
+			log.Warnf("match transition failure: %v", err)
+			return
+		}
+	}
+
+	if matched {
+		s.isTerminalState()
+	}
+}
+
+// correlate picks a partial for each slot of the unordered sequence, except
+// the slot matched by the given event. The partial closest in time to the
+// event is chosen among all partials sharing the join link. It returns nil
+// if any of the slots can't be satisfied or the events exceed the max span.
+func (s *sequenceState) correlate(slot int, e *event.Event) map[int]*event.Event {
+	matches := map[int]*event.Event{slot: e}
+	chosen := map[*event.Event]bool{e: true}
+	start, end := e.Timestamp, e.Timestamp
+
+	for seqID := range s.seq.Expressions {
+		if seqID == slot {
+			continue
+		}
+		var match *event.Event
+		for _, p := range s.partials[seqID] {
+			if chosen[p] {
+				continue
+			}
+			if s.seq.IsConstrained() && !filter.CompareSeqLinks(e.SequenceLinks(), p.SequenceLinks()) {
+				continue
+			}
+			if match == nil || p.Timestamp.Sub(e.Timestamp).Abs() < match.Timestamp.Sub(e.Timestamp).Abs() {
+				match = p
+			}
+		}
+		if match == nil {
+			return nil
+		}
+		matches[seqID] = match
+		chosen[match] = true
+		if match.Timestamp.Before(start) {
+			start = match.Timestamp
+		}
+		if match.Timestamp.After(end) {
+			end = match.Timestamp
+		}
+	}
+
+	if s.maxSpan != 0 && end.Sub(start) > s.maxSpan {
+		return nil
+	}
+
+	return matches
+}
+
 func (s *sequenceState) expire(e *event.Event) bool {
 	if !e.IsTerminateProcess() {
 		return false
@@ -597,6 +597,194 @@ func TestSequenceMultiLinks(t *testing.T) {
 	require.True(t, runSequence(ss, e2))
 }
 
+func TestSequenceComputedLinks(t *testing.T) {
+	log.SetLevel(log.DebugLevel)
+
+	c := &config.FilterConfig{Name: "Dropped executable spawned"}
+	f := filter.New(`
+	sequence
+	maxspan 1m
+  	|evt.name = 'CreateFile' and file.path iendswith '.exe'| by lower(base(file.path))
+  	|evt.name = 'CreateProcess'| by lower(ps.name)
+	`, &config.Config{EventSource: config.EventSourceConfig{EnableFileIOEvents: true}, Filters: &config.Filters{}})
+	require.NoError(t, f.Compile())
+
+	ss := newSequenceState(f, c, new(ps.SnapshotterMock))
+
+	e1 := &event.Event{
+		Type:      event.CreateFile,
+		Timestamp: time.Now(),
+		Name:      "CreateFile",
+		Tid:       2484,
+		PID:       859,
+		Category:  event.File,
+		PS: &pstypes.PS{
+			Name: "cmd.exe",
+			Exe:  "C:\\Windows\\system32\\cmd.exe",
+		},
+		Params: event.Params{
+			params.FilePath: {Name: params.FilePath, Type: params.UnicodeString, Value: "C:\\Temp\\Dropper.EXE"},
+		},
+		Metadata: map[event.MetadataKey]any{"foo": "bar", "fooz": "barzz"},
+	}
+
+	e2 := &event.Event{
+		Type:      event.CreateProcess,
+		Timestamp: time.Now().Add(time.Millisecond * 20),
+		Name:      "CreateProcess",
+		Tid:       2484,
+		PID:       859,
+		PS: &pstypes.PS{
+			Name: "notepad.exe",
+			Exe:  "C:\\Windows\\system32\\notepad.exe",
+		},
+		Metadata: map[event.MetadataKey]any{"foo": "bar", "fooz": "barzz"},
+	}
+
+	e3 := &event.Event{
+		Type:      event.CreateProcess,
+		Timestamp: time.Now().Add(time.Millisecond * 40),
+		Name:      "CreateProcess",
+		Tid:       2484,
+		PID:       859,
+		PS: &pstypes.PS{
+			Name: "dropper.exe",
+			Exe:  "C:\\Temp\\dropper.exe",
+		},
+		Metadata: map[event.MetadataKey]any{"foo": "bar", "fooz": "barzz"},
+	}
+
+	require.False(t, runSequence(ss, e1))
+	require.False(t, runSequence(ss, e2))
+	require.True(t, runSequence(ss, e3))
+}
+
+func TestSequenceProcessTreeLinks(t *testing.T) {
+	log.SetLevel(log.DebugLevel)
+
+	c := &config.FilterConfig{Name: "Office process tree drops and spawns executable"}
+	f := filter.New(`
+	sequence
+	maxspan 1m
+	by ps.tree
+  	|evt.name = 'CreateFile' and file.path iendswith '.exe'|
+  	|evt.name = 'CreateProcess'|
+	`, &config.Config{EventSource: config.EventSourceConfig{EnableFileIOEvents: true}, Filters: &config.Filters{}})
+	require.NoError(t, f.Compile())
+
+	ss := newSequenceState(f, c, new(ps.SnapshotterMock))
+
+	explorer := &pstypes.PS{PID: 1200, Name: "explorer.exe"}
+	winword := &pstypes.PS{PID: 2200, Name: "WINWORD.EXE", Parent: explorer}
+	cmd := &pstypes.PS{PID: 3200, Name: "cmd.exe", Parent: winword}
+	powershell := &pstypes.PS{PID: 4200, Name: "powershell.exe", Parent: cmd}
+	notepad := &pstypes.PS{PID: 5200, Name: "notepad.exe", Parent: explorer}
+
+	e1 := &event.Event{
+		Type:      event.CreateFile,
+		Timestamp: time.Now(),
+		Name:      "CreateFile",
+		Tid:       2484,
+		PID:       powershell.PID,
+		Category:  event.File,
+		PS:        powershell,
+		Params: event.Params{
+			params.FilePath: {Name: params.FilePath, Type: params.UnicodeString, Value: "C:\\Temp\\dropper.exe"},
+		},
+		Metadata: map[event.MetadataKey]any{"foo": "bar", "fooz": "barzz"},
+	}
+
+	// spawned from an unrelated process tree
+	e2 := &event.Event{
+		Type:      event.CreateProcess,
+		Timestamp: time.Now().Add(time.Millisecond * 20),
+		Name:      "CreateProcess",
+		Tid:       2484,
+		PID:       notepad.PID,
+		PS:        notepad,
+		Metadata:  map[event.MetadataKey]any{"foo": "bar", "fooz": "barzz"},
+	}
+
+	e3 := &event.Event{
+		Type:      event.CreateProcess,
+		Timestamp: time.Now().Add(time.Millisecond * 40),
+		Name:      "CreateProcess",
+		Tid:       2484,
+		PID:       cmd.PID,
+		PS:        cmd,
+		Metadata:  map[event.MetadataKey]any{"foo": "bar", "fooz": "barzz"},
+	}
+
+	require.False(t, runSequence(ss, e1))
+	require.False(t, runSequence(ss, e2))
+	require.True(t, runSequence(ss, e3))
+}
+
+func TestSequenceCrossStepComparisons(t *testing.T) {
+	log.SetLevel(log.DebugLevel)
+
+	c := &config.FilterConfig{Name: "Executable dropped and spawned shortly after"}
+	f := filter.New(`
+	sequence
+	maxspan 1m
+  	|evt.name = 'CreateFile' and file.path iendswith '.exe'| as e1
+  	|evt.name = 'CreateProcess' and base($e1.file.path) ~= ps.name and $e2.evt.time.ns - $e1.evt.time.ns < 5s| as e2
+	`, &config.Config{EventSource: config.EventSourceConfig{EnableFileIOEvents: true}, Filters: &config.Filters{}})
+	require.NoError(t, f.Compile())
+
+	ss := newSequenceState(f, c, new(ps.SnapshotterMock))
+
+	now := time.Now()
+
+	e1 := &event.Event{
+		Type:      event.CreateFile,
+		Timestamp: now,
+		Name:      "CreateFile",
+		Tid:       2484,
+		PID:       859,
+		Category:  event.File,
+		PS: &pstypes.PS{
+			Name: "cmd.exe",
+			Exe:  "C:\\Windows\\system32\\cmd.exe",
+		},
+		Params: event.Params{
+			params.FilePath: {Name: params.FilePath, Type: params.UnicodeString, Value: "C:\\Temp\\dropper.exe"},
+		},
+		Metadata: map[event.MetadataKey]any{"foo": "bar", "fooz": "barzz"},
+	}
+
+	// spawned too late
+	e2 := &event.Event{
+		Type:      event.CreateProcess,
+		Timestamp: now.Add(time.Second * 10),
+		Name:      "CreateProcess",
+		Tid:       2484,
+		PID:       859,
+		PS: &pstypes.PS{
+			Name: "dropper.exe",
+			Exe:  "C:\\Temp\\dropper.exe",
+		},
+		Metadata: map[event.MetadataKey]any{"foo": "bar", "fooz": "barzz"},
+	}
+
+	e3 := &event.Event{
+		Type:      event.CreateProcess,
+		Timestamp: now.Add(time.Second * 2),
+		Name:      "CreateProcess",
+		Tid:       2484,
+		PID:       859,
+		PS: &pstypes.PS{
+			Name: "Dropper.exe",
+			Exe:  "C:\\Temp\\Dropper.exe",
+		},
+		Metadata: map[event.MetadataKey]any{"foo": "bar", "fooz": "barzz"},
+	}
+
+	require.False(t, runSequence(ss, e1))
+	require.False(t, runSequence(ss, e2))
+	require.True(t, runSequence(ss, e3))
+}
+
 func TestComplexSequence(t *testing.T) {
 	log.SetLevel(log.DebugLevel)
 
@@ -1096,6 +1284,133 @@ func TestSequenceBoundFieldsWithFunctions(t *testing.T) {
 	require.True(t, runSequence(ss, e2))
 }
 
+func TestUnorderedSequence(t *testing.T) {
+	log.SetLevel(log.DebugLevel)
+
+	c := &config.FilterConfig{Name: "Registry persistence with dropped DLL"}
+	newFilterWithMaxSpan := func(maxspan string) filter.Filter {
+		f := filter.New(`
+	sequence unordered
+	maxspan `+maxspan+`
+	by ps.exe
+  	|evt.name = 'RegSetValue' and registry.path imatches 'HKEY_CURRENT_USER\\Software\\Microsoft\\Windows\\CurrentVersion\\Run\\*'|
+  	|evt.name = 'CreateFile' and file.path iendswith '.dll'|
+	`, &config.Config{EventSource: config.EventSourceConfig{EnableFileIOEvents: true, EnableRegistryEvents: true}, Filters: &config.Filters{}})
+		require.NoError(t, f.Compile())
+		return f
+	}
+	newFilter := func() filter.Filter { return newFilterWithMaxSpan("5m") }
+
+	now := time.Now()
+
+	newRegEvent := func(pid uint32, exe string, ts time.Time) *event.Event {
+		return &event.Event{
+			Type:      event.RegSetValue,
+			Name:      "RegSetValue",
+			Category:  event.Registry,
+			Timestamp: ts,
+			Tid:       2484,
+			PID:       pid,
+			PS: &pstypes.PS{
+				Name: "dropper.exe",
+				Exe:  exe,
+			},
+			Params: event.Params{
+				params.RegPath: {Name: params.RegPath, Type: params.UnicodeString, Value: "HKEY_CURRENT_USER\\Software\\Microsoft\\Windows\\CurrentVersion\\Run\\Updater"},
+			},
+			Metadata: map[event.MetadataKey]any{"foo": "bar", "fooz": "barzz"},
+		}
+	}
+
+	newFileEvent := func(pid uint32, exe string, ts time.Time) *event.Event {
+		return &event.Event{
+			Type:      event.CreateFile,
+			Name:      "CreateFile",
+			Category:  event.File,
+			Timestamp: ts,
+			Tid:       2484,
+			PID:       pid,
+			PS: &pstypes.PS{
+				Name: "dropper.exe",
+				Exe:  exe,
+			},
+			Params: event.Params{
+				params.FilePath: {Name: params.FilePath, Type: params.UnicodeString, Value: "C:\\Users\\admin\\AppData\\Local\\Temp\\payload.dll"},
+			},
+			Metadata: map[event.MetadataKey]any{"foo": "bar", "fooz": "barzz"},
+		}
+	}
+
+	t.Run("registry first", func(t *testing.T) {
+		ss := newSequenceState(newFilter(), c, new(ps.SnapshotterMock))
+		require.False(t, runSequence(ss, newRegEvent(859, "C:\\Temp\\dropper.exe", now)))
+		require.False(t, runSequence(ss, newFileEvent(860, "C:\\Temp\\loader.exe", now.Add(time.Second))))
+		require.True(t, runSequence(ss, newFileEvent(859, "C:\\Temp\\dropper.exe", now.Add(time.Second*2))))
+		assert.Len(t, ss.events(), 2)
+	})
+
+	t.Run("file first", func(t *testing.T) {
+		ss := newSequenceState(newFilter(), c, new(ps.SnapshotterMock))
+		require.False(t, runSequence(ss, newFileEvent(859, "C:\\Temp\\dropper.exe", now)))
+		require.False(t, runSequence(ss, newFileEvent(861, "C:\\Temp\\dropper.exe", now.Add(time.Second))))
+		require.True(t, runSequence(ss, newRegEvent(859, "C:\\Temp\\dropper.exe", now.Add(time.Second*2))))
+		events := ss.events()
+		require.Len(t, events, 2)
+		// the closest partial is picked
+		assert.Equal(t, now.Add(time.Second), events[0].Timestamp)
+		assert.Equal(t, event.RegSetValue, events[1].Type)
+	})
+
+	t.Run("max span exceeded", func(t *testing.T) {
+		ss := newSequenceState(newFilter(), c, new(ps.SnapshotterMock))
+		require.False(t, runSequence(ss, newFileEvent(859, "C:\\Temp\\dropper.exe", now.Add(-time.Minute*6))))
+		require.False(t, runSequence(ss, newRegEvent(859, "C:\\Temp\\dropper.exe", now)))
+		assert.Len(t, ss.partials[0], 1)
+		assert.Len(t, ss.partials[1], 1)
+	})
+
+	t.Run("state machine", func(t *testing.T) {
+		ss := newSequenceState(newFilter(), c, new(ps.SnapshotterMock))
+		require.False(t, runSequence(ss, newRegEvent(859, "C:\\Temp\\dropper.exe", now)))
+		assert.Equal(t, 1, ss.currentState())
+		assert.Contains(t, ss.spanDeadlines, 1)
+		require.False(t, runSequence(ss, newFileEvent(860, "C:\\Temp\\loader.exe", now.Add(time.Second))))
+		assert.Equal(t, 1, ss.currentState())
+		require.True(t, runSequence(ss, newFileEvent(859, "C:\\Temp\\dropper.exe", now.Add(time.Second*2))))
+		assert.Equal(t, sequenceInitialState, ss.currentState())
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		ss := newSequenceState(newFilterWithMaxSpan("100ms"), c, new(ps.SnapshotterMock))
+		require.False(t, runSequence(ss, newRegEvent(859, "C:\\Temp\\dropper.exe", time.Now())))
+		time.Sleep(time.Millisecond * 150)
+		assert.Equal(t, sequenceInitialState, ss.currentState())
+		assert.Len(t, ss.partials, 0)
+
+		// the sequence matches after the reset
+		require.False(t, runSequence(ss, newRegEvent(859, "C:\\Temp\\dropper.exe", time.Now())))
+		require.True(t, runSequence(ss, newFileEvent(859, "C:\\Temp\\dropper.exe", time.Now())))
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		ss := newSequenceState(newFilter(), c, new(ps.SnapshotterMock))
+		require.False(t, runSequence(ss, newRegEvent(859, "C:\\Temp\\dropper.exe", now)))
+		e := &event.Event{
+			Type: event.TerminateProcess,
+			Name: "TerminateProcess",
+			Tid:  2484,
+			PID:  4,
+			Params: event.Params{
+				params.ProcessID:   {Name: params.ProcessID, Type: params.PID, Value: uint32(859)},
+				params.ProcessName: {Name: params.ProcessName, Type: params.AnsiString, Value: "dropper.exe"},
+			},
+		}
+		require.True(t, ss.expire(e))
+		assert.Equal(t, sequenceInitialState, ss.currentState())
+		assert.Len(t, ss.partials, 0)
+	})
+}
+
 func TestIsExpressionEvaluable(t *testing.T) {
 	log.SetLevel(log.DebugLevel)
 
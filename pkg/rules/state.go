@@ -0,0 +1,177 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"expvar"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/filter/fields"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrRuleNotFound is returned when the rule with the given
+// identifier or name is not present in the compiled ruleset.
+var ErrRuleNotFound = func(id string) error {
+	return fmt.Errorf("rule %q not found", id)
+}
+
+// Rule describes the runtime state of the compiled rule.
+type Rule struct {
+	// ID is the rule identifier.
+	ID string `json:"id"`
+	// Name is the rule name.
+	Name string `json:"name"`
+	// Description is the rule description.
+	Description string `json:"description,omitempty"`
+	// Severity is the severity assigned to alerts produced by the rule.
+	Severity string `json:"severity,omitempty"`
+	// Tags contains the rule tags.
+	Tags []string `json:"tags,omitempty"`
+	// Condition is the rule condition expression.
+	Condition string `json:"condition"`
+	// Sequence indicates if the rule is a sequence rule.
+	Sequence bool `json:"sequence"`
+	// Enabled indicates whether the rule is evaluated by the engine.
+	Enabled bool `json:"enabled"`
+	// Matches is the number of times the rule fired.
+	Matches int64 `json:"matches"`
+	// Events contains event names and categories the rule is indexed by.
+	Events []string `json:"events"`
+}
+
+// SequencePartial contains the partials accumulated for the
+// single expression in the sequence.
+type SequencePartial struct {
+	// Index is the expression index in the sequence.
+	Index int `json:"index"`
+	// Expr is the string representation of the expression.
+	Expr string `json:"expr"`
+	// Events contains the summary of the events stored in the expression slot.
+	Events []PartialEvent `json:"events"`
+}
+
+// PartialEvent summarizes the event held in the sequence state.
+type PartialEvent struct {
+	Seq       uint64    `json:"seq"`
+	Name      string    `json:"name"`
+	Timestamp time.Time `json:"timestamp"`
+	PID       uint32    `json:"pid"`
+	Process   string    `json:"process,omitempty"`
+}
+
+// SequenceState is the snapshot of the sequence rule state machine.
+type SequenceState struct {
+	// Rule is the sequence rule name.
+	Rule string `json:"rule"`
+	// State is the current state of the sequence state machine.
+	State string `json:"state"`
+	// MaxSpan is the maximum time span of the sequence.
+	MaxSpan time.Duration `json:"max_span,omitempty"`
+	// Partials contains the partials for each sequence expression.
+	Partials []SequencePartial `json:"partials"`
+}
+
+// CompileResult returns the result of the last ruleset compilation.
+func (e *Engine) CompileResult() *config.RulesCompileResult { return e.rs }
+
+// Rules returns the runtime state of all compiled rules.
+func (e *Engine) Rules() []Rule {
+	rules := make([]Rule, 0, len(e.rules))
+	for _, f := range e.rules {
+		rules = append(rules, f.state())
+	}
+	return rules
+}
+
+// FindRule returns the runtime state of the rule
+// identified by the rule id or the rule name.
+func (e *Engine) FindRule(id string) (Rule, error) {
+	f := e.findRule(id)
+	if f == nil {
+		return Rule{}, ErrRuleNotFound(id)
+	}
+	return f.state(), nil
+}
+
+// EnableRule enables or disables the rule at runtime. When
+// the sequence rule is disabled, all its partials are discarded.
+func (e *Engine) EnableRule(id string, enabled bool) error {
+	f := e.findRule(id)
+	if f == nil {
+		return ErrRuleNotFound(id)
+	}
+	if f.disabled.Swap(!enabled) == !enabled {
+		return nil
+	}
+	if enabled {
+		log.Infof("[%s] rule enabled", f.config.Name)
+		return nil
+	}
+	log.Infof("[%s] rule disabled", f.config.Name)
+	if f.isSequence() {
+		f.ss.reset()
+	}
+	return nil
+}
+
+// SequenceState returns the snapshot of the sequence rule state.
+func (e *Engine) SequenceState(id string) (*SequenceState, error) {
+	f := e.findRule(id)
+	if f == nil || !f.isSequence() {
+		return nil, ErrRuleNotFound(id)
+	}
+	return f.ss.snapshot(), nil
+}
+
+func (e *Engine) findRule(id string) *compiledFilter {
+	for _, f := range e.rules {
+		if f.config.ID == id || f.config.Name == id {
+			return f
+		}
+	}
+	return nil
+}
+
+func (f *compiledFilter) state() Rule {
+	r := Rule{
+		ID:          f.config.ID,
+		Name:        f.config.Name,
+		Description: f.config.Description,
+		Severity:    f.config.Severity,
+		Tags:        f.config.Tags,
+		Condition:   f.config.Condition,
+		Sequence:    f.isSequence(),
+		Enabled:     !f.disabled.Load(),
+		Events:      make([]string, 0),
+	}
+	if v, ok := filterMatches.Get(f.config.Name).(*expvar.Int); ok {
+		r.Matches = v.Value()
+	}
+	for name, values := range f.filter.GetStringFields() {
+		if name == fields.EvtName || name == fields.EvtCategory {
+			r.Events = append(r.Events, values...)
+		}
+	}
+	sort.Strings(r.Events)
+	return r
+}
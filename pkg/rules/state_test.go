@@ -0,0 +1,178 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/event"
+	"github.com/rabbitstack/fibratus/pkg/event/params"
+	"github.com/rabbitstack/fibratus/pkg/fs"
+	"github.com/rabbitstack/fibratus/pkg/ps"
+	"github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineRuleState(t *testing.T) {
+	e := NewEngine(new(ps.SnapshotterMock), newConfig("_fixtures/sequence_rule_ps_uuid.yml", "_fixtures/simple_matches.yml"))
+	compileRules(t, e)
+
+	require.NotNil(t, e.CompileResult())
+	rules := e.Rules()
+	require.Len(t, rules, 2)
+
+	rule, err := e.FindRule("872902be-76e9-4ee7-a48a-6275fa571cf4")
+	require.NoError(t, err)
+	assert.Equal(t, "Unique process id", rule.Name)
+	assert.True(t, rule.Sequence)
+	assert.True(t, rule.Enabled)
+	assert.Equal(t, []string{"CreateFile", "CreateProcess"}, rule.Events)
+
+	rule, err = e.FindRule("match https connections")
+	require.NoError(t, err)
+	assert.False(t, rule.Sequence)
+
+	_, err = e.FindRule("unknown")
+	require.Error(t, err)
+	require.Error(t, e.EnableRule("unknown", false))
+	_, err = e.SequenceState("match https connections")
+	require.Error(t, err)
+
+	e1 := &event.Event{
+		Seq:       1,
+		Type:      event.CreateProcess,
+		Timestamp: time.Now(),
+		Category:  event.Process,
+		Name:      "CreateProcess",
+		Tid:       2243,
+		PID:       uint32(os.Getpid()),
+		PS: &types.PS{
+			PID:  uint32(os.Getpid()),
+			Name: "firefox.exe",
+			Exe:  "C:\\Program Files\\Firefox\\firefox.exe",
+		},
+		Params: event.Params{
+			params.ProcessID:   {Name: params.ProcessID, Type: params.PID, Value: uint32(os.Getpid())},
+			params.ProcessName: {Name: params.ProcessName, Type: params.UnicodeString, Value: "firefox.exe"},
+		},
+		Metadata: make(map[event.MetadataKey]any),
+	}
+
+	e2 := &event.Event{
+		Seq:       2,
+		Type:      event.CreateFile,
+		Timestamp: time.Now().Add(time.Second),
+		Name:      "CreateFile",
+		Tid:       2484,
+		PID:       uint32(os.Getpid()),
+		Category:  event.File,
+		PS: &types.PS{
+			PID:  uint32(os.Getpid()),
+			Name: "firefox.exe",
+			Exe:  "C:\\Program Files\\Mozilla Firefox\\firefox.exe",
+		},
+		Params: event.Params{
+			params.FilePath:      {Name: params.FilePath, Type: params.UnicodeString, Value: "C:\\Temp\\dropper.exe"},
+			params.FileOperation: {Name: params.FileOperation, Type: params.Enum, Value: uint32(2), Enum: fs.FileCreateDispositions},
+		},
+		Metadata: make(map[event.MetadataKey]any),
+	}
+
+	require.False(t, wrapProcessEvent(e1, e.ProcessEvent))
+
+	state, err := e.SequenceState("Unique process id")
+	require.NoError(t, err)
+	assert.Equal(t, "1", state.State)
+	require.Len(t, state.Partials, 2)
+	require.Len(t, state.Partials[0].Events, 1)
+	assert.Equal(t, uint64(1), state.Partials[0].Events[0].Seq)
+	assert.Equal(t, "firefox.exe", state.Partials[0].Events[0].Process)
+	assert.Empty(t, state.Partials[1].Events)
+
+	// disabling the sequence discards partials
+	require.NoError(t, e.EnableRule("Unique process id", false))
+	rule, err = e.FindRule("Unique process id")
+	require.NoError(t, err)
+	assert.False(t, rule.Enabled)
+
+	state, err = e.SequenceState("Unique process id")
+	require.NoError(t, err)
+	assert.Equal(t, "0", state.State)
+	assert.Empty(t, state.Partials[0].Events)
+
+	require.False(t, wrapProcessEvent(e1, e.ProcessEvent))
+	require.False(t, wrapProcessEvent(e2, e.ProcessEvent))
+
+	require.NoError(t, e.EnableRule("Unique process id", true))
+	e2.Timestamp = time.Now().Add(time.Second)
+	require.False(t, wrapProcessEvent(e1, e.ProcessEvent))
+	require.True(t, wrapProcessEvent(e2, e.ProcessEvent))
+}
+
+func TestEngineRuleStateToggleRace(t *testing.T) {
+	e := NewEngine(new(ps.SnapshotterMock), newConfig("_fixtures/sequence_rule_ps_uuid.yml"))
+	compileRules(t, e)
+
+	newEvent := func(seq uint64) *event.Event {
+		return &event.Event{
+			Seq:       seq,
+			Type:      event.CreateProcess,
+			Timestamp: time.Now(),
+			Category:  event.Process,
+			Name:      "CreateProcess",
+			Tid:       2243,
+			PID:       uint32(os.Getpid()),
+			PS: &types.PS{
+				PID:  uint32(os.Getpid()),
+				Name: "firefox.exe",
+				Exe:  "C:\\Program Files\\Firefox\\firefox.exe",
+			},
+			Params: event.Params{
+				params.ProcessID:   {Name: params.ProcessID, Type: params.PID, Value: uint32(os.Getpid())},
+				params.ProcessName: {Name: params.ProcessName, Type: params.UnicodeString, Value: "firefox.exe"},
+			},
+			Metadata: make(map[event.MetadataKey]any),
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 500; i++ {
+			wrapProcessEvent(newEvent(uint64(i)), e.ProcessEvent)
+		}
+	}()
+
+	// toggle the rule while events are being processed
+	for i := 0; i < 500; i++ {
+		require.NoError(t, e.EnableRule("Unique process id", i%2 != 0))
+		_, err := e.SequenceState("Unique process id")
+		require.NoError(t, err)
+	}
+	<-done
+
+	require.NoError(t, e.EnableRule("Unique process id", false))
+	state, err := e.SequenceState("Unique process id")
+	require.NoError(t, err)
+	assert.Equal(t, "0", state.State)
+	assert.Empty(t, state.Partials[0].Events)
+}
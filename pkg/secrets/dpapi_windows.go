@@ -0,0 +1,113 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secrets
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// entropy is the additional entropy mixed into the encryption key
+var entropy = []byte("fibratus-keystore")
+
+// defaultProtector encrypts keystore entries with DPAPI
+var defaultProtector protector = dpapi{}
+
+// keystoreDescriptor grants full access to the keystore file only to the
+// local system account and the built-in administrators group. The DACL is
+// protected, so permissions aren't inherited from the parent directory.
+const keystoreDescriptor = "D:P(A;;FA;;;SY)(A;;FA;;;BA)"
+
+// dpapi encrypts secrets with the Data Protection API. Secrets are bound to
+// the local machine rather than to the user, so the keystore populated by the
+// administrator can be decrypted by the agent running as the Windows service.
+// Secrets can't be decrypted on other machines.
+//
+// The machine-bound key means any process running on the machine is able to
+// decrypt the keystore entries, provided it can read the keystore file. The
+// keystore file is therefore the trust boundary. It is created with the DACL
+// that only allows access to the local system account and administrators.
+// Keystore entries are protected from unprivileged local users and from the
+// disclosure of the keystore file copied to another machine, but not from
+// administrators and processes running as the local system account.
+type dpapi struct{}
+
+func (dpapi) protect(b []byte) ([]byte, error) {
+	var out windows.DataBlob
+	err := windows.CryptProtectData(blob(b), nil, blob(entropy), 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN|windows.CRYPTPROTECT_LOCAL_MACHINE, &out)
+	if err != nil {
+		return nil, err
+	}
+	return copyBlob(&out), nil
+}
+
+func (dpapi) unprotect(b []byte) ([]byte, error) {
+	var out windows.DataBlob
+	err := windows.CryptUnprotectData(blob(b), nil, blob(entropy), 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out)
+	if err != nil {
+		return nil, err
+	}
+	return copyBlob(&out), nil
+}
+
+func blob(b []byte) *windows.DataBlob {
+	if len(b) == 0 {
+		return &windows.DataBlob{}
+	}
+	return &windows.DataBlob{Size: uint32(len(b)), Data: &b[0]}
+}
+
+// copyBlob copies the output blob to the Go-managed memory and frees the blob.
+func copyBlob(b *windows.DataBlob) []byte {
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(b.Data)))
+	out := make([]byte, b.Size)
+	copy(out, unsafe.Slice(b.Data, b.Size))
+	return out
+}
+
+// writeFile creates the file with the DACL restricted to the local system
+// account and administrators, and writes the data to it. The file must not
+// exist, since the security descriptor is not applied to existing files.
+func writeFile(path string, b []byte) error {
+	sd, err := windows.SecurityDescriptorFromString(keystoreDescriptor)
+	if err != nil {
+		return err
+	}
+	sa := &windows.SecurityAttributes{SecurityDescriptor: sd}
+	sa.Length = uint32(unsafe.Sizeof(*sa))
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return err
+	}
+	h, err := windows.CreateFile(name, windows.GENERIC_WRITE, 0, sa, windows.CREATE_NEW, windows.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		return err
+	}
+	f := os.NewFile(uintptr(h), path)
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
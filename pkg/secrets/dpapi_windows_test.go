@@ -0,0 +1,42 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secrets
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDPAPIKeystore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fibratus.keystore")
+
+	ks, err := OpenKeystore(path)
+	require.NoError(t, err)
+	require.NoError(t, ks.Set("es-password", "changeme"))
+	require.NoError(t, ks.Save())
+
+	ks, err = OpenKeystore(path)
+	require.NoError(t, err)
+	secret, err := ks.Get("es-password")
+	require.NoError(t, err)
+	assert.Equal(t, "changeme", secret)
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// keystoreVersion is the version of the keystore file format
+const keystoreVersion = 1
+
+// validName matches valid keystore entry names
+var validName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+var (
+	// ErrSecretNotFound is raised when the keystore doesn't contain the secret
+	ErrSecretNotFound = func(name string) error { return fmt.Errorf("secret %s not found in the keystore", name) }
+	// ErrInvalidSecretName is raised when the secret name contains illegal characters
+	ErrInvalidSecretName = func(name string) error {
+		return fmt.Errorf("invalid secret name %q: only letters, digits, dots, dashes, and underscores are allowed", name)
+	}
+	// ErrUnsupportedKeystore is raised when the keystore file format is not recognized
+	ErrUnsupportedKeystore = errors.New("unsupported keystore version")
+)
+
+// protector encrypts and decrypts keystore entries.
+type protector interface {
+	protect(b []byte) ([]byte, error)
+	unprotect(b []byte) ([]byte, error)
+}
+
+// Keystore is the local file that stores encrypted secrets. Each
+// secret is encrypted individually, so listing the keystore entries
+// doesn't require decrypting them.
+type Keystore struct {
+	path    string
+	p       protector
+	secrets map[string][]byte
+}
+
+// keystoreFile is the on-disk representation of the keystore.
+type keystoreFile struct {
+	Version int               `json:"version"`
+	Secrets map[string][]byte `json:"secrets"`
+}
+
+// DefaultKeystorePath returns the path of the keystore in the configuration directory.
+func DefaultKeystorePath() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return filepath.Join(os.Getenv("PROGRAMFILES"), "Fibratus", "Config", "fibratus.keystore")
+	}
+	return filepath.Join(filepath.Dir(exe), "..", "Config", "fibratus.keystore")
+}
+
+// OpenKeystore opens the keystore from the given path. The empty
+// keystore is returned if the keystore file doesn't exist.
+func OpenKeystore(path string) (*Keystore, error) {
+	return openKeystore(path, defaultProtector)
+}
+
+func openKeystore(path string, p protector) (*Keystore, error) {
+	ks := &Keystore{path: path, p: p, secrets: make(map[string][]byte)}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ks, nil
+		}
+		return nil, fmt.Errorf("unable to read keystore: %v", err)
+	}
+	var f keystoreFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("invalid keystore %s: %v", path, err)
+	}
+	if f.Version != keystoreVersion {
+		return nil, ErrUnsupportedKeystore
+	}
+	if f.Secrets != nil {
+		ks.secrets = f.Secrets
+	}
+	return ks, nil
+}
+
+// Get decrypts and returns the secret.
+func (ks *Keystore) Get(name string) (string, error) {
+	b, ok := ks.secrets[name]
+	if !ok {
+		return "", ErrSecretNotFound(name)
+	}
+	secret, err := ks.p.unprotect(b)
+	if err != nil {
+		return "", fmt.Errorf("unable to decrypt secret %s: %v", name, err)
+	}
+	return string(secret), nil
+}
+
+// Set encrypts and stores the secret. Changes are persisted by calling Save.
+func (ks *Keystore) Set(name, secret string) error {
+	if !validName.MatchString(name) {
+		return ErrInvalidSecretName(name)
+	}
+	b, err := ks.p.protect([]byte(secret))
+	if err != nil {
+		return fmt.Errorf("unable to encrypt secret %s: %v", name, err)
+	}
+	ks.secrets[name] = b
+	return nil
+}
+
+// Remove removes the secret. Changes are persisted by calling Save.
+func (ks *Keystore) Remove(name string) error {
+	if _, ok := ks.secrets[name]; !ok {
+		return ErrSecretNotFound(name)
+	}
+	delete(ks.secrets, name)
+	return nil
+}
+
+// Names returns sorted names of stored secrets.
+func (ks *Keystore) Names() []string {
+	names := make([]string, 0, len(ks.secrets))
+	for name := range ks.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Save writes the keystore to the file. The file is written to the
+// temporary location first, and then atomically renamed. Only the
+// owner of the file can access the keystore.
+func (ks *Keystore) Save() error {
+	b, err := json.MarshalIndent(keystoreFile{Version: keystoreVersion, Secrets: ks.secrets}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(ks.path), os.ModePerm); err != nil {
+		return err
+	}
+	tmp := ks.path + ".tmp"
+	if err := writeFile(tmp, b); err != nil {
+		return fmt.Errorf("unable to write keystore: %v", err)
+	}
+	return os.Rename(tmp, ks.path)
+}
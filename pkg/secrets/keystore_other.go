@@ -0,0 +1,47 @@
+//go:build !windows
+// +build !windows
+
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secrets
+
+import (
+	"errors"
+	"os"
+)
+
+// defaultProtector rejects keystore operations since
+// the keystore encryption relies on the Data Protection
+// API that is only available on Windows.
+var defaultProtector protector = unsupported{}
+
+type unsupported struct{}
+
+func (unsupported) protect([]byte) ([]byte, error) {
+	return nil, errors.New("keystore encryption is only supported on Windows")
+}
+
+func (unsupported) unprotect([]byte) ([]byte, error) {
+	return nil, errors.New("keystore encryption is only supported on Windows")
+}
+
+// writeFile writes the data to the file only accessible by the owner.
+func writeFile(path string, b []byte) error {
+	return os.WriteFile(path, b, 0600)
+}
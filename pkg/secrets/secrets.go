@@ -0,0 +1,119 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package secrets resolves references to credentials kept outside of
+// configuration files. Configuration values can refer to the file that
+// stores the secret, the environment variable, or the entry in the
+// encrypted local keystore.
+package secrets
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	// FileScheme is the prefix of references to secret files
+	FileScheme = "secret://"
+	// EnvScheme is the prefix of references to environment variables
+	EnvScheme = "env://"
+	// KeystoreScheme is the prefix of references to keystore entries
+	KeystoreScheme = "keystore://"
+)
+
+// redacted is the placeholder for redacted values
+const redacted = "********"
+
+// KeystorePath is the path of the keystore file used to resolve keystore references.
+var KeystorePath = DefaultKeystorePath()
+
+// ErrEnvNotSet is raised when the referenced environment variable is not set
+var ErrEnvNotSet = func(name string) error { return fmt.Errorf("environment variable %s is not set", name) }
+
+// IsReference determines if the value is a secret reference.
+func IsReference(s string) bool {
+	return strings.HasPrefix(s, FileScheme) || strings.HasPrefix(s, EnvScheme) || strings.HasPrefix(s, KeystoreScheme)
+}
+
+// Resolve returns the secret the value refers to. The following references
+// are recognized:
+//
+//   - secret://path reads the secret from the file, without trailing new lines
+//   - env://NAME reads the secret from the environment variable
+//   - keystore://NAME reads the secret from the encrypted keystore
+//
+// Values that are not references are returned unchanged.
+func Resolve(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, FileScheme):
+		path := strings.TrimPrefix(s, FileScheme)
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("unable to read secret file: %v", err)
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	case strings.HasPrefix(s, EnvScheme):
+		name := strings.TrimPrefix(s, EnvScheme)
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", ErrEnvNotSet(name)
+		}
+		return v, nil
+	case strings.HasPrefix(s, KeystoreScheme):
+		ks, err := OpenKeystore(KeystorePath)
+		if err != nil {
+			return "", err
+		}
+		return ks.Get(strings.TrimPrefix(s, KeystoreScheme))
+	default:
+		return s, nil
+	}
+}
+
+// IsSensitive determines if the setting key is likely to hold credentials.
+// Only the last segment of the dotted key is considered.
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key[strings.LastIndex(key, ".")+1:])
+	return strings.Contains(key, "password") || strings.Contains(key, "token") || strings.Contains(key, "secret")
+}
+
+// Redact hides the value of sensitive settings. Secret references are
+// retained since they don't disclose the secret, while the passwords
+// embedded in URLs are redacted regardless of the key.
+func Redact(key, value string) string {
+	if IsReference(value) {
+		return value
+	}
+	if IsSensitive(key) {
+		if value == "" {
+			return value
+		}
+		return redacted
+	}
+	if strings.Contains(value, "@") && strings.Contains(value, "://") {
+		u, err := url.Parse(value)
+		if err == nil && u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				return u.Redacted()
+			}
+		}
+	}
+	return value
+}
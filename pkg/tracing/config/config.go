@@ -0,0 +1,110 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	enabled       = "tracing.enabled"
+	sampleRate    = "tracing.sample-rate"
+	exporter      = "tracing.exporter"
+	protocol      = "tracing.protocol"
+	endpoint      = "tracing.endpoint"
+	headers       = "tracing.headers"
+	file          = "tracing.file"
+	flushInterval = "tracing.flush-interval"
+	maxQueueSize  = "tracing.max-queue-size"
+	timeout       = "tracing.timeout"
+)
+
+// Exporter is the alias for the span exporter type.
+type Exporter string
+
+const (
+	// OTLP exports spans to the OpenTelemetry collector.
+	OTLP Exporter = "otlp"
+	// File writes spans to the local file as JSON lines.
+	File Exporter = "file"
+)
+
+// Protocol is the alias for the OTLP transport protocol.
+type Protocol string
+
+const (
+	// HTTP sends protobuf-encoded spans over HTTP.
+	HTTP Protocol = "http"
+	// GRPC sends spans through the gRPC trace service.
+	GRPC Protocol = "grpc"
+)
+
+// Config contains the options for tracing the event processing pipeline.
+type Config struct {
+	// Enabled indicates if self-tracing is enabled.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// SampleRate is the ratio of events, in the [0, 1] range, for which the spans are recorded.
+	SampleRate float64 `json:"sample-rate" yaml:"sample-rate"`
+	// Exporter designates where the spans are exported.
+	Exporter Exporter `json:"exporter" yaml:"exporter"`
+	// Protocol is the transport protocol used to export spans to the collector.
+	Protocol Protocol `json:"protocol" yaml:"protocol"`
+	// Endpoint is the collector endpoint.
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+	// Headers contains additional headers/metadata sent on each export request.
+	Headers map[string]string `json:"headers" yaml:"headers"`
+	// File is the path of the file where spans are written by the file exporter.
+	File string `json:"file" yaml:"file"`
+	// FlushInterval determines how often the recorded spans are exported.
+	FlushInterval time.Duration `json:"flush-interval" yaml:"flush-interval"`
+	// MaxQueueSize is the max number of spans waiting to be exported.
+	MaxQueueSize int `json:"max-queue-size" yaml:"max-queue-size"`
+	// Timeout represents the timeout for the export requests.
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+// InitFromViper initializes tracing config from Viper.
+func (c *Config) InitFromViper(v *viper.Viper) {
+	c.Enabled = v.GetBool(enabled)
+	c.SampleRate = v.GetFloat64(sampleRate)
+	c.Exporter = Exporter(v.GetString(exporter))
+	c.Protocol = Protocol(v.GetString(protocol))
+	c.Endpoint = v.GetString(endpoint)
+	c.Headers = v.GetStringMapString(headers)
+	c.File = v.GetString(file)
+	c.FlushInterval = v.GetDuration(flushInterval)
+	c.MaxQueueSize = v.GetInt(maxQueueSize)
+	c.Timeout = v.GetDuration(timeout)
+}
+
+// AddFlags adds tracing config flags to the set.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool(enabled, false, "Indicates if self-tracing of the event processing pipeline is enabled")
+	flags.Float64(sampleRate, 0.001, "Specifies the ratio of events, in the [0, 1] range, for which the spans are recorded")
+	flags.String(exporter, string(OTLP), "Designates where the spans are exported. Possible values are otlp and file")
+	flags.String(protocol, string(HTTP), "The transport protocol used to export spans to the collector. Possible values are http and grpc")
+	flags.String(endpoint, "http://localhost:4318/v1/traces", "The collector endpoint. For gRPC protocol, the endpoint is specified as host:port")
+	flags.String(file, "", "The path of the file where spans are written by the file exporter. Defaults to traces.json in the logs directory")
+	flags.Duration(flushInterval, time.Second*5, "Determines how often the recorded spans are exported")
+	flags.Int(maxQueueSize, 4096, "Specifies the max number of spans waiting to be exported. Spans are dropped when the queue is full")
+	flags.Duration(timeout, time.Second*10, "Represents the timeout for the export requests")
+}
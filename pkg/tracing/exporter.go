@@ -0,0 +1,308 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tracing
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/tracing/config"
+	"github.com/rabbitstack/fibratus/pkg/util/version"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// scopeName is the name of the instrumentation scope that emits spans
+const scopeName = "fibratus"
+
+// protobufContentType is the content type of OTLP/HTTP binary protobuf payloads
+const protobufContentType = "application/x-protobuf"
+
+// exporter ships the batch of ended spans.
+type exporter interface {
+	export(ctx context.Context, spans []*Span) error
+	close() error
+}
+
+func newExporter(c config.Config) (exporter, error) {
+	switch c.Exporter {
+	case config.OTLP, "":
+		switch c.Protocol {
+		case config.HTTP, "":
+			return newHTTPExporter(c), nil
+		case config.GRPC:
+			return newGRPCExporter(c)
+		default:
+			return nil, fmt.Errorf("unknown tracing protocol: %s", c.Protocol)
+		}
+	case config.File:
+		return newFileExporter(c)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter: %s", c.Exporter)
+	}
+}
+
+// fileExporter writes spans to the local file as JSON lines.
+type fileExporter struct {
+	f *os.File
+	w *bufio.Writer
+}
+
+// fileSpan is the JSON representation of the span written by the file exporter.
+type fileSpan struct {
+	Seq        uint64         `json:"seq"`
+	TraceID    string         `json:"trace_id"`
+	SpanID     string         `json:"span_id"`
+	ParentID   string         `json:"parent_span_id,omitempty"`
+	Name       string         `json:"name"`
+	Start      time.Time      `json:"start"`
+	End        time.Time      `json:"end"`
+	Duration   string         `json:"duration"`
+	DurationNs int64          `json:"duration_ns"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+func newFileExporter(c config.Config) (*fileExporter, error) {
+	path := c.File
+	if path == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			path = filepath.Join(os.Getenv("PROGRAMFILES"), "Fibratus", "Logs", "traces.json")
+		} else {
+			path = filepath.Join(filepath.Dir(exe), "..", "Logs", "traces.json")
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("unable to create traces directory: %v", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open traces file: %v", err)
+	}
+	return &fileExporter{f: f, w: bufio.NewWriter(f)}, nil
+}
+
+func (e *fileExporter) export(_ context.Context, spans []*Span) error {
+	enc := json.NewEncoder(e.w)
+	for _, s := range spans {
+		fs := fileSpan{
+			Seq:        s.seq,
+			TraceID:    hex.EncodeToString(traceID(s)),
+			SpanID:     hex.EncodeToString(idBytes(s.spanID)),
+			Name:       s.name,
+			Start:      s.start,
+			End:        s.end,
+			Duration:   s.end.Sub(s.start).String(),
+			DurationNs: s.end.Sub(s.start).Nanoseconds(),
+			Error:      s.err,
+		}
+		if s.parentID != 0 {
+			fs.ParentID = hex.EncodeToString(idBytes(s.parentID))
+		}
+		if len(s.attrs) > 0 {
+			fs.Attributes = make(map[string]any, len(s.attrs))
+			for _, attr := range s.attrs {
+				fs.Attributes[attr.Key] = attr.Value
+			}
+		}
+		if err := enc.Encode(fs); err != nil {
+			return err
+		}
+	}
+	return e.w.Flush()
+}
+
+func (e *fileExporter) close() error {
+	if err := e.w.Flush(); err != nil {
+		_ = e.f.Close()
+		return err
+	}
+	return e.f.Close()
+}
+
+// httpExporter sends binary protobuf encoded export requests over HTTP.
+type httpExporter struct {
+	client *http.Client
+	c      config.Config
+}
+
+func newHTTPExporter(c config.Config) *httpExporter {
+	client := &http.Client{
+		Transport: &http.Transport{Proxy: http.ProxyFromEnvironment},
+		Timeout:   c.Timeout,
+	}
+	return &httpExporter{client: client, c: c}
+}
+
+func (e *httpExporter) export(ctx context.Context, spans []*Span) error {
+	buf, err := proto.Marshal(newExportRequest(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.c.Endpoint, bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", version.ProductToken())
+	req.Header.Set("Content-Type", protobufContentType)
+	for k, v := range e.c.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("otlp export failed with %d status code: %s", resp.StatusCode, string(body))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (e *httpExporter) close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// grpcExporter sends export requests through the gRPC trace service.
+type grpcExporter struct {
+	conn   *grpc.ClientConn
+	client coltracepb.TraceServiceClient
+	c      config.Config
+}
+
+func newGRPCExporter(c config.Config) (*grpcExporter, error) {
+	conn, err := grpc.NewClient(
+		c.Endpoint,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUserAgent(version.ProductToken()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create otlp grpc client: %v", err)
+	}
+	return &grpcExporter{conn: conn, client: coltracepb.NewTraceServiceClient(conn), c: c}, nil
+}
+
+func (e *grpcExporter) export(ctx context.Context, spans []*Span) error {
+	if len(e.c.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(e.c.Headers))
+	}
+	_, err := e.client.Export(ctx, newExportRequest(spans))
+	return err
+}
+
+func (e *grpcExporter) close() error {
+	return e.conn.Close()
+}
+
+// newExportRequest builds the trace export request from the given spans.
+func newExportRequest(spans []*Span) *coltracepb.ExportTraceServiceRequest {
+	host, _ := os.Hostname()
+	scope := &tracepb.ScopeSpans{
+		Scope: &commonpb.InstrumentationScope{Name: scopeName, Version: version.Get()},
+		Spans: make([]*tracepb.Span, 0, len(spans)),
+	}
+	for _, s := range spans {
+		scope.Spans = append(scope.Spans, newSpan(s))
+	}
+	return &coltracepb.ExportTraceServiceRequest{
+		ResourceSpans: []*tracepb.ResourceSpans{
+			{
+				Resource: &resourcepb.Resource{
+					Attributes: []*commonpb.KeyValue{
+						attr("service.name", "fibratus"),
+						attr("service.version", version.Get()),
+						attr("host.name", host),
+						attr("process.pid", int64(os.Getpid())),
+					},
+				},
+				ScopeSpans: []*tracepb.ScopeSpans{scope},
+			},
+		},
+	}
+}
+
+func newSpan(s *Span) *tracepb.Span {
+	span := &tracepb.Span{
+		TraceId:           traceID(s),
+		SpanId:            idBytes(s.spanID),
+		Name:              s.name,
+		Kind:              tracepb.Span_SPAN_KIND_INTERNAL,
+		StartTimeUnixNano: uint64(s.start.UnixNano()),
+		EndTimeUnixNano:   uint64(s.end.UnixNano()),
+		Attributes:        make([]*commonpb.KeyValue, 0, len(s.attrs)+1),
+	}
+	if s.parentID != 0 {
+		span.ParentSpanId = idBytes(s.parentID)
+	}
+	span.Attributes = append(span.Attributes, attr("event.seq", int64(s.seq)))
+	for _, a := range s.attrs {
+		span.Attributes = append(span.Attributes, attr(a.Key, a.Value))
+	}
+	if s.err != "" {
+		span.Status = &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR, Message: s.err}
+	}
+	return span
+}
+
+func attr(key string, value any) *commonpb.KeyValue {
+	var v *commonpb.AnyValue
+	switch val := value.(type) {
+	case string:
+		v = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: val}}
+	case int64:
+		v = &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: val}}
+	case bool:
+		v = &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: val}}
+	default:
+		v = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: fmt.Sprintf("%v", val)}}
+	}
+	return &commonpb.KeyValue{Key: key, Value: v}
+}
+
+func traceID(s *Span) []byte {
+	id := s.t.traceID(s.seq)
+	return id[:]
+}
+
+func idBytes(id uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, id)
+	return b
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tracing
+
+import (
+	"time"
+)
+
+// Attr is the span attribute.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// Span represents the unit of work performed on behalf of the
+// sampled event. All methods are safe to call on the nil span,
+// so the call sites don't have to check whether the event is
+// sampled.
+type Span struct {
+	t        *Tracer
+	seq      uint64
+	name     string
+	spanID   uint64
+	parentID uint64
+	start    time.Time
+	end      time.Time
+	attrs    []Attr
+	err      string
+	// nested indicates if the span is tracked in the stack of open spans
+	nested bool
+}
+
+// SetString sets the string attribute.
+func (s *Span) SetString(key, value string) {
+	if s == nil {
+		return
+	}
+	s.attrs = append(s.attrs, Attr{Key: key, Value: value})
+}
+
+// SetInt sets the integer attribute.
+func (s *Span) SetInt(key string, value int64) {
+	if s == nil {
+		return
+	}
+	s.attrs = append(s.attrs, Attr{Key: key, Value: value})
+}
+
+// SetBool sets the boolean attribute.
+func (s *Span) SetBool(key string, value bool) {
+	if s == nil {
+		return
+	}
+	s.attrs = append(s.attrs, Attr{Key: key, Value: value})
+}
+
+// SetError marks the span as failed if the error is not nil.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.err = err.Error()
+}
+
+// End completes the span and submits it for export.
+func (s *Span) End() {
+	if s == nil || !s.end.IsZero() {
+		return
+	}
+	s.end = time.Now()
+	if s.nested {
+		s.t.pop(s)
+	}
+	s.t.enqueue(s)
+}
@@ -0,0 +1,323 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package tracing records sampled spans that describe how much time each
+// stage of the event processing pipeline spends on the given event. Spans
+// of the same event share the trace identifier derived from the event
+// sequence number, so the whole journey of the event, from the moment it
+// is pushed to the queue until it is published to outputs, can be inspected
+// as a single trace.
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"expvar"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/tracing/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxBatchSize is the max number of spans in a single export request
+const maxBatchSize = 512
+
+var (
+	// spansRecorded counts the number of recorded spans
+	spansRecorded = expvar.NewInt("tracing.spans.recorded")
+	// spansDropped counts the number of spans dropped due to full export queue
+	spansDropped = expvar.NewInt("tracing.spans.dropped")
+	// spansExported counts the number of exported spans
+	spansExported = expvar.NewInt("tracing.spans.exported")
+	// exportErrors counts the number of failed span exports
+	exportErrors = expvar.NewInt("tracing.export.errors")
+)
+
+// ErrInvalidSampleRate is raised when the sample rate is not within the [0, 1] range
+var ErrInvalidSampleRate = func(rate float64) error {
+	return fmt.Errorf("invalid sample rate %v: must be in the [0, 1] range", rate)
+}
+
+// tracer is the active tracer. Nil if tracing is disabled.
+var tracer atomic.Pointer[Tracer]
+
+// Tracer samples events, records the spans of sampled
+// events and hands them over to the exporter.
+type Tracer struct {
+	c config.Config
+	// threshold is the upper bound of the mixed sequence value for sampled events
+	threshold uint64
+	all       bool
+	// salt makes trace identifiers unique across agent restarts
+	salt uint64
+
+	spans chan *Span
+	exp   exporter
+
+	// active keeps the stack of open nested spans per event sequence
+	mu     sync.Mutex
+	active map[uint64][]*Span
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+// New creates a new tracer from the config. The tracer
+// doesn't record spans until it is started.
+func New(c config.Config) (*Tracer, error) {
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		return nil, ErrInvalidSampleRate(c.SampleRate)
+	}
+	exp, err := newExporter(c)
+	if err != nil {
+		return nil, err
+	}
+	if c.MaxQueueSize <= 0 {
+		c.MaxQueueSize = 4096
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = time.Second * 5
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = time.Second * 10
+	}
+	t := &Tracer{
+		c:         c,
+		threshold: sampleThreshold(c.SampleRate),
+		all:       c.SampleRate == 1,
+		salt:      rand.Uint64(),
+		spans:     make(chan *Span, c.MaxQueueSize),
+		exp:       exp,
+		active:    make(map[uint64][]*Span),
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	return t, nil
+}
+
+// Init creates the tracer and installs it as the active
+// tracer. Spans are recorded for sampled events from now on.
+func Init(c config.Config) error {
+	t, err := New(c)
+	if err != nil {
+		return err
+	}
+	go t.run()
+	if prev := tracer.Swap(t); prev != nil {
+		if err := prev.shutdown(); err != nil {
+			log.Warnf("unable to shutdown previous tracer: %v", err)
+		}
+	}
+	log.Infof("tracing enabled: exporter=%s sample-rate=%v", c.Exporter, c.SampleRate)
+	return nil
+}
+
+// Shutdown uninstalls the active tracer and exports
+// all pending spans.
+func Shutdown() error {
+	t := tracer.Swap(nil)
+	if t == nil {
+		return nil
+	}
+	return t.shutdown()
+}
+
+// Enabled indicates if the tracer is active.
+func Enabled() bool { return tracer.Load() != nil }
+
+// Sampled determines if spans are recorded for the event
+// with the given sequence number.
+func Sampled(seq uint64) bool {
+	t := tracer.Load()
+	return t != nil && t.sampled(seq)
+}
+
+// Start starts a nested span for the event with the given sequence
+// number. The span becomes the child of the innermost open nested
+// span of the same event, or the root span of the event trace if
+// there are no open spans. Nested spans must be ended on the same
+// goroutine they were started. Returns nil if the event is not sampled.
+func Start(seq uint64, name string) *Span {
+	t := tracer.Load()
+	if t == nil || !t.sampled(seq) {
+		return nil
+	}
+	return t.start(seq, name)
+}
+
+// Begin starts a detached span for the event with the given sequence
+// number. Detached spans are direct children of the event root span
+// and may be ended on any goroutine. Returns nil if the event is not
+// sampled.
+func Begin(seq uint64, name string) *Span {
+	return BeginAt(seq, name, time.Now())
+}
+
+// BeginAt starts a detached span with the explicit start time.
+func BeginAt(seq uint64, name string, start time.Time) *Span {
+	t := tracer.Load()
+	if t == nil || !t.sampled(seq) {
+		return nil
+	}
+	return &Span{
+		t:        t,
+		seq:      seq,
+		name:     name,
+		spanID:   spanID(),
+		parentID: t.rootID(seq),
+		start:    start,
+	}
+}
+
+// sampleThreshold converts the sample rate to the upper bound of the mixed
+// sequence value. The rate of 1 is clamped, as the product with the maximum
+// integer overflows the uint64 range.
+func sampleThreshold(rate float64) uint64 {
+	switch {
+	case rate >= 1:
+		return math.MaxUint64
+	case rate <= 0:
+		return 0
+	}
+	return uint64(rate * math.MaxUint64)
+}
+
+// sampled deterministically samples events by mixing the sequence
+// number, so all spans of the same event are either recorded or not.
+func (t *Tracer) sampled(seq uint64) bool {
+	if t.all {
+		return true
+	}
+	return seq*0x9E3779B97F4A7C15 < t.threshold
+}
+
+func (t *Tracer) start(seq uint64, name string) *Span {
+	s := &Span{t: t, seq: seq, name: name, nested: true}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stack := t.active[seq]
+	if len(stack) > 0 {
+		s.parentID = stack[len(stack)-1].spanID
+		s.spanID = spanID()
+	} else {
+		s.spanID = t.rootID(seq)
+	}
+	t.active[seq] = append(stack, s)
+	s.start = time.Now()
+	return s
+}
+
+// pop removes the nested span from the stack of open spans.
+func (t *Tracer) pop(s *Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stack := t.active[s.seq]
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i] == s {
+			stack = append(stack[:i], stack[i+1:]...)
+			break
+		}
+	}
+	if len(stack) == 0 {
+		delete(t.active, s.seq)
+	} else {
+		t.active[s.seq] = stack
+	}
+}
+
+// enqueue hands over the ended span to the exporter.
+// The span is dropped if the export queue is full.
+func (t *Tracer) enqueue(s *Span) {
+	spansRecorded.Add(1)
+	select {
+	case t.spans <- s:
+	default:
+		spansDropped.Add(1)
+	}
+}
+
+// traceID derives the trace identifier from the event sequence number.
+func (t *Tracer) traceID(seq uint64) [16]byte {
+	var id [16]byte
+	binary.BigEndian.PutUint64(id[:8], t.salt)
+	binary.BigEndian.PutUint64(id[8:], seq)
+	return id
+}
+
+// rootID derives the identifier of the event root span from the sequence
+// number. Detached spans refer to the root span without tracking its state.
+func (t *Tracer) rootID(seq uint64) uint64 {
+	id := (seq ^ t.salt) * 0xBF58476D1CE4E5B9
+	return id | 1
+}
+
+func (t *Tracer) run() {
+	tick := time.NewTicker(t.c.FlushInterval)
+	defer tick.Stop()
+	batch := make([]*Span, 0, maxBatchSize)
+	for {
+		select {
+		case s := <-t.spans:
+			batch = append(batch, s)
+			if len(batch) >= maxBatchSize {
+				t.flush(batch)
+				batch = batch[:0]
+			}
+		case <-tick.C:
+			t.flush(batch)
+			batch = batch[:0]
+		case <-t.quit:
+			for len(t.spans) > 0 {
+				batch = append(batch, <-t.spans)
+			}
+			t.flush(batch)
+			close(t.done)
+			return
+		}
+	}
+}
+
+func (t *Tracer) flush(batch []*Span) {
+	if len(batch) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), t.c.Timeout)
+	defer cancel()
+	if err := t.exp.export(ctx, batch); err != nil {
+		exportErrors.Add(1)
+		log.Warnf("unable to export %d spans: %v", len(batch), err)
+		return
+	}
+	spansExported.Add(int64(len(batch)))
+}
+
+func (t *Tracer) shutdown() error {
+	close(t.quit)
+	<-t.done
+	return t.exp.close()
+}
+
+// spanID generates a random non-zero span identifier.
+func spanID() uint64 {
+	return rand.Uint64() | 1
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tracing
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/tracing/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSampling(t *testing.T) {
+	_, err := New(config.Config{SampleRate: 1.5, Exporter: config.File, File: filepath.Join(t.TempDir(), "traces.json")})
+	require.Error(t, err)
+
+	tr, err := New(config.Config{SampleRate: 0.1, Exporter: config.File, File: filepath.Join(t.TempDir(), "traces.json")})
+	require.NoError(t, err)
+	defer tr.exp.close()
+
+	var sampled int
+	for seq := uint64(1); seq <= 100000; seq++ {
+		if tr.sampled(seq) {
+			sampled++
+			// sampling decision is stable for the same event
+			assert.True(t, tr.sampled(seq))
+		}
+	}
+	assert.InDelta(t, 10000, sampled, 500)
+
+	tr, err = New(config.Config{SampleRate: 0, Exporter: config.File, File: filepath.Join(t.TempDir(), "traces.json")})
+	require.NoError(t, err)
+	defer tr.exp.close()
+	for seq := uint64(1); seq <= 1000; seq++ {
+		assert.False(t, tr.sampled(seq))
+	}
+}
+
+func TestSampleThreshold(t *testing.T) {
+	assert.Equal(t, uint64(math.MaxUint64), sampleThreshold(1))
+	assert.Equal(t, uint64(0), sampleThreshold(0))
+	assert.Equal(t, uint64(0), sampleThreshold(-0.5))
+	assert.Equal(t, uint64(1)<<63, sampleThreshold(0.5))
+}
+
+func TestSpans(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "traces.json")
+	require.NoError(t, Init(config.Config{Enabled: true, SampleRate: 1, Exporter: config.File, File: file, FlushInterval: time.Hour}))
+
+	assert.True(t, Enabled())
+	assert.True(t, Sampled(10))
+
+	root := Start(10, "event.push")
+	child := Start(10, "listener.process")
+	child.SetString("listener", "*rules.Engine")
+	grandchild := Start(10, "rules.eval")
+	grandchild.SetInt("rules", 12)
+	grandchild.SetError(errors.New("eval failed"))
+	grandchild.End()
+	child.End()
+	detached := Begin(10, "aggregator.batch")
+	root.End()
+	detached.SetBool("flushed", true)
+	detached.End()
+	// ending the span twice is no-op
+	detached.End()
+
+	require.NoError(t, Shutdown())
+	assert.False(t, Enabled())
+	assert.False(t, Sampled(10))
+
+	// nil spans are safe to use
+	span := Start(10, "event.push")
+	require.Nil(t, span)
+	span.SetString("k", "v")
+	span.End()
+
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+
+	spans := make(map[string]fileSpan)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s fileSpan
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &s))
+		spans[s.Name] = s
+	}
+	require.Len(t, spans, 4)
+
+	for _, s := range spans {
+		assert.Equal(t, uint64(10), s.Seq)
+		assert.Equal(t, spans["event.push"].TraceID, s.TraceID)
+	}
+	assert.Empty(t, spans["event.push"].ParentID)
+	assert.Equal(t, spans["event.push"].SpanID, spans["listener.process"].ParentID)
+	assert.Equal(t, spans["listener.process"].SpanID, spans["rules.eval"].ParentID)
+	assert.Equal(t, spans["event.push"].SpanID, spans["aggregator.batch"].ParentID)
+
+	assert.Equal(t, "*rules.Engine", spans["listener.process"].Attributes["listener"])
+	assert.Equal(t, float64(12), spans["rules.eval"].Attributes["rules"])
+	assert.Equal(t, "eval failed", spans["rules.eval"].Error)
+	assert.Equal(t, true, spans["aggregator.batch"].Attributes["flushed"])
+}
+
+func TestOTLPExport(t *testing.T) {
+	reqs := make(chan *coltracepb.ExportTraceServiceRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, protobufContentType, r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var req coltracepb.ExportTraceServiceRequest
+		require.NoError(t, proto.Unmarshal(b, &req))
+		reqs <- &req
+	}))
+	defer srv.Close()
+
+	require.NoError(t, Init(config.Config{
+		Enabled:    true,
+		SampleRate: 1,
+		Exporter:   config.OTLP,
+		Protocol:   config.HTTP,
+		Endpoint:   srv.URL,
+		Headers:    map[string]string{"X-Api-Key": "secret"},
+	}))
+
+	root := Start(7, "event.push")
+	child := Start(7, "listener.process")
+	child.SetError(errors.New("failed"))
+	child.End()
+	root.End()
+	require.NoError(t, Shutdown())
+
+	req := <-reqs
+	require.Len(t, req.ResourceSpans, 1)
+	require.Len(t, req.ResourceSpans[0].ScopeSpans, 1)
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "listener.process", spans[0].Name)
+	assert.Equal(t, spans[1].SpanId, spans[0].ParentSpanId)
+	assert.Equal(t, spans[1].TraceId, spans[0].TraceId)
+	assert.Equal(t, "failed", spans[0].Status.Message)
+	assert.Equal(t, "event.seq", spans[0].Attributes[0].Key)
+	assert.Equal(t, int64(7), spans[0].Attributes[0].Value.GetIntValue())
+}
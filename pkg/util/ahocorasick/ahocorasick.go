@@ -0,0 +1,188 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package ahocorasick implements the Aho-Corasick multi-pattern string
+// matching algorithm. Patterns are compiled into a deterministic automaton
+// with a compressed byte alphabet, so the input is scanned exactly once
+// regardless of the number of patterns.
+package ahocorasick
+
+// Matcher is a compiled Aho-Corasick automaton. It is safe for
+// concurrent use by multiple goroutines.
+type Matcher struct {
+	// classes maps every input byte to its equivalence class. Bytes
+	// that don't appear in any pattern share the class zero.
+	classes  [256]uint16
+	nclasses int
+	// delta is the full transition table indexed by state*nclasses+class
+	delta []int32
+	// accept indicates if any pattern ends in the state or
+	// in any of the states reachable through failure links
+	accept []bool
+	// out contains the patterns ending exactly in the state
+	out [][]int
+	// dict points to the nearest state on the failure chain
+	// that has outputs or is -1 if there is no such state
+	dict []int32
+	// empty contains the indices of empty patterns that match any input
+	empty []int
+}
+
+// New builds the automaton from the given patterns. If ignoreCase is
+// true, ASCII letters are compared case-insensitively. Other bytes are
+// always compared verbatim.
+func New(patterns []string, ignoreCase bool) *Matcher {
+	m := &Matcher{}
+
+	// compute byte equivalence classes
+	var used [256]bool
+	for _, p := range patterns {
+		for i := 0; i < len(p); i++ {
+			used[fold(p[i], ignoreCase)] = true
+		}
+	}
+	m.nclasses = 1
+	for b := 0; b < 256; b++ {
+		if used[b] {
+			m.classes[b] = uint16(m.nclasses)
+			m.nclasses++
+		}
+	}
+	if ignoreCase {
+		for b := 'A'; b <= 'Z'; b++ {
+			m.classes[b] = m.classes[b+'a'-'A']
+		}
+	}
+
+	// build the trie
+	m.addState()
+	for id, p := range patterns {
+		if p == "" {
+			m.empty = append(m.empty, id)
+			continue
+		}
+		state := int32(0)
+		for i := 0; i < len(p); i++ {
+			c := int(m.classes[p[i]])
+			next := m.delta[int(state)*m.nclasses+c]
+			if next < 0 {
+				next = m.addState()
+				m.delta[int(state)*m.nclasses+c] = next
+			}
+			state = next
+		}
+		m.out[state] = append(m.out[state], id)
+		m.accept[state] = true
+	}
+
+	// compute failure links in breadth-first order and turn
+	// the trie into the full transition function
+	fail := make([]int32, len(m.accept))
+	queue := make([]int32, 0, len(m.accept))
+	for c := 0; c < m.nclasses; c++ {
+		next := m.delta[c]
+		if next < 0 {
+			m.delta[c] = 0
+			continue
+		}
+		queue = append(queue, next)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		f := fail[state]
+		if len(m.out[f]) > 0 {
+			m.dict[state] = f
+		} else {
+			m.dict[state] = m.dict[f]
+		}
+		m.accept[state] = m.accept[state] || m.accept[f]
+		row := int(state) * m.nclasses
+		for c := 0; c < m.nclasses; c++ {
+			next := m.delta[row+c]
+			if next < 0 {
+				m.delta[row+c] = m.delta[int(f)*m.nclasses+c]
+				continue
+			}
+			if state != 0 {
+				fail[next] = m.delta[int(f)*m.nclasses+c]
+			}
+			queue = append(queue, next)
+		}
+	}
+
+	return m
+}
+
+func (m *Matcher) addState() int32 {
+	id := int32(len(m.accept))
+	for c := 0; c < m.nclasses; c++ {
+		m.delta = append(m.delta, -1)
+	}
+	m.accept = append(m.accept, false)
+	m.out = append(m.out, nil)
+	m.dict = append(m.dict, -1)
+	return id
+}
+
+// Contains reports whether any of the patterns occurs in s.
+func (m *Matcher) Contains(s string) bool {
+	if len(m.empty) > 0 {
+		return true
+	}
+	var state int32
+	for i := 0; i < len(s); i++ {
+		state = m.delta[int(state)*m.nclasses+int(m.classes[s[i]])]
+		if m.accept[state] {
+			return true
+		}
+	}
+	return false
+}
+
+// Each calls fn with the index of every pattern occurring in s. The
+// same pattern is reported once per occurrence. The scan stops as soon
+// as fn returns false.
+func (m *Matcher) Each(s string, fn func(pattern int) bool) {
+	for _, id := range m.empty {
+		if !fn(id) {
+			return
+		}
+	}
+	var state int32
+	for i := 0; i < len(s); i++ {
+		state = m.delta[int(state)*m.nclasses+int(m.classes[s[i]])]
+		if !m.accept[state] {
+			continue
+		}
+		for n := state; n > 0; n = m.dict[n] {
+			for _, id := range m.out[n] {
+				if !fn(id) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func fold(b byte, ignoreCase bool) byte {
+	if ignoreCase && b >= 'A' && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
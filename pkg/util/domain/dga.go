@@ -0,0 +1,93 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package domain
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/rabbitstack/fibratus/pkg/util/entropy"
+)
+
+// LabelEntropy returns the highest Shannon entropy, in bits per
+// character, of the domain name labels preceding the public suffix.
+// Long random-looking labels are typical for DNS tunnelling.
+func LabelEntropy(s string) float64 {
+	var e float64
+	for _, label := range Labels(s) {
+		e = max(e, entropy.ShannonBits(label))
+	}
+	return e
+}
+
+// DGAScore rates how likely the registered domain label was produced
+// by a domain generation algorithm. The score ranges from 0 to 1 and
+// combines the label entropy, the ratio of digits and vowels, the
+// longest run of consonants and the label length. The score is only
+// a heuristic, so it should be combined with other indicators.
+func DGAScore(s string) float64 {
+	labels := Labels(s)
+	if len(labels) == 0 {
+		return 0
+	}
+	// the registered domain label is
+	// the last label before the suffix
+	label := labels[len(labels)-1]
+	// internationalized labels are
+	// not produced by known algorithms
+	if strings.HasPrefix(label, "xn--") {
+		return 0
+	}
+	label = strings.ReplaceAll(label, "-", "")
+
+	n := len([]rune(label))
+	if n < 4 {
+		return 0
+	}
+
+	var digits, vowels, run, longestRun int
+	for _, c := range label {
+		switch {
+		case unicode.IsDigit(c):
+			digits++
+			run = 0
+		case strings.ContainsRune("aeiouy", c):
+			vowels++
+			run = 0
+		default:
+			run++
+			longestRun = max(longestRun, run)
+		}
+	}
+
+	// random labels approach the maximum entropy for their length
+	entropyScore := entropy.ShannonBits(label) / math.Log2(float64(min(n, 36)))
+	// the ratio of digits rarely exceeds a few percent in names picked by humans
+	digitScore := min(float64(digits)/float64(n)*2.5, 1)
+	// pronounceable names have around 40% of vowels
+	vowelScore := min(math.Abs(0.4-float64(vowels)/float64(n))/0.3, 1)
+	// runs of more than three consonants are uncommon in natural words
+	consonantScore := min(max(float64(longestRun-3), 0)/3, 1)
+	// generated labels tend to be longer than registered brand names
+	lengthScore := min(max(float64(n-8), 0)/12, 1)
+
+	score := 0.3*entropyScore + 0.2*digitScore + 0.15*vowelScore + 0.2*consonantScore + 0.15*lengthScore
+	return math.Round(score*1000) / 1000
+}
@@ -0,0 +1,44 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelEntropy(t *testing.T) {
+	assert.InDelta(t, 1.918, LabelEntropy("google.com"), 0.001)
+	assert.InDelta(t, 4.0, LabelEntropy("a8f3k2l9q0z1x7w4.info"), 0.001)
+	assert.InDelta(t, 4.0, LabelEntropy("a8f3k2l9q0z1x7w4.example.co.uk"), 0.001)
+	assert.Zero(t, LabelEntropy("co.uk"))
+}
+
+func TestDGAScore(t *testing.T) {
+	for _, name := range []string{"google.com", "microsoft.com", "login.microsoftonline.com", "stackoverflow.com", "bbc.co.uk"} {
+		assert.Less(t, DGAScore(name), 0.45, name)
+	}
+	for _, name := range []string{"xjw9qkz3vbn1prt.com", "kq3v9z7xj2.net", "qxkzjvbnmwrt.ru", "wdftyfhbbnmk.biz"} {
+		assert.Greater(t, DGAScore(name), 0.6, name)
+	}
+	assert.Zero(t, DGAScore("xn--e1afmkfd.xn--p1ai"))
+	assert.Zero(t, DGAScore("abc.com"))
+	assert.Zero(t, DGAScore("10.0.0.1"))
+}
@@ -0,0 +1,248 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package domain provides domain name parsing based on the public
+// suffix list and heuristics for detecting algorithmically generated
+// domain names.
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/net/idna"
+)
+
+// publicSuffixList is the public suffix list snapshot from https://publicsuffix.org
+// that is used unless a more recent list is loaded.
+//
+//go:embed public_suffix_list.dat
+var publicSuffixList []byte
+
+// ruleKind designates the type of the public suffix rule.
+type ruleKind uint8
+
+const (
+	// normal rule matches the suffix verbatim, e.g. co.uk
+	normal ruleKind = 1 << iota
+	// wildcard rule matches any label under the suffix, e.g. *.ck
+	wildcard
+	// exception rule excludes the suffix from the wildcard rule, e.g. !www.ck
+	exception
+)
+
+// List is the parsed public suffix list. Rules in Unicode form
+// are converted to their ASCII (punycode) representation.
+type List struct {
+	rules map[string]ruleKind
+}
+
+// Parse parses the public suffix list in the publicsuffix.org format.
+func Parse(r io.Reader) (*List, error) {
+	l := &List{rules: make(map[string]ruleKind)}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		// the rule ends at the first white space
+		if n := strings.IndexAny(line, " \t"); n > 0 {
+			line = line[:n]
+		}
+		kind := normal
+		switch {
+		case strings.HasPrefix(line, "!"):
+			kind, line = exception, line[1:]
+		case strings.HasPrefix(line, "*."):
+			kind, line = wildcard, line[2:]
+		}
+		rule, err := idna.ToASCII(strings.ToLower(line))
+		if err != nil || rule == "" {
+			continue
+		}
+		l.rules[rule] |= kind
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// suffixLabels returns the number of labels, counted from the
+// right, that make up the public suffix of the ASCII labels.
+func (l *List) suffixLabels(labels []string) int {
+	// the implicit * rule makes the last label the public suffix
+	n := 1
+	for k := 1; k <= len(labels); k++ {
+		s := strings.Join(labels[len(labels)-k:], ".")
+		kind := l.rules[s]
+		if kind&exception != 0 {
+			return k - 1
+		}
+		if kind&normal != 0 {
+			n = k
+		}
+		if k > 1 && l.rules[strings.Join(labels[len(labels)-k+1:], ".")]&wildcard != 0 {
+			n = k
+		}
+	}
+	return n
+}
+
+// name is the domain name split into labels.
+type name struct {
+	// labels are the lowercase labels of the original name
+	labels []string
+	// suffix is the number of public suffix labels
+	suffix int
+}
+
+// split normalizes and splits the domain name into labels and
+// resolves the public suffix. It returns false for empty names,
+// IP addresses and names with empty labels.
+func (l *List) split(s string) (name, bool) {
+	s = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(s), "."))
+	if s == "" || net.ParseIP(s) != nil {
+		return name{}, false
+	}
+	labels := strings.Split(s, ".")
+	ascii := make([]string, len(labels))
+	for i, label := range labels {
+		if label == "" {
+			return name{}, false
+		}
+		ascii[i] = label
+		if !isASCII(label) {
+			a, err := idna.ToASCII(label)
+			if err != nil {
+				return name{}, false
+			}
+			ascii[i] = a
+		}
+	}
+	return name{labels: labels, suffix: l.suffixLabels(ascii)}, true
+}
+
+// PublicSuffix returns the public suffix, also known as the
+// effective top-level domain, of the domain name, e.g. co.uk
+// for www.bbc.co.uk.
+func (l *List) PublicSuffix(s string) string {
+	n, ok := l.split(s)
+	if !ok {
+		return ""
+	}
+	return strings.Join(n.labels[len(n.labels)-n.suffix:], ".")
+}
+
+// Registered returns the registered domain, that is, the public
+// suffix plus one label, e.g. bbc.co.uk for www.bbc.co.uk. The
+// empty string is returned if the name is the public suffix.
+func (l *List) Registered(s string) string {
+	n, ok := l.split(s)
+	if !ok || len(n.labels) <= n.suffix {
+		return ""
+	}
+	return strings.Join(n.labels[len(n.labels)-n.suffix-1:], ".")
+}
+
+// SubdomainDepth returns the number of labels preceding the
+// registered domain, e.g. 2 for a.b.example.com.
+func (l *List) SubdomainDepth(s string) int {
+	n, ok := l.split(s)
+	if !ok || len(n.labels) <= n.suffix {
+		return 0
+	}
+	return len(n.labels) - n.suffix - 1
+}
+
+// Labels returns the labels preceding the public suffix, that is,
+// the registered domain label and all subdomain labels.
+func (l *List) Labels(s string) []string {
+	n, ok := l.split(s)
+	if !ok {
+		return nil
+	}
+	return n.labels[:len(n.labels)-n.suffix]
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	list     atomic.Pointer[List]
+	initList sync.Once
+)
+
+// Default returns the public suffix list used by the package
+// level functions. It is the embedded list unless a different
+// list was loaded.
+func Default() *List {
+	initList.Do(func() {
+		if list.Load() != nil {
+			return
+		}
+		l, err := Parse(bytes.NewReader(publicSuffixList))
+		if err != nil {
+			panic(err)
+		}
+		list.CompareAndSwap(nil, l)
+	})
+	return list.Load()
+}
+
+// Load refreshes the public suffix list from the file. The
+// embedded list is replaced and used by package level functions.
+func Load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	l, err := Parse(f)
+	if err != nil {
+		return err
+	}
+	list.Store(l)
+	return nil
+}
+
+// PublicSuffix returns the public suffix of the domain name.
+func PublicSuffix(s string) string { return Default().PublicSuffix(s) }
+
+// Registered returns the registered domain of the domain name.
+func Registered(s string) string { return Default().Registered(s) }
+
+// SubdomainDepth returns the number of subdomain labels of the domain name.
+func SubdomainDepth(s string) int { return Default().SubdomainDepth(s) }
+
+// Labels returns the domain name labels preceding the public suffix.
+func Labels(s string) []string { return Default().Labels(s) }
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package domain
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicSuffix(t *testing.T) {
+	var tests = []struct {
+		name       string
+		suffix     string
+		registered string
+		depth      int
+	}{
+		{"www.bbc.co.uk", "co.uk", "bbc.co.uk", 1},
+		{"a.b.c.example.com.", "com", "example.com", 3},
+		{"EXAMPLE.COM", "com", "example.com", 0},
+		{"com", "com", "", 0},
+		{"foo.duckdns.org", "duckdns.org", "foo.duckdns.org", 0},
+		{"www.ck", "ck", "www.ck", 0},
+		{"a.b.test.ck", "test.ck", "b.test.ck", 1},
+		{"localhost", "localhost", "", 0},
+		{"corp.local", "local", "corp.local", 0},
+		{"пример.рф", "рф", "пример.рф", 0},
+		{"xn--e1afmkfd.xn--p1ai", "xn--p1ai", "xn--e1afmkfd.xn--p1ai", 0},
+		{"10.0.0.1", "", "", 0},
+		{"a..com", "", "", 0},
+		{"", "", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.suffix, PublicSuffix(tt.name))
+			assert.Equal(t, tt.registered, Registered(tt.name))
+			assert.Equal(t, tt.depth, SubdomainDepth(tt.name))
+		})
+	}
+
+	assert.Equal(t, []string{"mail", "google"}, Labels("mail.google.com"))
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "public_suffix_list.dat")
+	require.NoError(t, os.WriteFile(path, []byte("// comment\ncom\n*.corp\n!www.corp\n"), 0644))
+
+	l, err := Parse(strings.NewReader("com\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "co.uk", l.Registered("www.bbc.co.uk"))
+
+	prev := Default()
+	defer list.Store(prev)
+	require.NoError(t, Load(path))
+
+	assert.Equal(t, "uk", Default().PublicSuffix("www.bbc.co.uk"))
+	assert.Equal(t, "x.y.corp", Registered("a.x.y.corp"))
+	assert.Equal(t, "www.corp", Registered("a.www.corp"))
+
+	require.Error(t, Load(filepath.Join(t.TempDir(), "missing.dat")))
+}
@@ -38,3 +38,24 @@ func Shannon(value string) int {
 
 	return int(math.Ceil(sum*-1)) * len(value)
 }
+
+// ShannonBits measures the Shannon entropy of a string
+// expressed as the average number of bits per character.
+func ShannonBits(value string) float64 {
+	frq := make(map[rune]float64)
+
+	var n float64
+	for _, i := range value {
+		frq[i]++
+		n++
+	}
+
+	var sum float64
+
+	for _, v := range frq {
+		f := v / n
+		sum -= f * math.Log2(f)
+	}
+
+	return sum
+}
@@ -0,0 +1,329 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hashers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	ssdeepRollingWindow  = 7
+	ssdeepMinBlocksize   = 3
+	ssdeepSpamsumLength  = 64
+	ssdeepNumBlockhashes = 31
+	ssdeepHashInit       = 0x27
+	ssdeepHashPrime      = 0x01000193
+)
+
+const ssdeepB64 = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// ErrInvalidSsdeep is returned when the ssdeep digest is malformed.
+var ErrInvalidSsdeep = errors.New("invalid ssdeep digest")
+
+// ssdeepRoll is the rolling hash that determines the trigger
+// points where the piecewise hashes are emitted.
+type ssdeepRoll struct {
+	window     [ssdeepRollingWindow]byte
+	h1, h2, h3 uint32
+	n          uint32
+}
+
+func (r *ssdeepRoll) update(c byte) {
+	r.h2 -= r.h1
+	r.h2 += ssdeepRollingWindow * uint32(c)
+
+	r.h1 += uint32(c)
+	r.h1 -= uint32(r.window[r.n])
+
+	r.window[r.n] = c
+	r.n = (r.n + 1) % ssdeepRollingWindow
+
+	r.h3 <<= 5
+	r.h3 ^= uint32(c)
+}
+
+func (r *ssdeepRoll) sum() uint32 { return r.h1 + r.h2 + r.h3 }
+
+// ssdeepBlockhash keeps the piecewise digest for a single block size.
+type ssdeepBlockhash struct {
+	digest     [ssdeepSpamsumLength]byte
+	dlen       int
+	h          uint8
+	halfh      uint8
+	halfdigest byte
+}
+
+// Ssdeep computes the context triggered piecewise hash (CTPH) of the
+// written data. The resulting digest is compatible with the ssdeep tool
+// and can be compared against other digests to measure the similarity
+// of the inputs.
+type Ssdeep struct {
+	bh    [ssdeepNumBlockhashes]ssdeepBlockhash
+	bhend int
+	roll  ssdeepRoll
+	total uint64
+}
+
+// NewSsdeep creates a new ssdeep hash.
+func NewSsdeep() *Ssdeep {
+	s := &Ssdeep{bhend: 1}
+	s.bh[0].h = ssdeepHashInit
+	s.bh[0].halfh = ssdeepHashInit
+	return s
+}
+
+// Write feeds the data to the hash. It never returns an error.
+func (s *Ssdeep) Write(p []byte) (int, error) {
+	s.total += uint64(len(p))
+	for _, c := range p {
+		s.update(c)
+	}
+	return len(p), nil
+}
+
+// Digest returns the ssdeep digest in the blocksize:hash:hash format.
+func (s *Ssdeep) Digest() string {
+	bi := 0
+	for bi < ssdeepNumBlockhashes-1 && ssdeepBlocksize(bi)*ssdeepSpamsumLength < s.total {
+		bi++
+	}
+	if bi >= s.bhend {
+		bi = s.bhend - 1
+	}
+	for bi > 0 && s.bh[bi].dlen < ssdeepSpamsumLength/2 {
+		bi--
+	}
+
+	h := s.roll.sum()
+	b := &s.bh[bi]
+
+	var sb strings.Builder
+	sb.WriteString(strconv.FormatUint(ssdeepBlocksize(bi), 10))
+	sb.WriteByte(':')
+	sb.Write(b.digest[:b.dlen])
+	if h != 0 {
+		sb.WriteByte(ssdeepB64[b.h])
+	} else if b.digest[b.dlen] != 0 {
+		sb.WriteByte(b.digest[b.dlen])
+	}
+	sb.WriteByte(':')
+
+	switch {
+	case bi < s.bhend-1:
+		n := &s.bh[bi+1]
+		sb.Write(n.digest[:min(n.dlen, ssdeepSpamsumLength/2-1)])
+		if h != 0 {
+			sb.WriteByte(ssdeepB64[n.halfh])
+		} else if n.halfdigest != 0 {
+			sb.WriteByte(n.halfdigest)
+		}
+	case h != 0:
+		if bi == 0 {
+			sb.WriteByte(ssdeepB64[b.h])
+		} else {
+			sb.WriteByte(ssdeepB64[b.halfh])
+		}
+	}
+
+	return sb.String()
+}
+
+func (s *Ssdeep) update(c byte) {
+	s.roll.update(c)
+	horg := s.roll.sum() + 1
+
+	for i := 0; i < s.bhend; i++ {
+		s.bh[i].h = ssdeepSumHash(c, s.bh[i].h)
+		s.bh[i].halfh = ssdeepSumHash(c, s.bh[i].halfh)
+	}
+
+	for i := 0; i < s.bhend; i++ {
+		if uint64(horg)%ssdeepBlocksize(i) != 0 {
+			break
+		}
+		b := &s.bh[i]
+		// the first trigger point of the largest
+		// block size spawns the next block size
+		if b.dlen == 0 {
+			s.fork()
+		}
+		b.digest[b.dlen] = ssdeepB64[b.h]
+		b.halfdigest = ssdeepB64[b.halfh]
+		if b.dlen < ssdeepSpamsumLength-1 {
+			b.dlen++
+			b.digest[b.dlen] = 0
+			b.h = ssdeepHashInit
+			if b.dlen < ssdeepSpamsumLength/2 {
+				b.halfh = ssdeepHashInit
+				b.halfdigest = 0
+			}
+		}
+	}
+}
+
+func (s *Ssdeep) fork() {
+	if s.bhend >= ssdeepNumBlockhashes {
+		return
+	}
+	o := &s.bh[s.bhend-1]
+	s.bh[s.bhend] = ssdeepBlockhash{h: o.h, halfh: o.halfh}
+	s.bhend++
+}
+
+func ssdeepBlocksize(i int) uint64 { return ssdeepMinBlocksize << i }
+
+func ssdeepSumHash(c byte, h uint8) uint8 {
+	return uint8((uint32(h)*ssdeepHashPrime ^ uint32(c)) & 0x3f)
+}
+
+// SsdeepDigest computes the ssdeep digest of the given data.
+func SsdeepDigest(b []byte) string {
+	s := NewSsdeep()
+	_, _ = s.Write(b)
+	return s.Digest()
+}
+
+// SsdeepCompare compares two ssdeep digests and returns the match
+// score in the range from 0 to 100. The score of 0 indicates no
+// similarity, while 100 denotes identical or nearly identical inputs.
+func SsdeepCompare(a, b string) (int, error) {
+	bs1, a1, a2, err := parseSsdeep(a)
+	if err != nil {
+		return 0, err
+	}
+	bs2, b1, b2, err := parseSsdeep(b)
+	if err != nil {
+		return 0, err
+	}
+
+	// only digests of the same or adjacent
+	// block sizes are comparable
+	if bs1 != bs2 && bs1 != bs2*2 && bs2 != bs1*2 {
+		return 0, nil
+	}
+
+	a1, a2 = eliminateSequences(a1), eliminateSequences(a2)
+	b1, b2 = eliminateSequences(b1), eliminateSequences(b2)
+
+	if bs1 == bs2 && a1 == b1 {
+		return 100, nil
+	}
+
+	switch {
+	case bs1 == bs2:
+		return max(scoreStrings(a1, b1, bs1), scoreStrings(a2, b2, bs1*2)), nil
+	case bs1 == bs2*2:
+		return scoreStrings(a1, b2, bs1), nil
+	default:
+		return scoreStrings(a2, b1, bs2), nil
+	}
+}
+
+func parseSsdeep(s string) (uint64, string, string, error) {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) != 3 {
+		return 0, "", "", fmt.Errorf("%w: %q", ErrInvalidSsdeep, s)
+	}
+	bs, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil || bs < ssdeepMinBlocksize {
+		return 0, "", "", fmt.Errorf("%w: %q", ErrInvalidSsdeep, s)
+	}
+	s1, s2 := parts[1], parts[2]
+	// strip the file name as printed by the ssdeep tool
+	if n := strings.IndexByte(s2, ','); n >= 0 {
+		s2 = s2[:n]
+	}
+	if len(s1) > ssdeepSpamsumLength || len(s2) > ssdeepSpamsumLength {
+		return 0, "", "", fmt.Errorf("%w: %q", ErrInvalidSsdeep, s)
+	}
+	return bs, s1, s2, nil
+}
+
+// eliminateSequences collapses runs of identical characters
+// longer than three, since they carry little information.
+func eliminateSequences(s string) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if i >= 3 && s[i] == s[i-1] && s[i] == s[i-2] && s[i] == s[i-3] {
+			continue
+		}
+		b = append(b, s[i])
+	}
+	return string(b)
+}
+
+func scoreStrings(s1, s2 string, bs uint64) int {
+	if !hasCommonSubstring(s1, s2) {
+		return 0
+	}
+
+	score := uint64(editDistance(s1, s2))
+	score = (score * ssdeepSpamsumLength) / uint64(len(s1)+len(s2))
+	score = (100 * score) / ssdeepSpamsumLength
+	score = 100 - score
+
+	// small block sizes can't produce strong matches
+	// unless the digests are long enough
+	if bs >= (99+ssdeepRollingWindow)/ssdeepRollingWindow*ssdeepMinBlocksize {
+		return int(score)
+	}
+	if limit := bs / ssdeepMinBlocksize * uint64(min(len(s1), len(s2))); score > limit {
+		score = limit
+	}
+	return int(score)
+}
+
+// hasCommonSubstring determines if both strings share
+// a substring as long as the rolling hash window.
+func hasCommonSubstring(s1, s2 string) bool {
+	if len(s1) < ssdeepRollingWindow || len(s2) < ssdeepRollingWindow {
+		return false
+	}
+	for i := 0; i+ssdeepRollingWindow <= len(s1); i++ {
+		if strings.Contains(s2, s1[i:i+ssdeepRollingWindow]) {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance computes the edit distance where insertions and
+// deletions cost one, and substitutions cost two operations.
+func editDistance(s1, s2 string) int {
+	prev := make([]int, len(s2)+1)
+	curr := make([]int, len(s2)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(s1); i++ {
+		curr[0] = i
+		for j := 1; j <= len(s2); j++ {
+			cost := 2
+			if s1[i-1] == s2[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(s2)]
+}
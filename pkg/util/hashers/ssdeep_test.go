@@ -0,0 +1,130 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hashers
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randBytes(seed int64, n int) []byte {
+	b := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(b)
+	return b
+}
+
+func TestSsdeepDigest(t *testing.T) {
+	assert.Equal(t, "3::", SsdeepDigest(nil))
+
+	b := randBytes(1, 100000)
+	digest := SsdeepDigest(b)
+	assert.Regexp(t, `^1536:[A-Za-z0-9+/]{1,64}:[A-Za-z0-9+/]{1,32}$`, digest)
+
+	// feeding the data in chunks yields the same digest
+	s := NewSsdeep()
+	for i := 0; i < len(b); i += 4096 {
+		_, err := s.Write(b[i:min(i+4096, len(b))])
+		require.NoError(t, err)
+	}
+	assert.Equal(t, digest, s.Digest())
+}
+
+// TestSsdeepKnownAnswers verifies digests and similarity scores against the
+// known-answer vectors from the test suite of the glaslos/ssdeep project.
+// The inputs are consecutive pseudo-random blobs read from the source seeded
+// with 1.
+func TestSsdeepKnownAnswers(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var tests = []struct {
+		size   int
+		digest string
+	}{
+		{4097, "96:yNDH/iNQaSXRLmOSxu1aQP4iWgC8JbkiA5Ix:yNLaNQhSxEgVYkiA5Ix"},
+		{45056, "768:mlHmRZnCRFRwSuK/UiwY37TMbsDEsb1Jqi6dcXoWpKXIUxpQDOAvWpPK:mqhCJwjmJD31DzbDwd+oGo9AvOi"},
+		{86016, "1536:Jdr3F6yZG0agLg/b6G6REjI+WUhWDKRSpzKjSUT4plmjvX6ex7RwdsHIGV:PrVbZG0BuuGzc+WcdRilmbPx7RwGV"},
+		{126976, "3072:pwP2ZmVLsvDAyshOZIzFkGxIE++3ysSsZCj3JwAjpn:ps2/DAyKIaRyE++RSsUj3JwaJ"},
+	}
+	for _, tt := range tests {
+		b := make([]byte, tt.size)
+		r.Read(b)
+		assert.Equal(t, tt.digest, SsdeepDigest(b), "size %d", tt.size)
+	}
+
+	var scores = []struct {
+		a, b  string
+		score int
+	}{
+		{"192:MUPMinqP6+wNQ7Q40L/iB3n2rIBrP0GZKF4jsef+0FVQLSwbLbj41iH8nFVYv980:x0CllivQiFmt", "192:JkjRcePWsNVQza3ntZStn5VfsoXMhRD9+xJMinqF6+wNQ7Q40L/i737rPVt:JkjlQyIrx+kll2", 35},
+		{"196608:pDSC8olnoL1v/uawvbQD7XlZUFYzYyMb615NktYHF7dREN/JNnQrmhnUPI+/n2Yr:5DHoJXv7XOq7Mb2TwYHXREN/3QrmktPd", "196608:7DSC8olnoL1v/uawvbQD7XlZUFYzYyMb615NktYHF7dREN/JNnQrmhnUPI+/n2Y7:3DHoJXv7XOq7Mb2TwYHXREN/3QrmktPt", 97},
+		{"24:YDVLfsT1ds/1H9Wpgq7n4XMijV6h4Z3QCw4qat:YD51H9CiMuV6uACwVat", "24:YDVLfyvDj+C+opg8DV0Mdle6hPZ3QCw4qat:YDMvDj+C+kBOM+6HACwVat", 54},
+	}
+	for _, tt := range scores {
+		score, err := SsdeepCompare(tt.a, tt.b)
+		require.NoError(t, err)
+		assert.Equal(t, tt.score, score, "comparing %s and %s", tt.a, tt.b)
+	}
+}
+
+func TestSsdeepCompare(t *testing.T) {
+	b := randBytes(1, 100000)
+	d1 := SsdeepDigest(b)
+
+	c := append([]byte{}, b...)
+	copy(c[50000:], "some modification in the middle of the buffer")
+	d2 := SsdeepDigest(c)
+
+	d3 := SsdeepDigest(randBytes(2, 100000))
+
+	var tests = []struct {
+		a, b  string
+		score func(int) bool
+	}{
+		{d1, d1, func(s int) bool { return s == 100 }},
+		{d1, d2, func(s int) bool { return s > 90 && s < 100 }},
+		{d1, d3, func(s int) bool { return s == 0 }},
+		{d1, d1 + `,"C:\Windows\notepad.exe"`, func(s int) bool { return s == 100 }},
+		{"3:AXGBicFlgVNhBGcL6wCrFQEv:AXGHsNhxLsr2C", "48:AXGHsNhxLsr2C:AXGHsNhxLsr2C", func(s int) bool { return s == 0 }},
+	}
+
+	for _, tt := range tests {
+		score, err := SsdeepCompare(tt.a, tt.b)
+		require.NoError(t, err)
+		assert.True(t, tt.score(score), "unexpected score %d comparing %s and %s", score, tt.a, tt.b)
+	}
+
+	_, err := SsdeepCompare(d1, "1536:")
+	require.ErrorIs(t, err, ErrInvalidSsdeep)
+	_, err = SsdeepCompare("foo:bar:baz", d1)
+	require.ErrorIs(t, err, ErrInvalidSsdeep)
+}
+
+func TestEliminateSequences(t *testing.T) {
+	assert.Equal(t, "AAABCCCD", eliminateSequences("AAAAAABCCCCD"))
+	assert.Equal(t, "ABC", eliminateSequences("ABC"))
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("abc", "abc"))
+	assert.Equal(t, 1, editDistance("abc", "abcd"))
+	assert.Equal(t, 2, editDistance("abc", "abd"))
+	assert.Equal(t, 3, editDistance("", "abc"))
+}
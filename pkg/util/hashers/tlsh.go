@@ -0,0 +1,283 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hashers
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+const (
+	tlshBuckets       = 128
+	tlshCodeSize      = 32
+	tlshWindowSize    = 5
+	tlshMinDataLength = 50
+	// tlshVersion is the prefix of the version 4 digests
+	tlshVersion = "T1"
+)
+
+var (
+	// ErrTLSHNotEnoughData is returned when the input is too short
+	// or lacks the variability to produce the TLSH digest.
+	ErrTLSHNotEnoughData = errors.New("not enough data to compute TLSH digest")
+	// ErrInvalidTLSH is returned when the TLSH digest is malformed.
+	ErrInvalidTLSH = errors.New("invalid TLSH digest")
+)
+
+// tlshPearson is the permutation table used for the Pearson hashing.
+var tlshPearson = [256]byte{
+	1, 87, 49, 12, 176, 178, 102, 166, 121, 193, 6, 84, 249, 230, 44, 163,
+	14, 197, 213, 181, 161, 85, 218, 80, 64, 239, 24, 226, 236, 142, 38, 200,
+	110, 177, 104, 103, 141, 253, 255, 50, 77, 101, 81, 18, 45, 96, 31, 222,
+	25, 107, 190, 70, 86, 237, 240, 34, 72, 242, 20, 214, 244, 227, 149, 235,
+	97, 234, 57, 22, 60, 250, 82, 175, 208, 5, 127, 199, 111, 62, 135, 248,
+	174, 169, 211, 58, 66, 154, 106, 195, 245, 171, 17, 187, 182, 179, 0, 243,
+	132, 56, 148, 75, 128, 133, 158, 100, 130, 126, 91, 13, 153, 246, 216, 219,
+	119, 68, 223, 78, 83, 88, 201, 99, 122, 11, 92, 32, 136, 114, 52, 10,
+	138, 30, 48, 183, 156, 35, 61, 26, 143, 74, 251, 94, 129, 162, 63, 152,
+	170, 7, 115, 167, 241, 206, 3, 150, 55, 59, 151, 220, 90, 53, 23, 131,
+	125, 173, 15, 238, 79, 95, 89, 16, 105, 137, 225, 224, 217, 160, 37, 123,
+	118, 73, 2, 157, 46, 116, 9, 145, 134, 228, 207, 212, 202, 215, 69, 229,
+	27, 188, 67, 124, 168, 252, 42, 4, 29, 108, 21, 247, 19, 205, 39, 203,
+	233, 40, 186, 147, 198, 192, 155, 33, 164, 191, 98, 204, 165, 180, 117, 76,
+	140, 36, 210, 172, 41, 54, 159, 8, 185, 232, 113, 196, 231, 47, 146, 120,
+	51, 65, 28, 144, 254, 221, 93, 189, 194, 139, 112, 43, 71, 109, 184, 209,
+}
+
+// TLSH computes the Trend Micro Locality Sensitive Hash of the written
+// data. The digest is produced in the version 4 format with 128 buckets
+// and the 1-byte checksum. Similar inputs yield digests with a small
+// distance.
+type TLSH struct {
+	buckets  [256]uint32
+	window   [tlshWindowSize]byte
+	checksum byte
+	n        int
+}
+
+// NewTLSH creates a new TLSH hash.
+func NewTLSH() *TLSH {
+	return &TLSH{}
+}
+
+// Write feeds the data to the hash. It never returns an error.
+func (t *TLSH) Write(p []byte) (int, error) {
+	for _, c := range p {
+		j := t.n % tlshWindowSize
+		t.window[j] = c
+		t.n++
+		if t.n < tlshWindowSize {
+			continue
+		}
+		c1 := t.window[(j+4)%tlshWindowSize]
+		c2 := t.window[(j+3)%tlshWindowSize]
+		c3 := t.window[(j+2)%tlshWindowSize]
+		c4 := t.window[(j+1)%tlshWindowSize]
+
+		t.checksum = pearson(0, c, c1, t.checksum)
+
+		t.buckets[pearson(2, c, c1, c2)]++
+		t.buckets[pearson(3, c, c1, c3)]++
+		t.buckets[pearson(5, c, c2, c3)]++
+		t.buckets[pearson(7, c, c2, c4)]++
+		t.buckets[pearson(11, c, c1, c4)]++
+		t.buckets[pearson(13, c, c3, c4)]++
+	}
+	return len(p), nil
+}
+
+// Digest returns the hex encoded TLSH digest. An error is returned if
+// the input is shorter than 50 bytes or too uniform to fill at least
+// half of the buckets.
+func (t *TLSH) Digest() (string, error) {
+	if t.n < tlshMinDataLength {
+		return "", ErrTLSHNotEnoughData
+	}
+
+	var nonzero int
+	for _, b := range t.buckets[:tlshBuckets] {
+		if b > 0 {
+			nonzero++
+		}
+	}
+	if nonzero <= tlshBuckets/2 {
+		return "", ErrTLSHNotEnoughData
+	}
+
+	sorted := make([]uint32, tlshBuckets)
+	copy(sorted, t.buckets[:tlshBuckets])
+	slices.Sort(sorted)
+	q1, q2, q3 := sorted[tlshBuckets/4-1], sorted[tlshBuckets/2-1], sorted[tlshBuckets*3/4-1]
+	if q3 == 0 {
+		return "", ErrTLSHNotEnoughData
+	}
+
+	// the header consists of the checksum, the length
+	// capturing value, and the quartile ratios. Each
+	// header byte has the nibbles swapped
+	b := make([]byte, 0, 3+tlshCodeSize)
+	b = append(b, swapNibbles(t.checksum))
+	b = append(b, swapNibbles(lvalue(t.n)))
+	q1ratio := byte((q1 * 100 / q3) % 16)
+	q2ratio := byte((q2 * 100 / q3) % 16)
+	b = append(b, q1ratio<<4|q2ratio)
+
+	// the body encodes each bucket in two bits depending
+	// on the quartile the bucket count falls into. The
+	// body is emitted in the reverse order
+	for i := tlshCodeSize - 1; i >= 0; i-- {
+		var h byte
+		for j := 0; j < 4; j++ {
+			k := t.buckets[4*i+j]
+			switch {
+			case q3 < k:
+				h += 3 << (j * 2)
+			case q2 < k:
+				h += 2 << (j * 2)
+			case q1 < k:
+				h += 1 << (j * 2)
+			}
+		}
+		b = append(b, h)
+	}
+
+	return tlshVersion + strings.ToUpper(hex.EncodeToString(b)), nil
+}
+
+// TLSHDigest computes the TLSH digest of the given data.
+func TLSHDigest(b []byte) (string, error) {
+	t := NewTLSH()
+	_, _ = t.Write(b)
+	return t.Digest()
+}
+
+// TLSHDiff computes the distance between two TLSH digests. The distance
+// of 0 indicates identical inputs, while larger distances denote less
+// similar inputs. Distances below 100 usually point to related content.
+func TLSHDiff(a, b string) (int, error) {
+	h1, err := parseTLSH(a)
+	if err != nil {
+		return 0, err
+	}
+	h2, err := parseTLSH(b)
+	if err != nil {
+		return 0, err
+	}
+
+	var diff int
+
+	switch ldiff := modDiff(int(swapNibbles(h1[1])), int(swapNibbles(h2[1])), 256); ldiff {
+	case 0:
+	case 1:
+		diff = 1
+	default:
+		diff += ldiff * 12
+	}
+
+	q1diff := modDiff(int(h1[2]>>4), int(h2[2]>>4), 16)
+	if q1diff <= 1 {
+		diff += q1diff
+	} else {
+		diff += (q1diff - 1) * 12
+	}
+	q2diff := modDiff(int(h1[2]&0x0f), int(h2[2]&0x0f), 16)
+	if q2diff <= 1 {
+		diff += q2diff
+	} else {
+		diff += (q2diff - 1) * 12
+	}
+
+	if h1[0] != h2[0] {
+		diff++
+	}
+
+	for i := 3; i < len(h1); i++ {
+		x, y := h1[i], h2[i]
+		for j := 0; j < 4; j++ {
+			d := int(x&3) - int(y&3)
+			if d < 0 {
+				d = -d
+			}
+			if d == 3 {
+				d = 6
+			}
+			diff += d
+			x >>= 2
+			y >>= 2
+		}
+	}
+
+	return diff, nil
+}
+
+func parseTLSH(s string) ([]byte, error) {
+	s = strings.TrimPrefix(strings.ToUpper(s), tlshVersion)
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 3+tlshCodeSize {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTLSH, s)
+	}
+	return b, nil
+}
+
+func pearson(salt, i, j, k byte) byte {
+	h := tlshPearson[salt]
+	h = tlshPearson[h^i]
+	h = tlshPearson[h^j]
+	return tlshPearson[h^k]
+}
+
+// logarithm constants as truncated by the reference implementation
+const (
+	log15 = 0.4054651
+	log13 = 0.26236426
+	log11 = 0.095310180
+)
+
+// lvalue captures the logarithm of the data length in a single byte.
+func lvalue(n int) byte {
+	var i float64
+	l := float64(n)
+	switch {
+	case n <= 656:
+		i = math.Floor(math.Log(l) / log15)
+	case n <= 3199:
+		i = math.Floor(math.Log(l)/log13 - 8.72777)
+	default:
+		i = math.Floor(math.Log(l)/log11 - 62.5472)
+	}
+	return byte(int(i) & 0xff)
+}
+
+func modDiff(x, y, r int) int {
+	var dl, dr int
+	if y > x {
+		dl = y - x
+		dr = x + r - y
+	} else {
+		dl = x - y
+		dr = y + r - x
+	}
+	return min(dl, dr)
+}
+
+func swapNibbles(b byte) byte { return b<<4 | b>>4 }
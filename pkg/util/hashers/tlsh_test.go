@@ -0,0 +1,145 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hashers
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSHDigest(t *testing.T) {
+	_, err := TLSHDigest([]byte("too short"))
+	require.ErrorIs(t, err, ErrTLSHNotEnoughData)
+	_, err = TLSHDigest(bytes.Repeat([]byte{'a'}, 1024))
+	require.ErrorIs(t, err, ErrTLSHNotEnoughData)
+
+	b := randBytes(1, 10000)
+	digest, err := TLSHDigest(b)
+	require.NoError(t, err)
+	assert.Regexp(t, `^T1[0-9A-F]{70}$`, digest)
+
+	// feeding the data in chunks yields the same digest
+	h := NewTLSH()
+	for i := 0; i < len(b); i += 333 {
+		_, err := h.Write(b[i:min(i+333, len(b))])
+		require.NoError(t, err)
+	}
+	d, err := h.Digest()
+	require.NoError(t, err)
+	assert.Equal(t, digest, d)
+}
+
+// tlshVectors are the digests of the test files shipped with the TLSH
+// distribution as produced by the reference implementation.
+var tlshVectors = map[string]string{
+	"test_file_1": "T18ED02202FC30802303A002B03B33300FC30A82F83008C2FA000A0080B8BA0E02CCA0C3",
+	"test_file_2": "T1B2319634F5C033244EB792AA3168A366E737553DA305A28440CE842D7B57A2CC63B6EC",
+	"test_file_3": "T1EA31834386C503B62A920319BA4F92D3BF6FC2B863384515A4EA5638450BC1E9376AE9",
+	"test_file_5": "T1E1D1B7337E4E03044FE22379D7C9C95ED66CE42426C39759CCEA9A2AF516838E723364",
+	"test_file_6": "T12FE1A7723E8603145BF222F9979ACC7EF74CE4242BD3A7D49899F919F146814C3233A8",
+}
+
+func TestTLSHDigestVectors(t *testing.T) {
+	for file, want := range tlshVectors {
+		t.Run(file, func(t *testing.T) {
+			b, err := os.ReadFile(filepath.Join("_fixtures", "tlsh", file))
+			require.NoError(t, err)
+			digest, err := TLSHDigest(b)
+			require.NoError(t, err)
+			assert.Equal(t, want, digest)
+		})
+	}
+}
+
+func TestTLSHDiffVectors(t *testing.T) {
+	var tests = []struct {
+		a, b string
+		diff int
+	}{
+		{"test_file_1", "test_file_1", 0},
+		{"test_file_1", "test_file_2", 418},
+		{"test_file_3", "test_file_1", 374},
+	}
+
+	for _, tt := range tests {
+		diff, err := TLSHDiff(tlshVectors[tt.a], tlshVectors[tt.b])
+		require.NoError(t, err)
+		assert.Equal(t, tt.diff, diff, "%s/%s", tt.a, tt.b)
+	}
+}
+
+func TestTLSHDiff(t *testing.T) {
+	b := randBytes(1, 10000)
+	d1, err := TLSHDigest(b)
+	require.NoError(t, err)
+
+	c := append([]byte{}, b...)
+	copy(c[5000:], "some modification in the middle of the buffer")
+	d2, err := TLSHDigest(c)
+	require.NoError(t, err)
+
+	d3, err := TLSHDigest(randBytes(2, 10000))
+	require.NoError(t, err)
+
+	diff, err := TLSHDiff(d1, d1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, diff)
+
+	diff, err = TLSHDiff(d1, d2)
+	require.NoError(t, err)
+	assert.True(t, diff > 0 && diff < 50, "unexpected distance %d", diff)
+
+	diff, err = TLSHDiff(d1, d3)
+	require.NoError(t, err)
+	assert.True(t, diff > 100, "unexpected distance %d", diff)
+
+	// the version prefix is optional
+	diff, err = TLSHDiff(d1, d1[2:])
+	require.NoError(t, err)
+	assert.Equal(t, 0, diff)
+
+	_, err = TLSHDiff(d1, "T1ZZ")
+	require.ErrorIs(t, err, ErrInvalidTLSH)
+}
+
+func TestModDiff(t *testing.T) {
+	assert.Equal(t, 2, modDiff(1, 15, 16))
+	assert.Equal(t, 3, modDiff(4, 7, 16))
+	assert.Equal(t, 0, modDiff(9, 9, 256))
+}
+
+func TestTLSHLvalue(t *testing.T) {
+	// upper bounds of the data length for each length capturing
+	// value as listed in the topval table of the reference TLSH
+	// implementation
+	topval := []int{
+		1, 2, 3, 5, 7, 11, 17, 25, 38, 57, 86, 129, 194, 291, 437, 656,
+		854, 1110, 1443, 1876, 2439, 3171, 3475, 3823, 4205, 4626, 5088,
+		5597, 6157, 6772, 7450, 8195, 9014, 9916, 10907,
+	}
+	for i, n := range topval {
+		assert.Equal(t, byte(i), lvalue(n), "length %d", n)
+		assert.Equal(t, byte(i+1), lvalue(n+1), "length %d", n+1)
+	}
+}
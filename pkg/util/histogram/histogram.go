@@ -0,0 +1,176 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package histogram provides expvar-compatible histograms for tracking
+// the distribution of observed values, such as operation latencies.
+package histogram
+
+import (
+	"encoding/json"
+	"expvar"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LatencyBuckets are the default bucket upper bounds, in seconds, suitable for measuring
+// latencies that range from a few microseconds up to several seconds.
+var LatencyBuckets = []float64{.00001, .00005, .0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Bucket represents the cumulative number of observations less than or equal to the upper bound.
+type Bucket struct {
+	UpperBound float64 `json:"le"`
+	Count      uint64  `json:"count"`
+}
+
+// Snapshot is the point-in-time state of the histogram.
+type Snapshot struct {
+	Buckets []Bucket `json:"buckets"`
+	Count   uint64   `json:"count"`
+	Sum     float64  `json:"sum"`
+}
+
+// Histogram counts observations in configurable buckets. Observations
+// are recorded without locking, so the histogram is suitable for hot
+// paths. Histogram satisfies the expvar.Var interface.
+type Histogram struct {
+	bounds []float64
+	// counts holds non-cumulative counters for each bucket
+	// plus the last counter for the implicit +Inf bucket
+	counts []atomic.Uint64
+	count  atomic.Uint64
+	sum    atomic.Uint64 // float64 bits
+}
+
+// NewHistogram creates a histogram with the given bucket upper bounds.
+func NewHistogram(buckets []float64) *Histogram {
+	bounds := make([]float64, len(buckets))
+	copy(bounds, buckets)
+	sort.Float64s(bounds)
+	return &Histogram{bounds: bounds, counts: make([]atomic.Uint64, len(bounds)+1)}
+}
+
+// New creates a histogram and publishes it under the given expvar name.
+func New(name string, buckets []float64) *Histogram {
+	h := NewHistogram(buckets)
+	expvar.Publish(name, h)
+	return h
+}
+
+// Observe records the value in the histogram.
+func (h *Histogram) Observe(v float64) {
+	h.counts[sort.SearchFloat64s(h.bounds, v)].Add(1)
+	h.count.Add(1)
+	for {
+		old := h.sum.Load()
+		if h.sum.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			break
+		}
+	}
+}
+
+// Since records the number of seconds elapsed since the start time.
+func (h *Histogram) Since(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// Snapshot returns the current state of the histogram with cumulative bucket counts.
+func (h *Histogram) Snapshot() Snapshot {
+	s := Snapshot{Buckets: make([]Bucket, len(h.bounds))}
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i].Load()
+		s.Buckets[i] = Bucket{UpperBound: bound, Count: cumulative}
+	}
+	s.Count = h.count.Load()
+	s.Sum = math.Float64frombits(h.sum.Load())
+	return s
+}
+
+// String returns the JSON representation of the histogram snapshot.
+func (h *Histogram) String() string {
+	b, err := json.Marshal(h.Snapshot())
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+// Map is the collection of histograms keyed by the label value. Map
+// satisfies the expvar.Var interface.
+type Map struct {
+	mu      sync.RWMutex
+	buckets []float64
+	m       map[string]*Histogram
+}
+
+// NewMap creates the histogram map and publishes it under the given expvar name.
+func NewMap(name string, buckets []float64) *Map {
+	m := &Map{buckets: buckets, m: make(map[string]*Histogram)}
+	expvar.Publish(name, m)
+	return m
+}
+
+// Get returns the histogram for the key. The histogram is created if it doesn't exist.
+func (m *Map) Get(key string) *Histogram {
+	m.mu.RLock()
+	h, ok := m.m[key]
+	m.mu.RUnlock()
+	if ok {
+		return h
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if h, ok = m.m[key]; !ok {
+		h = NewHistogram(m.buckets)
+		m.m[key] = h
+	}
+	return h
+}
+
+// Do calls fn for each histogram in the map in the lexicographical order of keys.
+func (m *Map) Do(fn func(key string, h *Histogram)) {
+	m.mu.RLock()
+	keys := make([]string, 0, len(m.m))
+	for k := range m.m {
+		keys = append(keys, k)
+	}
+	m.mu.RUnlock()
+	sort.Strings(keys)
+	for _, k := range keys {
+		m.mu.RLock()
+		h := m.m[k]
+		m.mu.RUnlock()
+		fn(k, h)
+	}
+}
+
+// String returns the JSON representation of all histograms in the map.
+func (m *Map) String() string {
+	snapshots := make(map[string]Snapshot)
+	m.Do(func(key string, h *Histogram) {
+		snapshots[key] = h.Snapshot()
+	})
+	b, err := json.Marshal(snapshots)
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package histogram
+
+import (
+	"encoding/json"
+	"expvar"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogram(t *testing.T) {
+	h := New("test.histogram", []float64{1, 0.1, 0.5})
+	require.NotNil(t, expvar.Get("test.histogram"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.Observe(0.05)
+			h.Observe(0.3)
+			h.Observe(2)
+		}()
+	}
+	wg.Wait()
+
+	s := h.Snapshot()
+	assert.Equal(t, uint64(30), s.Count)
+	assert.InDelta(t, 23.5, s.Sum, 1e-9)
+	require.Len(t, s.Buckets, 3)
+	assert.Equal(t, Bucket{UpperBound: 0.1, Count: 10}, s.Buckets[0])
+	assert.Equal(t, Bucket{UpperBound: 0.5, Count: 20}, s.Buckets[1])
+	assert.Equal(t, Bucket{UpperBound: 1, Count: 20}, s.Buckets[2])
+
+	var js Snapshot
+	require.NoError(t, json.Unmarshal([]byte(h.String()), &js))
+	assert.Equal(t, s, js)
+}
+
+func TestMap(t *testing.T) {
+	m := NewMap("test.histogram.map", LatencyBuckets)
+	m.Get("http").Observe(0.002)
+	m.Get("elasticsearch").Observe(0.02)
+	m.Get("http").Observe(0.004)
+
+	var keys []string
+	m.Do(func(key string, h *Histogram) { keys = append(keys, key) })
+	assert.Equal(t, []string{"elasticsearch", "http"}, keys)
+	assert.Equal(t, uint64(2), m.Get("http").Snapshot().Count)
+
+	var js map[string]Snapshot
+	require.NoError(t, json.Unmarshal([]byte(m.String()), &js))
+	assert.Len(t, js, 2)
+}
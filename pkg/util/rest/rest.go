@@ -19,24 +19,47 @@
 package rest
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
+	"fmt"
 	"github.com/rabbitstack/fibratus/pkg/api"
+	"github.com/rabbitstack/fibratus/pkg/config"
+	tlsutil "github.com/rabbitstack/fibratus/pkg/util/tls"
 	"io"
 	"net"
 	"net/http"
+	"net/url"
 	"path"
 	"strings"
 	"time"
 )
 
-var transport *http.Transport
+// maxEventSize is the maximum size of the single line in the event stream
+const maxEventSize = 4 * 1024 * 1024
 
 type opts struct {
 	addr        string
 	uri         string
 	contentType string
 	timeout     time.Duration
+	query       url.Values
+	token       string
+	tlsConfig   *tls.Config
+	transport   *http.Transport
+	err         error
+}
+
+// StatusError is returned when the server responds with the unexpected status code.
+type StatusError struct {
+	Status string
+	Body   string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %s: %s", e.Status, e.Body)
 }
 
 // Option represents the option for the HTTP client.
@@ -47,14 +70,31 @@ func WithTransport(addr string) Option {
 	return func(o *opts) {
 		o.addr = addr
 		if strings.HasPrefix(addr, `npipe:///`) {
-			transport = &http.Transport{
+			o.transport = &http.Transport{
 				DialContext: api.DialPipe(addr),
 			}
 		} else {
-			transport = &http.Transport{
+			o.transport = &http.Transport{
 				DialContext: (&net.Dialer{}).DialContext,
 			}
 		}
+		o.transport.TLSClientConfig = o.tlsConfig
+	}
+}
+
+// WithAPIConfig sets the transport from the API server configuration.
+// If the API server is serving over TLS, the client verifies the server
+// certificate against the configured certificate authority and presents
+// the same certificate for mutual TLS authentication. The bearer token is
+// sent along with the request if configured.
+func WithAPIConfig(c config.APIConfig) Option {
+	return func(o *opts) {
+		WithTransport(c.Transport)(o)
+		o.token = c.Token
+		if c.IsTLSEnabled() {
+			o.tlsConfig, o.err = tlsutil.MakeConfig(c.TLSCert, c.TLSKey, c.TLSCA, false)
+			o.transport.TLSClientConfig = o.tlsConfig
+		}
 	}
 }
 
@@ -65,6 +105,13 @@ func WithURI(uri string) Option {
 	}
 }
 
+// WithQuery sets the query parameters of the request URL.
+func WithQuery(query url.Values) Option {
+	return func(o *opts) {
+		o.query = query
+	}
+}
+
 // WithContentType sets the content type header for the HTTP requests.
 func WithContentType(contentType string) Option {
 	return func(o *opts) {
@@ -83,7 +130,10 @@ func request(method string, options ...Option) ([]byte, error) {
 		opt(&opts)
 	}
 
-	if transport == nil {
+	if opts.err != nil {
+		return nil, opts.err
+	}
+	if opts.transport == nil {
 		return nil, errors.New("transport is not initialized")
 	}
 
@@ -92,26 +142,17 @@ func request(method string, options ...Option) ([]byte, error) {
 		timeout = time.Second * 10
 	}
 
-	contentType := opts.contentType
-	if contentType == "" {
-		contentType = "application/json"
-	}
-
 	client := http.Client{
-		Transport: transport,
+		Transport: opts.transport,
 		Timeout:   timeout,
 	}
 
-	scheme := "http://"
-	addr := strings.TrimPrefix(opts.addr, `npipe:///`)
-
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer cancel()
-	req, err := http.NewRequestWithContext(ctx, method, scheme+path.Join(addr, opts.uri), nil)
+	req, err := newRequest(ctx, method, opts)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("Content-Type", contentType)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -124,3 +165,99 @@ func request(method string, options ...Option) ([]byte, error) {
 	}
 	return body, nil
 }
+
+// Stream performs the GET request and consumes the Server-Sent Events
+// stream. The function is invoked for each received message with the
+// event type and the event data. Streaming stops when the context is
+// canceled, the server closes the stream, or the function returns an
+// error.
+func Stream(ctx context.Context, fn func(typ string, data []byte) error, options ...Option) error {
+	var opts opts
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	if opts.err != nil {
+		return opts.err
+	}
+	if opts.transport == nil {
+		return errors.New("transport is not initialized")
+	}
+
+	client := http.Client{Transport: opts.transport}
+
+	req, err := newRequest(ctx, http.MethodGet, opts)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &StatusError{Status: resp.Status, Body: strings.TrimSpace(string(body))}
+	}
+
+	var typ string
+	var data []byte
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		switch {
+		case len(line) == 0:
+			// blank line dispatches the event
+			if data != nil {
+				if err := fn(typ, data); err != nil {
+					return err
+				}
+			}
+			typ, data = "", nil
+		case line[0] == ':':
+			// comment line used for keepalives
+		case bytes.HasPrefix(line, []byte("event:")):
+			typ = string(bytes.TrimSpace(line[6:]))
+		case bytes.HasPrefix(line, []byte("data:")):
+			if data != nil {
+				data = append(data, '\n')
+			}
+			data = append(data, bytes.TrimPrefix(line[5:], []byte(" "))...)
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+
+	return nil
+}
+
+func newRequest(ctx context.Context, method string, opts opts) (*http.Request, error) {
+	contentType := opts.contentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	scheme := "http://"
+	if opts.tlsConfig != nil {
+		scheme = "https://"
+	}
+	addr := strings.TrimPrefix(opts.addr, `npipe:///`)
+
+	uri := scheme + path.Join(addr, opts.uri)
+	if len(opts.query) > 0 {
+		uri += "?" + opts.query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", contentType)
+	if opts.token != "" {
+		req.Header.Add("Authorization", "Bearer "+opts.token)
+	}
+	return req, nil
+}
@@ -27,6 +27,7 @@ import (
 	"net/http/httptest"
 	"os/user"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -47,6 +48,31 @@ func TestGet(t *testing.T) {
 	assert.Equal(t, "test", string(resp))
 }
 
+func TestGetConcurrent(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/config", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := w.Write([]byte("test")); err != nil {
+			t.Error(err)
+		}
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	// each request builds its own transport
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := Get(WithURI("config"), WithTransport(fmt.Sprintf("localhost:%s", port(srv.URL))))
+			assert.NoError(t, err)
+			assert.Equal(t, "test", string(resp))
+		}()
+	}
+	wg.Wait()
+}
+
 func TestGetPipe(t *testing.T) {
 	usr, err := user.Current()
 	require.NoError(t, err)
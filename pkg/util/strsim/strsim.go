@@ -0,0 +1,158 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package strsim implements string similarity metrics used
+// to detect typo-squatted names, such as svch0st.exe.
+package strsim
+
+// Levenshtein computes the minimum number of single-character
+// insertions, deletions, or substitutions required to change
+// one string into the other. The distance is measured in runes.
+func Levenshtein(a, b string) int {
+	return levenshtein([]rune(a), []rune(b), nil)
+}
+
+// MinLevenshtein returns the smallest Levenshtein distance between
+// the string and any of the candidates or -1 if there are no candidates.
+// The buffer holding the distance row is reused across candidates.
+func MinLevenshtein(s string, candidates []string) int {
+	r1 := []rune(s)
+	row := make([]int, len(r1)+1)
+	dist := -1
+	for _, c := range candidates {
+		d := levenshtein(r1, []rune(c), row)
+		if dist == -1 || d < dist {
+			dist = d
+		}
+		if dist == 0 {
+			break
+		}
+	}
+	return dist
+}
+
+func levenshtein(r1, r2 []rune, row []int) int {
+	if len(r1) == 0 {
+		return len(r2)
+	}
+	if len(r2) == 0 {
+		return len(r1)
+	}
+	if cap(row) < len(r1)+1 {
+		row = make([]int, len(r1)+1)
+	}
+	row = row[:len(r1)+1]
+	for i := range row {
+		row[i] = i
+	}
+	for x := 1; x <= len(r2); x++ {
+		diag := row[0]
+		row[0] = x
+		for y := 1; y <= len(r1); y++ {
+			prev := row[y]
+			cost := 1
+			if r1[y-1] == r2[x-1] {
+				cost = 0
+			}
+			row[y] = min(row[y]+1, row[y-1]+1, diag+cost)
+			diag = prev
+		}
+	}
+	return row[len(r1)]
+}
+
+// JaroWinkler computes the Jaro-Winkler similarity of two strings.
+// The similarity is in the range [0, 1] where 1 means the strings
+// are identical. Strings sharing the common prefix are rated higher.
+func JaroWinkler(a, b string) float64 {
+	r1, r2 := []rune(a), []rune(b)
+	sim := jaro(r1, r2)
+	if sim <= 0.7 {
+		return sim
+	}
+	// the common prefix is limited to four characters
+	var prefix int
+	for prefix < min(len(r1), len(r2), 4) && r1[prefix] == r2[prefix] {
+		prefix++
+	}
+	return sim + float64(prefix)*0.1*(1-sim)
+}
+
+// MaxJaroWinkler returns the highest Jaro-Winkler similarity
+// between the string and any of the candidates.
+func MaxJaroWinkler(s string, candidates []string) float64 {
+	var sim float64
+	for _, c := range candidates {
+		sim = max(sim, JaroWinkler(s, c))
+		if sim == 1 {
+			break
+		}
+	}
+	return sim
+}
+
+func jaro(r1, r2 []rune) float64 {
+	if len(r1) == 0 && len(r2) == 0 {
+		return 1
+	}
+	if len(r1) == 0 || len(r2) == 0 {
+		return 0
+	}
+
+	window := max(len(r1), len(r2))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	m1 := make([]bool, len(r1))
+	m2 := make([]bool, len(r2))
+
+	var matches int
+	for i := range r1 {
+		lo, hi := max(0, i-window), min(len(r2), i+window+1)
+		for j := lo; j < hi; j++ {
+			if m2[j] || r1[i] != r2[j] {
+				continue
+			}
+			m1[i], m2[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	// count half transpositions
+	var transpositions, k int
+	for i := range r1 {
+		if !m1[i] {
+			continue
+		}
+		for !m2[k] {
+			k++
+		}
+		if r1[i] != r2[k] {
+			transpositions++
+		}
+		k++
+	}
+
+	m := float64(matches)
+	return (m/float64(len(r1)) + m/float64(len(r2)) + (m-float64(transpositions/2))/m) / 3
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package strsim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevenshtein(t *testing.T) {
+	var tests = []struct {
+		a, b string
+		dist int
+	}{
+		{"svchost.exe", "svchost.exe", 0},
+		{"svch0st.exe", "svchost.exe", 1},
+		{"lsasss.exe", "lsass.exe", 1},
+		{"scvhost.exe", "svchost.exe", 2},
+		{"kitten", "sitting", 3},
+		{"", "lsass.exe", 9},
+		{"lsass.exe", "", 9},
+		{"ѕvchost.exe", "svchost.exe", 1},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.dist, Levenshtein(tt.a, tt.b), "%s -> %s", tt.a, tt.b)
+	}
+}
+
+func TestMinLevenshtein(t *testing.T) {
+	procs := []string{"smss.exe", "csrss.exe", "lsass.exe", "svchost.exe", "services.exe"}
+
+	assert.Equal(t, 1, MinLevenshtein("lsasss.exe", procs))
+	assert.Equal(t, 0, MinLevenshtein("csrss.exe", procs))
+	assert.Equal(t, 1, MinLevenshtein("scrss.exe", []string{"csrss.exe", "scrs.exe"}))
+	assert.Equal(t, -1, MinLevenshtein("lsass.exe", nil))
+}
+
+func TestJaroWinkler(t *testing.T) {
+	var tests = []struct {
+		a, b string
+		sim  float64
+	}{
+		{"svchost.exe", "svchost.exe", 1},
+		{"MARTHA", "MARHTA", 0.961},
+		{"DIXON", "DICKSONX", 0.813},
+		{"DWAYNE", "DUANE", 0.84},
+		{"abc", "xyz", 0},
+		{"", "", 1},
+		{"", "abc", 0},
+	}
+
+	for _, tt := range tests {
+		assert.InDelta(t, tt.sim, JaroWinkler(tt.a, tt.b), 0.001, "%s -> %s", tt.a, tt.b)
+	}
+
+	assert.Greater(t, JaroWinkler("svch0st.exe", "svchost.exe"), 0.9)
+	assert.InDelta(t, 1, MaxJaroWinkler("lsass.exe", []string{"csrss.exe", "lsass.exe"}), 0.001)
+	assert.Zero(t, MaxJaroWinkler("lsass.exe", nil))
+}
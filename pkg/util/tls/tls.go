@@ -37,7 +37,7 @@ func MakeConfig(certFile, keyFile, caFile string, insecureSkipVerify bool) (*tls
 	}
 
 	// load certificate/key
-	if certFile != "" && keyFile == "" {
+	if certFile != "" && keyFile != "" {
 		var err error
 		cert, err = tls.LoadX509KeyPair(certFile, keyFile)
 		if err != nil {
@@ -61,3 +61,32 @@ func MakeConfig(certFile, keyFile, caFile string, insecureSkipVerify bool) (*tls
 
 	return tlsConfig, nil
 }
+
+// MakeServerConfig builds a TLS server config from the certificate and private key files.
+// If the CA file is given, client certificates are verified against the certificate
+// authority when presented. Clients without the certificate can still connect, so it
+// is up to the handlers to require the verified certificate.
+func MakeServerConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if caFile != "" {
+		cpool := x509.NewCertPool()
+		caCert, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		ok := cpool.AppendCertsFromPEM(caCert)
+		if !ok {
+			return nil, fmt.Errorf("fail to load certificate authority: %s", caFile)
+		}
+		tlsConfig.ClientCAs = cpool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
+}
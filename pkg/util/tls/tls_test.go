@@ -0,0 +1,140 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCert generates the self-signed certificate and
+// writes the certificate and the private key PEM files.
+func writeCert(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fibratus"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	b, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: b}), 0600))
+	return certFile, keyFile
+}
+
+func TestMakeConfig(t *testing.T) {
+	certFile, keyFile := writeCert(t)
+
+	c, err := MakeConfig("", "", "", false)
+	require.NoError(t, err)
+	assert.Nil(t, c)
+
+	c, err = MakeConfig(certFile, keyFile, "", false)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Len(t, c.Certificates, 1)
+	assert.Nil(t, c.RootCAs)
+
+	c, err = MakeConfig(certFile, keyFile, certFile, true)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Len(t, c.Certificates, 1)
+	assert.NotNil(t, c.RootCAs)
+	assert.True(t, c.InsecureSkipVerify)
+
+	c, err = MakeConfig("", "", certFile, false)
+	require.NoError(t, err)
+	assert.Empty(t, c.Certificates)
+	assert.NotNil(t, c.RootCAs)
+
+	_, err = MakeConfig(certFile, filepath.Join(t.TempDir(), "missing.pem"), "", false)
+	require.Error(t, err)
+}
+
+func TestMakeServerConfig(t *testing.T) {
+	certFile, keyFile := writeCert(t)
+
+	c, err := MakeServerConfig(certFile, keyFile, "")
+	require.NoError(t, err)
+	assert.Len(t, c.Certificates, 1)
+	assert.Equal(t, tls.NoClientCert, c.ClientAuth)
+
+	c, err = MakeServerConfig(certFile, keyFile, certFile)
+	require.NoError(t, err)
+	assert.NotNil(t, c.ClientCAs)
+	assert.Equal(t, tls.VerifyClientCertIfGiven, c.ClientAuth)
+
+	l, err := tls.Listen("tcp", "127.0.0.1:0", c)
+	require.NoError(t, err)
+	defer l.Close()
+
+	states := make(chan tls.ConnectionState, 2)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			tc := conn.(*tls.Conn)
+			if err := tc.Handshake(); err == nil {
+				states <- tc.ConnectionState()
+			}
+			tc.Close()
+		}
+	}()
+
+	// clients without the certificate can connect, but
+	// the connection doesn't carry the verified chains
+	conn, err := tls.Dial("tcp", l.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	require.NoError(t, conn.Handshake())
+	conn.Close()
+	assert.Empty(t, (<-states).VerifiedChains)
+
+	cc, err := MakeConfig(certFile, keyFile, "", true)
+	require.NoError(t, err)
+	conn, err = tls.Dial("tcp", l.Addr().String(), cc)
+	require.NoError(t, err)
+	require.NoError(t, conn.Handshake())
+	conn.Close()
+	assert.NotEmpty(t, (<-states).VerifiedChains)
+}
@@ -33,6 +33,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/rabbitstack/fibratus/pkg/alertsender"
 	"github.com/rabbitstack/fibratus/pkg/event/params"
+	"github.com/rabbitstack/fibratus/pkg/health"
+	hconfig "github.com/rabbitstack/fibratus/pkg/health/config"
 	"github.com/rabbitstack/fibratus/pkg/sys"
 	"github.com/rabbitstack/fibratus/pkg/util/signature"
 	"github.com/rabbitstack/fibratus/pkg/util/va"
@@ -136,6 +138,18 @@ func NewScanner(psnap ps.Snapshotter, config config.Config) (Scanner, error) {
 		return nil, fmt.Errorf("couldn't compile yara rules: %v", err)
 	}
 
+	health.Register("yara", func(hconfig.Config) health.Component {
+		return health.Component{
+			Status: health.Healthy,
+			Ready:  true,
+			Details: map[string]any{
+				"rules":        rulesInCompiler.Value(),
+				"scans":        totalScans.Value(),
+				"rule_matches": ruleMatches.Value(),
+			},
+		}
+	})
+
 	return &scanner{
 		c:      c,
 		rules:  rules,
@@ -162,6 +176,58 @@ func (s scanner) newInternalScanner() (*yara.Scanner, error) {
 	return sn, nil
 }
 
+// Compile compiles the rules from the rule paths and rule strings
+// without setting up the scanner. Each rule file is compiled in
+// isolation, so all erroneous files are reported at once.
+func Compile(config config.Config) ([]CompileError, error) {
+	var errors []CompileError
+	compile := func(add func(c *yara.Compiler) error) error {
+		c, err := yara.NewCompiler()
+		if err != nil {
+			return fmt.Errorf("unable to create yara compiler: %v", err)
+		}
+		defer c.Destroy()
+		if err := add(c); err != nil && len(c.Errors) == 0 {
+			return err
+		}
+		for _, msg := range c.Errors {
+			errors = append(errors, CompileError{Filename: msg.Filename, Line: msg.Line, Text: msg.Text})
+		}
+		return nil
+	}
+
+	for _, dir := range config.Rule.Paths {
+		err := filepath.Walk(dir.Path, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if filepath.Ext(path) != ".yar" {
+				return nil
+			}
+			return compile(func(c *yara.Compiler) error {
+				f, err := os.Open(path)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				return c.AddFile(f, dir.Namespace)
+			})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't walk %s path: %v", dir.Path, err)
+		}
+	}
+
+	for _, s := range config.Rule.Strings {
+		err := compile(func(c *yara.Compiler) error { return c.AddString(s.String, s.Namespace) })
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return errors, nil
+}
+
 func parseCompilerErrors(errors []yara.CompilerMessage) error {
 	errs := make([]error, len(errors))
 	for i, err := range errors {
@@ -405,28 +471,30 @@ func (s scanner) emit(matches yara.MatchRules, e *event.Event) error {
 			return err
 		}
 
+		log.Infof("sending alert: [%s]. Text: %s Event: %s", title, text, e.String())
+
+		alert := alertsender.NewAlert(
+			title,
+			text,
+			m.Tags,
+			m.SeverityFromScore(),
+		)
+
+		id := m.ID()
+		// generate id if it doesn't exist in meta fields
+		if id == "" {
+			id = uuid.New().String()
+		}
+		alert.ID = id
+		alert.Events = []*event.Event{e}
+		alert.Labels = m.Labels()
+		alert.Description = m.Description()
+
+		alertsender.Record(alert)
+
 		// send alert via all registered alert senders
 		for _, sender := range senders {
-			log.Infof("sending alert: [%s]. Text: %s Event: %s", title, text, e.String())
-
-			alert := alertsender.NewAlert(
-				title,
-				text,
-				m.Tags,
-				m.SeverityFromScore(),
-			)
-
-			id := m.ID()
-			// generate id if it doesn't exist in meta fields
-			if id == "" {
-				id = uuid.New().String()
-			}
-			alert.ID = id
-			alert.Events = []*event.Event{e}
-			alert.Labels = m.Labels()
-			alert.Description = m.Description()
-
-			err := sender.Send(alert)
+			err := alertsender.Send(sender, alert)
 			if err != nil {
 				return fmt.Errorf("unable to emit YARA alert via [%s] sender: %v", sender.Type(), err)
 			}
@@ -31,3 +31,8 @@ import (
 func NewScanner(psnap ps.Snapshotter, config config.Config) (Scanner, error) {
 	return nil, errs.ErrFeatureUnsupported("yara")
 }
+
+// Compile returns unsupported scanner error.
+func Compile(config config.Config) ([]CompileError, error) {
+	return nil, errs.ErrFeatureUnsupported("yara")
+}
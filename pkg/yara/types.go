@@ -32,3 +32,14 @@ type Scanner interface {
 	// Close disposes any resources allocated by the scanner.
 	Close()
 }
+
+// CompileError describes the error raised by the compiler
+// when the rule is syntactically or semantically invalid.
+type CompileError struct {
+	// Filename is the path of the rule file. It is empty for in-place rule strings.
+	Filename string
+	// Line is the line number where the error is located.
+	Line int
+	// Text is the error message.
+	Text string
+}